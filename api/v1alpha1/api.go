@@ -76,10 +76,12 @@ type AIGatewayRouteSpec struct {
 	// Based on this schema, the ai-gateway will perform the necessary transformation to the
 	// output schema specified in the selected AIServiceBackend during the routing process.
 	//
-	// Currently, the only supported schema is OpenAI as the input schema.
+	// Currently, the supported input schemas are OpenAI and AWSBedrock. With AWSBedrock, the Gateway(s) will receive
+	// the Converse API requests at /model/{modelId}/converse and /model/{modelId}/converse-stream, and the backends
+	// must be either OpenAI or AWSBedrock.
 	//
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:XValidation:rule="self.name == 'OpenAI'"
+	// +kubebuilder:validation:XValidation:rule="self.name == 'OpenAI' || self.name == 'AWSBedrock'"
 	APISchema VersionedAPISchema `json:"schema"`
 	// Rules is the list of AIGatewayRouteRule that this AIGatewayRoute will match the traffic to.
 	// Each rule is a subset of the HTTPRoute in the Gateway API (https://gateway-api.sigs.k8s.io/api-types/httproute/).
@@ -92,8 +94,9 @@ type AIGatewayRouteSpec struct {
 	// if we want to describe the routing behavior based on the model name. The model name is extracted
 	// from the request content before the routing decision.
 	//
-	// How multiple rules are matched is the same as the Gateway API. See for the details:
-	// https://gateway-api.sigs.k8s.io/reference/spec/#gateway.networking.k8s.io%2fv1.HTTPRoute
+	// When multiple rules match the request, the last one in this list is used regardless of how specific
+	// their matches are, so the rules whose matches overlap, e.g. a rule matching a specific tenant and
+	// a catch-all rule of the same model, must be ordered from the least specific to the most specific.
 	//
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MaxItems=128
@@ -201,8 +204,181 @@ type AIGatewayRouteSpec struct {
 	// +optional
 	// +kubebuilder:validation:MaxItems=36
 	LLMRequestCosts []LLMRequestCost `json:"llmRequestCosts,omitempty"`
+
+	// TokenBudgets are the budgets of the request costs enforced locally by the AI Gateway filter, which
+	// limits the token consumption without deploying the global rate limit service of Envoy Gateway.
+	//
+	// Each budget sums up the cost of one of the LLMRequestCosts per key, e.g. per user, over a sliding window.
+	// Once the sum reaches the limit, the subsequent requests of the key are rejected with the status code 429
+	// and the OpenAI error body until enough of the cost falls out of the window. Since the cost is only known
+	// after the response, the consumption can exceed the limit by the cost of the requests in flight.
+	//
+	// The consumption is kept in the memory of each AI Gateway filter instance, so the limit applies per instance.
+	// The consumption of a budget is kept across the configuration updates as long as its name and window are
+	// unchanged.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	TokenBudgets []AIGatewayRouteTokenBudget `json:"tokenBudgets,omitempty"`
+
+	// RequestLimits limits the chat completion requests before they are translated and sent to the backends,
+	// which protects the quotas from a single request consuming too many tokens before the request costs are
+	// even calculated from the response.
+	//
+	// The requests violating the limits are rejected with the status code 400 and the OpenAI error body.
+	//
+	// +optional
+	RequestLimits *AIGatewayRouteRequestLimits `json:"requestLimits,omitempty"`
+
+	// ForceStreamUsage makes the streamed chat completion requests to the OpenAI and AzureOpenAI schema backends
+	// always ask for the token usage with "stream_options.include_usage", so that the LLMRequestCosts are calculated
+	// even when the clients do not ask for it. Otherwise, the cost of such streamed requests is zero.
+	//
+	// The extra chunk carrying the usage is removed from the response to the clients that did not ask for it.
+	//
+	// +optional
+	ForceStreamUsage bool `json:"forceStreamUsage,omitempty"`
+
+	// GatewayAPIKeys makes the AIGatewayRoute only accept the requests with one of the selected GatewayAPIKeys
+	// in the "Authorization: Bearer <key>" header. The requests without a valid key are rejected with the status
+	// code 401, and the key is removed from the request before it is sent to the backend.
+	//
+	// The key is only read from the "Authorization: Bearer <key>" header, so the clients that authenticate with
+	// the other headers, e.g. the AWS SigV4 signed requests of the AWSBedrock schema or the "x-api-key" header of
+	// the Anthropic clients, cannot use this AIGatewayRoute when this is set.
+	//
+	// When not set, the requests are not authenticated by the AI Gateway filter.
+	//
+	// +optional
+	GatewayAPIKeys *AIGatewayRouteGatewayAPIKeys `json:"gatewayAPIKeys,omitempty"`
+}
+
+// AIGatewayRouteGatewayAPIKeys selects the GatewayAPIKeys accepted by the AIGatewayRoute.
+type AIGatewayRouteGatewayAPIKeys struct {
+	// Selector selects the GatewayAPIKeys in the same namespace as the AIGatewayRoute by their labels.
+	// When not set, all the GatewayAPIKeys in the namespace are accepted.
+	//
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
+// AIGatewayRouteRequestLimits limits the chat completion requests. Each limit is not applied when it is not set.
+type AIGatewayRouteRequestLimits struct {
+	// MaxTokens is the maximum number of the output tokens a request can ask for with max_tokens or
+	// max_completion_tokens. The request setting neither of them is given max_completion_tokens of MaxTokens.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxTokens *int64 `json:"maxTokens,omitempty"`
+
+	// MaxTokensAction specifies what happens to the request asking for more output tokens than MaxTokens.
+	//
+	// Clamp lowers max_tokens and max_completion_tokens of the request to MaxTokens.
+	//
+	// Reject rejects the request.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Clamp;Reject
+	// +kubebuilder:default=Clamp
+	MaxTokensAction *MaxTokensAction `json:"maxTokensAction,omitempty"`
+
+	// MaxEstimatedInputTokens is the maximum number of the input tokens of a request, i.e. the messages and the tool
+	// definitions, estimated by the AI Gateway filter without calling the backend. See AIGatewayRouteRuleMatch.EstimatedInputTokens
+	// for the details of the estimation.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxEstimatedInputTokens *int64 `json:"maxEstimatedInputTokens,omitempty"`
+
+	// MaxMessages is the maximum number of the messages of a request.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxMessages *int32 `json:"maxMessages,omitempty"`
+
+	// MaxTools is the maximum number of the tools of a request.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxTools *int32 `json:"maxTools,omitempty"`
+}
+
+// MaxTokensAction specifies what happens to the request asking for more output tokens than the limit.
+type MaxTokensAction string
+
+const (
+	// MaxTokensActionClamp lowers the maximum number of the output tokens of the request to the limit.
+	MaxTokensActionClamp MaxTokensAction = "Clamp"
+	// MaxTokensActionReject rejects the request.
+	MaxTokensActionReject MaxTokensAction = "Reject"
+)
+
+// AIGatewayRouteTokenBudget is the budget of a request cost per key over a sliding window.
+type AIGatewayRouteTokenBudget struct {
+	// Name is the name of the budget, which must be unique within the AIGatewayRoute.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key specifies the key the consumption is tracked per. The requests without the key are not limited.
+	//
+	// +kubebuilder:validation:Required
+	Key AIGatewayRouteTokenBudgetKey `json:"key"`
+
+	// MetadataKey is the metadataKey of the LLMRequestCost whose cost is consumed from the budget,
+	// e.g. the one of the TotalToken type to limit the total number of tokens.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	MetadataKey string `json:"metadataKey"`
+
+	// Limit is the maximum cost of a key within the window.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Limit int64 `json:"limit"`
+
+	// Window is the length of the sliding window, e.g. "1m" or "24h".
+	//
+	// +kubebuilder:validation:Required
+	Window gwapiv1.Duration `json:"window"`
+}
+
+// AIGatewayRouteTokenBudgetKey specifies the key of the token budget.
+//
+// +kubebuilder:validation:XValidation:rule="self.type == 'Header' ? has(self.header) : !has(self.header)", message="header must be set if and only if the type is Header"
+type AIGatewayRouteTokenBudgetKey struct {
+	// Type is the source of the key.
+	//
+	// Header uses the value of the request header specified by Header, e.g. the API key of the client.
+	//
+	// User uses the "user" field of the OpenAI request body. This is not available for the AWSBedrock input schema.
+	//
+	// APIKey uses the name of the GatewayAPIKey the request is authenticated with, which requires GatewayAPIKeys
+	// to be set in the AIGatewayRoute.
+	//
+	// +kubebuilder:validation:Enum=Header;User;APIKey
+	Type TokenBudgetKeyType `json:"type"`
+
+	// Header is the name of the request header whose value is used as the key, e.g. x-api-key.
+	//
+	// +optional
+	Header *gwapiv1.HTTPHeaderName `json:"header,omitempty"`
+}
+
+// TokenBudgetKeyType specifies the source of the key of the token budget.
+type TokenBudgetKeyType string
+
+const (
+	// TokenBudgetKeyTypeHeader uses the value of a request header as the key.
+	TokenBudgetKeyTypeHeader TokenBudgetKeyType = "Header"
+	// TokenBudgetKeyTypeUser uses the "user" field of the OpenAI request body as the key.
+	TokenBudgetKeyTypeUser TokenBudgetKeyType = "User"
+	// TokenBudgetKeyTypeAPIKey uses the name of the GatewayAPIKey of the request as the key.
+	TokenBudgetKeyTypeAPIKey TokenBudgetKeyType = "APIKey"
+)
+
 // AIGatewayRouteRule is a rule that defines the routing behavior of the AIGatewayRoute.
 type AIGatewayRouteRule struct {
 	// BackendRefs is the list of AIServiceBackend that this rule will route the traffic to.
@@ -214,10 +390,64 @@ type AIGatewayRouteRule struct {
 	// +kubebuilder:validation:MaxItems=128
 	BackendRefs []AIGatewayRouteRuleBackendRef `json:"backendRefs,omitempty"`
 
+	// LoadBalancingPolicy specifies how to select a backend among the BackendRefs.
+	// When not set, the backend is selected randomly with the probability proportional to its weight.
+	//
+	// +optional
+	LoadBalancingPolicy *AIGatewayRouteRuleLoadBalancingPolicy `json:"loadBalancingPolicy,omitempty"`
+
+	// CircuitBreaker temporarily excludes the backends in BackendRefs that fail consecutively from the selection.
+	// When not set, all the backends are always selectable.
+	//
+	// +optional
+	CircuitBreaker *AIGatewayRouteRuleCircuitBreaker `json:"circuitBreaker,omitempty"`
+
+	// Fallbacks is the ordered list of AIServiceBackend to retry the request on when the backend selected from
+	// BackendRefs responds with the status code 429 or 5xx. The fallbacks are tried in order until one of them
+	// succeeds, and the request is translated and authenticated for each of them according to its own schema and
+	// BackendSecurityPolicy.
+	//
+	// The fallback request is sent by the AI Gateway filter directly to the backendRef of the AIServiceBackend,
+	// not by Envoy. The address is the cluster IP or the external name of the Service with the port of the
+	// backendRef, or the first endpoint of the Backend resource of Envoy Gateway. Same as Envoy, the request is
+	// sent over TLS only when a BackendTLSPolicy targets the backendRef. The result of the fallback request is
+	// taken into account by the LoadBalancingPolicy and the CircuitBreaker of the backend.
+	//
+	// The streaming requests, e.g. the chat completions with "stream": true, are never retried on the fallbacks
+	// since the response of a fallback is sent back to the client at once. The 429 or 5xx response of the selected
+	// backend is returned to the client as is for them. Use the CircuitBreaker to move the streaming requests away
+	// from the failing backends instead.
+	//
+	// The namespace of each fallback is "local", i.e. the same namespace as the AIGatewayRoute.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Fallbacks []AIGatewayRouteRuleFallbackRef `json:"fallbacks,omitempty"`
+
+	// Mirror is the AIServiceBackend that receives a copy of each chat completion request matching this rule,
+	// e.g. to evaluate a new model or provider on the real traffic before shifting the weights to it.
+	// The copy is translated and authenticated according to the mirror's own schema and BackendSecurityPolicy,
+	// and its response is discarded, i.e. the client only sees the response of the backend selected from BackendRefs.
+	//
+	// The mirrored request is sent by the AI Gateway filter in the background to the backendRef of the
+	// AIServiceBackend in the same way as the Fallbacks. This does not use the request mirroring of the HTTPRoute
+	// since Envoy mirrors the request after it has been translated and authenticated for the selected backend, so the
+	// mirror may have a different schema and BackendSecurityPolicy from the backends in BackendRefs. The copies are
+	// dropped while too many mirrored requests are in flight and time out after 30 seconds, so the mirror may not
+	// receive all the requests.
+	//
+	// The namespace of the mirror is "local", i.e. the same namespace as the AIGatewayRoute.
+	//
+	// +optional
+	Mirror *AIGatewayRouteRuleMirrorRef `json:"mirror,omitempty"`
+
 	// Matches is the list of AIGatewayRouteMatch that this rule will match the traffic to.
 	// This is a subset of the HTTPRouteMatch in the Gateway API. See for the details:
 	// https://gateway-api.sigs.k8s.io/reference/spec/#gateway.networking.k8s.io%2fv1.HTTPRouteMatch
 	//
+	// The rule matches the request when any of the matches matches, i.e. the matches are OR-ed.
+	// When multiple rules match the request, the first one in the list of rules is used.
+	//
 	// +optional
 	// +kubebuilder:validation:MaxItems=128
 	Matches []AIGatewayRouteRuleMatch `json:"matches,omitempty"`
@@ -241,22 +471,232 @@ type AIGatewayRouteRuleBackendRef struct {
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:default=1
 	Weight int `json:"weight,omitempty"`
+
+	// ModelNameOverride is the model name sent to this backend instead of the model name in the request.
+	// This allows a client-facing model name to be mapped to the backend specific model name, for example,
+	// "claude-sonnet" to "anthropic.claude-3-5-sonnet-20241022-v2:0" on AWS Bedrock.
+	//
+	// The model name in the request is still used for the routing, i.e. the "x-ai-eg-model" header,
+	// as well as for the LLMRequestCosts.
+	//
+	// +optional
+	ModelNameOverride string `json:"modelNameOverride,omitempty"`
+}
+
+// AIGatewayRouteRuleLoadBalancingPolicy specifies how to select a backend among the BackendRefs of a rule.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.type) && self.type == 'ConsistentHash' ? has(self.consistentHash) : !has(self.consistentHash)", message="consistentHash must be set if and only if the type is ConsistentHash"
+type AIGatewayRouteRuleLoadBalancingPolicy struct {
+	// Type is the type of the load balancing policy. Default is WeightedRandom.
+	//
+	// WeightedRandom selects a backend randomly with the probability proportional to its weight.
+	//
+	// Adaptive tracks the exponentially weighted moving average of the time to first token and the error rate
+	// as well as the number of in-flight requests of each backend, and biases the weighted random selection away
+	// from the slow or failing backends. A failing backend still receives a small share of the traffic so that
+	// its recovery is observed. The observations are local to each AI Gateway filter instance and are reset
+	// when the configuration is updated.
+	//
+	// ConsistentHash selects the backend by the consistent hashing of a session key, so that the requests with
+	// the same key, e.g. the turns of a conversation, stay on the same backend as long as it is available. This keeps
+	// the provider-side prompt caches warm. The backends are weighted, and adding or removing a backend only moves
+	// the keys from or to that backend. The requests without the key fall back to the WeightedRandom selection.
+	//
+	// +kubebuilder:validation:Enum=WeightedRandom;Adaptive;ConsistentHash
+	// +kubebuilder:default=WeightedRandom
+	// +optional
+	Type LoadBalancingPolicyType `json:"type,omitempty"`
+
+	// ConsistentHash specifies the session key for the ConsistentHash type. This must be set if and only if
+	// the type is ConsistentHash.
+	//
+	// +optional
+	ConsistentHash *AIGatewayRouteRuleConsistentHash `json:"consistentHash,omitempty"`
+}
+
+// AIGatewayRouteRuleConsistentHash specifies the session key of the ConsistentHash load balancing policy.
+//
+// +kubebuilder:validation:XValidation:rule="self.type == 'Header' ? has(self.header) : !has(self.header)", message="header must be set if and only if the type is Header"
+type AIGatewayRouteRuleConsistentHash struct {
+	// Type is the source of the session key.
+	//
+	// Header uses the value of the request header specified by Header.
+	//
+	// User uses the "user" field of the OpenAI request body. This is not available for the AWSBedrock input schema.
+	//
+	// +kubebuilder:validation:Enum=Header;User
+	Type ConsistentHashType `json:"type"`
+
+	// Header is the name of the request header whose value is used as the session key, e.g. x-session-id.
+	//
+	// +optional
+	Header *gwapiv1.HTTPHeaderName `json:"header,omitempty"`
+}
+
+// ConsistentHashType specifies the source of the session key of the consistent hashing.
+type ConsistentHashType string
+
+const (
+	// ConsistentHashTypeHeader uses the value of a request header as the session key.
+	ConsistentHashTypeHeader ConsistentHashType = "Header"
+	// ConsistentHashTypeUser uses the "user" field of the OpenAI request body as the session key.
+	ConsistentHashTypeUser ConsistentHashType = "User"
+)
+
+// AIGatewayRouteRuleCircuitBreaker is the circuit breaker of the backends in an AIGatewayRouteRule.
+//
+// Each backend starts closed, i.e. selectable. After ConsecutiveFailures consecutive failures, i.e. the responses
+// with the status code 429 or 5xx or no response at all, the backend is opened and excluded from the selection
+// for OpenDuration. Then the backend becomes half-open and a single probe request is sent to it: the backend is
+// closed when the probe succeeds, and opened again otherwise. Another probe is sent when the result of the probe is
+// not known within OpenDuration. When all the backends of the rule are excluded, the requests matching the rule
+// are rejected with the status code 503 except for the probe requests.
+//
+// The state is kept per backend of each rule, i.e. the backends shared across rules have independent states
+// with the settings of each rule. The state is local to each AI Gateway filter instance and reset when
+// the configuration is updated.
+type AIGatewayRouteRuleCircuitBreaker struct {
+	// ConsecutiveFailures is the number of the consecutive failures of a backend to open its circuit.
+	// Default is 5.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=5
+	// +optional
+	ConsecutiveFailures *int32 `json:"consecutiveFailures,omitempty"`
+
+	// OpenDuration is the duration for which an open backend is excluded from the selection before
+	// a probe request is sent to it. Default is 30s.
+	//
+	// +kubebuilder:default="30s"
+	// +optional
+	OpenDuration *gwapiv1.Duration `json:"openDuration,omitempty"`
+}
+
+// LoadBalancingPolicyType specifies the type of the load balancing policy.
+type LoadBalancingPolicyType string
+
+const (
+	// LoadBalancingPolicyTypeWeightedRandom is the weighted random load balancing policy.
+	LoadBalancingPolicyTypeWeightedRandom LoadBalancingPolicyType = "WeightedRandom"
+	// LoadBalancingPolicyTypeAdaptive is the latency- and error-aware load balancing policy.
+	LoadBalancingPolicyTypeAdaptive LoadBalancingPolicyType = "Adaptive"
+	// LoadBalancingPolicyTypeConsistentHash is the session-sticky load balancing policy by the consistent hashing.
+	LoadBalancingPolicyTypeConsistentHash LoadBalancingPolicyType = "ConsistentHash"
+)
+
+// AIGatewayRouteRuleFallbackRef is a reference to a AIServiceBackend to fall back on.
+type AIGatewayRouteRuleFallbackRef struct {
+	// Name is the name of the AIServiceBackend.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ModelNameOverride is the model name sent to this fallback instead of the model name in the request.
+	// See AIGatewayRouteRuleBackendRef.ModelNameOverride for the details.
+	//
+	// +optional
+	ModelNameOverride string `json:"modelNameOverride,omitempty"`
+}
+
+// AIGatewayRouteRuleMirrorRef is a reference to a AIServiceBackend to mirror the requests to.
+type AIGatewayRouteRuleMirrorRef struct {
+	// Name is the name of the AIServiceBackend.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ModelNameOverride is the model name sent to this mirror instead of the model name in the request.
+	// See AIGatewayRouteRuleBackendRef.ModelNameOverride for the details.
+	//
+	// +optional
+	ModelNameOverride string `json:"modelNameOverride,omitempty"`
 }
 
+// AIGatewayRouteRuleMatch is a set of conditions that a request must satisfy to match a rule.
+// A rule matches the request when any of its AIGatewayRouteRuleMatch matches.
 type AIGatewayRouteRuleMatch struct {
-	// Headers specifies HTTP request header matchers. See HeaderMatch in the Gateway API for the details:
+	// Headers specifies HTTP request header matchers. This is similar to HeaderMatch in the Gateway API:
 	// https://gateway-api.sigs.k8s.io/reference/spec/#gateway.networking.k8s.io%2fv1.HTTPHeaderMatch
+	// but additionally supports the Prefix match type.
 	//
-	// Currently, only the exact header matching is supported.
+	// All the headers must match for the request to match, i.e. the headers are AND-ed.
 	//
 	// +listType=map
 	// +listMapKey=name
 	// +optional
 	// +kubebuilder:validation:MaxItems=16
-	// +kubebuilder:validation:XValidation:rule="self.all(match, match.type != 'RegularExpression')", message="currently only exact match is supported"
-	Headers []gwapiv1.HTTPHeaderMatch `json:"headers,omitempty"`
+	Headers []AIGatewayRouteRuleHeaderMatch `json:"headers,omitempty"`
+
+	// EstimatedInputTokens matches the number of the input tokens of the request, which is estimated by the AI Gateway
+	// filter from the parsed request body, i.e. the messages, the images and the tool definitions. For example, this
+	// allows routing the long-context prompts to a model with a larger context window and the short ones to a cheaper
+	// model. This is AND-ed with the Headers.
+	//
+	// The estimation is a rough approximation that does not depend on the model, so the range should have a margin
+	// from the actual limit of the model. Only the chat completion requests are estimated, and the other requests
+	// never match when this is set.
+	//
+	// +optional
+	EstimatedInputTokens *AIGatewayRouteRuleTokenRange `json:"estimatedInputTokens,omitempty"`
+}
+
+// AIGatewayRouteRuleTokenRange is a range of the number of tokens.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.min) || has(self.max)", message="either min or max must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.max) || self.min < self.max", message="min must be less than max"
+type AIGatewayRouteRuleTokenRange struct {
+	// Min is the inclusive lower bound of the range. When not set, the range has no lower bound.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Min *int64 `json:"min,omitempty"`
+
+	// Max is the exclusive upper bound of the range. When not set, the range has no upper bound.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Max *int64 `json:"max,omitempty"`
+}
+
+// AIGatewayRouteRuleHeaderMatch describes how to select a request by matching the value of a request header.
+type AIGatewayRouteRuleHeaderMatch struct {
+	// Type specifies how to match against the value of the header. Default is Exact.
+	//
+	// The RegularExpression type uses the RE2 syntax and must match the entire header value.
+	//
+	// +optional
+	// +kubebuilder:default=Exact
+	Type *HeaderMatchType `json:"type,omitempty"`
+
+	// Name is the name of the HTTP Header to be matched. Name matching is case-insensitive.
+	//
+	// +kubebuilder:validation:Required
+	Name gwapiv1.HTTPHeaderName `json:"name"`
+
+	// Value is the value of HTTP Header to be matched.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=4096
+	Value string `json:"value"`
 }
 
+// HeaderMatchType specifies the semantics of how the value of a header is compared.
+//
+// +kubebuilder:validation:Enum=Exact;Prefix;RegularExpression
+type HeaderMatchType string
+
+const (
+	// HeaderMatchTypeExact matches the header value exactly.
+	HeaderMatchTypeExact HeaderMatchType = "Exact"
+	// HeaderMatchTypePrefix matches when the header value starts with the given value.
+	HeaderMatchTypePrefix HeaderMatchType = "Prefix"
+	// HeaderMatchTypeRegularExpression matches when the header value matches the given RE2 regular expression.
+	HeaderMatchTypeRegularExpression HeaderMatchType = "RegularExpression"
+)
+
 type AIGatewayFilterConfig struct {
 	// Type specifies the type of the filter configuration.
 	//
@@ -364,14 +804,28 @@ type AIServiceBackendSpec struct {
 //
 // Note that this is vendor specific, and the stability of the API schema is not guaranteed by
 // the ai-gateway, but by the vendor via proper versioning.
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.deployments) || self.name == 'AzureOpenAI'", message="deployments can only be set for the AzureOpenAI schema"
 type VersionedAPISchema struct {
 	// Name is the name of the API schema of the AIGatewayRoute or AIServiceBackend.
 	//
-	// +kubebuilder:validation:Enum=OpenAI;AWSBedrock
+	// +kubebuilder:validation:Enum=OpenAI;AWSBedrock;Anthropic;AzureOpenAI;GCPVertexAI
 	Name APISchema `json:"name"`
 
 	// Version is the version of the API schema.
+	//
+	// For the Anthropic schema, this is sent as the "anthropic-version" header and defaults to "2023-06-01".
+	// For the AzureOpenAI schema, this is sent as the "api-version" query parameter and defaults to "2024-10-21".
+	// For the GCPVertexAI schema, this is the version prefix of the request path, e.g. "v1beta1", and defaults to "v1".
 	Version string `json:"version,omitempty"`
+
+	// Deployments maps the model name in the request to the Azure OpenAI deployment name.
+	// When a model is not found in this map, the model name is used as the deployment name as-is.
+	//
+	// This is only allowed for the AzureOpenAI schema.
+	//
+	// +optional
+	Deployments map[string]string `json:"deployments,omitempty"`
 }
 
 // APISchema defines the API schema.
@@ -386,6 +840,22 @@ const (
 	//
 	// https://docs.aws.amazon.com/bedrock/latest/APIReference/API_Operations_Amazon_Bedrock_Runtime.html
 	APISchemaAWSBedrock APISchema = "AWSBedrock"
+	// APISchemaAnthropic is the Anthropic Messages API schema.
+	//
+	// https://docs.anthropic.com/en/api/messages
+	APISchemaAnthropic APISchema = "Anthropic"
+	// APISchemaAzureOpenAI is the Azure OpenAI schema. Requests are routed to the deployment
+	// of the model, so it is usually combined with the "api-key" header of the APIKey BackendSecurityPolicy.
+	//
+	// https://learn.microsoft.com/en-us/azure/ai-services/openai/reference
+	APISchemaAzureOpenAI APISchema = "AzureOpenAI"
+	// APISchemaGCPVertexAI is the Gemini generateContent API schema served by GCP Vertex AI.
+	//
+	// The model name is either a publisher model such as "gemini-2.0-flash", or a full resource
+	// name such as "projects/my-project/locations/us-central1/publishers/google/models/gemini-2.0-flash".
+	//
+	// https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/inference
+	APISchemaGCPVertexAI APISchema = "GCPVertexAI"
 )
 
 const (
@@ -398,8 +868,9 @@ const (
 type BackendSecurityPolicyType string
 
 const (
-	BackendSecurityPolicyTypeAPIKey         BackendSecurityPolicyType = "APIKey"
-	BackendSecurityPolicyTypeAWSCredentials BackendSecurityPolicyType = "AWSCredentials"
+	BackendSecurityPolicyTypeAPIKey           BackendSecurityPolicyType = "APIKey"
+	BackendSecurityPolicyTypeAWSCredentials   BackendSecurityPolicyType = "AWSCredentials"
+	BackendSecurityPolicyTypeAzureCredentials BackendSecurityPolicyType = "AzureCredentials"
 )
 
 // +kubebuilder:object:root=true
@@ -418,12 +889,14 @@ type BackendSecurityPolicy struct {
 // Only one type of BackendSecurityPolicy can be defined.
 // +kubebuilder:validation:MaxProperties=2
 type BackendSecurityPolicySpec struct {
-	// Type specifies the auth mechanism used to access the provider. Currently, only "APIKey", AND "AWSCredentials" are supported.
+	// Type specifies the auth mechanism used to access the provider. Currently, only "APIKey", "AWSCredentials"
+	// and "AzureCredentials" are supported.
 	//
-	// +kubebuilder:validation:Enum=APIKey;AWSCredentials
+	// +kubebuilder:validation:Enum=APIKey;AWSCredentials;AzureCredentials
 	Type BackendSecurityPolicyType `json:"type"`
 
-	// APIKey is a mechanism to access a backend(s). The API key will be injected into the Authorization header.
+	// APIKey is a mechanism to access a backend(s). The API key will be injected into the Authorization header
+	// unless the HeaderName is specified.
 	//
 	// +optional
 	APIKey *BackendSecurityPolicyAPIKey `json:"apiKey,omitempty"`
@@ -432,6 +905,12 @@ type BackendSecurityPolicySpec struct {
 	//
 	// +optional
 	AWSCredentials *BackendSecurityPolicyAWSCredentials `json:"awsCredentials,omitempty"`
+
+	// AzureCredentials is a mechanism to access Azure OpenAI backend(s) with the Microsoft Entra ID access token,
+	// which will be injected into the Authorization header in the "Bearer <token>" form.
+	//
+	// +optional
+	AzureCredentials *BackendSecurityPolicyAzureCredentials `json:"azureCredentials,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -449,6 +928,14 @@ type BackendSecurityPolicyAPIKey struct {
 	// ai-gateway must be given the permission to read this secret.
 	// The key of the secret should be "apiKey".
 	SecretRef *gwapiv1.SecretObjectReference `json:"secretRef"`
+
+	// HeaderName is the name of the header to which the API key is set as-is, e.g. "api-key" for Azure OpenAI
+	// or "x-api-key" for Anthropic. When unset, the API key is set to the "Authorization" header
+	// in the "Bearer <key>" form.
+	//
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	HeaderName string `json:"headerName,omitempty"`
 }
 
 // BackendSecurityPolicyAWSCredentials contains the supported authentication mechanisms to access aws
@@ -470,6 +957,30 @@ type BackendSecurityPolicyAWSCredentials struct {
 	OIDCExchangeToken *AWSOIDCExchangeToken `json:"oidcExchangeToken,omitempty"`
 }
 
+// BackendSecurityPolicyAzureCredentials contains the credentials of the Microsoft Entra ID application to access Azure OpenAI.
+// The controller obtains the access token of the application with the OAuth2 client credentials flow, and refreshes it
+// before it expires by storing it in the secret mounted to the AI Gateway filter.
+type BackendSecurityPolicyAzureCredentials struct {
+	// TenantID is the ID of the Microsoft Entra ID tenant of the application.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	TenantID string `json:"tenantID"`
+
+	// ClientID is the client ID of the application, a.k.a. the application ID.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ClientID string `json:"clientID"`
+
+	// ClientSecretRef is the reference to the secret containing the client secret of the application.
+	// ai-gateway must be given the permission to read this secret.
+	// The key of the secret should be "client-secret".
+	//
+	// +kubebuilder:validation:Required
+	ClientSecretRef *gwapiv1.SecretObjectReference `json:"clientSecretRef"`
+}
+
 // AWSCredentialsFile specifies the credentials file to use for the AWS provider.
 // Envoy reads the secret file, and the profile to use is specified by the Profile field.
 type AWSCredentialsFile struct {
@@ -512,6 +1023,9 @@ type AWSOIDCExchangeToken struct {
 }
 
 // LLMRequestCost configures each request cost.
+//
+// +kubebuilder:validation:XValidation:rule="self.type == 'CEL' || self.type == 'Price' || (!has(self.scale) && !has(self.rounding))", message="scale and rounding can only be set for the CEL and Price types"
+// +kubebuilder:validation:XValidation:rule="self.type == 'Price' ? has(self.llmPricingName) : !has(self.llmPricingName)", message="llmPricingName must be set if and only if the type is Price"
 type LLMRequestCost struct {
 	// MetadataKey is the key of the metadata to store this cost of the request.
 	//
@@ -519,13 +1033,17 @@ type LLMRequestCost struct {
 	MetadataKey string `json:"metadataKey"`
 	// Type specifies the type of the request cost. The default is "OutputToken",
 	// and it uses "output token" as the cost. The other types are "InputToken", "TotalToken",
-	// and "CEL".
+	// "CachedInputToken", "CacheWriteInputToken", "ReasoningToken", "CEL" and "Price".
+	//
+	// The input tokens include the cached and the cache write input tokens, and the output tokens
+	// include the reasoning tokens regardless of the backend, following the OpenAI convention.
 	//
-	// +kubebuilder:validation:Enum=OutputToken;InputToken;TotalToken;CEL
+	// +kubebuilder:validation:Enum=OutputToken;InputToken;TotalToken;CachedInputToken;CacheWriteInputToken;ReasoningToken;CEL;Price
 	Type LLMRequestCostType `json:"type"`
 	// CEL is the CEL expression to calculate the cost of the request.
-	// The CEL expression must return a signed or unsigned integer. If the
-	// return value is negative, it will be error.
+	// The CEL expression must return a signed or unsigned integer, or a double. If the
+	// return value is negative, it will be error. The result is multiplied by the Scale,
+	// and a double result is then rounded to an integer as specified by the Rounding.
 	//
 	// The expression can use the following variables:
 	//
@@ -534,6 +1052,14 @@ type LLMRequestCost struct {
 	//	* input_tokens: the number of input tokens. Type: unsigned integer.
 	//	* output_tokens: the number of output tokens. Type: unsigned integer.
 	//	* total_tokens: the total number of tokens. Type: unsigned integer.
+	//	* cached_input_tokens: the number of input tokens read from the cache. Type: unsigned integer.
+	//	* cache_write_input_tokens: the number of input tokens written to the cache. Type: unsigned integer.
+	//	* reasoning_tokens: the number of output tokens generated for reasoning. Type: unsigned integer.
+	//	* schema: the API schema name of the backend, e.g. "OpenAI" or "AWSBedrock". Type: string.
+	//	* headers: the request headers keyed by the lower-cased names. Type: map of string to string.
+	//	* user: the "user" field of the request body, or empty if not set. Type: string.
+	//	* stream: whether the response is streamed. Type: bool.
+	//	* max_tokens: the maximum number of output tokens in the request, or zero if not set. Type: unsigned integer.
 	//
 	// For example, the following expressions are valid:
 	//
@@ -541,11 +1067,55 @@ type LLMRequestCost struct {
 	//	* "backend == 'foo.default' ?  input_tokens + output_tokens : total_tokens"
 	//	* "input_tokens + output_tokens + total_tokens"
 	//	* "input_tokens * output_tokens"
+	//	* "(input_tokens - cached_input_tokens) * 10u + cached_input_tokens + output_tokens * 40u"
+	//	* "'x-tenant' in headers && headers['x-tenant'] == 'free' ? 0u : total_tokens"
+	//	* "double(input_tokens) * 3.0 + double(output_tokens) * 15.0"
+	//
+	// The last one calculates the cost in micro-dollars at $3 per million input tokens and
+	// $15 per million output tokens.
 	//
 	// +optional
 	CEL *string `json:"cel,omitempty"`
+	// LLMPricingName is the name of the LLMPricing in the same namespace as the AIGatewayRoute,
+	// which prices the requests when the type is "Price".
+	//
+	// The cost is the sum of the number of each kind of tokens multiplied by its price per million
+	// tokens, i.e. in the millionth of the currency unit of the prices, e.g. micro-dollars.
+	// The model and the backend serving the request without the price cost zero.
+	//
+	// +optional
+	LLMPricingName *string `json:"llmPricingName,omitempty"`
+	// Scale is the multiplier applied to the result of the CEL expression or the price. This allows the expression
+	// to be written in the natural unit, e.g. dollars, while the cost is stored in the smaller integer
+	// unit, e.g. micro-dollars with the scale of 1000000. This can only be set for the CEL and Price types.
+	//
+	// Defaults to 1.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Scale *int64 `json:"scale,omitempty"`
+	// Rounding specifies how the scaled result of the CEL expression or the price is rounded to an integer when
+	// it is a double. One of "Round", "Floor" or "Ceil". This can only be set for the CEL and Price types.
+	//
+	// Defaults to "Round", which rounds half away from zero.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Round;Floor;Ceil
+	Rounding *LLMRequestCostRounding `json:"rounding,omitempty"`
 }
 
+// LLMRequestCostRounding specifies how the result of the CEL expression is rounded to an integer.
+type LLMRequestCostRounding string
+
+const (
+	// LLMRequestCostRoundingRound rounds the result to the nearest integer, rounding half away from zero.
+	LLMRequestCostRoundingRound LLMRequestCostRounding = "Round"
+	// LLMRequestCostRoundingFloor rounds the result down.
+	LLMRequestCostRoundingFloor LLMRequestCostRounding = "Floor"
+	// LLMRequestCostRoundingCeil rounds the result up.
+	LLMRequestCostRoundingCeil LLMRequestCostRounding = "Ceil"
+)
+
 // LLMRequestCostType specifies the type of the LLMRequestCost.
 type LLMRequestCostType string
 
@@ -556,11 +1126,173 @@ const (
 	LLMRequestCostTypeOutputToken LLMRequestCostType = "OutputToken"
 	// LLMRequestCostTypeTotalToken is the cost type of the total token.
 	LLMRequestCostTypeTotalToken LLMRequestCostType = "TotalToken"
+	// LLMRequestCostTypeCachedInputToken is the cost type of the input token read from the cache.
+	LLMRequestCostTypeCachedInputToken LLMRequestCostType = "CachedInputToken"
+	// LLMRequestCostTypeCacheWriteInputToken is the cost type of the input token written to the cache.
+	LLMRequestCostTypeCacheWriteInputToken LLMRequestCostType = "CacheWriteInputToken"
+	// LLMRequestCostTypeReasoningToken is the cost type of the reasoning token.
+	LLMRequestCostTypeReasoningToken LLMRequestCostType = "ReasoningToken"
 	// LLMRequestCostTypeCEL is for calculating the cost using the CEL expression.
 	LLMRequestCostTypeCEL LLMRequestCostType = "CEL"
+	// LLMRequestCostTypePrice is for calculating the monetary cost using the prices in the LLMPricing.
+	LLMRequestCostTypePrice LLMRequestCostType = "Price"
 )
 
 const (
 	// AIGatewayFilterMetadataNamespace is the namespace for the ai-gateway filter metadata.
 	AIGatewayFilterMetadataNamespace = "io.envoy.ai_gateway"
 )
+
+// +kubebuilder:object:root=true
+
+// LLMPricing describes the prices of the models per backend. This is referenced by the LLMRequestCost
+// of the "Price" type in the AIGatewayRoute to calculate the monetary cost of the requests, so that the
+// prices do not have to be duplicated in the CEL expressions across the AIGatewayRoutes.
+type LLMPricing struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              LLMPricingSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LLMPricingList contains a list of LLMPricing.
+type LLMPricingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LLMPricing `json:"items"`
+}
+
+// LLMPricingSpec details the LLMPricing configuration.
+type LLMPricingSpec struct {
+	// Models is the list of the prices of the models.
+	//
+	// A request is priced by the first entry whose ModelName is the model name of the request, and whose
+	// BackendName, if set, is the AIServiceBackend that served the request. Hence, the entries with the
+	// BackendName should come before the entry without it for the same model.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=1024
+	Models []LLMModelPrice `json:"models"`
+}
+
+// LLMModelPrice is the price of a model per million tokens. The prices are decimal numbers in any
+// currency unit as long as it is consistent within the LLMPricing, e.g. "3" or "0.075" for dollars.
+type LLMModelPrice struct {
+	// ModelName is the name of the model, which is matched against the model name extracted from the request content.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ModelName string `json:"modelName"`
+	// BackendName is the name of the AIServiceBackend in the same namespace as the LLMPricing. When set, the price only
+	// applies to the requests served by the backend, which allows the same model to be priced differently per provider.
+	//
+	// +optional
+	BackendName *string `json:"backendName,omitempty"`
+	// InputTokenPrice is the price of a million input tokens that are neither read from nor written to the cache.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	InputTokenPrice string `json:"inputTokenPrice"`
+	// OutputTokenPrice is the price of a million output tokens that are not for reasoning.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	OutputTokenPrice string `json:"outputTokenPrice"`
+	// CachedInputTokenPrice is the price of a million input tokens read from the cache.
+	//
+	// Defaults to the InputTokenPrice.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	CachedInputTokenPrice *string `json:"cachedInputTokenPrice,omitempty"`
+	// CacheWriteInputTokenPrice is the price of a million input tokens written to the cache.
+	//
+	// Defaults to the InputTokenPrice.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	CacheWriteInputTokenPrice *string `json:"cacheWriteInputTokenPrice,omitempty"`
+	// ReasoningTokenPrice is the price of a million output tokens generated for reasoning.
+	//
+	// Defaults to the OutputTokenPrice.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	ReasoningTokenPrice *string `json:"reasoningTokenPrice,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GatewayAPIKey is an API key issued by the AI Gateway to a client, a.k.a. a virtual key. This is accepted by
+// the AIGatewayRoutes in the same namespace selecting it with GatewayAPIKeys, so that the clients share neither
+// the credentials of the providers configured by the BackendSecurityPolicies nor the budgets of each other.
+//
+// Only the SHA-256 hash of the key is stored so that the key itself is only known to its owner.
+// The clients send the key in the "Authorization: Bearer <key>" header, which is the only supported location.
+type GatewayAPIKey struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              GatewayAPIKeySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GatewayAPIKeyList contains a list of GatewayAPIKey.
+type GatewayAPIKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GatewayAPIKey `json:"items"`
+}
+
+// GatewayAPIKeySpec details the GatewayAPIKey configuration.
+type GatewayAPIKeySpec struct {
+	// KeyHash is the SHA-256 hash of the key in the lowercase hex, e.g. the output of `echo -n "$KEY" | sha256sum`.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{64}$`
+	KeyHash string `json:"keyHash"`
+	// Owner is the user or the team the key is issued to, which is logged with the requests authenticated with the key.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Owner string `json:"owner"`
+	// AllowedModels are the names of the models the key can be used for. The requests for the other models are
+	// rejected with the status code 404 as if the models did not exist. When empty, all the models are allowed.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=128
+	AllowedModels []string `json:"allowedModels,omitempty"`
+	// TokenBudgets are the budgets of the request costs of the key over the sliding windows. These are enforced in the
+	// same way as the TokenBudgets of the AIGatewayRoute keyed by the key, but only in the AIGatewayRoutes with
+	// the LLMRequestCost of the metadataKey.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	TokenBudgets []GatewayAPIKeyTokenBudget `json:"tokenBudgets,omitempty"`
+	// ExpiresAt is the time after which the key is rejected. When not set, the key does not expire.
+	//
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// GatewayAPIKeyTokenBudget is the budget of a request cost of a GatewayAPIKey over a sliding window.
+type GatewayAPIKeyTokenBudget struct {
+	// MetadataKey is the metadataKey of the LLMRequestCost whose cost is consumed from the budget.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	MetadataKey string `json:"metadataKey"`
+
+	// Limit is the maximum cost of the key within the window.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Limit int64 `json:"limit"`
+
+	// Window is the length of the sliding window, e.g. "1m" or "24h".
+	//
+	// +kubebuilder:validation:Required
+	Window gwapiv1.Duration `json:"window"`
+}
@@ -14,6 +14,8 @@ func init() {
 	SchemeBuilder.Register(&AIGatewayRoute{}, &AIGatewayRouteList{})
 	SchemeBuilder.Register(&AIServiceBackend{}, &AIServiceBackendList{})
 	SchemeBuilder.Register(&BackendSecurityPolicy{}, &BackendSecurityPolicyList{})
+	SchemeBuilder.Register(&LLMPricing{}, &LLMPricingList{})
+	SchemeBuilder.Register(&GatewayAPIKey{}, &GatewayAPIKeyList{})
 }
 
 const GroupName = "aigateway.envoyproxy.io"
@@ -89,6 +89,26 @@ func (in *AIGatewayRoute) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteGatewayAPIKeys) DeepCopyInto(out *AIGatewayRouteGatewayAPIKeys) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteGatewayAPIKeys.
+func (in *AIGatewayRouteGatewayAPIKeys) DeepCopy() *AIGatewayRouteGatewayAPIKeys {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteGatewayAPIKeys)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIGatewayRouteList) DeepCopyInto(out *AIGatewayRouteList) {
 	*out = *in
@@ -121,6 +141,46 @@ func (in *AIGatewayRouteList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteRequestLimits) DeepCopyInto(out *AIGatewayRouteRequestLimits) {
+	*out = *in
+	if in.MaxTokens != nil {
+		in, out := &in.MaxTokens, &out.MaxTokens
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxTokensAction != nil {
+		in, out := &in.MaxTokensAction, &out.MaxTokensAction
+		*out = new(MaxTokensAction)
+		**out = **in
+	}
+	if in.MaxEstimatedInputTokens != nil {
+		in, out := &in.MaxEstimatedInputTokens, &out.MaxEstimatedInputTokens
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxMessages != nil {
+		in, out := &in.MaxMessages, &out.MaxMessages
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxTools != nil {
+		in, out := &in.MaxTools, &out.MaxTools
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteRequestLimits.
+func (in *AIGatewayRouteRequestLimits) DeepCopy() *AIGatewayRouteRequestLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteRequestLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIGatewayRouteRule) DeepCopyInto(out *AIGatewayRouteRule) {
 	*out = *in
@@ -129,6 +189,26 @@ func (in *AIGatewayRouteRule) DeepCopyInto(out *AIGatewayRouteRule) {
 		*out = make([]AIGatewayRouteRuleBackendRef, len(*in))
 		copy(*out, *in)
 	}
+	if in.LoadBalancingPolicy != nil {
+		in, out := &in.LoadBalancingPolicy, &out.LoadBalancingPolicy
+		*out = new(AIGatewayRouteRuleLoadBalancingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(AIGatewayRouteRuleCircuitBreaker)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]AIGatewayRouteRuleFallbackRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(AIGatewayRouteRuleMirrorRef)
+		**out = **in
+	}
 	if in.Matches != nil {
 		in, out := &in.Matches, &out.Matches
 		*out = make([]AIGatewayRouteRuleMatch, len(*in))
@@ -163,16 +243,121 @@ func (in *AIGatewayRouteRuleBackendRef) DeepCopy() *AIGatewayRouteRuleBackendRef
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteRuleCircuitBreaker) DeepCopyInto(out *AIGatewayRouteRuleCircuitBreaker) {
+	*out = *in
+	if in.ConsecutiveFailures != nil {
+		in, out := &in.ConsecutiveFailures, &out.ConsecutiveFailures
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OpenDuration != nil {
+		in, out := &in.OpenDuration, &out.OpenDuration
+		*out = new(apisv1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteRuleCircuitBreaker.
+func (in *AIGatewayRouteRuleCircuitBreaker) DeepCopy() *AIGatewayRouteRuleCircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteRuleCircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteRuleConsistentHash) DeepCopyInto(out *AIGatewayRouteRuleConsistentHash) {
+	*out = *in
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = new(apisv1.HTTPHeaderName)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteRuleConsistentHash.
+func (in *AIGatewayRouteRuleConsistentHash) DeepCopy() *AIGatewayRouteRuleConsistentHash {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteRuleConsistentHash)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteRuleFallbackRef) DeepCopyInto(out *AIGatewayRouteRuleFallbackRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteRuleFallbackRef.
+func (in *AIGatewayRouteRuleFallbackRef) DeepCopy() *AIGatewayRouteRuleFallbackRef {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteRuleFallbackRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteRuleHeaderMatch) DeepCopyInto(out *AIGatewayRouteRuleHeaderMatch) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(HeaderMatchType)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteRuleHeaderMatch.
+func (in *AIGatewayRouteRuleHeaderMatch) DeepCopy() *AIGatewayRouteRuleHeaderMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteRuleHeaderMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteRuleLoadBalancingPolicy) DeepCopyInto(out *AIGatewayRouteRuleLoadBalancingPolicy) {
+	*out = *in
+	if in.ConsistentHash != nil {
+		in, out := &in.ConsistentHash, &out.ConsistentHash
+		*out = new(AIGatewayRouteRuleConsistentHash)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteRuleLoadBalancingPolicy.
+func (in *AIGatewayRouteRuleLoadBalancingPolicy) DeepCopy() *AIGatewayRouteRuleLoadBalancingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteRuleLoadBalancingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIGatewayRouteRuleMatch) DeepCopyInto(out *AIGatewayRouteRuleMatch) {
 	*out = *in
 	if in.Headers != nil {
 		in, out := &in.Headers, &out.Headers
-		*out = make([]apisv1.HTTPHeaderMatch, len(*in))
+		*out = make([]AIGatewayRouteRuleHeaderMatch, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.EstimatedInputTokens != nil {
+		in, out := &in.EstimatedInputTokens, &out.EstimatedInputTokens
+		*out = new(AIGatewayRouteRuleTokenRange)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteRuleMatch.
@@ -185,6 +370,46 @@ func (in *AIGatewayRouteRuleMatch) DeepCopy() *AIGatewayRouteRuleMatch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteRuleMirrorRef) DeepCopyInto(out *AIGatewayRouteRuleMirrorRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteRuleMirrorRef.
+func (in *AIGatewayRouteRuleMirrorRef) DeepCopy() *AIGatewayRouteRuleMirrorRef {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteRuleMirrorRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteRuleTokenRange) DeepCopyInto(out *AIGatewayRouteRuleTokenRange) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteRuleTokenRange.
+func (in *AIGatewayRouteRuleTokenRange) DeepCopy() *AIGatewayRouteRuleTokenRange {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteRuleTokenRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIGatewayRouteSpec) DeepCopyInto(out *AIGatewayRouteSpec) {
 	*out = *in
@@ -195,7 +420,7 @@ func (in *AIGatewayRouteSpec) DeepCopyInto(out *AIGatewayRouteSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.APISchema = in.APISchema
+	in.APISchema.DeepCopyInto(&out.APISchema)
 	if in.Rules != nil {
 		in, out := &in.Rules, &out.Rules
 		*out = make([]AIGatewayRouteRule, len(*in))
@@ -215,6 +440,23 @@ func (in *AIGatewayRouteSpec) DeepCopyInto(out *AIGatewayRouteSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TokenBudgets != nil {
+		in, out := &in.TokenBudgets, &out.TokenBudgets
+		*out = make([]AIGatewayRouteTokenBudget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RequestLimits != nil {
+		in, out := &in.RequestLimits, &out.RequestLimits
+		*out = new(AIGatewayRouteRequestLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GatewayAPIKeys != nil {
+		in, out := &in.GatewayAPIKeys, &out.GatewayAPIKeys
+		*out = new(AIGatewayRouteGatewayAPIKeys)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteSpec.
@@ -249,6 +491,42 @@ func (in *AIGatewayRouteStatus) DeepCopy() *AIGatewayRouteStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteTokenBudget) DeepCopyInto(out *AIGatewayRouteTokenBudget) {
+	*out = *in
+	in.Key.DeepCopyInto(&out.Key)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteTokenBudget.
+func (in *AIGatewayRouteTokenBudget) DeepCopy() *AIGatewayRouteTokenBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteTokenBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIGatewayRouteTokenBudgetKey) DeepCopyInto(out *AIGatewayRouteTokenBudgetKey) {
+	*out = *in
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = new(apisv1.HTTPHeaderName)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIGatewayRouteTokenBudgetKey.
+func (in *AIGatewayRouteTokenBudgetKey) DeepCopy() *AIGatewayRouteTokenBudgetKey {
+	if in == nil {
+		return nil
+	}
+	out := new(AIGatewayRouteTokenBudgetKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIServiceBackend) DeepCopyInto(out *AIServiceBackend) {
 	*out = *in
@@ -310,7 +588,7 @@ func (in *AIServiceBackendList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIServiceBackendSpec) DeepCopyInto(out *AIServiceBackendSpec) {
 	*out = *in
-	out.APISchema = in.APISchema
+	in.APISchema.DeepCopyInto(&out.APISchema)
 	in.BackendRef.DeepCopyInto(&out.BackendRef)
 	if in.BackendSecurityPolicyRef != nil {
 		in, out := &in.BackendSecurityPolicyRef, &out.BackendSecurityPolicyRef
@@ -441,6 +719,26 @@ func (in *BackendSecurityPolicyAWSCredentials) DeepCopy() *BackendSecurityPolicy
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendSecurityPolicyAzureCredentials) DeepCopyInto(out *BackendSecurityPolicyAzureCredentials) {
+	*out = *in
+	if in.ClientSecretRef != nil {
+		in, out := &in.ClientSecretRef, &out.ClientSecretRef
+		*out = new(apisv1.SecretObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendSecurityPolicyAzureCredentials.
+func (in *BackendSecurityPolicyAzureCredentials) DeepCopy() *BackendSecurityPolicyAzureCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendSecurityPolicyAzureCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackendSecurityPolicyList) DeepCopyInto(out *BackendSecurityPolicyList) {
 	*out = *in
@@ -486,6 +784,11 @@ func (in *BackendSecurityPolicySpec) DeepCopyInto(out *BackendSecurityPolicySpec
 		*out = new(BackendSecurityPolicyAWSCredentials)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AzureCredentials != nil {
+		in, out := &in.AzureCredentials, &out.AzureCredentials
+		*out = new(BackendSecurityPolicyAzureCredentials)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendSecurityPolicySpec.
@@ -498,6 +801,223 @@ func (in *BackendSecurityPolicySpec) DeepCopy() *BackendSecurityPolicySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayAPIKey) DeepCopyInto(out *GatewayAPIKey) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAPIKey.
+func (in *GatewayAPIKey) DeepCopy() *GatewayAPIKey {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayAPIKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GatewayAPIKey) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayAPIKeyList) DeepCopyInto(out *GatewayAPIKeyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GatewayAPIKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAPIKeyList.
+func (in *GatewayAPIKeyList) DeepCopy() *GatewayAPIKeyList {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayAPIKeyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GatewayAPIKeyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayAPIKeySpec) DeepCopyInto(out *GatewayAPIKeySpec) {
+	*out = *in
+	if in.AllowedModels != nil {
+		in, out := &in.AllowedModels, &out.AllowedModels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TokenBudgets != nil {
+		in, out := &in.TokenBudgets, &out.TokenBudgets
+		*out = make([]GatewayAPIKeyTokenBudget, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAPIKeySpec.
+func (in *GatewayAPIKeySpec) DeepCopy() *GatewayAPIKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayAPIKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayAPIKeyTokenBudget) DeepCopyInto(out *GatewayAPIKeyTokenBudget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAPIKeyTokenBudget.
+func (in *GatewayAPIKeyTokenBudget) DeepCopy() *GatewayAPIKeyTokenBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayAPIKeyTokenBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMModelPrice) DeepCopyInto(out *LLMModelPrice) {
+	*out = *in
+	if in.BackendName != nil {
+		in, out := &in.BackendName, &out.BackendName
+		*out = new(string)
+		**out = **in
+	}
+	if in.CachedInputTokenPrice != nil {
+		in, out := &in.CachedInputTokenPrice, &out.CachedInputTokenPrice
+		*out = new(string)
+		**out = **in
+	}
+	if in.CacheWriteInputTokenPrice != nil {
+		in, out := &in.CacheWriteInputTokenPrice, &out.CacheWriteInputTokenPrice
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReasoningTokenPrice != nil {
+		in, out := &in.ReasoningTokenPrice, &out.ReasoningTokenPrice
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMModelPrice.
+func (in *LLMModelPrice) DeepCopy() *LLMModelPrice {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMModelPrice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMPricing) DeepCopyInto(out *LLMPricing) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMPricing.
+func (in *LLMPricing) DeepCopy() *LLMPricing {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMPricing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LLMPricing) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMPricingList) DeepCopyInto(out *LLMPricingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LLMPricing, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMPricingList.
+func (in *LLMPricingList) DeepCopy() *LLMPricingList {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMPricingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LLMPricingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLMPricingSpec) DeepCopyInto(out *LLMPricingSpec) {
+	*out = *in
+	if in.Models != nil {
+		in, out := &in.Models, &out.Models
+		*out = make([]LLMModelPrice, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMPricingSpec.
+func (in *LLMPricingSpec) DeepCopy() *LLMPricingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LLMPricingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LLMRequestCost) DeepCopyInto(out *LLMRequestCost) {
 	*out = *in
@@ -506,6 +1026,21 @@ func (in *LLMRequestCost) DeepCopyInto(out *LLMRequestCost) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.LLMPricingName != nil {
+		in, out := &in.LLMPricingName, &out.LLMPricingName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Scale != nil {
+		in, out := &in.Scale, &out.Scale
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Rounding != nil {
+		in, out := &in.Rounding, &out.Rounding
+		*out = new(LLMRequestCostRounding)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMRequestCost.
@@ -521,6 +1056,13 @@ func (in *LLMRequestCost) DeepCopy() *LLMRequestCost {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VersionedAPISchema) DeepCopyInto(out *VersionedAPISchema) {
 	*out = *in
+	if in.Deployments != nil {
+		in, out := &in.Deployments, &out.Deployments
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionedAPISchema.
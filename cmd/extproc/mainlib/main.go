@@ -24,6 +24,7 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/envoyproxy/ai-gateway/internal/extproc"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
 	"github.com/envoyproxy/ai-gateway/internal/version"
 )
 
@@ -32,6 +33,8 @@ type extProcFlags struct {
 	configPath  string     // path to the configuration file.
 	extProcAddr string     // gRPC address for the external processor.
 	logLevel    slog.Level // log level for the external processor.
+	// tokenizerVocabPath is the path to the vocabulary file of the BPE tokenizer, which is optional.
+	tokenizerVocabPath string
 }
 
 // parseAndValidateFlags parses and validates the flas passed to the external processor.
@@ -53,6 +56,12 @@ func parseAndValidateFlags(args []string) (extProcFlags, error) {
 		":1063",
 		"gRPC address for the external processor. For example, :1063 or unix:///tmp/ext_proc.sock",
 	)
+	fs.StringVar(&flags.tokenizerVocabPath,
+		"tokenizerVocabPath",
+		"",
+		"path to the vocabulary file in the tiktoken format, e.g. cl100k_base.tiktoken, used to estimate the number of tokens. "+
+			"When not set, the number of tokens is estimated from the number of characters.",
+	)
 	logLevelPtr := fs.String(
 		"logLevel",
 		"info",
@@ -106,8 +115,19 @@ func Main() {
 	if err != nil {
 		log.Fatalf("failed to create external processor server: %v", err)
 	}
+	if flags.tokenizerVocabPath != "" {
+		bpe, err := tokenizer.LoadBPE(flags.tokenizerVocabPath)
+		if err != nil {
+			log.Fatalf("failed to load tokenizer: %v", err)
+		}
+		server.SetTokenizer(bpe)
+	}
 	server.Register("/v1/chat/completions", extproc.NewChatCompletionProcessor)
+	server.Register("/v1/embeddings", extproc.NewEmbeddingsProcessor)
+	server.Register("/v1/completions", extproc.NewCompletionsProcessor)
 	server.Register("/v1/models", extproc.NewModelsProcessor)
+	server.Register("/model/{modelId}/converse", extproc.NewConverseProcessor)
+	server.Register("/model/{modelId}/converse-stream", extproc.NewConverseProcessor)
 
 	if err := extproc.StartConfigWatcher(ctx, flags.configPath, server, l, time.Second*5); err != nil {
 		log.Fatalf("failed to start config watcher: %v", err)
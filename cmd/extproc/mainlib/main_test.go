@@ -16,11 +16,12 @@ import (
 func Test_parseAndValidateFlags(t *testing.T) {
 	t.Run("ok extProcFlags", func(t *testing.T) {
 		for _, tc := range []struct {
-			name       string
-			args       []string
-			configPath string
-			addr       string
-			logLevel   slog.Level
+			name               string
+			args               []string
+			configPath         string
+			addr               string
+			logLevel           slog.Level
+			tokenizerVocabPath string
 		}{
 			{
 				name:       "minimal extProcFlags",
@@ -63,10 +64,12 @@ func Test_parseAndValidateFlags(t *testing.T) {
 					"-configPath", "/path/to/config.yaml",
 					"-extProcAddr", "unix:///tmp/ext_proc.sock",
 					"-logLevel", "debug",
+					"-tokenizerVocabPath", "/path/to/cl100k_base.tiktoken",
 				},
-				configPath: "/path/to/config.yaml",
-				addr:       "unix:///tmp/ext_proc.sock",
-				logLevel:   slog.LevelDebug,
+				configPath:         "/path/to/config.yaml",
+				addr:               "unix:///tmp/ext_proc.sock",
+				logLevel:           slog.LevelDebug,
+				tokenizerVocabPath: "/path/to/cl100k_base.tiktoken",
 			},
 		} {
 			t.Run(tc.name, func(t *testing.T) {
@@ -75,6 +78,7 @@ func Test_parseAndValidateFlags(t *testing.T) {
 				assert.Equal(t, tc.configPath, flags.configPath)
 				assert.Equal(t, tc.addr, flags.extProcAddr)
 				assert.Equal(t, tc.logLevel, flags.logLevel)
+				assert.Equal(t, tc.tokenizerVocabPath, flags.tokenizerVocabPath)
 			})
 		}
 	})
@@ -13,34 +13,36 @@ import (
 	"github.com/envoyproxy/ai-gateway/filterapi/x"
 )
 
-// newCustomRouter implements [x.NewCustomRouter].
-func newCustomRouter(defaultRouter x.Router, config *filterapi.Config) x.Router {
+// newCustomRouter implements [x.NewCustomRouterV2].
+func newCustomRouter(defaultRouter x.Router, config *filterapi.Config) x.RouterV2 {
 	// You can poke the current configuration of the routes, and the list of backends
 	// specified in the AIGatewayRoute.Rules, etc.
 	return &myCustomRouter{config: config, defaultRouter: defaultRouter}
 }
 
-// myCustomRouter implements [filterapi.Router].
+// myCustomRouter implements [x.RouterV2].
 type myCustomRouter struct {
 	config        *filterapi.Config
 	defaultRouter x.Router
 }
 
-// Calculate implements [x.Router.Calculate].
-func (m *myCustomRouter) Calculate(headers map[string]string) (backend *filterapi.Backend, err error) {
-	// Simply logs the headers and delegates the calculation to the default router.
+// Calculate implements [x.RouterV2.Calculate].
+func (m *myCustomRouter) Calculate(headers map[string]string, request x.Request) (backend *filterapi.Backend, err error) {
+	// Simply logs the parsed request and delegates the calculation to the default router.
+	// The request can be used to route e.g. on the declared tools or the end user.
 	modelName, ok := headers[m.config.ModelNameHeaderKey]
 	if !ok {
 		panic("model name not found in the headers")
 	}
-	fmt.Printf("model name: %s\n", modelName)
+	fmt.Printf("model name: %s, stream: %t, messages: %d, tools: %v, user: %s\n",
+		modelName, request.Stream, request.MessageCount, request.Tools, request.User)
 	return m.defaultRouter.Calculate(headers)
 }
 
 // This demonstrates how to build a custom router for the external processor.
 func main() {
-	// Initializes the custom router.
-	x.NewCustomRouter = newCustomRouter
+	// Initializes the custom router. Use x.NewCustomRouter instead to route only on the headers.
+	x.NewCustomRouterV2 = newCustomRouter
 	// Executes the main function of the external processor.
 	mainlib.Main()
 }
@@ -16,6 +16,7 @@ package filterapi
 
 import (
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/yaml"
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -78,6 +79,16 @@ type Config struct {
 	// LLMRequestCost configures the cost of each LLM-related request. Optional. If this is provided, the filter will populate
 	// the "calculated" cost in the filter metadata at the end of the response body processing.
 	LLMRequestCosts []LLMRequestCost `json:"llmRequestCosts,omitempty"`
+	// TokenBudgets are the budgets of the LLMRequestCosts enforced by the filter in memory. Optional.
+	TokenBudgets []TokenBudget `json:"tokenBudgets,omitempty"`
+	// RequestLimits limits the chat completion requests before they are sent to the backends. Optional.
+	RequestLimits *RequestLimits `json:"requestLimits,omitempty"`
+	// ForceStreamUsage makes the streamed chat completion requests to the OpenAI compatible backends ask for the usage
+	// while removing the usage chunk from the response to the clients that did not ask for it.
+	ForceStreamUsage bool `json:"forceStreamUsage,omitempty"`
+	// GatewayAPIKeys are the API keys the requests are authenticated with. Optional. When set, the requests without
+	// one of the keys are rejected.
+	GatewayAPIKeys *GatewayAPIKeys `json:"gatewayAPIKeys,omitempty"`
 	// InputSchema specifies the API schema of the input format of requests to the filter.
 	Schema VersionedAPISchema `json:"schema"`
 	// ModelNameHeaderKey is the header key to be populated with the model name by the filter.
@@ -87,6 +98,7 @@ type Config struct {
 	SelectedBackendHeaderKey string `json:"selectedBackendHeaderKey"`
 	// Rules is the routing rules to be used by the filter to make the routing decision.
 	// Inside the routing rules, the header ModelNameHeaderKey may be used to make the routing decision.
+	// The last rule matching the request is used.
 	Rules []RouteRule `json:"rules"`
 }
 
@@ -105,8 +117,118 @@ type LLMRequestCost struct {
 	// CEL is the CEL expression to calculate the cost of the request.
 	// This is not empty when the Type is LLMRequestCostTypeCEL.
 	CEL string `json:"cel,omitempty"`
+	// Prices are the prices of the models to calculate the monetary cost of the request.
+	// This is not empty when the Type is LLMRequestCostTypePrice.
+	Prices []ModelPrice `json:"prices,omitempty"`
+	// Scale is the multiplier applied to the result of the CEL expression or the price. Zero means 1.
+	Scale int64 `json:"scale,omitempty"`
+	// Rounding is how the scaled result of the CEL expression or the price is rounded to an integer when it is a double.
+	// Empty means LLMRequestCostRoundingRound.
+	Rounding LLMRequestCostRounding `json:"rounding,omitempty"`
 }
 
+// RequestLimits corresponds to AIGatewayRouteRequestLimits in api/v1alpha1/api.go. Zero means no limit.
+type RequestLimits struct {
+	// MaxTokens is the maximum number of the output tokens a request can ask for.
+	MaxTokens int64 `json:"maxTokens,omitempty"`
+	// RejectMaxTokens is true if the request asking for more than MaxTokens is rejected instead of being clamped.
+	RejectMaxTokens bool `json:"rejectMaxTokens,omitempty"`
+	// MaxEstimatedInputTokens is the maximum estimated number of the input tokens of a request.
+	MaxEstimatedInputTokens int `json:"maxEstimatedInputTokens,omitempty"`
+	// MaxMessages is the maximum number of the messages of a request.
+	MaxMessages int `json:"maxMessages,omitempty"`
+	// MaxTools is the maximum number of the tools of a request. Nil means no limit since zero is a valid limit.
+	MaxTools *int `json:"maxTools,omitempty"`
+}
+
+// TokenBudget corresponds to AIGatewayRouteTokenBudget in api/v1alpha1/api.go.
+type TokenBudget struct {
+	// Name is the name of the budget, which identifies the consumption across the configuration updates.
+	Name string `json:"name"`
+	// Key is the key the consumption is tracked per.
+	Key TokenBudgetKey `json:"key"`
+	// MetadataKey is the metadata key of the LLMRequestCost whose cost is consumed from the budget.
+	MetadataKey string `json:"metadataKey"`
+	// Limit is the maximum cost of a key within the Window.
+	Limit uint64 `json:"limit"`
+	// Window is the length of the sliding window.
+	Window time.Duration `json:"window"`
+}
+
+// TokenBudgetKey specifies the key of the [TokenBudget].
+type TokenBudgetKey struct {
+	// Type is the source of the key.
+	Type TokenBudgetKeyType `json:"type"`
+	// Header is the lower-cased name of the request header whose value is used as the key
+	// when Type is TokenBudgetKeyTypeHeader.
+	Header string `json:"header,omitempty"`
+}
+
+// TokenBudgetKeyType specifies the source of the key of the [TokenBudget].
+type TokenBudgetKeyType string
+
+const (
+	// TokenBudgetKeyTypeHeader uses the value of a request header as the key.
+	TokenBudgetKeyTypeHeader TokenBudgetKeyType = "Header"
+	// TokenBudgetKeyTypeUser uses the "user" field of the request body as the key.
+	TokenBudgetKeyTypeUser TokenBudgetKeyType = "User"
+	// TokenBudgetKeyTypeAPIKey uses the name of the GatewayAPIKey of the request as the key.
+	TokenBudgetKeyTypeAPIKey TokenBudgetKeyType = "APIKey"
+)
+
+// GatewayAPIKeys corresponds to AIGatewayRouteGatewayAPIKeys in api/v1alpha1/api.go with the selected keys resolved.
+type GatewayAPIKeys struct {
+	// Keys are the accepted keys. This can be empty, in which case all the requests are rejected.
+	Keys []GatewayAPIKey `json:"keys"`
+}
+
+// GatewayAPIKey corresponds to GatewayAPIKey in api/v1alpha1/api.go.
+type GatewayAPIKey struct {
+	// Name is the name of the GatewayAPIKey.
+	Name string `json:"name"`
+	// Hash is the SHA-256 hash of the key in the lowercase hex.
+	Hash string `json:"hash"`
+	// Owner is the user or the team the key is issued to.
+	Owner string `json:"owner"`
+	// AllowedModels are the models the key can be used for. Empty means all the models.
+	AllowedModels []string `json:"allowedModels,omitempty"`
+	// TokenBudgets are the budgets of the key, whose Key is always of TokenBudgetKeyTypeAPIKey.
+	TokenBudgets []TokenBudget `json:"tokenBudgets,omitempty"`
+	// ExpiresAt is the time after which the key is rejected. Nil means the key does not expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// ModelPrice is the price of a model per million tokens, which corresponds to LLMModelPrice in api/v1alpha1/api.go.
+// The defaults of the optional prices are already applied.
+type ModelPrice struct {
+	// Model is the name of the model.
+	Model string `json:"model"`
+	// Backend is the name of the backend in the form of "name.namespace", or empty if the price applies to any backend.
+	Backend string `json:"backend,omitempty"`
+	// Input is the price of a million input tokens that are neither read from nor written to the cache.
+	Input float64 `json:"input"`
+	// Output is the price of a million output tokens that are not for reasoning.
+	Output float64 `json:"output"`
+	// CachedInput is the price of a million input tokens read from the cache.
+	CachedInput float64 `json:"cachedInput"`
+	// CacheWriteInput is the price of a million input tokens written to the cache.
+	CacheWriteInput float64 `json:"cacheWriteInput"`
+	// Reasoning is the price of a million output tokens generated for reasoning.
+	Reasoning float64 `json:"reasoning"`
+}
+
+// LLMRequestCostRounding specifies how the result of the CEL expression is rounded to an integer.
+type LLMRequestCostRounding string
+
+const (
+	// LLMRequestCostRoundingRound rounds the result to the nearest integer, rounding half away from zero.
+	LLMRequestCostRoundingRound LLMRequestCostRounding = "Round"
+	// LLMRequestCostRoundingFloor rounds the result down.
+	LLMRequestCostRoundingFloor LLMRequestCostRounding = "Floor"
+	// LLMRequestCostRoundingCeil rounds the result up.
+	LLMRequestCostRoundingCeil LLMRequestCostRounding = "Ceil"
+)
+
 // LLMRequestCostType specifies the kind of the request cost calculation.
 type LLMRequestCostType string
 
@@ -117,8 +239,16 @@ const (
 	LLMRequestCostTypeInputToken LLMRequestCostType = "InputToken"
 	// LLMRequestCostTypeTotalToken specifies that the request cost is calculated from the total token.
 	LLMRequestCostTypeTotalToken LLMRequestCostType = "TotalToken"
+	// LLMRequestCostTypeCachedInputToken specifies that the request cost is calculated from the input token read from the cache.
+	LLMRequestCostTypeCachedInputToken LLMRequestCostType = "CachedInputToken"
+	// LLMRequestCostTypeCacheWriteInputToken specifies that the request cost is calculated from the input token written to the cache.
+	LLMRequestCostTypeCacheWriteInputToken LLMRequestCostType = "CacheWriteInputToken"
+	// LLMRequestCostTypeReasoningToken specifies that the request cost is calculated from the reasoning token.
+	LLMRequestCostTypeReasoningToken LLMRequestCostType = "ReasoningToken"
 	// LLMRequestCostTypeCEL specifies that the request cost is calculated from the CEL expression.
 	LLMRequestCostTypeCEL LLMRequestCostType = "CEL"
+	// LLMRequestCostTypePrice specifies that the request cost is the monetary cost calculated from the prices.
+	LLMRequestCostTypePrice LLMRequestCostType = "Price"
 )
 
 // VersionedAPISchema corresponds to VersionedAPISchema in api/v1alpha1/api.go.
@@ -127,28 +257,124 @@ type VersionedAPISchema struct {
 	Name APISchemaName `json:"name"`
 	// Version is the version of the API schema. Optional.
 	Version string `json:"version,omitempty"`
+	// Deployments maps the model name to the deployment name. Only used by the AzureOpenAI schema. Optional.
+	Deployments map[string]string `json:"deployments,omitempty"`
 }
 
 // APISchemaName corresponds to APISchemaName in api/v1alpha1/api.go.
 type APISchemaName string
 
 const (
-	APISchemaOpenAI     APISchemaName = "OpenAI"
-	APISchemaAWSBedrock APISchemaName = "AWSBedrock"
+	APISchemaOpenAI      APISchemaName = "OpenAI"
+	APISchemaAWSBedrock  APISchemaName = "AWSBedrock"
+	APISchemaAnthropic   APISchemaName = "Anthropic"
+	APISchemaAzureOpenAI APISchemaName = "AzureOpenAI"
+	APISchemaGCPVertexAI APISchemaName = "GCPVertexAI"
 )
 
 // HeaderMatch is an alias for HTTPHeaderMatch of the Gateway API.
+//
+// In addition to the match types of the Gateway API, the Type can be [HeaderMatchPrefix].
 type HeaderMatch = gwapiv1.HTTPHeaderMatch
 
+// HeaderMatchPrefix is the match type of [HeaderMatch] that matches when the header value starts with the Value.
+const HeaderMatchPrefix gwapiv1.HeaderMatchType = "Prefix"
+
 // RouteRule corresponds to AIGatewayRoute in api/v1alpha1/api.go
 // besides the `Backends` field is modified to abstract the concept of a backend
 // at Envoy Gateway level to a simple name.
 type RouteRule struct {
-	// Headers is the list of headers to match for the routing decision.
-	// Currently, only exact match is supported.
-	Headers []HeaderMatch `json:"headers"`
+	// Headers is the list of headers to match for the routing decision. Each header is matched
+	// independently, i.e. the rule matches when any of the headers matches.
+	//
+	// This is equivalent to the Matches with one header per match, and the rule matches
+	// when either the Headers or the Matches match.
+	Headers []HeaderMatch `json:"headers,omitempty"`
+	// Matches is the list of matches for the routing decision. The rule matches when any of the matches matches.
+	Matches []RouteRuleMatch `json:"matches,omitempty"`
 	// Backends is the list of backends to which the request should be routed to when the headers match.
 	Backends []Backend `json:"backends"`
+	// LoadBalancingPolicy specifies how to select a backend among the Backends. Optional, and defaults to
+	// the weighted random selection.
+	LoadBalancingPolicy *LoadBalancingPolicy `json:"loadBalancingPolicy,omitempty"`
+	// CircuitBreaker temporarily excludes the backends in Backends that fail consecutively from the selection. Optional.
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"`
+	// Fallbacks is the ordered list of backends to retry the request on when the backend selected from
+	// Backends responds with the status code 429 or 5xx. Each fallback must have the Endpoint set.
+	// The streaming requests are never retried on the fallbacks.
+	Fallbacks []Backend `json:"fallbacks,omitempty"`
+	// Mirror is the backend that receives a copy of each chat completion request matching this rule in the background,
+	// whose response is discarded. The Endpoint must be set.
+	Mirror *Backend `json:"mirror,omitempty"`
+}
+
+// LoadBalancingPolicy corresponds to AIGatewayRouteRuleLoadBalancingPolicy in api/v1alpha1/api.go.
+type LoadBalancingPolicy struct {
+	// Type is the type of the load balancing policy.
+	Type LoadBalancingPolicyType `json:"type"`
+	// ConsistentHash is the session key of the consistent hashing, which is set when Type is ConsistentHash.
+	ConsistentHash *ConsistentHash `json:"consistentHash,omitempty"`
+}
+
+// ConsistentHash specifies the session key of the [LoadBalancingPolicyTypeConsistentHash].
+type ConsistentHash struct {
+	// Type is the source of the session key.
+	Type ConsistentHashType `json:"type"`
+	// Header is the lower-cased name of the request header whose value is used as the session key
+	// when Type is ConsistentHashTypeHeader.
+	Header string `json:"header,omitempty"`
+}
+
+// ConsistentHashType specifies the source of the session key of the consistent hashing.
+type ConsistentHashType string
+
+const (
+	// ConsistentHashTypeHeader uses the value of the request header as the session key.
+	ConsistentHashTypeHeader ConsistentHashType = "Header"
+	// ConsistentHashTypeUser uses the "user" field of the OpenAI request body as the session key.
+	ConsistentHashTypeUser ConsistentHashType = "User"
+)
+
+// CircuitBreaker is the circuit breaker of the backends in a [RouteRule].
+//
+// A backend is excluded from the selection for OpenDuration after ConsecutiveFailures consecutive failures,
+// and then a single probe request is sent to it to decide whether to include it again.
+type CircuitBreaker struct {
+	// ConsecutiveFailures is the number of the consecutive failures of a backend to open its circuit.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// OpenDuration is the duration for which an open backend is excluded from the selection.
+	OpenDuration time.Duration `json:"openDuration"`
+}
+
+// LoadBalancingPolicyType specifies the type of the load balancing policy.
+type LoadBalancingPolicyType string
+
+const (
+	// LoadBalancingPolicyTypeWeightedRandom selects a backend randomly with the probability proportional to its weight.
+	LoadBalancingPolicyTypeWeightedRandom LoadBalancingPolicyType = "WeightedRandom"
+	// LoadBalancingPolicyTypeAdaptive biases the weighted random selection away from the backends with the slow
+	// time to first token, the high error rate or many in-flight requests.
+	LoadBalancingPolicyTypeAdaptive LoadBalancingPolicyType = "Adaptive"
+	// LoadBalancingPolicyTypeConsistentHash selects the backend by the weighted consistent hashing of the session key
+	// specified by [ConsistentHash], and falls back to the weighted random selection when the key is missing.
+	LoadBalancingPolicyTypeConsistentHash LoadBalancingPolicyType = "ConsistentHash"
+)
+
+// RouteRuleMatch corresponds to AIGatewayRouteRuleMatch in api/v1alpha1/api.go.
+type RouteRuleMatch struct {
+	// Headers is the list of headers to match. The match matches when all the headers match.
+	Headers []HeaderMatch `json:"headers"`
+	// EstimatedInputTokens is the range of the estimated number of the input tokens of the request to match,
+	// which is AND-ed with the Headers. Optional.
+	EstimatedInputTokens *TokenRange `json:"estimatedInputTokens,omitempty"`
+}
+
+// TokenRange is a range of the number of tokens.
+type TokenRange struct {
+	// Min is the inclusive lower bound. Optional.
+	Min *int64 `json:"min,omitempty"`
+	// Max is the exclusive upper bound. Optional.
+	Max *int64 `json:"max,omitempty"`
 }
 
 // Backend corresponds to AIGatewayRouteRuleBackendRef in api/v1alpha1/api.go
@@ -161,17 +387,44 @@ type Backend struct {
 	Schema VersionedAPISchema `json:"schema"`
 	// Weight is the weight of the backend in the routing decision.
 	Weight int `json:"weight"`
+	// ModelNameOverride is the model name sent to the backend instead of the model name in the request. Optional.
+	//
+	// The original model name is still used for the [Config.ModelNameHeaderKey] header and the request cost calculation.
+	ModelNameOverride string `json:"modelNameOverride,omitempty"`
+	// Endpoint is the base URL of the backend, e.g. "https://api.openai.com:443". This is only used when
+	// the request is sent to the backend by the AI Gateway filter itself, i.e. for [RouteRule.Fallbacks].
+	Endpoint string `json:"endpoint,omitempty"`
+	// EndpointTLS is the TLS configuration to connect to the Endpoint, which is derived from the BackendTLSPolicy
+	// targeting the backend. Optional.
+	EndpointTLS *BackendTLS `json:"endpointTLS,omitempty"`
 	// Auth is the authn/z configuration for the backend. Optional.
 	// TODO: refactor after https://github.com/envoyproxy/ai-gateway/pull/43.
 	Auth *BackendAuth `json:"auth,omitempty"`
 }
 
+// BackendTLS is the TLS configuration to connect to the [Backend.Endpoint].
+type BackendTLS struct {
+	// Hostname is the server name used for the SNI and to verify the certificate of the backend.
+	Hostname string `json:"hostname"`
+	// CACertificates are the PEM encoded CA certificates to verify the certificate of the backend.
+	// The system CA certificates are used when empty.
+	CACertificates string `json:"caCertificates,omitempty"`
+}
+
 // BackendAuth corresponds partially to BackendSecurityPolicy in api/v1alpha1/api.go.
 type BackendAuth struct {
 	// APIKey is a location of the api key secret file.
 	APIKey *APIKeyAuth `json:"apiKey,omitempty"`
 	// AWSAuth specifies the location of the AWS credential file and region.
 	AWSAuth *AWSAuth `json:"aws,omitempty"`
+	// AzureAuth specifies the location of the Azure access token file.
+	AzureAuth *AzureAuth `json:"azure,omitempty"`
+}
+
+// AzureAuth defines the file of the Microsoft Entra ID access token that will be mounted to the external proc.
+// The file is refreshed by the controller before the token expires.
+type AzureAuth struct {
+	Filename string `json:"filename"`
 }
 
 // AWSAuth defines the credentials needed to access AWS.
@@ -183,6 +436,9 @@ type AWSAuth struct {
 // APIKeyAuth defines the file that will be mounted to the external proc.
 type APIKeyAuth struct {
 	Filename string `json:"filename"`
+	// HeaderName is the name of the header to which the raw api key is set, e.g. "api-key" for Azure OpenAI.
+	// When empty, the api key is set to the "Authorization" header in the "Bearer <key>" form.
+	HeaderName string `json:"headerName,omitempty"`
 }
 
 // UnmarshalConfigYaml reads the file at the given path and unmarshals it into a Config struct.
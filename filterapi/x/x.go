@@ -14,11 +14,22 @@ import (
 
 // NewCustomRouter is the function to create a custom router over the default router.
 // This is nil by default and can be set by the custom build of external processor.
+//
+// To route on the parsed request body in addition to the headers, set [NewCustomRouterV2] instead.
 var NewCustomRouter NewCustomRouterFn
 
+// NewCustomRouterV2 is the function to create a custom [RouterV2] over the default router.
+// This is nil by default and can be set by the custom build of external processor.
+// This takes precedence over [NewCustomRouter] when both are set.
+var NewCustomRouterV2 NewCustomRouterV2Fn
+
 // ErrNoMatchingRule is the error the router function must return if there is no matching rule.
 var ErrNoMatchingRule = errors.New("no matching rule found")
 
+// ErrNoAvailableBackend is the error the router function returns if none of the backends of the matching rule
+// is available, e.g. the circuits of all of them are open.
+var ErrNoAvailableBackend = errors.New("no available backend")
+
 // NewCustomRouterFn is the function signature for [NewCustomRouter].
 //
 // It accepts the exptproc config passed to the AI Gateway filter and returns a [Router].
@@ -39,3 +50,37 @@ type Router interface {
 	// Returns the backend.
 	Calculate(requestHeaders map[string]string) (backend *filterapi.Backend, err error)
 }
+
+// NewCustomRouterV2Fn is the function signature for [NewCustomRouterV2].
+//
+// This is the same as [NewCustomRouterFn] except that it returns a [RouterV2].
+// The defaultRouter only routes on the headers as it does for [NewCustomRouterFn].
+type NewCustomRouterV2Fn func(defaultRouter Router, config *filterapi.Config) RouterV2
+
+// RouterV2 is the version of [Router] that routes on the parsed request body in addition to the request headers.
+//
+// RouterV2 must be goroutine-safe as it is shared across multiple requests.
+type RouterV2 interface {
+	// Calculate determines the backend to route to based on the request headers and the parsed request.
+	//
+	// The request headers are the same as the ones given to [Router.Calculate]. The request is a copy of the
+	// fields of the parsed request body, so modifying it has no effect on the request sent to the backend.
+	//
+	// Returns the backend.
+	Calculate(requestHeaders map[string]string, request Request) (backend *filterapi.Backend, err error)
+}
+
+// Request is the read-only view of the parsed request body given to [RouterV2.Calculate].
+// The fields not applicable to the API of the request are left zero, e.g. MessageCount for the embeddings.
+type Request struct {
+	// Model is the model name in the request, which is the same as the [filterapi.Config.ModelNameHeaderKey] header.
+	Model string
+	// Stream is true if the request asks for the streaming response.
+	Stream bool
+	// MessageCount is the number of the messages in the chat completion or the converse request.
+	MessageCount int
+	// Tools is the names of the tools declared in the request.
+	Tools []string
+	// User is the "user" field of the OpenAI request body, which identifies the end user.
+	User string
+}
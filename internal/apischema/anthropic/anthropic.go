@@ -0,0 +1,243 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package anthropic contains the Anthropic Messages API schema definitions.
+// Only the subset of the API that is necessary for the translation from/to the OpenAI schema is defined here.
+// https://docs.anthropic.com/en/api/messages
+package anthropic
+
+const (
+	// MessageRoleUser is the role of the user message.
+	MessageRoleUser = "user"
+	// MessageRoleAssistant is the role of the assistant message.
+	MessageRoleAssistant = "assistant"
+
+	// ContentBlockTypeText is the type of the text content block.
+	ContentBlockTypeText = "text"
+	// ContentBlockTypeImage is the type of the image content block.
+	ContentBlockTypeImage = "image"
+	// ContentBlockTypeToolUse is the type of the tool use content block.
+	ContentBlockTypeToolUse = "tool_use"
+	// ContentBlockTypeToolResult is the type of the tool result content block.
+	ContentBlockTypeToolResult = "tool_result"
+
+	// ImageSourceTypeBase64 is the type of the base64 encoded image source.
+	ImageSourceTypeBase64 = "base64"
+	// ImageSourceTypeURL is the type of the URL image source.
+	ImageSourceTypeURL = "url"
+
+	// StopReasonEndTurn is a StopReason enum value.
+	StopReasonEndTurn = "end_turn"
+	// StopReasonMaxTokens is a StopReason enum value.
+	StopReasonMaxTokens = "max_tokens"
+	// StopReasonStopSequence is a StopReason enum value.
+	StopReasonStopSequence = "stop_sequence"
+	// StopReasonToolUse is a StopReason enum value.
+	StopReasonToolUse = "tool_use"
+
+	// ToolChoiceTypeAuto lets the model decide whether to call any provided tools or not.
+	ToolChoiceTypeAuto = "auto"
+	// ToolChoiceTypeAny tells the model that it must use one of the provided tools.
+	ToolChoiceTypeAny = "any"
+	// ToolChoiceTypeTool forces the model to always use a particular tool.
+	ToolChoiceTypeTool = "tool"
+	// ToolChoiceTypeNone prevents the model from using any tools.
+	ToolChoiceTypeNone = "none"
+)
+
+// Stream event types of the Messages API.
+// https://docs.anthropic.com/en/api/messages-streaming#event-types
+const (
+	StreamEventTypeMessageStart      = "message_start"
+	StreamEventTypeContentBlockStart = "content_block_start"
+	StreamEventTypeContentBlockDelta = "content_block_delta"
+	StreamEventTypeContentBlockStop  = "content_block_stop"
+	StreamEventTypeMessageDelta      = "message_delta"
+	StreamEventTypeMessageStop       = "message_stop"
+	StreamEventTypePing              = "ping"
+	StreamEventTypeError             = "error"
+
+	// DeltaTypeText is the type of the delta in content_block_delta events for text blocks.
+	DeltaTypeText = "text_delta"
+	// DeltaTypeInputJSON is the type of the delta in content_block_delta events for tool use blocks.
+	DeltaTypeInputJSON = "input_json_delta"
+)
+
+// MessagesRequest is the request body of the Messages API.
+// https://docs.anthropic.com/en/api/messages
+type MessagesRequest struct {
+	// Model is the model that will complete the prompt.
+	Model string `json:"model"`
+	// Messages is the list of input messages.
+	Messages []Message `json:"messages"`
+	// System is the system prompt.
+	System []ContentBlock `json:"system,omitempty"`
+	// MaxTokens is the maximum number of tokens to generate before stopping. This is required by the API.
+	MaxTokens int64 `json:"max_tokens"`
+	// Metadata is an object describing metadata about the request.
+	Metadata *Metadata `json:"metadata,omitempty"`
+	// StopSequences are the custom text sequences that will cause the model to stop generating.
+	StopSequences []string `json:"stop_sequences,omitempty"`
+	// Stream indicates whether to incrementally stream the response using server-sent events.
+	Stream bool `json:"stream,omitempty"`
+	// Temperature is the amount of randomness injected into the response.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// TopP is used for nucleus sampling.
+	TopP *float64 `json:"top_p,omitempty"`
+	// Tools are the definitions of tools that the model may use.
+	Tools []Tool `json:"tools,omitempty"`
+	// ToolChoice specifies how the model should use the provided tools.
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+}
+
+// Metadata is an object describing metadata about the request.
+type Metadata struct {
+	// UserID is an external identifier for the user who is associated with the request.
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Message is a single input message.
+type Message struct {
+	// Role is either "user" or "assistant".
+	Role string `json:"role"`
+	// Content is the list of content blocks of the message.
+	Content []ContentBlock `json:"content"`
+}
+
+// ContentBlock is the union of all content block types used in the request and the response.
+// The Type field determines which of the other fields are populated.
+type ContentBlock struct {
+	// Type is one of "text", "image", "tool_use" or "tool_result".
+	Type string `json:"type"`
+	// Text is set for the "text" blocks.
+	Text string `json:"text,omitempty"`
+	// Source is set for the "image" blocks.
+	Source *ImageSource `json:"source,omitempty"`
+	// ID is set for the "tool_use" blocks.
+	ID string `json:"id,omitempty"`
+	// Name is set for the "tool_use" blocks.
+	Name string `json:"name,omitempty"`
+	// Input is set for the "tool_use" blocks.
+	Input any `json:"input,omitempty"`
+	// ToolUseID is set for the "tool_result" blocks.
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	// Content is set for the "tool_result" blocks.
+	Content []ContentBlock `json:"content,omitempty"`
+	// IsError is set for the "tool_result" blocks.
+	IsError bool `json:"is_error,omitempty"`
+}
+
+// ImageSource is the source of the image content block.
+type ImageSource struct {
+	// Type is either "base64" or "url".
+	Type string `json:"type"`
+	// MediaType is the media type of the base64 encoded image, e.g. "image/png".
+	MediaType string `json:"media_type,omitempty"`
+	// Data is the base64 encoded image data.
+	Data string `json:"data,omitempty"`
+	// URL is the URL of the image.
+	URL string `json:"url,omitempty"`
+}
+
+// Tool is the definition of a tool that the model may use.
+// https://docs.anthropic.com/en/docs/build-with-claude/tool-use
+type Tool struct {
+	// Name is the name of the tool.
+	Name string `json:"name"`
+	// Description is the description of the tool.
+	Description string `json:"description,omitempty"`
+	// InputSchema is the JSON schema for the tool input.
+	InputSchema any `json:"input_schema"`
+}
+
+// ToolChoice specifies how the model should use the provided tools.
+type ToolChoice struct {
+	// Type is one of "auto", "any", "tool" or "none".
+	Type string `json:"type"`
+	// Name is the name of the tool to use. This is only set when Type is "tool".
+	Name string `json:"name,omitempty"`
+}
+
+// MessagesResponse is the response body of the Messages API.
+type MessagesResponse struct {
+	// ID is the unique object identifier.
+	ID string `json:"id"`
+	// Type is always "message".
+	Type string `json:"type"`
+	// Role is always "assistant".
+	Role string `json:"role"`
+	// Content is the content generated by the model.
+	Content []ContentBlock `json:"content"`
+	// Model is the model that handled the request.
+	Model string `json:"model"`
+	// StopReason is the reason that the model stopped.
+	StopReason *string `json:"stop_reason"`
+	// StopSequence is the custom stop sequence that was generated, if any.
+	StopSequence *string `json:"stop_sequence"`
+	// Usage is the billing and rate-limit usage.
+	Usage Usage `json:"usage"`
+}
+
+// Usage is the billing and rate-limit usage.
+type Usage struct {
+	// InputTokens is the number of input tokens which were used.
+	InputTokens int `json:"input_tokens"`
+	// OutputTokens is the number of output tokens which were used.
+	OutputTokens int `json:"output_tokens"`
+	// CacheCreationInputTokens is the number of input tokens used to create the cache entry.
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	// CacheReadInputTokens is the number of input tokens read from the cache.
+	CacheReadInputTokens int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// StreamEvent is the union of all possible event types in the streamed response.
+// https://docs.anthropic.com/en/api/messages-streaming
+type StreamEvent struct {
+	// Type is the type of the event.
+	Type string `json:"type"`
+	// Message is set for the "message_start" events.
+	Message *MessagesResponse `json:"message,omitempty"`
+	// Index is the index of the content block for the "content_block_*" events.
+	Index int `json:"index"`
+	// ContentBlock is set for the "content_block_start" events.
+	ContentBlock *ContentBlock `json:"content_block,omitempty"`
+	// Delta is set for the "content_block_delta" and "message_delta" events.
+	Delta *StreamEventDelta `json:"delta,omitempty"`
+	// Usage is set for the "message_delta" events. The token counts are cumulative.
+	Usage *Usage `json:"usage,omitempty"`
+	// Error is set for the "error" events.
+	Error *ErrorDetail `json:"error,omitempty"`
+}
+
+// StreamEventDelta is the delta of either the content block or the message.
+type StreamEventDelta struct {
+	// Type is either "text_delta" or "input_json_delta" for the "content_block_delta" events.
+	Type string `json:"type,omitempty"`
+	// Text is set for the "text_delta" deltas.
+	Text string `json:"text,omitempty"`
+	// PartialJSON is set for the "input_json_delta" deltas.
+	PartialJSON string `json:"partial_json,omitempty"`
+	// StopReason is set for the "message_delta" events.
+	StopReason *string `json:"stop_reason,omitempty"`
+	// StopSequence is set for the "message_delta" events.
+	StopSequence *string `json:"stop_sequence,omitempty"`
+}
+
+// Error is the error response body of the Messages API.
+// https://docs.anthropic.com/en/api/errors
+type Error struct {
+	// Type is always "error".
+	Type string `json:"type"`
+	// Error is the details of the error.
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail is the details of the error.
+type ErrorDetail struct {
+	// Type is the type of the error, e.g. "invalid_request_error", "overloaded_error".
+	Type string `json:"type"`
+	// Message is a human-readable error message.
+	Message string `json:"message"`
+}
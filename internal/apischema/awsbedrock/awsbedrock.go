@@ -279,8 +279,8 @@ type ToolResultContentBlock struct {
 	// A tool result that is text.
 	Text *string `json:"text" type:"string,omitempty"`
 
-	// A tool result that is JSON format data.
-	JSON *string `json:"json" type:"string,omitempty"`
+	// A tool result that is JSON format data. This is an arbitrary JSON value, typically an object.
+	JSON any `json:"json,omitempty"`
 }
 
 // ToolResultBlock A tool result block that contains the results for a tool request that the
@@ -348,7 +348,7 @@ type ConverseResponse struct {
 	// Metrics for the call to Converse.
 	//
 	// Metrics is a required field
-	Metrics *ConverseMetrics `json:"metrics"`
+	Metrics *ConverseMetrics `json:"metrics,omitempty"`
 
 	// The result from the call to Converse.
 	//
@@ -381,12 +381,16 @@ type TokenUsage struct {
 	InputTokens  int `json:"inputTokens"`
 	OutputTokens int `json:"outputTokens"`
 	TotalTokens  int `json:"totalTokens"`
+	// CacheReadInputTokens is the number of the input tokens read from the cache. This is not included in the input tokens.
+	CacheReadInputTokens int `json:"cacheReadInputTokens,omitempty"`
+	// CacheWriteInputTokens is the number of the input tokens written to the cache. This is not included in the input tokens.
+	CacheWriteInputTokens int `json:"cacheWriteInputTokens,omitempty"`
 }
 
 // ConverseStreamEvent is the union of all possible event types in the AWS Bedrock API:
 // https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_ConverseStream.html
 type ConverseStreamEvent struct {
-	ContentBlockIndex int                                   `json:"contentBlockIndex,omitempty"`
+	ContentBlockIndex *int                                  `json:"contentBlockIndex,omitempty"`
 	Delta             *ConverseStreamEventContentBlockDelta `json:"delta,omitempty"`
 	Role              *string                               `json:"role,omitempty"`
 	StopReason        *string                               `json:"stopReason,omitempty"`
@@ -508,3 +512,43 @@ type ToolSpecification struct {
 	// Name is a required field
 	Name *string `json:"name"`
 }
+
+// TitanEmbeddingRequest is the InvokeModel request body of the Amazon Titan Text Embeddings models.
+// https://docs.aws.amazon.com/bedrock/latest/userguide/model-parameters-titan-embed-text.html
+type TitanEmbeddingRequest struct {
+	// InputText is the text to convert to an embedding.
+	InputText string `json:"inputText"`
+	// Dimensions is the number of dimensions the output embedding should have. Only supported by V2.
+	Dimensions *int `json:"dimensions,omitempty"`
+}
+
+// TitanEmbeddingResponse is the InvokeModel response body of the Amazon Titan Text Embeddings models.
+type TitanEmbeddingResponse struct {
+	// Embedding is the embedding vector of the input.
+	Embedding []float64 `json:"embedding"`
+	// InputTextTokenCount is the number of tokens in the input.
+	InputTextTokenCount int `json:"inputTextTokenCount"`
+}
+
+// CohereEmbeddingRequest is the InvokeModel request body of the Cohere Embed models.
+// https://docs.aws.amazon.com/bedrock/latest/userguide/model-parameters-embed.html
+type CohereEmbeddingRequest struct {
+	// Texts is the array of strings for the model to embed.
+	Texts []string `json:"texts"`
+	// InputType prepends special tokens to differentiate each type from one another, e.g. "search_document".
+	InputType string `json:"input_type"`
+	// Truncate specifies how the API handles inputs longer than the maximum token length.
+	Truncate string `json:"truncate,omitempty"`
+}
+
+// CohereEmbeddingResponse is the InvokeModel response body of the Cohere Embed models.
+type CohereEmbeddingResponse struct {
+	// ID is the identifier for the response.
+	ID string `json:"id"`
+	// Embeddings is the array of the embeddings, one for each input text.
+	Embeddings [][]float64 `json:"embeddings"`
+	// Texts is the array of the input texts.
+	Texts []string `json:"texts,omitempty"`
+	// ResponseType is the type of the response, e.g. "embeddings_floats".
+	ResponseType string `json:"response_type"`
+}
@@ -0,0 +1,200 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package gcpvertexai contains the Gemini generateContent API schema definitions served by GCP Vertex AI.
+// Only the subset of the API that is necessary for the translation from/to the OpenAI schema is defined here.
+// https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/inference
+package gcpvertexai
+
+const (
+	// RoleUser is the role of the user content, which is also used for the function responses.
+	RoleUser = "user"
+	// RoleModel is the role of the content generated by the model.
+	RoleModel = "model"
+
+	// FinishReasonStop is a FinishReason enum value.
+	FinishReasonStop = "STOP"
+	// FinishReasonMaxTokens is a FinishReason enum value.
+	FinishReasonMaxTokens = "MAX_TOKENS"
+	// FinishReasonSafety is a FinishReason enum value.
+	FinishReasonSafety = "SAFETY"
+	// FinishReasonRecitation is a FinishReason enum value.
+	FinishReasonRecitation = "RECITATION"
+	// FinishReasonBlocklist is a FinishReason enum value.
+	FinishReasonBlocklist = "BLOCKLIST"
+	// FinishReasonProhibitedContent is a FinishReason enum value.
+	FinishReasonProhibitedContent = "PROHIBITED_CONTENT"
+	// FinishReasonSPII is a FinishReason enum value.
+	FinishReasonSPII = "SPII"
+
+	// FunctionCallingModeAuto lets the model decide whether to call functions or not.
+	FunctionCallingModeAuto = "AUTO"
+	// FunctionCallingModeAny forces the model to call one of the functions.
+	FunctionCallingModeAny = "ANY"
+	// FunctionCallingModeNone prevents the model from calling functions.
+	FunctionCallingModeNone = "NONE"
+)
+
+// GenerateContentRequest is the request body of the generateContent and streamGenerateContent methods.
+// https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/inference#request
+type GenerateContentRequest struct {
+	// Contents is the content of the current conversation with the model.
+	Contents []Content `json:"contents"`
+	// SystemInstruction is the instruction for the model to steer it toward better performance.
+	SystemInstruction *Content `json:"systemInstruction,omitempty"`
+	// Tools is the list of tools the model may use to generate the next response.
+	Tools []Tool `json:"tools,omitempty"`
+	// ToolConfig is the tool configuration for any tools specified in the request.
+	ToolConfig *ToolConfig `json:"toolConfig,omitempty"`
+	// GenerationConfig is the configuration options for model generation and outputs.
+	GenerationConfig *GenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// Content is the multi-part content of a message.
+type Content struct {
+	// Role is either "user" or "model".
+	Role string `json:"role,omitempty"`
+	// Parts is the ordered parts that constitute a single message.
+	Parts []Part `json:"parts"`
+}
+
+// Part is a datatype containing media that is part of a multi-part Content message.
+// Exactly one of the fields is set.
+type Part struct {
+	// Text is the text content.
+	Text string `json:"text,omitempty"`
+	// InlineData is the inline media bytes.
+	InlineData *Blob `json:"inlineData,omitempty"`
+	// FileData is the URI based media.
+	FileData *FileData `json:"fileData,omitempty"`
+	// FunctionCall is the function call predicted by the model.
+	FunctionCall *FunctionCall `json:"functionCall,omitempty"`
+	// FunctionResponse is the result of the function call.
+	FunctionResponse *FunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// Blob is the raw media bytes.
+type Blob struct {
+	// MimeType is the IANA standard MIME type of the source data.
+	MimeType string `json:"mimeType"`
+	// Data is the base64 encoded raw bytes.
+	Data string `json:"data"`
+}
+
+// FileData is the URI based media.
+type FileData struct {
+	// MimeType is the IANA standard MIME type of the source data.
+	MimeType string `json:"mimeType"`
+	// FileURI is the URI of the file, either a Cloud Storage URI or a HTTP URL.
+	FileURI string `json:"fileUri"`
+}
+
+// FunctionCall is the function call predicted by the model.
+type FunctionCall struct {
+	// Name is the name of the function to call.
+	Name string `json:"name"`
+	// Args is the function parameters and values in JSON object format.
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// FunctionResponse is the result of a FunctionCall.
+type FunctionResponse struct {
+	// Name is the name of the function that was called.
+	Name string `json:"name"`
+	// Response is the function response in JSON object format.
+	Response map[string]any `json:"response"`
+}
+
+// Tool is the tool details that the model may use to generate a response.
+type Tool struct {
+	// FunctionDeclarations is the list of functions available to the model.
+	FunctionDeclarations []FunctionDeclaration `json:"functionDeclarations,omitempty"`
+}
+
+// FunctionDeclaration is the structured representation of a function declaration.
+type FunctionDeclaration struct {
+	// Name is the name of the function.
+	Name string `json:"name"`
+	// Description is the description and purpose of the function.
+	Description string `json:"description,omitempty"`
+	// Parameters is the parameters of the function in the OpenAPI schema object format.
+	Parameters any `json:"parameters,omitempty"`
+}
+
+// ToolConfig is the tool configuration shared by all tools in the request.
+type ToolConfig struct {
+	// FunctionCallingConfig is the function calling config.
+	FunctionCallingConfig *FunctionCallingConfig `json:"functionCallingConfig,omitempty"`
+}
+
+// FunctionCallingConfig configures the function calling behavior.
+type FunctionCallingConfig struct {
+	// Mode is one of "AUTO", "ANY" or "NONE".
+	Mode string `json:"mode,omitempty"`
+	// AllowedFunctionNames limits the functions the model will call. This is only set when Mode is "ANY".
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// GenerationConfig is the configuration options for model generation and outputs.
+type GenerationConfig struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"topP,omitempty"`
+	CandidateCount   *int     `json:"candidateCount,omitempty"`
+	MaxOutputTokens  *int64   `json:"maxOutputTokens,omitempty"`
+	StopSequences    []string `json:"stopSequences,omitempty"`
+	PresencePenalty  *float32 `json:"presencePenalty,omitempty"`
+	FrequencyPenalty *float32 `json:"frequencyPenalty,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	ResponseMimeType string   `json:"responseMimeType,omitempty"`
+}
+
+// GenerateContentResponse is the response body of the generateContent method, as well as
+// each event of the streamGenerateContent method.
+// https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/inference#response
+type GenerateContentResponse struct {
+	// Candidates is the list of the generated responses.
+	Candidates []Candidate `json:"candidates,omitempty"`
+	// UsageMetadata is the token usage of the request. In the streamed response, the counts are cumulative.
+	UsageMetadata *UsageMetadata `json:"usageMetadata,omitempty"`
+	// ModelVersion is the model version used to generate the response.
+	ModelVersion string `json:"modelVersion,omitempty"`
+}
+
+// Candidate is a response candidate generated from the model.
+type Candidate struct {
+	// Index is the index of the candidate.
+	Index int64 `json:"index,omitempty"`
+	// Content is the generated content.
+	Content Content `json:"content"`
+	// FinishReason is the reason why the model stopped generating tokens. This is empty until the model finishes.
+	FinishReason string `json:"finishReason,omitempty"`
+}
+
+// UsageMetadata is the token usage of the request.
+type UsageMetadata struct {
+	// PromptTokenCount is the number of tokens in the request.
+	PromptTokenCount int `json:"promptTokenCount,omitempty"`
+	// CandidatesTokenCount is the number of tokens in the response(s).
+	CandidatesTokenCount int `json:"candidatesTokenCount,omitempty"`
+	// TotalTokenCount is the total number of tokens.
+	TotalTokenCount int `json:"totalTokenCount,omitempty"`
+}
+
+// Error is the error response body.
+// https://cloud.google.com/apis/design/errors#http_mapping
+type Error struct {
+	// Error is the details of the error.
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail is the details of the error.
+type ErrorDetail struct {
+	// Code is the HTTP status code.
+	Code int `json:"code"`
+	// Message is a human-readable error message.
+	Message string `json:"message"`
+	// Status is the canonical error code, e.g. "INVALID_ARGUMENT", "RESOURCE_EXHAUSTED".
+	Status string `json:"status"`
+}
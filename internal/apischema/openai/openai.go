@@ -149,6 +149,20 @@ func (c *ChatCompletionContentPartUserUnionParam) UnmarshalJSON(data []byte) err
 	return nil
 }
 
+// MarshalJSON implements [json.Marshaler].
+func (c ChatCompletionContentPartUserUnionParam) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.TextContent != nil:
+		return json.Marshal(c.TextContent)
+	case c.InputAudioContent != nil:
+		return json.Marshal(c.InputAudioContent)
+	case c.ImageContent != nil:
+		return json.Marshal(c.ImageContent)
+	default:
+		return nil, fmt.Errorf("no content is set in ChatCompletionContentPartUserUnionParam")
+	}
+}
+
 type StringOrArray struct {
 	Value interface{}
 }
@@ -171,6 +185,11 @@ func (s *StringOrArray) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("cannot unmarshal JSON data as string or array of string")
 }
 
+// MarshalJSON implements [json.Marshaler].
+func (s StringOrArray) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Value)
+}
+
 type StringOrUserRoleContentUnion struct {
 	Value interface{}
 }
@@ -193,6 +212,11 @@ func (s *StringOrUserRoleContentUnion) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("cannot unmarshal JSON data as string or array of content parts")
 }
 
+// MarshalJSON implements [json.Marshaler].
+func (s StringOrUserRoleContentUnion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Value)
+}
+
 type ChatCompletionMessageParamUnion struct {
 	Value interface{}
 	Type  string
@@ -253,6 +277,11 @@ func (c *ChatCompletionMessageParamUnion) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON implements [json.Marshaler].
+func (c ChatCompletionMessageParamUnion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Value)
+}
+
 // ChatCompletionUserMessageParam Messages sent by an end user, containing prompts or additional context
 // information.
 type ChatCompletionUserMessageParam struct {
@@ -326,13 +355,70 @@ type ChatCompletionAssistantMessageParamContent struct {
 	Text *string `json:"text,omitempty"`
 }
 
+// UnmarshalJSON implements [json.Unmarshaler].
+//
+// In the OpenAI API, the content is either a string or an array of text and refusal parts. The text parts are
+// concatenated into Text. The object form of this type itself is also accepted.
+func (c *ChatCompletionAssistantMessageParamContent) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		c.Type = ChatCompletionAssistantMessageParamContentTypeText
+		c.Text = &str
+		return nil
+	}
+	type content ChatCompletionAssistantMessageParamContent
+	var parts []content
+	if err := json.Unmarshal(data, &parts); err == nil {
+		var text strings.Builder
+		for _, part := range parts {
+			switch {
+			case part.Type == ChatCompletionAssistantMessageParamContentTypeRefusal:
+				c.Type, c.Refusal = part.Type, part.Refusal
+				return nil
+			case part.Text != nil:
+				text.WriteString(*part.Text)
+			}
+		}
+		c.Type = ChatCompletionAssistantMessageParamContentTypeText
+		c.Text = ptrString(text.String())
+		return nil
+	}
+	var obj content
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("cannot unmarshal JSON data as string, array of content parts or content part: %w", err)
+	}
+	*c = ChatCompletionAssistantMessageParamContent(obj)
+	return nil
+}
+
+// MarshalJSON implements [json.Marshaler].
+//
+// This emits the string form for the text content and the array form for the refusal content, which are accepted by the
+// OpenAI API, and null when neither is set, e.g. the message only has tool calls.
+func (c ChatCompletionAssistantMessageParamContent) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Type == ChatCompletionAssistantMessageParamContentTypeRefusal && c.Refusal != nil:
+		type content ChatCompletionAssistantMessageParamContent
+		return json.Marshal([]content{content(c)})
+	case c.Text != nil:
+		return json.Marshal(*c.Text)
+	default:
+		return []byte("null"), nil
+	}
+}
+
+func ptrString(s string) *string { return &s }
+
 // ChatCompletionAssistantMessageParam Messages sent by the model in response to user messages.
 type ChatCompletionAssistantMessageParam struct {
 	// The role of the messages author, in this case `assistant`.
 	Role string `json:"role"`
 	// Data about a previous audio response from the model.
 	// [Learn more](https://platform.openai.com/docs/guides/audio).
-	Audio ChatCompletionAssistantMessageParamAudio `json:"audio,omitempty"`
+	Audio *ChatCompletionAssistantMessageParamAudio `json:"audio,omitempty"`
 	// The contents of the assistant message. Required unless `tool_calls` or
 	// `function_call` is specified.
 	Content ChatCompletionAssistantMessageParamContent `json:"content"`
@@ -431,6 +517,11 @@ type ChatCompletionRequest struct {
 	// refs: https://platform.openai.com/docs/api-reference/chat/create#chat-create-max_tokens
 	MaxTokens *int64 `json:"max_tokens,omitempty"` //nolint:tagliatelle //follow openai api
 
+	// MaxCompletionTokens An upper bound for the number of tokens that can be generated for a completion,
+	// including visible output tokens and reasoning tokens.
+	// Docs: https://platform.openai.com/docs/api-reference/chat/create#chat-create-max_completion_tokens
+	MaxCompletionTokens *int64 `json:"max_completion_tokens,omitempty"` //nolint:tagliatelle //follow openai api
+
 	// N: LLM Gateway does not support multiple completions.
 	// The only accepted value is 1.
 	// Docs: https://platform.openai.com/docs/api-reference/chat/create#chat-create-n
@@ -668,6 +759,25 @@ type ChatCompletionResponseUsage struct {
 	CompletionTokens int `json:"completion_tokens,omitempty"`
 	PromptTokens     int `json:"prompt_tokens,omitempty"`
 	TotalTokens      int `json:"total_tokens,omitempty"`
+	// PromptTokensDetails is the breakdown of the prompt tokens.
+	PromptTokensDetails *PromptTokensDetails `json:"prompt_tokens_details,omitempty"` //nolint:tagliatelle //follow openai api
+	// CompletionTokensDetails is the breakdown of the completion tokens.
+	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"` //nolint:tagliatelle //follow openai api
+}
+
+// PromptTokensDetails is described in the OpenAI API documentation:
+// https://platform.openai.com/docs/api-reference/chat/object#chat/object-usage
+type PromptTokensDetails struct {
+	// CachedTokens is the number of the prompt tokens read from the cache. This is included in the prompt tokens.
+	CachedTokens int `json:"cached_tokens,omitempty"` //nolint:tagliatelle //follow openai api
+}
+
+// CompletionTokensDetails is described in the OpenAI API documentation:
+// https://platform.openai.com/docs/api-reference/chat/object#chat/object-usage
+type CompletionTokensDetails struct {
+	// ReasoningTokens is the number of the tokens generated by the model for reasoning. This is included in
+	// the completion tokens.
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"` //nolint:tagliatelle //follow openai api
 }
 
 // ChatCompletionResponseChunk is described in the OpenAI API documentation:
@@ -695,6 +805,9 @@ func (c *ChatCompletionResponseChunk) String() string {
 // ChatCompletionResponseChunkChoice is described in the OpenAI API documentation:
 // https://platform.openai.com/docs/api-reference/chat/streaming#chat/streaming-choices
 type ChatCompletionResponseChunkChoice struct {
+	// The index of the choice in the list of choices. This is omitted for the first choice to keep the chunks of
+	// the single choice responses compact.
+	Index        int64                                   `json:"index,omitempty"`
 	Delta        *ChatCompletionResponseChunkChoiceDelta `json:"delta,omitempty"`
 	FinishReason ChatCompletionChoicesFinishReason       `json:"finish_reason,omitempty"`
 }
@@ -707,6 +820,204 @@ type ChatCompletionResponseChunkChoiceDelta struct {
 	ToolCalls []ChatCompletionMessageToolCallParam `json:"tool_calls,omitempty"`
 }
 
+// CompletionRequest is described in the OpenAI API documentation
+// https://platform.openai.com/docs/api-reference/completions/create
+type CompletionRequest struct {
+	// Model: ID of the model to use.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-model
+	Model string `json:"model"`
+
+	// Prompt: The prompt(s) to generate completions for, encoded as a string, array of strings,
+	// array of tokens, or array of token arrays.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-prompt
+	Prompt CompletionRequestPrompt `json:"prompt"`
+
+	// BestOf: Generates best_of completions server-side and returns the "best".
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-best_of
+	BestOf *int `json:"best_of,omitempty"` //nolint:tagliatelle //follow openai api
+
+	// Echo: Echo back the prompt in addition to the completion.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-echo
+	Echo bool `json:"echo,omitempty"`
+
+	// FrequencyPenalty: Number between -2.0 and 2.0.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-frequency_penalty
+	FrequencyPenalty *float32 `json:"frequency_penalty,omitempty"` //nolint:tagliatelle //follow openai api
+
+	// LogitBias Modify the likelihood of specified tokens appearing in the completion.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-logit_bias
+	LogitBias map[string]int `json:"logit_bias,omitempty"` //nolint:tagliatelle //follow openai api
+
+	// LogProbs: Include the log probabilities on the logprobs most likely output tokens.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-logprobs
+	LogProbs *int `json:"logprobs,omitempty"`
+
+	// MaxTokens The maximum number of tokens that can be generated in the completion.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-max_tokens
+	MaxTokens *int64 `json:"max_tokens,omitempty"` //nolint:tagliatelle //follow openai api
+
+	// N: How many completions to generate for each prompt.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-n
+	N *int `json:"n,omitempty"`
+
+	// PresencePenalty Positive values penalize new tokens based on whether they appear in the text so far.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-presence_penalty
+	PresencePenalty *float32 `json:"presence_penalty,omitempty"` //nolint:tagliatelle //follow openai api
+
+	// Seed: If specified, the system will make a best effort to sample deterministically.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-seed
+	Seed *int `json:"seed,omitempty"`
+
+	// Stop string / array / null Defaults to null
+	// Up to 4 sequences where the API will stop generating further tokens.
+	// Unlike the chat completions, the legacy clients commonly send a single string, so this is either string or []any.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-stop
+	Stop any `json:"stop,omitempty"`
+
+	// Stream: Whether to stream back partial progress.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-stream
+	Stream bool `json:"stream,omitempty"`
+
+	// StreamOptions for streaming response. Only set this when you set stream: true.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-stream_options
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"` //nolint:tagliatelle //follow openai api
+
+	// Suffix: The suffix that comes after a completion of inserted text.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-suffix
+	Suffix *string `json:"suffix,omitempty"`
+
+	// Temperature What sampling temperature to use, between 0 and 2.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-temperature
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// TopP An alternative to sampling with temperature, called nucleus sampling.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-top_p
+	TopP *float64 `json:"top_p,omitempty"` //nolint:tagliatelle //follow openai api
+
+	// User: A unique identifier representing your end-user.
+	// Docs: https://platform.openai.com/docs/api-reference/completions/create#completions-create-user
+	User string `json:"user,omitempty"`
+}
+
+// CompletionRequestPrompt is the union of the prompt types of the CompletionRequest.
+// This accepts the same shapes as the input of the EmbeddingRequest.
+type CompletionRequestPrompt = EmbeddingRequestInput
+
+// CompletionResponse represents a response from /v1/completions. This is also used for the streamed chunks.
+// https://platform.openai.com/docs/api-reference/completions/object
+type CompletionResponse struct {
+	// ID is a unique identifier for the completion.
+	ID string `json:"id,omitempty"`
+
+	// Object is always "text_completion".
+	Object string `json:"object,omitempty"`
+
+	// Model is the model used for completion.
+	Model string `json:"model,omitempty"`
+
+	// Choices is the list of completion choices the model generated for the input prompt.
+	Choices []CompletionResponseChoice `json:"choices"`
+
+	// Usage is the usage statistics for the completion request. This is nil in the streamed chunks
+	// except for the last one when stream_options.include_usage is set.
+	Usage *ChatCompletionResponseUsage `json:"usage,omitempty"`
+}
+
+// CompletionResponseChoice is described in the OpenAI API documentation:
+// https://platform.openai.com/docs/api-reference/completions/object#completions/object-choices
+type CompletionResponseChoice struct {
+	// Text is the generated text.
+	Text string `json:"text"`
+	// Index is the index of the choice in the list of choices.
+	Index int64 `json:"index"`
+	// FinishReason is the reason the model stopped generating tokens. This is either `stop` or `length`,
+	// and is empty in the streamed chunks until the last one.
+	FinishReason ChatCompletionChoicesFinishReason `json:"finish_reason,omitempty"`
+}
+
+// EmbeddingRequest is described in the OpenAI API documentation
+// https://platform.openai.com/docs/api-reference/embeddings/create
+type EmbeddingRequest struct {
+	// Input is the text to embed, encoded as a string, an array of strings, an array of tokens,
+	// or an array of token arrays.
+	Input EmbeddingRequestInput `json:"input"`
+	// Model is the ID of the model to use.
+	Model string `json:"model"`
+	// EncodingFormat is the format to return the embeddings in. Can be either "float" or "base64".
+	EncodingFormat *string `json:"encoding_format,omitempty"` //nolint:tagliatelle //follow openai api
+	// Dimensions is the number of dimensions the resulting output embeddings should have.
+	Dimensions *int `json:"dimensions,omitempty"`
+	// User is a unique identifier representing your end-user.
+	User string `json:"user,omitempty"`
+}
+
+// EmbeddingRequestInput is the union of the input types of the EmbeddingRequest.
+// Value is either string, []string, []int64 or [][]int64.
+type EmbeddingRequestInput struct {
+	Value interface{}
+}
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (e *EmbeddingRequestInput) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		e.Value = str
+		return nil
+	}
+	var strs []string
+	if err := json.Unmarshal(data, &strs); err == nil {
+		e.Value = strs
+		return nil
+	}
+	var tokens []int64
+	if err := json.Unmarshal(data, &tokens); err == nil {
+		e.Value = tokens
+		return nil
+	}
+	var tokenArrays [][]int64
+	if err := json.Unmarshal(data, &tokenArrays); err == nil {
+		e.Value = tokenArrays
+		return nil
+	}
+	return fmt.Errorf("cannot unmarshal JSON data as string, array of strings, array of tokens or array of token arrays")
+}
+
+// MarshalJSON implements [json.Marshaler].
+func (e EmbeddingRequestInput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Value)
+}
+
+// EmbeddingResponse is described in the OpenAI API documentation
+// https://platform.openai.com/docs/api-reference/embeddings/object
+type EmbeddingResponse struct {
+	// Object is always "list".
+	Object string `json:"object"`
+	// Data is the list of embeddings generated by the model.
+	Data []Embedding `json:"data"`
+	// Model is the name of the model used to generate the embedding.
+	Model string `json:"model"`
+	// Usage is the usage information for the request.
+	Usage EmbeddingUsage `json:"usage"`
+}
+
+// Embedding is described in the OpenAI API documentation
+// https://platform.openai.com/docs/api-reference/embeddings/object
+type Embedding struct {
+	// Object is always "embedding".
+	Object string `json:"object"`
+	// Embedding is the embedding vector, which is either []float64 or a base64 encoded string
+	// depending on the encoding_format of the request.
+	Embedding interface{} `json:"embedding"`
+	// Index is the index of the embedding in the list of embeddings.
+	Index int `json:"index"`
+}
+
+// EmbeddingUsage is the usage information of the EmbeddingResponse.
+type EmbeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"` //nolint:tagliatelle //follow openai api
+	TotalTokens  int `json:"total_tokens"`  //nolint:tagliatelle //follow openai api
+}
+
 // Error is described in the OpenAI API documentation
 // https://platform.openai.com/docs/api-reference/realtime-server-events/error
 type Error struct {
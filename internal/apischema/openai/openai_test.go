@@ -241,6 +241,81 @@ func TestOpenAIChatCompletionMessageUnmarshal(t *testing.T) {
 	}
 }
 
+func TestOpenAIChatCompletionRequestMarshal(t *testing.T) {
+	in := `{"model":"gpu-o4","messages":[` +
+		`{"role":"system","content":"you are a helpful assistant"},` +
+		`{"role":"user","content":[{"type":"text","text":"what is this"},{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]},` +
+		`{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","function":{"arguments":"{}","name":"f"},"type":"function"}]},` +
+		`{"role":"tool","content":"result","tool_call_id":"call_1"},` +
+		`{"role":"assistant","content":"done"},` +
+		`{"role":"assistant","content":[{"type":"refusal","refusal":"no"}]}]}`
+	var req ChatCompletionRequest
+	require.NoError(t, json.Unmarshal([]byte(in), &req))
+	out, err := json.Marshal(&req)
+	require.NoError(t, err)
+	require.JSONEq(t, in, string(out))
+}
+
+func TestChatCompletionAssistantMessageParamContentUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		exp  ChatCompletionAssistantMessageParamContent
+	}{
+		{name: "null", in: `null`, exp: ChatCompletionAssistantMessageParamContent{}},
+		{
+			name: "string", in: `"hi"`,
+			exp: ChatCompletionAssistantMessageParamContent{Type: ChatCompletionAssistantMessageParamContentTypeText, Text: ptr.To("hi")},
+		},
+		{
+			name: "text parts", in: `[{"type":"text","text":"a"},{"type":"text","text":"b"}]`,
+			exp: ChatCompletionAssistantMessageParamContent{Type: ChatCompletionAssistantMessageParamContentTypeText, Text: ptr.To("ab")},
+		},
+		{
+			name: "object", in: `{"type":"refusal","refusal":"no"}`,
+			exp: ChatCompletionAssistantMessageParamContent{Type: ChatCompletionAssistantMessageParamContentTypeRefusal, Refusal: ptr.To("no")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var c ChatCompletionAssistantMessageParamContent
+			require.NoError(t, json.Unmarshal([]byte(tc.in), &c))
+			require.Equal(t, tc.exp, c)
+		})
+	}
+}
+
+func TestEmbeddingRequestInputUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		in     string
+		out    interface{}
+		expErr string
+	}{
+		{name: "string", in: `"foo"`, out: "foo"},
+		{name: "strings", in: `["foo", "bar"]`, out: []string{"foo", "bar"}},
+		{name: "tokens", in: `[1, 2, 3]`, out: []int64{1, 2, 3}},
+		{name: "token arrays", in: `[[1, 2], [3]]`, out: [][]int64{{1, 2}, {3}}},
+		{name: "invalid", in: `{"foo": "bar"}`, expErr: "cannot unmarshal JSON data as string, array of strings"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var req EmbeddingRequest
+			err := json.Unmarshal([]byte(`{"model": "text-embedding-3-small", "input": `+tc.in+`}`), &req)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "text-embedding-3-small", req.Model)
+			require.Equal(t, tc.out, req.Input.Value)
+
+			// Marshalling should round-trip the input as-is.
+			b, err := json.Marshal(req.Input)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.in, string(b))
+		})
+	}
+}
+
 func TestModelListMarshal(t *testing.T) {
 	var (
 		model = Model{
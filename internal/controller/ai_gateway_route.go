@@ -9,15 +9,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"path"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	egv1a1 "github.com/envoyproxy/gateway/api/v1alpha1"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	uuid2 "k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/kubernetes"
@@ -28,6 +36,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gwapiv1a3 "sigs.k8s.io/gateway-api/apis/v1alpha3"
 	"sigs.k8s.io/yaml"
 
 	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
@@ -315,54 +325,103 @@ func (c *AIGatewayRouteController) updateExtProcConfigMap(ctx context.Context, a
 		ec.Rules[i].Backends = make([]filterapi.Backend, len(rule.BackendRefs))
 		for j := range rule.BackendRefs {
 			backend := &rule.BackendRefs[j]
-			key := fmt.Sprintf("%s.%s", backend.Name, aiGatewayRoute.Namespace)
-			ec.Rules[i].Backends[j].Name = key
+			if _, err = c.filterConfigBackend(ctx, aiGatewayRoute.Namespace, backend.Name, i, j, &ec.Rules[i].Backends[j]); err != nil {
+				return err
+			}
 			ec.Rules[i].Backends[j].Weight = backend.Weight
+			ec.Rules[i].Backends[j].ModelNameOverride = backend.ModelNameOverride
+		}
+		if lb := rule.LoadBalancingPolicy; lb != nil {
+			switch lb.Type {
+			case aigv1a1.LoadBalancingPolicyTypeWeightedRandom, "":
+				ec.Rules[i].LoadBalancingPolicy = &filterapi.LoadBalancingPolicy{Type: filterapi.LoadBalancingPolicyTypeWeightedRandom}
+			case aigv1a1.LoadBalancingPolicyTypeAdaptive:
+				ec.Rules[i].LoadBalancingPolicy = &filterapi.LoadBalancingPolicy{Type: filterapi.LoadBalancingPolicyTypeAdaptive}
+			case aigv1a1.LoadBalancingPolicyTypeConsistentHash:
+				ch, err := consistentHashConfig(lb.ConsistentHash)
+				if err != nil {
+					return err
+				}
+				ec.Rules[i].LoadBalancingPolicy = &filterapi.LoadBalancingPolicy{
+					Type: filterapi.LoadBalancingPolicyTypeConsistentHash, ConsistentHash: ch,
+				}
+			default:
+				return fmt.Errorf("unknown load balancing policy type: %s", lb.Type)
+			}
+		}
+		if cb := rule.CircuitBreaker; cb != nil {
+			ec.Rules[i].CircuitBreaker = &filterapi.CircuitBreaker{ConsecutiveFailures: 5, OpenDuration: 30 * time.Second}
+			if cb.ConsecutiveFailures != nil {
+				ec.Rules[i].CircuitBreaker.ConsecutiveFailures = int(*cb.ConsecutiveFailures)
+			}
+			if cb.OpenDuration != nil {
+				d, err := time.ParseDuration(string(*cb.OpenDuration))
+				if err != nil {
+					return fmt.Errorf("invalid circuit breaker open duration %q: %w", *cb.OpenDuration, err)
+				}
+				ec.Rules[i].CircuitBreaker.OpenDuration = d
+			}
+		}
+		if len(rule.Fallbacks) > 0 {
+			ec.Rules[i].Fallbacks = make([]filterapi.Backend, len(rule.Fallbacks))
+		}
+		for k := range rule.Fallbacks {
+			fallback := &ec.Rules[i].Fallbacks[k]
+			// The fallbacks are indexed after the backendRefs for the volume names of the BackendSecurityPolicy secrets.
 			var backendObj *aigv1a1.AIServiceBackend
-			backendObj, err = c.backend(ctx, aiGatewayRoute.Namespace, backend.Name)
+			backendObj, err = c.filterConfigBackend(ctx, aiGatewayRoute.Namespace, rule.Fallbacks[k].Name, i, len(rule.BackendRefs)+k, fallback)
 			if err != nil {
-				return fmt.Errorf("failed to get AIServiceBackend %s: %w", key, err)
+				return err
 			}
-			ec.Rules[i].Backends[j].Schema.Name = filterapi.APISchemaName(backendObj.Spec.APISchema.Name)
-			ec.Rules[i].Backends[j].Schema.Version = backendObj.Spec.APISchema.Version
-
-			if bspRef := backendObj.Spec.BackendSecurityPolicyRef; bspRef != nil {
-				volumeName := backendSecurityPolicyVolumeName(
-					i, j, string(backendObj.Spec.BackendSecurityPolicyRef.Name),
-				)
-				var backendSecurityPolicy *aigv1a1.BackendSecurityPolicy
-				backendSecurityPolicy, err = c.backendSecurityPolicy(ctx, aiGatewayRoute.Namespace, string(bspRef.Name))
-				if err != nil {
-					return fmt.Errorf("failed to get BackendSecurityPolicy %s: %w", bspRef.Name, err)
+			if fallback.Endpoint, fallback.EndpointTLS, err = c.backendEndpoint(ctx, aiGatewayRoute.Namespace, &backendObj.Spec.BackendRef); err != nil {
+				return fmt.Errorf("failed to resolve the endpoint of fallback %s: %w", fallback.Name, err)
+			}
+			fallback.ModelNameOverride = rule.Fallbacks[k].ModelNameOverride
+		}
+		if m := rule.Mirror; m != nil {
+			mirror := &filterapi.Backend{}
+			// The mirror is indexed after the fallbacks for the volume name of the BackendSecurityPolicy secret.
+			var backendObj *aigv1a1.AIServiceBackend
+			backendObj, err = c.filterConfigBackend(ctx, aiGatewayRoute.Namespace, m.Name, i, len(rule.BackendRefs)+len(rule.Fallbacks), mirror)
+			if err != nil {
+				return err
+			}
+			if mirror.Endpoint, mirror.EndpointTLS, err = c.backendEndpoint(ctx, aiGatewayRoute.Namespace, &backendObj.Spec.BackendRef); err != nil {
+				return fmt.Errorf("failed to resolve the endpoint of mirror %s: %w", mirror.Name, err)
+			}
+			mirror.ModelNameOverride = m.ModelNameOverride
+			ec.Rules[i].Mirror = mirror
+		}
+		ec.Rules[i].Matches = make([]filterapi.RouteRuleMatch, len(rule.Matches))
+		for j, match := range rule.Matches {
+			headers := make([]filterapi.HeaderMatch, len(match.Headers))
+			for k, h := range match.Headers {
+				headers[k].Name = h.Name
+				headers[k].Value = h.Value
+				if h.Type == nil {
+					continue
 				}
-
-				switch backendSecurityPolicy.Spec.Type {
-				case aigv1a1.BackendSecurityPolicyTypeAPIKey:
-					ec.Rules[i].Backends[j].Auth = &filterapi.BackendAuth{
-						APIKey: &filterapi.APIKeyAuth{Filename: path.Join(backendSecurityMountPath(volumeName), "/apiKey")},
-					}
-				case aigv1a1.BackendSecurityPolicyTypeAWSCredentials:
-					if backendSecurityPolicy.Spec.AWSCredentials == nil {
-						return fmt.Errorf("AWSCredentials type selected but not defined %s", backendSecurityPolicy.Name)
-					}
-					if awsCred := backendSecurityPolicy.Spec.AWSCredentials; awsCred.CredentialsFile != nil || awsCred.OIDCExchangeToken != nil {
-						ec.Rules[i].Backends[j].Auth = &filterapi.BackendAuth{
-							AWSAuth: &filterapi.AWSAuth{
-								CredentialFileName: path.Join(backendSecurityMountPath(volumeName), "/credentials"),
-								Region:             backendSecurityPolicy.Spec.AWSCredentials.Region,
-							},
-						}
+				var typ gwapiv1.HeaderMatchType
+				switch *h.Type {
+				case aigv1a1.HeaderMatchTypeExact:
+					typ = gwapiv1.HeaderMatchExact
+				case aigv1a1.HeaderMatchTypePrefix:
+					typ = filterapi.HeaderMatchPrefix
+				case aigv1a1.HeaderMatchTypeRegularExpression:
+					typ = gwapiv1.HeaderMatchRegularExpression
+					// Sanity check the regular expression, as otherwise the external processor fails to load the config.
+					if _, err = regexp.Compile(h.Value); err != nil {
+						return fmt.Errorf("invalid regular expression %q in the header match %s: %w", h.Value, h.Name, err)
 					}
 				default:
-					return fmt.Errorf("invalid backend security type %s for policy %s", backendSecurityPolicy.Spec.Type,
-						backendSecurityPolicy.Name)
+					return fmt.Errorf("unknown header match type: %s", *h.Type)
 				}
+				headers[k].Type = &typ
+			}
+			ec.Rules[i].Matches[j].Headers = headers
+			if r := match.EstimatedInputTokens; r != nil {
+				ec.Rules[i].Matches[j].EstimatedInputTokens = &filterapi.TokenRange{Min: r.Min, Max: r.Max}
 			}
-		}
-		ec.Rules[i].Headers = make([]filterapi.HeaderMatch, len(rule.Matches))
-		for j, match := range rule.Matches {
-			ec.Rules[i].Headers[j].Name = match.Headers[0].Name
-			ec.Rules[i].Headers[j].Value = match.Headers[0].Value
 		}
 	}
 
@@ -376,6 +435,12 @@ func (c *AIGatewayRouteController) updateExtProcConfigMap(ctx context.Context, a
 			fc.Type = filterapi.LLMRequestCostTypeOutputToken
 		case aigv1a1.LLMRequestCostTypeTotalToken:
 			fc.Type = filterapi.LLMRequestCostTypeTotalToken
+		case aigv1a1.LLMRequestCostTypeCachedInputToken:
+			fc.Type = filterapi.LLMRequestCostTypeCachedInputToken
+		case aigv1a1.LLMRequestCostTypeCacheWriteInputToken:
+			fc.Type = filterapi.LLMRequestCostTypeCacheWriteInputToken
+		case aigv1a1.LLMRequestCostTypeReasoningToken:
+			fc.Type = filterapi.LLMRequestCostTypeReasoningToken
 		case aigv1a1.LLMRequestCostTypeCEL:
 			fc.Type = filterapi.LLMRequestCostTypeCEL
 			expr := *cost.CEL
@@ -385,12 +450,51 @@ func (c *AIGatewayRouteController) updateExtProcConfigMap(ctx context.Context, a
 				return fmt.Errorf("invalid CEL expression: %w", err)
 			}
 			fc.CEL = expr
+		case aigv1a1.LLMRequestCostTypePrice:
+			fc.Type = filterapi.LLMRequestCostTypePrice
+			fc.Prices, err = c.modelPrices(ctx, aiGatewayRoute.Namespace, *cost.LLMPricingName)
+			if err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("unknown request cost type: %s", cost.Type)
 		}
+		if cost.Scale != nil {
+			fc.Scale = *cost.Scale
+		}
+		if cost.Rounding != nil {
+			fc.Rounding = filterapi.LLMRequestCostRounding(*cost.Rounding)
+		}
 		ec.LLMRequestCosts = append(ec.LLMRequestCosts, fc)
 	}
 
+	budgetNames := make(map[string]struct{}, len(aiGatewayRoute.Spec.TokenBudgets))
+	for i := range aiGatewayRoute.Spec.TokenBudgets {
+		budget := &aiGatewayRoute.Spec.TokenBudgets[i]
+		if _, ok := budgetNames[budget.Name]; ok {
+			return fmt.Errorf("duplicate token budget name: %s", budget.Name)
+		}
+		budgetNames[budget.Name] = struct{}{}
+		tb, err := tokenBudgetConfig(budget, ec.LLMRequestCosts)
+		if err != nil {
+			return fmt.Errorf("invalid token budget %s: %w", budget.Name, err)
+		}
+		if tb.Key.Type == filterapi.TokenBudgetKeyTypeAPIKey && aiGatewayRoute.Spec.GatewayAPIKeys == nil {
+			return fmt.Errorf("invalid token budget %s: gatewayAPIKeys must be set for the APIKey key type", budget.Name)
+		}
+		ec.TokenBudgets = append(ec.TokenBudgets, *tb)
+	}
+	if l := aiGatewayRoute.Spec.RequestLimits; l != nil {
+		ec.RequestLimits = requestLimitsConfig(l)
+	}
+	ec.ForceStreamUsage = aiGatewayRoute.Spec.ForceStreamUsage
+	if keys := aiGatewayRoute.Spec.GatewayAPIKeys; keys != nil {
+		ec.GatewayAPIKeys, err = c.gatewayAPIKeys(ctx, aiGatewayRoute.Namespace, keys)
+		if err != nil {
+			return err
+		}
+	}
+
 	marshaled, err := yaml.Marshal(ec)
 	if err != nil {
 		return fmt.Errorf("failed to marshal extproc config: %w", err)
@@ -405,6 +509,131 @@ func (c *AIGatewayRouteController) updateExtProcConfigMap(ctx context.Context, a
 	return nil
 }
 
+// filterConfigBackend populates dst with the filter configuration of the AIServiceBackend of the given name. The ruleIndex
+// and backendRefIndex are used to derive the volume name of the mounted BackendSecurityPolicy secret.
+func (c *AIGatewayRouteController) filterConfigBackend(ctx context.Context, namespace, name string, ruleIndex, backendRefIndex int,
+	dst *filterapi.Backend,
+) (*aigv1a1.AIServiceBackend, error) {
+	key := fmt.Sprintf("%s.%s", name, namespace)
+	dst.Name = key
+	backendObj, err := c.backend(ctx, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AIServiceBackend %s: %w", key, err)
+	}
+	dst.Schema.Name = filterapi.APISchemaName(backendObj.Spec.APISchema.Name)
+	dst.Schema.Version = backendObj.Spec.APISchema.Version
+	dst.Schema.Deployments = backendObj.Spec.APISchema.Deployments
+
+	if bspRef := backendObj.Spec.BackendSecurityPolicyRef; bspRef != nil {
+		volumeName := backendSecurityPolicyVolumeName(
+			ruleIndex, backendRefIndex, string(backendObj.Spec.BackendSecurityPolicyRef.Name),
+		)
+		var backendSecurityPolicy *aigv1a1.BackendSecurityPolicy
+		backendSecurityPolicy, err = c.backendSecurityPolicy(ctx, namespace, string(bspRef.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get BackendSecurityPolicy %s: %w", bspRef.Name, err)
+		}
+
+		switch backendSecurityPolicy.Spec.Type {
+		case aigv1a1.BackendSecurityPolicyTypeAPIKey:
+			apiKey := &filterapi.APIKeyAuth{Filename: path.Join(backendSecurityMountPath(volumeName), "/apiKey")}
+			if backendSecurityPolicy.Spec.APIKey != nil {
+				apiKey.HeaderName = backendSecurityPolicy.Spec.APIKey.HeaderName
+			}
+			dst.Auth = &filterapi.BackendAuth{APIKey: apiKey}
+		case aigv1a1.BackendSecurityPolicyTypeAWSCredentials:
+			if backendSecurityPolicy.Spec.AWSCredentials == nil {
+				return nil, fmt.Errorf("AWSCredentials type selected but not defined %s", backendSecurityPolicy.Name)
+			}
+			if awsCred := backendSecurityPolicy.Spec.AWSCredentials; awsCred.CredentialsFile != nil || awsCred.OIDCExchangeToken != nil {
+				dst.Auth = &filterapi.BackendAuth{
+					AWSAuth: &filterapi.AWSAuth{
+						CredentialFileName: path.Join(backendSecurityMountPath(volumeName), "/credentials"),
+						Region:             backendSecurityPolicy.Spec.AWSCredentials.Region,
+					},
+				}
+			}
+		case aigv1a1.BackendSecurityPolicyTypeAzureCredentials:
+			dst.Auth = &filterapi.BackendAuth{
+				AzureAuth: &filterapi.AzureAuth{Filename: path.Join(backendSecurityMountPath(volumeName), "/azureAccessToken")},
+			}
+		default:
+			return nil, fmt.Errorf("invalid backend security type %s for policy %s", backendSecurityPolicy.Spec.Type,
+				backendSecurityPolicy.Name)
+		}
+	}
+	return backendObj, nil
+}
+
+// requestLimitsConfig converts the request limits to the filter config.
+func requestLimitsConfig(l *aigv1a1.AIGatewayRouteRequestLimits) *filterapi.RequestLimits {
+	ret := &filterapi.RequestLimits{}
+	if l.MaxTokens != nil {
+		ret.MaxTokens = *l.MaxTokens
+		ret.RejectMaxTokens = l.MaxTokensAction != nil && *l.MaxTokensAction == aigv1a1.MaxTokensActionReject
+	}
+	if l.MaxEstimatedInputTokens != nil {
+		ret.MaxEstimatedInputTokens = int(*l.MaxEstimatedInputTokens)
+	}
+	if l.MaxMessages != nil {
+		ret.MaxMessages = int(*l.MaxMessages)
+	}
+	if l.MaxTools != nil {
+		ret.MaxTools = ptr.To(int(*l.MaxTools))
+	}
+	return ret
+}
+
+// tokenBudgetConfig converts the token budget to the filter config. The metadata key of the budget must be
+// one of the given request costs.
+func tokenBudgetConfig(b *aigv1a1.AIGatewayRouteTokenBudget, costs []filterapi.LLMRequestCost) (*filterapi.TokenBudget, error) {
+	if !slices.ContainsFunc(costs, func(c filterapi.LLMRequestCost) bool { return c.MetadataKey == b.MetadataKey }) {
+		return nil, fmt.Errorf("metadataKey %s is not one of the llmRequestCosts", b.MetadataKey)
+	}
+	if b.Limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	window, err := time.ParseDuration(string(b.Window))
+	if err != nil {
+		return nil, fmt.Errorf("invalid window %q: %w", b.Window, err)
+	} else if window <= 0 {
+		return nil, fmt.Errorf("window must be positive")
+	}
+	ret := &filterapi.TokenBudget{Name: b.Name, MetadataKey: b.MetadataKey, Limit: uint64(b.Limit), Window: window}
+	switch b.Key.Type {
+	case aigv1a1.TokenBudgetKeyTypeHeader:
+		if b.Key.Header == nil || *b.Key.Header == "" {
+			return nil, fmt.Errorf("header must be set for the Header key type")
+		}
+		ret.Key = filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeHeader, Header: strings.ToLower(string(*b.Key.Header))}
+	case aigv1a1.TokenBudgetKeyTypeUser:
+		ret.Key = filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser}
+	case aigv1a1.TokenBudgetKeyTypeAPIKey:
+		ret.Key = filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeAPIKey}
+	default:
+		return nil, fmt.Errorf("unknown key type: %s", b.Key.Type)
+	}
+	return ret, nil
+}
+
+// consistentHashConfig converts the consistent hashing of the load balancing policy to the filter config.
+func consistentHashConfig(ch *aigv1a1.AIGatewayRouteRuleConsistentHash) (*filterapi.ConsistentHash, error) {
+	if ch == nil {
+		return nil, fmt.Errorf("consistentHash must be set for the ConsistentHash load balancing policy")
+	}
+	switch ch.Type {
+	case aigv1a1.ConsistentHashTypeHeader:
+		if ch.Header == nil || *ch.Header == "" {
+			return nil, fmt.Errorf("header must be set for the Header consistent hash type")
+		}
+		return &filterapi.ConsistentHash{Type: filterapi.ConsistentHashTypeHeader, Header: strings.ToLower(string(*ch.Header))}, nil
+	case aigv1a1.ConsistentHashTypeUser:
+		return &filterapi.ConsistentHash{Type: filterapi.ConsistentHashTypeUser}, nil
+	default:
+		return nil, fmt.Errorf("unknown consistent hash type: %s", ch.Type)
+	}
+}
+
 // newHTTPRoute updates the HTTPRoute with the new AIGatewayRoute.
 func (c *AIGatewayRouteController) newHTTPRoute(ctx context.Context, dst *gwapiv1.HTTPRoute, aiGatewayRoute *aigv1a1.AIGatewayRoute) error {
 	var backends []*aigv1a1.AIServiceBackend
@@ -623,11 +852,22 @@ func (c *AIGatewayRouteController) mountBackendSecurityPolicySecrets(ctx context
 
 	for i := range aiGatewayRoute.Spec.Rules {
 		rule := &aiGatewayRoute.Spec.Rules[i]
+		// The fallbacks are indexed after the backendRefs, and the mirror after the fallbacks,
+		// which must be consistent with updateExtProcConfigMap.
+		backendNames := make([]string, 0, len(rule.BackendRefs)+len(rule.Fallbacks)+1)
 		for j := range rule.BackendRefs {
-			backendRef := &rule.BackendRefs[j]
-			backend, err := c.backend(ctx, aiGatewayRoute.Namespace, backendRef.Name)
+			backendNames = append(backendNames, rule.BackendRefs[j].Name)
+		}
+		for j := range rule.Fallbacks {
+			backendNames = append(backendNames, rule.Fallbacks[j].Name)
+		}
+		if rule.Mirror != nil {
+			backendNames = append(backendNames, rule.Mirror.Name)
+		}
+		for j, backendName := range backendNames {
+			backend, err := c.backend(ctx, aiGatewayRoute.Namespace, backendName)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get backend %s: %w", backendRef.Name, err)
+				return nil, fmt.Errorf("failed to get backend %s: %w", backendName, err)
 			}
 
 			if backendSecurityPolicyRef := backend.Spec.BackendSecurityPolicyRef; backendSecurityPolicyRef != nil {
@@ -646,6 +886,8 @@ func (c *AIGatewayRouteController) mountBackendSecurityPolicySecrets(ctx context
 					} else {
 						secretName = rotators.GetBSPSecretName(backendSecurityPolicy.Name)
 					}
+				case aigv1a1.BackendSecurityPolicyTypeAzureCredentials:
+					secretName = rotators.GetBSPSecretName(backendSecurityPolicy.Name)
 				default:
 					return nil, fmt.Errorf("backend security policy %s is not supported", backendSecurityPolicy.Spec.Type)
 				}
@@ -677,6 +919,108 @@ func (c *AIGatewayRouteController) backend(ctx context.Context, namespace, name
 	return backend, nil
 }
 
+// backendEndpoint returns the base URL of the backend referenced by the AIServiceBackend, which is either a k8s Service
+// or a Backend resource of Envoy Gateway. The host is taken from the cluster IP or the external name of the Service,
+// or from the first endpoint of the Backend.
+//
+// Same as Envoy, the scheme is "https" only when a BackendTLSPolicy targets the backend, and the TLS configuration is
+// derived from it.
+func (c *AIGatewayRouteController) backendEndpoint(ctx context.Context, namespace string, ref *gwapiv1.BackendObjectReference) (
+	string, *filterapi.BackendTLS, error,
+) {
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	var host, group string
+	var port int32
+	switch kind := ptr.Deref(ref.Kind, "Service"); kind {
+	case "Service":
+		if ref.Port == nil {
+			return "", nil, fmt.Errorf("port must be specified for Service %s", ref.Name)
+		}
+		var svc corev1.Service
+		if err := c.client.Get(ctx, client.ObjectKey{Name: string(ref.Name), Namespace: namespace}, &svc); err != nil {
+			return "", nil, fmt.Errorf("failed to get Service %s: %w", ref.Name, err)
+		}
+		switch {
+		case svc.Spec.Type == corev1.ServiceTypeExternalName:
+			host = svc.Spec.ExternalName
+		case svc.Spec.ClusterIP != "" && svc.Spec.ClusterIP != corev1.ClusterIPNone:
+			host = svc.Spec.ClusterIP
+		default:
+			return "", nil, fmt.Errorf("service %s has neither a cluster IP nor an external name", ref.Name)
+		}
+		port = int32(*ref.Port)
+	case "Backend":
+		var backend egv1a1.Backend
+		if err := c.client.Get(ctx, client.ObjectKey{Name: string(ref.Name), Namespace: namespace}, &backend); err != nil {
+			return "", nil, fmt.Errorf("failed to get Backend %s: %w", ref.Name, err)
+		}
+		if len(backend.Spec.Endpoints) == 0 {
+			return "", nil, fmt.Errorf("backend %s has no endpoints", ref.Name)
+		}
+		switch ep := backend.Spec.Endpoints[0]; {
+		case ep.FQDN != nil:
+			host, port = ep.FQDN.Hostname, ep.FQDN.Port
+		case ep.IP != nil:
+			host, port = ep.IP.Address, ep.IP.Port
+		default:
+			return "", nil, fmt.Errorf("unsupported endpoint type of Backend %s", ref.Name)
+		}
+		group = egv1a1.GroupName
+	default:
+		return "", nil, fmt.Errorf("unsupported backend kind %s", kind)
+	}
+	tlsConfig, err := c.backendTLS(ctx, namespace, group, ptr.Deref(ref.Kind, "Service"), string(ref.Name))
+	if err != nil {
+		return "", nil, err
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(port)))), tlsConfig, nil
+}
+
+// backendTLS returns the TLS configuration of the backend from the BackendTLSPolicy targeting it, or nil if there is
+// no such policy. The CA certificates are read from the "ca.crt" key of the referenced ConfigMaps.
+func (c *AIGatewayRouteController) backendTLS(ctx context.Context, namespace, group string, kind gwapiv1.Kind, name string) (
+	*filterapi.BackendTLS, error,
+) {
+	var policies gwapiv1a3.BackendTLSPolicyList
+	if err := c.client.List(ctx, &policies, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil // The BackendTLSPolicy CRD is not installed.
+		}
+		return nil, fmt.Errorf("failed to list BackendTLSPolicies: %w", err)
+	}
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if !slices.ContainsFunc(policy.Spec.TargetRefs, func(ref gwapiv1a2.LocalPolicyTargetReferenceWithSectionName) bool {
+			return string(ref.Group) == group && ref.Kind == kind && string(ref.Name) == name
+		}) {
+			continue
+		}
+		tlsConfig := &filterapi.BackendTLS{Hostname: string(policy.Spec.Validation.Hostname)}
+		for _, caRef := range policy.Spec.Validation.CACertificateRefs {
+			if caRef.Group != "" || caRef.Kind != "ConfigMap" {
+				return nil, fmt.Errorf("unsupported CA certificate reference %s/%s of BackendTLSPolicy %s", caRef.Group, caRef.Kind, policy.Name)
+			}
+			var cm corev1.ConfigMap
+			if err := c.client.Get(ctx, client.ObjectKey{Name: string(caRef.Name), Namespace: namespace}, &cm); err != nil {
+				return nil, fmt.Errorf("failed to get CA certificate ConfigMap %s: %w", caRef.Name, err)
+			}
+			caCert, ok := cm.Data["ca.crt"]
+			if !ok {
+				return nil, fmt.Errorf("ConfigMap %s has no ca.crt", caRef.Name)
+			}
+			tlsConfig.CACertificates += caCert
+		}
+		return tlsConfig, nil
+	}
+	return nil, nil
+}
+
 func (c *AIGatewayRouteController) backendSecurityPolicy(ctx context.Context, namespace, name string) (*aigv1a1.BackendSecurityPolicy, error) {
 	backendSecurityPolicy := &aigv1a1.BackendSecurityPolicy{}
 	if err := c.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, backendSecurityPolicy); err != nil {
@@ -685,6 +1029,103 @@ func (c *AIGatewayRouteController) backendSecurityPolicy(ctx context.Context, na
 	return backendSecurityPolicy, nil
 }
 
+// gatewayAPIKeys returns the GatewayAPIKeys in the namespace selected by keys for the filter config.
+func (c *AIGatewayRouteController) gatewayAPIKeys(ctx context.Context, namespace string, keys *aigv1a1.AIGatewayRouteGatewayAPIKeys) (*filterapi.GatewayAPIKeys, error) {
+	selector := labels.Everything()
+	if keys.Selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(keys.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gatewayAPIKeys selector: %w", err)
+		}
+	}
+	var list aigv1a1.GatewayAPIKeyList
+	if err := c.client.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list GatewayAPIKeys: %w", err)
+	}
+	// Sort the keys so that the filter config does not change with the order of the list.
+	slices.SortFunc(list.Items, func(a, b aigv1a1.GatewayAPIKey) int { return strings.Compare(a.Name, b.Name) })
+	ret := &filterapi.GatewayAPIKeys{Keys: make([]filterapi.GatewayAPIKey, 0, len(list.Items))}
+	for i := range list.Items {
+		key := &list.Items[i]
+		k := filterapi.GatewayAPIKey{
+			Name:          key.Name,
+			Hash:          key.Spec.KeyHash,
+			Owner:         key.Spec.Owner,
+			AllowedModels: key.Spec.AllowedModels,
+		}
+		if key.Spec.ExpiresAt != nil {
+			k.ExpiresAt = ptr.To(key.Spec.ExpiresAt.UTC())
+		}
+		for _, b := range key.Spec.TokenBudgets {
+			window, err := time.ParseDuration(string(b.Window))
+			if err != nil {
+				return nil, fmt.Errorf("invalid window %q of GatewayAPIKey %s: %w", b.Window, key.Name, err)
+			} else if window <= 0 {
+				return nil, fmt.Errorf("window of GatewayAPIKey %s must be positive", key.Name)
+			}
+			k.TokenBudgets = append(k.TokenBudgets, filterapi.TokenBudget{
+				Name:        fmt.Sprintf("%s/%s", key.Name, b.MetadataKey),
+				Key:         filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeAPIKey},
+				MetadataKey: b.MetadataKey,
+				Limit:       uint64(b.Limit),
+				Window:      window,
+			})
+		}
+		ret.Keys = append(ret.Keys, k)
+	}
+	return ret, nil
+}
+
+// modelPrices returns the prices of the models in the LLMPricing of the given name for the filter config.
+func (c *AIGatewayRouteController) modelPrices(ctx context.Context, namespace, name string) ([]filterapi.ModelPrice, error) {
+	var pricing aigv1a1.LLMPricing
+	if err := c.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &pricing); err != nil {
+		return nil, fmt.Errorf("failed to get LLMPricing %s: %w", name, err)
+	}
+	prices := make([]filterapi.ModelPrice, 0, len(pricing.Spec.Models))
+	for i := range pricing.Spec.Models {
+		price, err := modelPrice(&pricing.Spec.Models[i], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price of model %s in LLMPricing %s: %w", pricing.Spec.Models[i].ModelName, name, err)
+		}
+		prices = append(prices, price)
+	}
+	return prices, nil
+}
+
+// modelPrice converts the LLMModelPrice in the given namespace to the filter config, applying the default prices.
+func modelPrice(m *aigv1a1.LLMModelPrice, namespace string) (price filterapi.ModelPrice, err error) {
+	price.Model = m.ModelName
+	if m.BackendName != nil {
+		price.Backend = fmt.Sprintf("%s.%s", *m.BackendName, namespace)
+	}
+	if price.Input, err = strconv.ParseFloat(m.InputTokenPrice, 64); err != nil {
+		return price, fmt.Errorf("invalid input token price: %w", err)
+	}
+	if price.Output, err = strconv.ParseFloat(m.OutputTokenPrice, 64); err != nil {
+		return price, fmt.Errorf("invalid output token price: %w", err)
+	}
+	price.CachedInput, price.CacheWriteInput, price.Reasoning = price.Input, price.Input, price.Output
+	for _, p := range []struct {
+		name  string
+		value *string
+		dst   *float64
+	}{
+		{name: "cached input", value: m.CachedInputTokenPrice, dst: &price.CachedInput},
+		{name: "cache write input", value: m.CacheWriteInputTokenPrice, dst: &price.CacheWriteInput},
+		{name: "reasoning", value: m.ReasoningTokenPrice, dst: &price.Reasoning},
+	} {
+		if p.value == nil {
+			continue
+		}
+		if *p.dst, err = strconv.ParseFloat(*p.value, 64); err != nil {
+			return price, fmt.Errorf("invalid %s token price: %w", p.name, err)
+		}
+	}
+	return price, nil
+}
+
 func backendSecurityPolicyVolumeName(ruleIndex, backendRefIndex int, name string) string {
 	// Note: do not use "." as it's not allowed in the volume name.
 	return fmt.Sprintf("rule%d-backref%d-%s", ruleIndex, backendRefIndex, name)
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gwapiv1a3 "sigs.k8s.io/gateway-api/apis/v1alpha3"
 
 	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
 	"github.com/envoyproxy/ai-gateway/filterapi"
@@ -488,6 +490,27 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 				},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-backend-security-policy-4", Namespace: "ns"},
+			Spec: aigv1a1.BackendSecurityPolicySpec{
+				Type: aigv1a1.BackendSecurityPolicyTypeAPIKey,
+				APIKey: &aigv1a1.BackendSecurityPolicyAPIKey{
+					SecretRef:  &gwapiv1.SecretObjectReference{Name: "some-secret-policy", Namespace: ptr.To[gwapiv1.Namespace]("ns")},
+					HeaderName: "api-key",
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-backend-security-policy-5", Namespace: "ns"},
+			Spec: aigv1a1.BackendSecurityPolicySpec{
+				Type: aigv1a1.BackendSecurityPolicyTypeAzureCredentials,
+				AzureCredentials: &aigv1a1.BackendSecurityPolicyAzureCredentials{
+					TenantID:        "some-tenant-id",
+					ClientID:        "some-client-id",
+					ClientSecretRef: &gwapiv1.SecretObjectReference{Name: "some-secret-policy"},
+				},
+			},
+		},
 	} {
 		err := fakeClient.Create(t.Context(), bsp, &client.CreateOptions{})
 		require.NoError(t, err)
@@ -531,10 +554,65 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 				BackendSecurityPolicyRef: &gwapiv1.LocalObjectReference{Name: "some-backend-security-policy-3"},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "azure", Namespace: "ns"},
+			Spec: aigv1a1.AIServiceBackendSpec{
+				APISchema: aigv1a1.VersionedAPISchema{
+					Name:        aigv1a1.APISchemaAzureOpenAI,
+					Version:     "2024-10-21",
+					Deployments: map[string]string{"gpt-4o": "my-gpt-4o"},
+				},
+				BackendRef:               gwapiv1.BackendObjectReference{Name: "some-backend6", Namespace: ptr.To[gwapiv1.Namespace]("ns")},
+				BackendSecurityPolicyRef: &gwapiv1.LocalObjectReference{Name: "some-backend-security-policy-4"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "azure-entra", Namespace: "ns"},
+			Spec: aigv1a1.AIServiceBackendSpec{
+				APISchema:                aigv1a1.VersionedAPISchema{Name: aigv1a1.APISchemaAzureOpenAI, Version: "2024-10-21"},
+				BackendRef:               gwapiv1.BackendObjectReference{Name: "some-backend8", Namespace: ptr.To[gwapiv1.Namespace]("ns")},
+				BackendSecurityPolicyRef: &gwapiv1.LocalObjectReference{Name: "some-backend-security-policy-5"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: "ns"},
+			Spec: aigv1a1.AIServiceBackendSpec{
+				APISchema: aigv1a1.VersionedAPISchema{Name: aigv1a1.APISchemaOpenAI},
+				BackendRef: gwapiv1.BackendObjectReference{
+					Name: "some-backend7", Namespace: ptr.To[gwapiv1.Namespace]("ns"),
+					Group: ptr.To[gwapiv1.Group]("gateway.envoyproxy.io"), Kind: ptr.To[gwapiv1.Kind]("Backend"),
+				},
+				BackendSecurityPolicyRef: &gwapiv1.LocalObjectReference{Name: "some-backend-security-policy-1"},
+			},
+		},
 	} {
 		err := fakeClient.Create(t.Context(), b, &client.CreateOptions{})
 		require.NoError(t, err)
 	}
+	require.NoError(t, fakeClient.Create(t.Context(), &egv1a1.Backend{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-backend7", Namespace: "ns"},
+		Spec: egv1a1.BackendSpec{Endpoints: []egv1a1.BackendEndpoint{
+			{FQDN: &egv1a1.FQDNEndpoint{Hostname: "api.openai.com", Port: 443}},
+		}},
+	}))
+	require.NoError(t, fakeClient.Create(t.Context(), &gwapiv1a3.BackendTLSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-backend7", Namespace: "ns"},
+		Spec: gwapiv1a3.BackendTLSPolicySpec{
+			TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{
+				{LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{Group: "gateway.envoyproxy.io", Kind: "Backend", Name: "some-backend7"}},
+			},
+			Validation: gwapiv1a3.BackendTLSPolicyValidation{
+				Hostname:                "api.openai.com",
+				WellKnownCACertificates: ptr.To(gwapiv1a3.WellKnownCACertificatesSystem),
+			},
+		},
+	}))
+	require.NoError(t, fakeClient.Create(t.Context(), &aigv1a1.LLMPricing{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-pricing", Namespace: "ns"},
+		Spec: aigv1a1.LLMPricingSpec{Models: []aigv1a1.LLMModelPrice{
+			{ModelName: "gpt-4o", BackendName: ptr.To("azure"), InputTokenPrice: "2.5", OutputTokenPrice: "10", CachedInputTokenPrice: ptr.To("1.25")},
+		}},
+	}))
 	require.NotNil(t, s)
 
 	for _, tc := range []struct {
@@ -551,17 +629,28 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 					Rules: []aigv1a1.AIGatewayRouteRule{
 						{
 							BackendRefs: []aigv1a1.AIGatewayRouteRuleBackendRef{
-								{Name: "apple", Weight: 1},
+								{Name: "apple", Weight: 1, ModelNameOverride: "anthropic.claude-3-5-sonnet-20241022-v2:0"},
 								{Name: "pineapple", Weight: 2},
 							},
 							Matches: []aigv1a1.AIGatewayRouteRuleMatch{
-								{Headers: []gwapiv1.HTTPHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai"}}},
+								{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai"}}},
 							},
 						},
 						{
 							BackendRefs: []aigv1a1.AIGatewayRouteRuleBackendRef{{Name: "cat", Weight: 1}},
+							LoadBalancingPolicy: &aigv1a1.AIGatewayRouteRuleLoadBalancingPolicy{
+								Type: aigv1a1.LoadBalancingPolicyTypeAdaptive,
+							},
+							CircuitBreaker: &aigv1a1.AIGatewayRouteRuleCircuitBreaker{
+								ConsecutiveFailures: ptr.To[int32](3),
+							},
+							Fallbacks: []aigv1a1.AIGatewayRouteRuleFallbackRef{{Name: "fallback"}},
+							Mirror:    &aigv1a1.AIGatewayRouteRuleMirrorRef{Name: "fallback", ModelNameOverride: "gpt-4o"},
 							Matches: []aigv1a1.AIGatewayRouteRuleMatch{
-								{Headers: []gwapiv1.HTTPHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai"}}},
+								{
+									Headers:              []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai"}},
+									EstimatedInputTokens: &aigv1a1.AIGatewayRouteRuleTokenRange{Max: ptr.To[int64](1000)},
+								},
 							},
 						},
 						{
@@ -569,7 +658,13 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 								{Name: "pen", Weight: 2},
 							},
 							Matches: []aigv1a1.AIGatewayRouteRuleMatch{
-								{Headers: []gwapiv1.HTTPHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai-2"}}},
+								{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{
+									{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai-.*", Type: ptr.To(aigv1a1.HeaderMatchTypeRegularExpression)},
+									{Name: "x-tenant", Value: "pen", Type: ptr.To(aigv1a1.HeaderMatchTypeExact)},
+								}},
+								{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{
+									{Name: aigv1a1.AIModelHeaderKey, Value: "pen-", Type: ptr.To(aigv1a1.HeaderMatchTypePrefix)},
+								}},
 							},
 						},
 						{
@@ -577,7 +672,23 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 								{Name: "dog", Weight: 1},
 							},
 							Matches: []aigv1a1.AIGatewayRouteRuleMatch{
-								{Headers: []gwapiv1.HTTPHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai-3"}}},
+								{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai-3"}}},
+							},
+						},
+						{
+							BackendRefs: []aigv1a1.AIGatewayRouteRuleBackendRef{
+								{Name: "azure", Weight: 1},
+							},
+							Matches: []aigv1a1.AIGatewayRouteRuleMatch{
+								{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "gpt-4o"}}},
+							},
+						},
+						{
+							BackendRefs: []aigv1a1.AIGatewayRouteRuleBackendRef{
+								{Name: "azure-entra", Weight: 1},
+							},
+							Matches: []aigv1a1.AIGatewayRouteRuleMatch{
+								{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "gpt-4o-mini"}}},
 							},
 						},
 					},
@@ -594,12 +705,49 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 							Type:        aigv1a1.LLMRequestCostTypeTotalToken,
 							MetadataKey: "total-token",
 						},
+						{
+							Type:        aigv1a1.LLMRequestCostTypeCachedInputToken,
+							MetadataKey: "cached-input-token",
+						},
+						{
+							Type:        aigv1a1.LLMRequestCostTypeCacheWriteInputToken,
+							MetadataKey: "cache-write-input-token",
+						},
+						{
+							Type:        aigv1a1.LLMRequestCostTypeReasoningToken,
+							MetadataKey: "reasoning-token",
+						},
 						{
 							Type:        aigv1a1.LLMRequestCostTypeCEL,
 							MetadataKey: "cel-token",
 							CEL:         ptr.To("model == 'cool_model' ?  input_tokens * output_tokens : total_tokens"),
+							Scale:       ptr.To[int64](1000),
+							Rounding:    ptr.To(aigv1a1.LLMRequestCostRoundingCeil),
 						},
+						{
+							Type:           aigv1a1.LLMRequestCostTypePrice,
+							MetadataKey:    "usd",
+							LLMPricingName: ptr.To("some-pricing"),
+							Rounding:       ptr.To(aigv1a1.LLMRequestCostRoundingFloor),
+						},
+					},
+					TokenBudgets: []aigv1a1.AIGatewayRouteTokenBudget{
+						{
+							Name:        "per-api-key",
+							Key:         aigv1a1.AIGatewayRouteTokenBudgetKey{Type: aigv1a1.TokenBudgetKeyTypeHeader, Header: ptr.To[gwapiv1.HTTPHeaderName]("X-API-Key")},
+							MetadataKey: "total-token",
+							Limit:       100000,
+							Window:      "1h",
+						},
+					},
+					RequestLimits: &aigv1a1.AIGatewayRouteRequestLimits{
+						MaxTokens:       ptr.To[int64](4096),
+						MaxTokensAction: ptr.To(aigv1a1.MaxTokensActionReject),
+						MaxMessages:     ptr.To[int32](100),
+						MaxTools:        ptr.To[int32](0),
 					},
+					ForceStreamUsage: true,
+					GatewayAPIKeys:   &aigv1a1.AIGatewayRouteGatewayAPIKeys{},
 				},
 			},
 			exp: &filterapi.Config{
@@ -611,13 +759,13 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 				Rules: []filterapi.RouteRule{
 					{
 						Backends: []filterapi.Backend{
-							{Name: "apple.ns", Weight: 1, Schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaAWSBedrock}, Auth: &filterapi.BackendAuth{
+							{Name: "apple.ns", Weight: 1, ModelNameOverride: "anthropic.claude-3-5-sonnet-20241022-v2:0", Schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaAWSBedrock}, Auth: &filterapi.BackendAuth{
 								APIKey: &filterapi.APIKeyAuth{
 									Filename: "/etc/backend_security_policy/rule0-backref0-some-backend-security-policy-1/apiKey",
 								},
 							}}, {Name: "pineapple.ns", Weight: 2},
 						},
-						Headers: []filterapi.HeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai"}},
+						Matches: []filterapi.RouteRuleMatch{{Headers: []filterapi.HeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai"}}}},
 					},
 					{
 						Backends: []filterapi.Backend{{Name: "cat.ns", Weight: 1, Auth: &filterapi.BackendAuth{
@@ -625,7 +773,31 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 								Filename: "/etc/backend_security_policy/rule1-backref0-some-backend-security-policy-1/apiKey",
 							},
 						}}},
-						Headers: []filterapi.HeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai"}},
+						LoadBalancingPolicy: &filterapi.LoadBalancingPolicy{Type: filterapi.LoadBalancingPolicyTypeAdaptive},
+						CircuitBreaker:      &filterapi.CircuitBreaker{ConsecutiveFailures: 3, OpenDuration: 30 * time.Second},
+						Fallbacks: []filterapi.Backend{{
+							Name:        "fallback.ns",
+							Schema:      filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI},
+							Endpoint:    "https://api.openai.com:443",
+							EndpointTLS: &filterapi.BackendTLS{Hostname: "api.openai.com"},
+							Auth: &filterapi.BackendAuth{APIKey: &filterapi.APIKeyAuth{
+								Filename: "/etc/backend_security_policy/rule1-backref1-some-backend-security-policy-1/apiKey",
+							}},
+						}},
+						Mirror: &filterapi.Backend{
+							Name:              "fallback.ns",
+							Schema:            filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI},
+							Endpoint:          "https://api.openai.com:443",
+							EndpointTLS:       &filterapi.BackendTLS{Hostname: "api.openai.com"},
+							ModelNameOverride: "gpt-4o",
+							Auth: &filterapi.BackendAuth{APIKey: &filterapi.APIKeyAuth{
+								Filename: "/etc/backend_security_policy/rule1-backref2-some-backend-security-policy-1/apiKey",
+							}},
+						},
+						Matches: []filterapi.RouteRuleMatch{{
+							Headers:              []filterapi.HeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai"}},
+							EstimatedInputTokens: &filterapi.TokenRange{Max: ptr.To[int64](1000)},
+						}},
 					},
 					{
 						Backends: []filterapi.Backend{{Name: "pen.ns", Weight: 2, Auth: &filterapi.BackendAuth{
@@ -634,7 +806,15 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 								Region:             "us-east-1",
 							},
 						}}},
-						Headers: []filterapi.HeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai-2"}},
+						Matches: []filterapi.RouteRuleMatch{
+							{Headers: []filterapi.HeaderMatch{
+								{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai-.*", Type: ptr.To(gwapiv1.HeaderMatchRegularExpression)},
+								{Name: "x-tenant", Value: "pen", Type: ptr.To(gwapiv1.HeaderMatchExact)},
+							}},
+							{Headers: []filterapi.HeaderMatch{
+								{Name: aigv1a1.AIModelHeaderKey, Value: "pen-", Type: ptr.To(filterapi.HeaderMatchPrefix)},
+							}},
+						},
 					},
 					{
 						Backends: []filterapi.Backend{{Name: "dog.ns", Weight: 1, Auth: &filterapi.BackendAuth{
@@ -643,15 +823,54 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 								Region:             "us-east-1",
 							},
 						}}},
-						Headers: []filterapi.HeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai-3"}},
+						Matches: []filterapi.RouteRuleMatch{{Headers: []filterapi.HeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai-3"}}}},
+					},
+					{
+						Backends: []filterapi.Backend{{Name: "azure.ns", Weight: 1, Schema: filterapi.VersionedAPISchema{
+							Name:        filterapi.APISchemaAzureOpenAI,
+							Version:     "2024-10-21",
+							Deployments: map[string]string{"gpt-4o": "my-gpt-4o"},
+						}, Auth: &filterapi.BackendAuth{
+							APIKey: &filterapi.APIKeyAuth{
+								Filename:   "/etc/backend_security_policy/rule4-backref0-some-backend-security-policy-4/apiKey",
+								HeaderName: "api-key",
+							},
+						}}},
+						Matches: []filterapi.RouteRuleMatch{{Headers: []filterapi.HeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "gpt-4o"}}}},
+					},
+					{
+						Backends: []filterapi.Backend{{Name: "azure-entra.ns", Weight: 1, Schema: filterapi.VersionedAPISchema{
+							Name:    filterapi.APISchemaAzureOpenAI,
+							Version: "2024-10-21",
+						}, Auth: &filterapi.BackendAuth{
+							AzureAuth: &filterapi.AzureAuth{
+								Filename: "/etc/backend_security_policy/rule5-backref0-some-backend-security-policy-5/azureAccessToken",
+							},
+						}}},
+						Matches: []filterapi.RouteRuleMatch{{Headers: []filterapi.HeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "gpt-4o-mini"}}}},
 					},
 				},
 				LLMRequestCosts: []filterapi.LLMRequestCost{
 					{Type: filterapi.LLMRequestCostTypeOutputToken, MetadataKey: "output-token"},
 					{Type: filterapi.LLMRequestCostTypeInputToken, MetadataKey: "input-token"},
 					{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total-token"},
-					{Type: filterapi.LLMRequestCostTypeCEL, MetadataKey: "cel-token", CEL: "model == 'cool_model' ?  input_tokens * output_tokens : total_tokens"},
+					{Type: filterapi.LLMRequestCostTypeCachedInputToken, MetadataKey: "cached-input-token"},
+					{Type: filterapi.LLMRequestCostTypeCacheWriteInputToken, MetadataKey: "cache-write-input-token"},
+					{Type: filterapi.LLMRequestCostTypeReasoningToken, MetadataKey: "reasoning-token"},
+					{Type: filterapi.LLMRequestCostTypeCEL, MetadataKey: "cel-token", CEL: "model == 'cool_model' ?  input_tokens * output_tokens : total_tokens", Scale: 1000, Rounding: filterapi.LLMRequestCostRoundingCeil},
+					{Type: filterapi.LLMRequestCostTypePrice, MetadataKey: "usd", Prices: []filterapi.ModelPrice{
+						{Model: "gpt-4o", Backend: "azure.ns", Input: 2.5, Output: 10, CachedInput: 1.25, CacheWriteInput: 2.5, Reasoning: 10},
+					}, Rounding: filterapi.LLMRequestCostRoundingFloor},
+				},
+				TokenBudgets: []filterapi.TokenBudget{
+					{
+						Name: "per-api-key", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeHeader, Header: "x-api-key"},
+						MetadataKey: "total-token", Limit: 100000, Window: time.Hour,
+					},
 				},
+				RequestLimits:    &filterapi.RequestLimits{MaxTokens: 4096, RejectMaxTokens: true, MaxMessages: 100, MaxTools: ptr.To(0)},
+				ForceStreamUsage: true,
+				GatewayAPIKeys:   &filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{}},
 			},
 		},
 	} {
@@ -676,6 +895,332 @@ func TestAIGatewayRouteController_updateExtProcConfigMap(t *testing.T) {
 	}
 }
 
+func TestAIGatewayRouteController_backendEndpoint(t *testing.T) {
+	fakeClient := requireNewFakeClientWithIndexes(t)
+	s := NewAIGatewayRouteController(fakeClient, fake2.NewClientset(), logr.Discard(), "defaultExtProcImage", "debug")
+	for _, b := range []*egv1a1.Backend{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "fqdn", Namespace: "ns"},
+			Spec: egv1a1.BackendSpec{Endpoints: []egv1a1.BackendEndpoint{
+				{FQDN: &egv1a1.FQDNEndpoint{Hostname: "api.openai.com", Port: 443}},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ip", Namespace: "other"},
+			Spec: egv1a1.BackendSpec{Endpoints: []egv1a1.BackendEndpoint{
+				{IP: &egv1a1.IPEndpoint{Address: "10.0.0.1", Port: 8080}},
+			}},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "ns"}},
+	} {
+		require.NoError(t, fakeClient.Create(t.Context(), b))
+	}
+	for _, obj := range []client.Object{
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "ns"}, Spec: corev1.ServiceSpec{ClusterIP: "10.96.0.10"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "tls-svc", Namespace: "ns"}, Spec: corev1.ServiceSpec{ClusterIP: "10.96.0.11"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "missing-ca-svc", Namespace: "ns"}, Spec: corev1.ServiceSpec{ClusterIP: "10.96.0.12"}},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "vllm.example.com"},
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "headless", Namespace: "ns"}, Spec: corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "ns"}, Data: map[string]string{"ca.crt": "some-ca"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "no-ca", Namespace: "ns"}},
+		&gwapiv1a3.BackendTLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls-svc", Namespace: "ns"},
+			Spec: gwapiv1a3.BackendTLSPolicySpec{
+				TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{
+					{LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{Kind: "Service", Name: "tls-svc"}},
+				},
+				Validation: gwapiv1a3.BackendTLSPolicyValidation{
+					Hostname:          "tls.example.com",
+					CACertificateRefs: []gwapiv1.LocalObjectReference{{Kind: "ConfigMap", Name: "ca"}},
+				},
+			},
+		},
+		&gwapiv1a3.BackendTLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls-ip", Namespace: "other"},
+			Spec: gwapiv1a3.BackendTLSPolicySpec{
+				TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{
+					{LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{Group: "gateway.envoyproxy.io", Kind: "Backend", Name: "ip"}},
+				},
+				Validation: gwapiv1a3.BackendTLSPolicyValidation{
+					Hostname:                "ip.example.com",
+					WellKnownCACertificates: ptr.To(gwapiv1a3.WellKnownCACertificatesSystem),
+				},
+			},
+		},
+		&gwapiv1a3.BackendTLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-ca", Namespace: "ns"},
+			Spec: gwapiv1a3.BackendTLSPolicySpec{
+				TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{
+					{LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{Kind: "Service", Name: "missing-ca-svc"}},
+				},
+				Validation: gwapiv1a3.BackendTLSPolicyValidation{
+					Hostname:          "tls.example.com",
+					CACertificateRefs: []gwapiv1.LocalObjectReference{{Kind: "ConfigMap", Name: "no-ca"}},
+				},
+			},
+		},
+	} {
+		require.NoError(t, fakeClient.Create(t.Context(), obj))
+	}
+
+	backendKind := ptr.To[gwapiv1.Kind]("Backend")
+	for _, tc := range []struct {
+		name   string
+		ref    gwapiv1.BackendObjectReference
+		exp    string
+		expTLS *filterapi.BackendTLS
+		expErr string
+	}{
+		{name: "service", ref: gwapiv1.BackendObjectReference{Name: "svc", Port: ptr.To[gwapiv1.PortNumber](8000)}, exp: "http://10.96.0.10:8000"},
+		{
+			name: "external name service",
+			ref:  gwapiv1.BackendObjectReference{Name: "external", Port: ptr.To[gwapiv1.PortNumber](8000)},
+			exp:  "http://vllm.example.com:8000",
+		},
+		{
+			name:   "headless service",
+			ref:    gwapiv1.BackendObjectReference{Name: "headless", Port: ptr.To[gwapiv1.PortNumber](8000)},
+			expErr: "service headless has neither a cluster IP nor an external name",
+		},
+		{
+			name:   "service not found",
+			ref:    gwapiv1.BackendObjectReference{Name: "missing", Port: ptr.To[gwapiv1.PortNumber](8000)},
+			expErr: "failed to get Service missing",
+		},
+		{
+			name:   "service with BackendTLSPolicy",
+			ref:    gwapiv1.BackendObjectReference{Name: "tls-svc", Port: ptr.To[gwapiv1.PortNumber](8443)},
+			exp:    "https://10.96.0.11:8443",
+			expTLS: &filterapi.BackendTLS{Hostname: "tls.example.com", CACertificates: "some-ca"},
+		},
+		{
+			name:   "BackendTLSPolicy without ca.crt",
+			ref:    gwapiv1.BackendObjectReference{Name: "missing-ca-svc", Port: ptr.To[gwapiv1.PortNumber](8443)},
+			expErr: "ConfigMap no-ca has no ca.crt",
+		},
+		{name: "service without port", ref: gwapiv1.BackendObjectReference{Name: "svc"}, expErr: "port must be specified for Service svc"},
+		{name: "fqdn without BackendTLSPolicy", ref: gwapiv1.BackendObjectReference{Name: "fqdn", Kind: backendKind}, exp: "http://api.openai.com:443"},
+		{
+			name:   "ip in another namespace",
+			ref:    gwapiv1.BackendObjectReference{Name: "ip", Kind: backendKind, Namespace: ptr.To[gwapiv1.Namespace]("other")},
+			exp:    "https://10.0.0.1:8080",
+			expTLS: &filterapi.BackendTLS{Hostname: "ip.example.com"},
+		},
+		{name: "no endpoints", ref: gwapiv1.BackendObjectReference{Name: "empty", Kind: backendKind}, expErr: "backend empty has no endpoints"},
+		{name: "not found", ref: gwapiv1.BackendObjectReference{Name: "missing", Kind: backendKind}, expErr: "failed to get Backend missing"},
+		{name: "unknown kind", ref: gwapiv1.BackendObjectReference{Name: "foo", Kind: ptr.To[gwapiv1.Kind]("Foo")}, expErr: "unsupported backend kind Foo"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint, tlsConfig, err := s.backendEndpoint(t.Context(), "ns", &tc.ref)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, endpoint)
+			require.Equal(t, tc.expTLS, tlsConfig)
+		})
+	}
+}
+
+func TestAIGatewayRouteController_modelPrices(t *testing.T) {
+	fakeClient := requireNewFakeClientWithIndexes(t)
+	s := NewAIGatewayRouteController(fakeClient, fake2.NewClientset(), logr.Discard(), "defaultExtProcImage", "debug")
+	require.NoError(t, fakeClient.Create(t.Context(), &aigv1a1.LLMPricing{
+		ObjectMeta: metav1.ObjectMeta{Name: "pricing", Namespace: "ns"},
+		Spec: aigv1a1.LLMPricingSpec{Models: []aigv1a1.LLMModelPrice{
+			{
+				ModelName: "claude", InputTokenPrice: "3", OutputTokenPrice: "15",
+				CacheWriteInputTokenPrice: ptr.To("3.75"), CachedInputTokenPrice: ptr.To("0.3"), ReasoningTokenPrice: ptr.To("20"),
+			},
+			{ModelName: "gpt-4o-mini", BackendName: ptr.To("openai"), InputTokenPrice: "0.15", OutputTokenPrice: "0.6"},
+		}},
+	}))
+	require.NoError(t, fakeClient.Create(t.Context(), &aigv1a1.LLMPricing{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "ns"},
+		Spec: aigv1a1.LLMPricingSpec{Models: []aigv1a1.LLMModelPrice{
+			{ModelName: "claude", InputTokenPrice: "3", OutputTokenPrice: "15", ReasoningTokenPrice: ptr.To("twenty")},
+		}},
+	}))
+
+	prices, err := s.modelPrices(t.Context(), "ns", "pricing")
+	require.NoError(t, err)
+	require.Equal(t, []filterapi.ModelPrice{
+		{Model: "claude", Input: 3, Output: 15, CachedInput: 0.3, CacheWriteInput: 3.75, Reasoning: 20},
+		{Model: "gpt-4o-mini", Backend: "openai.ns", Input: 0.15, Output: 0.6, CachedInput: 0.15, CacheWriteInput: 0.15, Reasoning: 0.6},
+	}, prices)
+
+	_, err = s.modelPrices(t.Context(), "ns", "invalid")
+	require.ErrorContains(t, err, "invalid price of model claude in LLMPricing invalid: invalid reasoning token price")
+	_, err = s.modelPrices(t.Context(), "ns", "nonexistent")
+	require.ErrorContains(t, err, "failed to get LLMPricing nonexistent")
+}
+
+func TestAIGatewayRouteController_gatewayAPIKeys(t *testing.T) {
+	fakeClient := requireNewFakeClientWithIndexes(t)
+	s := NewAIGatewayRouteController(fakeClient, fake2.NewClientset(), logr.Discard(), "defaultExtProcImage", "debug")
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, key := range []*aigv1a1.GatewayAPIKey{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bob", Namespace: "ns", Labels: map[string]string{"team": "b"}},
+			Spec:       aigv1a1.GatewayAPIKeySpec{KeyHash: strings.Repeat("b", 64), Owner: "bob"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "ns", Labels: map[string]string{"team": "a"}},
+			Spec: aigv1a1.GatewayAPIKeySpec{
+				KeyHash: strings.Repeat("a", 64), Owner: "alice", AllowedModels: []string{"gpt-4o"},
+				TokenBudgets: []aigv1a1.GatewayAPIKeyTokenBudget{{MetadataKey: "total", Limit: 100, Window: "24h"}},
+				ExpiresAt:    &metav1.Time{Time: expiresAt},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "carol", Namespace: "other"},
+			Spec:       aigv1a1.GatewayAPIKeySpec{KeyHash: strings.Repeat("c", 64), Owner: "carol"},
+		},
+	} {
+		require.NoError(t, fakeClient.Create(t.Context(), key))
+	}
+	alice := filterapi.GatewayAPIKey{
+		Name: "alice", Hash: strings.Repeat("a", 64), Owner: "alice", AllowedModels: []string{"gpt-4o"},
+		TokenBudgets: []filterapi.TokenBudget{{
+			Name: "alice/total", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeAPIKey},
+			MetadataKey: "total", Limit: 100, Window: 24 * time.Hour,
+		}},
+		ExpiresAt: &expiresAt,
+	}
+	bob := filterapi.GatewayAPIKey{Name: "bob", Hash: strings.Repeat("b", 64), Owner: "bob"}
+
+	keys, err := s.gatewayAPIKeys(t.Context(), "ns", &aigv1a1.AIGatewayRouteGatewayAPIKeys{})
+	require.NoError(t, err)
+	require.Equal(t, &filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{alice, bob}}, keys)
+
+	keys, err = s.gatewayAPIKeys(t.Context(), "ns", &aigv1a1.AIGatewayRouteGatewayAPIKeys{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{bob}}, keys)
+
+	keys, err = s.gatewayAPIKeys(t.Context(), "empty", &aigv1a1.AIGatewayRouteGatewayAPIKeys{})
+	require.NoError(t, err)
+	require.Equal(t, &filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{}}, keys)
+
+	_, err = s.gatewayAPIKeys(t.Context(), "ns", &aigv1a1.AIGatewayRouteGatewayAPIKeys{
+		Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Unknown"}}},
+	})
+	require.ErrorContains(t, err, "invalid gatewayAPIKeys selector")
+}
+
+func Test_tokenBudgetConfig(t *testing.T) {
+	costs := []filterapi.LLMRequestCost{{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}}
+	for _, tc := range []struct {
+		name   string
+		in     aigv1a1.AIGatewayRouteTokenBudget
+		exp    *filterapi.TokenBudget
+		expErr string
+	}{
+		{
+			name: "user",
+			in: aigv1a1.AIGatewayRouteTokenBudget{
+				Name: "per-user", Key: aigv1a1.AIGatewayRouteTokenBudgetKey{Type: aigv1a1.TokenBudgetKeyTypeUser},
+				MetadataKey: "total", Limit: 10, Window: "1m",
+			},
+			exp: &filterapi.TokenBudget{
+				Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser},
+				MetadataKey: "total", Limit: 10, Window: time.Minute,
+			},
+		},
+		{
+			name: "api key",
+			in: aigv1a1.AIGatewayRouteTokenBudget{
+				Name: "per-api-key", Key: aigv1a1.AIGatewayRouteTokenBudgetKey{Type: aigv1a1.TokenBudgetKeyTypeAPIKey},
+				MetadataKey: "total", Limit: 10, Window: "1m",
+			},
+			exp: &filterapi.TokenBudget{
+				Name: "per-api-key", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeAPIKey},
+				MetadataKey: "total", Limit: 10, Window: time.Minute,
+			},
+		},
+		{
+			name: "unknown metadata key",
+			in: aigv1a1.AIGatewayRouteTokenBudget{
+				Name: "per-user", Key: aigv1a1.AIGatewayRouteTokenBudgetKey{Type: aigv1a1.TokenBudgetKeyTypeUser},
+				MetadataKey: "output", Limit: 10, Window: "1m",
+			},
+			expErr: "metadataKey output is not one of the llmRequestCosts",
+		},
+		{
+			name: "invalid window",
+			in: aigv1a1.AIGatewayRouteTokenBudget{
+				Name: "per-user", Key: aigv1a1.AIGatewayRouteTokenBudgetKey{Type: aigv1a1.TokenBudgetKeyTypeUser},
+				MetadataKey: "total", Limit: 10, Window: "1d",
+			},
+			expErr: `invalid window "1d"`,
+		},
+		{
+			name: "header without name",
+			in: aigv1a1.AIGatewayRouteTokenBudget{
+				Name: "per-key", Key: aigv1a1.AIGatewayRouteTokenBudgetKey{Type: aigv1a1.TokenBudgetKeyTypeHeader},
+				MetadataKey: "total", Limit: 10, Window: "1m",
+			},
+			expErr: "header must be set",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tb, err := tokenBudgetConfig(&tc.in, costs)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, tb)
+		})
+	}
+}
+
+func Test_consistentHashConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		in     *aigv1a1.AIGatewayRouteRuleConsistentHash
+		exp    *filterapi.ConsistentHash
+		expErr string
+	}{
+		{
+			name: "header",
+			in:   &aigv1a1.AIGatewayRouteRuleConsistentHash{Type: aigv1a1.ConsistentHashTypeHeader, Header: ptr.To[gwapiv1.HTTPHeaderName]("X-Session-ID")},
+			exp:  &filterapi.ConsistentHash{Type: filterapi.ConsistentHashTypeHeader, Header: "x-session-id"},
+		},
+		{
+			name: "user",
+			in:   &aigv1a1.AIGatewayRouteRuleConsistentHash{Type: aigv1a1.ConsistentHashTypeUser},
+			exp:  &filterapi.ConsistentHash{Type: filterapi.ConsistentHashTypeUser},
+		},
+		{name: "nil", expErr: "consistentHash must be set"},
+		{
+			name:   "header without name",
+			in:     &aigv1a1.AIGatewayRouteRuleConsistentHash{Type: aigv1a1.ConsistentHashTypeHeader},
+			expErr: "header must be set",
+		},
+		{
+			name:   "unknown",
+			in:     &aigv1a1.AIGatewayRouteRuleConsistentHash{Type: "Cookie"},
+			expErr: "unknown consistent hash type: Cookie",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ch, err := consistentHashConfig(tc.in)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, ch)
+		})
+	}
+}
+
 func TestAIGatewayRouteController_syncExtProcDeployment(t *testing.T) {
 	t.Skip()
 	fakeClient := requireNewFakeClientWithIndexes(t)
@@ -754,13 +1299,13 @@ func TestAIGatewayRouteController_syncExtProcDeployment(t *testing.T) {
 						{Name: "pineapple", Weight: 2},
 					},
 					Matches: []aigv1a1.AIGatewayRouteRuleMatch{
-						{Headers: []gwapiv1.HTTPHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai"}}},
+						{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai"}}},
 					},
 				},
 				{
 					BackendRefs: []aigv1a1.AIGatewayRouteRuleBackendRef{{Name: "cat", Weight: 1}},
 					Matches: []aigv1a1.AIGatewayRouteRuleMatch{
-						{Headers: []gwapiv1.HTTPHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai"}}},
+						{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "another-ai"}}},
 					},
 				},
 			},
@@ -854,6 +1399,7 @@ func TestAIGatewayRouteController_MountBackendSecurityPolicySecrets(t *testing.T
 		{ObjectMeta: metav1.ObjectMeta{Name: "some-secret-policy-2"}},
 		{ObjectMeta: metav1.ObjectMeta{Name: "some-secret-policy-3"}},
 		{ObjectMeta: metav1.ObjectMeta{Name: "aws-oidc-name"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "azure-client-secret"}},
 	} {
 		require.NoError(t, fakeClient.Create(t.Context(), secret, &client.CreateOptions{}))
 	}
@@ -900,6 +1446,17 @@ func TestAIGatewayRouteController_MountBackendSecurityPolicySecrets(t *testing.T
 				},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "azure-name", Namespace: "ns"},
+			Spec: aigv1a1.BackendSecurityPolicySpec{
+				Type: aigv1a1.BackendSecurityPolicyTypeAzureCredentials,
+				AzureCredentials: &aigv1a1.BackendSecurityPolicyAzureCredentials{
+					TenantID:        "some-tenant-id",
+					ClientID:        "some-client-id",
+					ClientSecretRef: &gwapiv1.SecretObjectReference{Name: "azure-client-secret"},
+				},
+			},
+		},
 	} {
 		require.NoError(t, fakeClient.Create(t.Context(), bsp, &client.CreateOptions{}))
 	}
@@ -935,6 +1492,16 @@ func TestAIGatewayRouteController_MountBackendSecurityPolicySecrets(t *testing.T
 				BackendSecurityPolicyRef: &gwapiv1.LocalObjectReference{Name: "aws-oidc-name"},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cat", Namespace: "ns"},
+			Spec: aigv1a1.AIServiceBackendSpec{
+				APISchema: aigv1a1.VersionedAPISchema{
+					Name: aigv1a1.APISchemaAzureOpenAI,
+				},
+				BackendRef:               gwapiv1.BackendObjectReference{Name: "some-backend5", Namespace: ptr.To[gwapiv1.Namespace]("ns")},
+				BackendSecurityPolicyRef: &gwapiv1.LocalObjectReference{Name: "azure-name"},
+			},
+		},
 	} {
 		require.NoError(t, fakeClient.Create(t.Context(), backend, &client.CreateOptions{}))
 		require.NotNil(t, c)
@@ -949,7 +1516,7 @@ func TestAIGatewayRouteController_MountBackendSecurityPolicySecrets(t *testing.T
 						{Name: "apple", Weight: 1},
 					},
 					Matches: []aigv1a1.AIGatewayRouteRuleMatch{
-						{Headers: []gwapiv1.HTTPHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai"}}},
+						{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai"}}},
 					},
 				},
 				{
@@ -957,7 +1524,7 @@ func TestAIGatewayRouteController_MountBackendSecurityPolicySecrets(t *testing.T
 						{Name: "pineapple", Weight: 1},
 					},
 					Matches: []aigv1a1.AIGatewayRouteRuleMatch{
-						{Headers: []gwapiv1.HTTPHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai-2"}}},
+						{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai-2"}}},
 					},
 				},
 				{
@@ -965,7 +1532,15 @@ func TestAIGatewayRouteController_MountBackendSecurityPolicySecrets(t *testing.T
 						{Name: "dog", Weight: 1},
 					},
 					Matches: []aigv1a1.AIGatewayRouteRuleMatch{
-						{Headers: []gwapiv1.HTTPHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai-3"}}},
+						{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai-3"}}},
+					},
+				},
+				{
+					BackendRefs: []aigv1a1.AIGatewayRouteRuleBackendRef{
+						{Name: "cat", Weight: 1},
+					},
+					Matches: []aigv1a1.AIGatewayRouteRuleMatch{
+						{Headers: []aigv1a1.AIGatewayRouteRuleHeaderMatch{{Name: aigv1a1.AIModelHeaderKey, Value: "some-ai-4"}}},
 					},
 				},
 			},
@@ -995,8 +1570,8 @@ func TestAIGatewayRouteController_MountBackendSecurityPolicySecrets(t *testing.T
 	updatedSpec, err := c.mountBackendSecurityPolicySecrets(t.Context(), &spec, &aiGateway)
 	require.NoError(t, err)
 
-	require.Len(t, updatedSpec.Volumes, 4)
-	require.Len(t, updatedSpec.Containers[0].VolumeMounts, 4)
+	require.Len(t, updatedSpec.Volumes, 5)
+	require.Len(t, updatedSpec.Containers[0].VolumeMounts, 5)
 	// API Key.
 	require.Equal(t, "some-secret-policy-1", updatedSpec.Volumes[1].VolumeSource.Secret.SecretName)
 	require.Equal(t, "rule0-backref0-some-other-backend-security-policy-1", updatedSpec.Volumes[1].Name)
@@ -1012,6 +1587,10 @@ func TestAIGatewayRouteController_MountBackendSecurityPolicySecrets(t *testing.T
 	require.Equal(t, "rule2-backref0-aws-oidc-name", updatedSpec.Volumes[3].Name)
 	require.Equal(t, "rule2-backref0-aws-oidc-name", updatedSpec.Containers[0].VolumeMounts[3].Name)
 	require.Equal(t, "/etc/backend_security_policy/rule2-backref0-aws-oidc-name", updatedSpec.Containers[0].VolumeMounts[3].MountPath)
+	// Azure Credentials.
+	require.Equal(t, rotators.GetBSPSecretName("azure-name"), updatedSpec.Volumes[4].VolumeSource.Secret.SecretName)
+	require.Equal(t, "rule3-backref0-azure-name", updatedSpec.Volumes[4].Name)
+	require.Equal(t, "/etc/backend_security_policy/rule3-backref0-azure-name", updatedSpec.Containers[0].VolumeMounts[4].MountPath)
 
 	require.NoError(t, fakeClient.Delete(t.Context(), &aigv1a1.AIServiceBackend{ObjectMeta: metav1.ObjectMeta{Name: "apple", Namespace: "ns"}}, &client.DeleteOptions{}))
 
@@ -1033,8 +1612,8 @@ func TestAIGatewayRouteController_MountBackendSecurityPolicySecrets(t *testing.T
 	updatedSpec, err = c.mountBackendSecurityPolicySecrets(t.Context(), &spec, &aiGateway)
 	require.NoError(t, err)
 
-	require.Len(t, updatedSpec.Volumes, 4)
-	require.Len(t, updatedSpec.Containers[0].VolumeMounts, 4)
+	require.Len(t, updatedSpec.Volumes, 5)
+	require.Len(t, updatedSpec.Containers[0].VolumeMounts, 5)
 	require.Equal(t, "some-secret-policy-2", updatedSpec.Volumes[1].VolumeSource.Secret.SecretName)
 	require.Equal(t, "rule0-backref0-some-other-backend-security-policy-2", updatedSpec.Volumes[1].Name)
 	require.Equal(t, "rule0-backref0-some-other-backend-security-policy-2", updatedSpec.Containers[0].VolumeMounts[1].Name)
@@ -19,6 +19,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
 	"github.com/envoyproxy/ai-gateway/internal/controller/oauth"
@@ -29,6 +30,13 @@ import (
 // Temporarily a fixed duration.
 const preRotationWindow = 5 * time.Minute
 
+const (
+	// azureTokenEndpointFormat is the format of the Microsoft Entra ID token endpoint of the tenant.
+	azureTokenEndpointFormat = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+	// azureCognitiveServicesScope is the scope of the access token for Azure OpenAI.
+	azureCognitiveServicesScope = "https://cognitiveservices.azure.com/.default"
+)
+
 // BackendSecurityPolicyController implements [reconcile.TypedReconciler] for [aigv1a1.BackendSecurityPolicy].
 //
 // Exported for testing purposes.
@@ -62,7 +70,8 @@ func (c *BackendSecurityPolicyController) Reconcile(ctx context.Context, req ctr
 		}
 		return ctrl.Result{}, err
 	}
-	if oidc := getBackendSecurityPolicyAuthOIDC(backendSecurityPolicy.Spec); oidc != nil {
+	oidc := getBackendSecurityPolicyAuthOIDC(backendSecurityPolicy.Spec)
+	if oidc != nil || backendSecurityPolicy.Spec.Type == aigv1a1.BackendSecurityPolicyTypeAzureCredentials {
 		var rotator rotators.Rotator
 		switch backendSecurityPolicy.Spec.Type {
 		case aigv1a1.BackendSecurityPolicyTypeAWSCredentials:
@@ -72,6 +81,15 @@ func (c *BackendSecurityPolicyController) Reconcile(ctx context.Context, req ctr
 			if err != nil {
 				return ctrl.Result{}, err
 			}
+		case aigv1a1.BackendSecurityPolicyTypeAzureCredentials:
+			azureCreds := backendSecurityPolicy.Spec.AzureCredentials
+			if azureCreds == nil {
+				err = fmt.Errorf("backend security policy %s/%s of type %s is missing azureCredentials",
+					backendSecurityPolicy.Namespace, backendSecurityPolicy.Name, backendSecurityPolicy.Spec.Type)
+				return ctrl.Result{}, err
+			}
+			tokenProvider := oauth.NewClientCredentialsProvider(c.client, getAzureClientCredentialsOIDC(backendSecurityPolicy.Namespace, azureCreds))
+			rotator = rotators.NewAzureTokenRotator(c.client, tokenProvider, c.logger, backendSecurityPolicy.Namespace, backendSecurityPolicy.Name, preRotationWindow)
 		default:
 			err = fmt.Errorf("backend security type %s does not support OIDC token exchange", backendSecurityPolicy.Spec.Type)
 			c.logger.Error(err, "namespace", backendSecurityPolicy.Namespace, "name", backendSecurityPolicy.Name)
@@ -85,9 +103,9 @@ func (c *BackendSecurityPolicyController) Reconcile(ctx context.Context, req ctr
 			c.logger.Error(err, "failed to get rotation time, retry in one minute")
 		} else {
 			if rotator.IsExpired(rotationTime) {
-				requeue, err = c.rotateCredential(ctx, &backendSecurityPolicy, *oidc, rotator)
+				requeue, err = c.rotateCredential(ctx, &backendSecurityPolicy, oidc, rotator)
 				if err != nil {
-					c.logger.Error(err, "failed to rotate OIDC exchange token, retry in one minute")
+					c.logger.Error(err, "failed to rotate credentials, retry in one minute")
 				} else {
 					c.logger.Info(
 						fmt.Sprintf("successfully rotated credentials for %s in namespace %s of auth type %s, renewing in %f minutes",
@@ -103,25 +121,29 @@ func (c *BackendSecurityPolicyController) Reconcile(ctx context.Context, req ctr
 }
 
 // rotateCredential rotates the credentials using the access token from OIDC provider and return the requeue time for next rotation.
-func (c *BackendSecurityPolicyController) rotateCredential(ctx context.Context, policy *aigv1a1.BackendSecurityPolicy, oidcCreds egv1a1.OIDC, rotator rotators.Rotator) (time.Duration, error) {
+// When oidcCreds is nil, the rotator obtains the credentials by itself, e.g. the Azure access token.
+func (c *BackendSecurityPolicyController) rotateCredential(ctx context.Context, policy *aigv1a1.BackendSecurityPolicy, oidcCreds *egv1a1.OIDC, rotator rotators.Rotator) (time.Duration, error) {
 	bspKey := backendSecurityPolicyKey(policy.Namespace, policy.Name)
 
 	var err error
-	c.oidcTokenCacheMutex.RLock()
-	validToken, ok := c.oidcTokenCache[bspKey]
-	c.oidcTokenCacheMutex.RUnlock()
-	if !ok || validToken == nil || rotators.IsBufferedTimeExpired(preRotationWindow, validToken.Expiry) {
-		oidcProvider := oauth.NewOIDCProvider(c.client, oidcCreds)
-		validToken, err = oidcProvider.FetchToken(ctx)
-		if err != nil {
-			return time.Minute, err
+	var token string
+	if oidcCreds != nil {
+		c.oidcTokenCacheMutex.RLock()
+		validToken, ok := c.oidcTokenCache[bspKey]
+		c.oidcTokenCacheMutex.RUnlock()
+		if !ok || validToken == nil || rotators.IsBufferedTimeExpired(preRotationWindow, validToken.Expiry) {
+			oidcProvider := oauth.NewOIDCProvider(c.client, *oidcCreds)
+			validToken, err = oidcProvider.FetchToken(ctx)
+			if err != nil {
+				return time.Minute, err
+			}
+			c.oidcTokenCacheMutex.Lock()
+			c.oidcTokenCache[bspKey] = validToken
+			c.oidcTokenCacheMutex.Unlock()
 		}
-		c.oidcTokenCacheMutex.Lock()
-		c.oidcTokenCache[bspKey] = validToken
-		c.oidcTokenCacheMutex.Unlock()
+		token = validToken.AccessToken
 	}
 
-	token := validToken.AccessToken
 	err = rotator.Rotate(ctx, token)
 	if err != nil {
 		return time.Minute, err
@@ -147,6 +169,23 @@ func getBackendSecurityPolicyAuthOIDC(spec aigv1a1.BackendSecurityPolicySpec) *e
 	return nil
 }
 
+// getAzureClientCredentialsOIDC returns the OIDC config to obtain the Azure access token of the application with the
+// client credentials flow. The namespace of the client secret defaults to that of the backendSecurityPolicy.
+func getAzureClientCredentialsOIDC(namespace string, creds *aigv1a1.BackendSecurityPolicyAzureCredentials) *egv1a1.OIDC {
+	clientSecret := *creds.ClientSecretRef
+	if clientSecret.Namespace == nil {
+		ns := gwapiv1.Namespace(namespace)
+		clientSecret.Namespace = &ns
+	}
+	tokenEndpoint := fmt.Sprintf(azureTokenEndpointFormat, creds.TenantID)
+	return &egv1a1.OIDC{
+		Provider:     egv1a1.OIDCProvider{TokenEndpoint: &tokenEndpoint},
+		ClientID:     creds.ClientID,
+		ClientSecret: clientSecret,
+		Scopes:       []string{azureCognitiveServicesScope},
+	}
+}
+
 // backendSecurityPolicyKey returns the key used for indexing and caching the backendSecurityPolicy.
 func backendSecurityPolicyKey(namespace, name string) string {
 	return fmt.Sprintf("%s.%s", name, namespace)
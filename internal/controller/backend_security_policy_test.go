@@ -33,6 +33,7 @@ import (
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
+	"github.com/envoyproxy/ai-gateway/internal/controller/oauth"
 	"github.com/envoyproxy/ai-gateway/internal/controller/rotators"
 	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
 )
@@ -203,7 +204,7 @@ func TestBackendSecurityController_RotateCredentials(t *testing.T) {
 	require.Error(t, err)
 
 	// first credential rotation should create aws credentials secret
-	res, err := c.rotateCredential(ctx, bsp, oidc, rotator)
+	res, err := c.rotateCredential(ctx, bsp, &oidc, rotator)
 	require.NoError(t, err)
 	require.WithinRange(t, time.Now().Add(res), time.Now().Add(50*time.Minute), time.Now().Add(time.Hour))
 
@@ -232,7 +233,7 @@ func TestBackendSecurityController_RotateCredentials(t *testing.T) {
 	require.NoError(t, cl.Update(t.Context(), awsSecret1))
 
 	// rotate credential
-	_, err = c.rotateCredential(ctx, bsp, oidc, rotator)
+	_, err = c.rotateCredential(ctx, bsp, &oidc, rotator)
 	require.NoError(t, err)
 	awsSecret2, err := rotators.LookupSecret(t.Context(), cl, bspNamespace, awsSecretName)
 	require.NoError(t, err)
@@ -266,3 +267,105 @@ func TestBackendSecurityController_GetBackendSecurityPolicyAuthOIDC(t *testing.T
 	require.NotNil(t, oidc)
 	require.Equal(t, "some-client-id", oidc.ClientID)
 }
+
+func TestBackendSecurityPolicyController_ReconcileAzure(t *testing.T) {
+	syncFn := internaltesting.NewSyncFnImpl[aigv1a1.AIServiceBackend]()
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+	c := NewBackendSecurityPolicyController(cl, fake2.NewClientset(), ctrl.Log, syncFn.Sync)
+	const namespace = "default"
+
+	t.Run("missing azureCredentials", func(t *testing.T) {
+		bsp := &aigv1a1.BackendSecurityPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "azure-missing", Namespace: namespace},
+			Spec:       aigv1a1.BackendSecurityPolicySpec{Type: aigv1a1.BackendSecurityPolicyTypeAzureCredentials},
+		}
+		require.NoError(t, cl.Create(t.Context(), bsp))
+		_, err := c.Reconcile(t.Context(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: bsp.Name}})
+		require.ErrorContains(t, err, "missing azureCredentials")
+	})
+
+	t.Run("rotation failure", func(t *testing.T) {
+		bsp := &aigv1a1.BackendSecurityPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "azure", Namespace: namespace},
+			Spec: aigv1a1.BackendSecurityPolicySpec{
+				Type: aigv1a1.BackendSecurityPolicyTypeAzureCredentials,
+				AzureCredentials: &aigv1a1.BackendSecurityPolicyAzureCredentials{
+					TenantID:        "some-tenant-id",
+					ClientID:        "some-client-id",
+					ClientSecretRef: &gwapiv1.SecretObjectReference{Name: "not-found"},
+				},
+			},
+		}
+		require.NoError(t, cl.Create(t.Context(), bsp))
+		// Expects rotate credentials to fail due to the missing client secret.
+		res, err := c.Reconcile(t.Context(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: bsp.Name}})
+		require.Error(t, err)
+		require.Equal(t, time.Minute, res.RequeueAfter)
+	})
+}
+
+func TestBackendSecurityController_RotateCredentialsAzure(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, azureCognitiveServicesScope, r.PostForm.Get("scope"))
+		w.Header().Add("Content-Type", "application/json")
+		b, err := json.Marshal(oauth2.Token{AccessToken: "some-azure-access-token", TokenType: "Bearer", ExpiresIn: 3600})
+		require.NoError(t, err)
+		_, err = w.Write(b)
+		require.NoError(t, err)
+	}))
+	defer tokenServer.Close()
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+	c := NewBackendSecurityPolicyController(cl, fake2.NewClientset(), ctrl.Log, internaltesting.NewSyncFnImpl[aigv1a1.AIServiceBackend]().Sync)
+	const bspNamespace = "default"
+	require.NoError(t, cl.Create(t.Context(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "azure-client-secret", Namespace: bspNamespace},
+		Data:       map[string][]byte{"client-secret": []byte("client-secret")},
+	}))
+	bsp := &aigv1a1.BackendSecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "azure", Namespace: bspNamespace},
+		Spec: aigv1a1.BackendSecurityPolicySpec{
+			Type: aigv1a1.BackendSecurityPolicyTypeAzureCredentials,
+			AzureCredentials: &aigv1a1.BackendSecurityPolicyAzureCredentials{
+				TenantID:        "some-tenant-id",
+				ClientID:        "some-client-id",
+				ClientSecretRef: &gwapiv1.SecretObjectReference{Name: "azure-client-secret"},
+			},
+		},
+	}
+	require.NoError(t, cl.Create(t.Context(), bsp))
+
+	oidc := getAzureClientCredentialsOIDC(bspNamespace, bsp.Spec.AzureCredentials)
+	oidc.Provider.TokenEndpoint = &tokenServer.URL
+	rotator := rotators.NewAzureTokenRotator(cl, oauth.NewClientCredentialsProvider(cl, oidc), ctrl.Log, bspNamespace, bsp.Name, preRotationWindow)
+
+	res, err := c.rotateCredential(t.Context(), bsp, nil, rotator)
+	require.NoError(t, err)
+	require.WithinRange(t, time.Now().Add(res), time.Now().Add(50*time.Minute), time.Now().Add(time.Hour))
+	// The rotator obtains the token by itself, so the OIDC token cache is not used.
+	require.Empty(t, c.oidcTokenCache)
+
+	secret, err := rotators.LookupSecret(t.Context(), cl, bspNamespace, rotators.GetBSPSecretName(bsp.Name))
+	require.NoError(t, err)
+	require.Equal(t, "some-azure-access-token", string(secret.Data["azureAccessToken"]))
+}
+
+func TestBackendSecurityController_GetAzureClientCredentialsOIDC(t *testing.T) {
+	creds := &aigv1a1.BackendSecurityPolicyAzureCredentials{
+		TenantID:        "some-tenant-id",
+		ClientID:        "some-client-id",
+		ClientSecretRef: &gwapiv1.SecretObjectReference{Name: "secret"},
+	}
+	oidc := getAzureClientCredentialsOIDC("ns", creds)
+	require.Equal(t, "https://login.microsoftonline.com/some-tenant-id/oauth2/v2.0/token", *oidc.Provider.TokenEndpoint)
+	require.Equal(t, "some-client-id", oidc.ClientID)
+	require.Equal(t, []string{"https://cognitiveservices.azure.com/.default"}, oidc.Scopes)
+	// The namespace of the client secret defaults to that of the policy without modifying the policy.
+	require.Equal(t, gwapiv1.Namespace("ns"), *oidc.ClientSecret.Namespace)
+	require.Nil(t, creds.ClientSecretRef.Namespace)
+
+	creds.ClientSecretRef.Namespace = ptr.To[gwapiv1.Namespace]("other")
+	oidc = getAzureClientCredentialsOIDC("ns", creds)
+	require.Equal(t, gwapiv1.Namespace("other"), *oidc.ClientSecret.Namespace)
+}
@@ -22,6 +22,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a3 "sigs.k8s.io/gateway-api/apis/v1alpha3"
 	gwapiv1b1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
@@ -41,6 +42,7 @@ func MustInitializeScheme(scheme *runtime.Scheme) {
 	utilruntime.Must(egv1a1.AddToScheme(scheme))
 	utilruntime.Must(gwapiv1.Install(scheme))
 	utilruntime.Must(gwapiv1b1.Install(scheme))
+	utilruntime.Must(gwapiv1a3.Install(scheme))
 }
 
 // Options defines the program configurable options that may be passed on the command line.
@@ -112,6 +114,22 @@ func StartControllers(ctx context.Context, config *rest.Config, logger logr.Logg
 		return fmt.Errorf("failed to create controller for BackendSecurityPolicy: %w", err)
 	}
 
+	llmPricingC := NewLLMPricingController(c, kubernetes.NewForConfigOrDie(config), logger.
+		WithName("llm-pricing"), routeC.syncAIGatewayRoute)
+	if err = ctrl.NewControllerManagedBy(mgr).
+		For(&aigv1a1.LLMPricing{}).
+		Complete(llmPricingC); err != nil {
+		return fmt.Errorf("failed to create controller for LLMPricing: %w", err)
+	}
+
+	gatewayAPIKeyC := NewGatewayAPIKeyController(c, kubernetes.NewForConfigOrDie(config), logger.
+		WithName("gateway-api-key"), routeC.syncAIGatewayRoute)
+	if err = ctrl.NewControllerManagedBy(mgr).
+		For(&aigv1a1.GatewayAPIKey{}).
+		Complete(gatewayAPIKeyC); err != nil {
+		return fmt.Errorf("failed to create controller for GatewayAPIKey: %w", err)
+	}
+
 	secretC := NewSecretController(c, kubernetes.NewForConfigOrDie(config), logger.
 		WithName("secret"), backendSecurityPolicyC.syncBackendSecurityPolicy)
 	if err = ctrl.NewControllerManagedBy(mgr).
@@ -136,6 +154,9 @@ const (
 	// k8sClientIndexBackendSecurityPolicyToReferencingAIServiceBackend is the index name that maps from a BackendSecurityPolicy
 	// to the AIServiceBackend that references it.
 	k8sClientIndexBackendSecurityPolicyToReferencingAIServiceBackend = "BackendSecurityPolicyToReferencingAIServiceBackend"
+	// k8sClientIndexLLMPricingToReferencingAIGatewayRoute is the index name that maps from a LLMPricing to the
+	// AIGatewayRoute that references it.
+	k8sClientIndexLLMPricingToReferencingAIGatewayRoute = "LLMPricingToReferencingAIGatewayRoute"
 )
 
 // ApplyIndexing applies indexing to the given indexer. This is exported for testing purposes.
@@ -145,6 +166,11 @@ func ApplyIndexing(ctx context.Context, indexer func(ctx context.Context, obj cl
 	if err != nil {
 		return fmt.Errorf("failed to index field for AIGatewayRoute: %w", err)
 	}
+	err = indexer(ctx, &aigv1a1.AIGatewayRoute{},
+		k8sClientIndexLLMPricingToReferencingAIGatewayRoute, aiGatewayRouteLLMPricingIndexFunc)
+	if err != nil {
+		return fmt.Errorf("failed to index field for AIGatewayRoute: %w", err)
+	}
 	err = indexer(ctx, &aigv1a1.AIServiceBackend{},
 		k8sClientIndexBackendSecurityPolicyToReferencingAIServiceBackend, aiServiceBackendIndexFunc)
 	if err != nil {
@@ -166,6 +192,24 @@ func aiGatewayRouteIndexFunc(o client.Object) []string {
 			key := fmt.Sprintf("%s.%s", backend.Name, aiGatewayRoute.Namespace)
 			ret = append(ret, key)
 		}
+		for _, fallback := range rule.Fallbacks {
+			key := fmt.Sprintf("%s.%s", fallback.Name, aiGatewayRoute.Namespace)
+			ret = append(ret, key)
+		}
+		if rule.Mirror != nil {
+			ret = append(ret, fmt.Sprintf("%s.%s", rule.Mirror.Name, aiGatewayRoute.Namespace))
+		}
+	}
+	return ret
+}
+
+func aiGatewayRouteLLMPricingIndexFunc(o client.Object) []string {
+	aiGatewayRoute := o.(*aigv1a1.AIGatewayRoute)
+	var ret []string
+	for _, cost := range aiGatewayRoute.Spec.LLMRequestCosts {
+		if cost.LLMPricingName != nil {
+			ret = append(ret, fmt.Sprintf("%s.%s", *cost.LLMPricingName, aiGatewayRoute.Namespace))
+		}
 	}
 	return ret
 }
@@ -193,6 +237,10 @@ func backendSecurityPolicyIndexFunc(o client.Object) []string {
 		} else if awsCreds.OIDCExchangeToken != nil {
 			key = backendSecurityPolicyKey(backendSecurityPolicy.Namespace, backendSecurityPolicy.Name)
 		}
+	case aigv1a1.BackendSecurityPolicyTypeAzureCredentials:
+		if azureCreds := backendSecurityPolicy.Spec.AzureCredentials; azureCreds != nil {
+			key = getSecretNameAndNamespace(azureCreds.ClientSecretRef, backendSecurityPolicy.Namespace)
+		}
 	}
 	return []string{key}
 }
@@ -68,6 +68,42 @@ func Test_aiGatewayRouteIndexFunc(t *testing.T) {
 	require.Equal(t, aiGatewayRoute.Name, aiGatewayRoutes.Items[0].Name)
 }
 
+func Test_aiGatewayRouteLLMPricingIndexFunc(t *testing.T) {
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithIndex(&aigv1a1.AIGatewayRoute{}, k8sClientIndexLLMPricingToReferencingAIGatewayRoute, aiGatewayRouteLLMPricingIndexFunc).
+		Build()
+
+	aiGatewayRoute := &aigv1a1.AIGatewayRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "myroute", Namespace: "default"},
+		Spec: aigv1a1.AIGatewayRouteSpec{
+			TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{
+				{LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{Name: "mytarget"}},
+			},
+			Rules: []aigv1a1.AIGatewayRouteRule{
+				{BackendRefs: []aigv1a1.AIGatewayRouteRuleBackendRef{{Name: "backend1", Weight: 1}}},
+			},
+			LLMRequestCosts: []aigv1a1.LLMRequestCost{
+				{MetadataKey: "total", Type: aigv1a1.LLMRequestCostTypeTotalToken},
+				{MetadataKey: "usd", Type: aigv1a1.LLMRequestCostTypePrice, LLMPricingName: ptr.To("pricing")},
+			},
+		},
+	}
+	require.NoError(t, c.Create(t.Context(), aiGatewayRoute))
+
+	var aiGatewayRoutes aigv1a1.AIGatewayRouteList
+	err := c.List(t.Context(), &aiGatewayRoutes,
+		client.MatchingFields{k8sClientIndexLLMPricingToReferencingAIGatewayRoute: "pricing.default"})
+	require.NoError(t, err)
+	require.Len(t, aiGatewayRoutes.Items, 1)
+	require.Equal(t, aiGatewayRoute.Name, aiGatewayRoutes.Items[0].Name)
+
+	err = c.List(t.Context(), &aiGatewayRoutes,
+		client.MatchingFields{k8sClientIndexLLMPricingToReferencingAIGatewayRoute: "pricing.other"})
+	require.NoError(t, err)
+	require.Empty(t, aiGatewayRoutes.Items)
+}
+
 func Test_backendSecurityPolicyIndexFunc(t *testing.T) {
 	for _, bsp := range []struct {
 		name                  string
@@ -135,6 +171,21 @@ func Test_backendSecurityPolicyIndexFunc(t *testing.T) {
 			},
 			expKey: "some-secret4.ns",
 		},
+		{
+			name: "azure credentials without namespace",
+			backendSecurityPolicy: &aigv1a1.BackendSecurityPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-backend-security-policy-5", Namespace: "ns"},
+				Spec: aigv1a1.BackendSecurityPolicySpec{
+					Type: aigv1a1.BackendSecurityPolicyTypeAzureCredentials,
+					AzureCredentials: &aigv1a1.BackendSecurityPolicyAzureCredentials{
+						TenantID:        "some-tenant-id",
+						ClientID:        "some-client-id",
+						ClientSecretRef: &gwapiv1.SecretObjectReference{Name: "some-secret5"},
+					},
+				},
+			},
+			expKey: "some-secret5.ns",
+		},
 	} {
 		t.Run(bsp.name, func(t *testing.T) {
 			c := fake.NewClientBuilder().
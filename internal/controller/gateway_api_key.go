@@ -0,0 +1,76 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
+)
+
+// GatewayAPIKeyController implements [reconcile.TypedReconciler] for [aigv1a1.GatewayAPIKey].
+//
+// Exported for testing purposes.
+type GatewayAPIKeyController struct {
+	client    client.Client
+	kube      kubernetes.Interface
+	logger    logr.Logger
+	syncRoute syncAIGatewayRouteFn
+}
+
+// NewGatewayAPIKeyController creates a new [reconcile.TypedReconciler] for [aigv1a1.GatewayAPIKey].
+func NewGatewayAPIKeyController(client client.Client, kube kubernetes.Interface, logger logr.Logger, syncRoute syncAIGatewayRouteFn) *GatewayAPIKeyController {
+	return &GatewayAPIKeyController{
+		client:    client,
+		kube:      kube,
+		logger:    logger,
+		syncRoute: syncRoute,
+	}
+}
+
+// Reconcile implements the [reconcile.TypedReconciler] for [aigv1a1.GatewayAPIKey].
+//
+// The keys are compiled into the filter config of the AIGatewayRoutes selecting them, so this syncs the
+// AIGatewayRoutes with GatewayAPIKeys in the same namespace. The labels are not checked against their selectors
+// since the labels of the deleted GatewayAPIKey or the ones before the update are not known.
+func (c *GatewayAPIKeyController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	c.logger.Info("Reconciling GatewayAPIKey", "namespace", req.Namespace, "name", req.Name)
+	return ctrl.Result{}, c.syncGatewayAPIKey(ctx, req.Namespace, req.Name)
+}
+
+// syncGatewayAPIKey syncs the AIGatewayRoutes possibly selecting the GatewayAPIKey of the given namespace and name.
+func (c *GatewayAPIKeyController) syncGatewayAPIKey(ctx context.Context, namespace, name string) error {
+	var aiGatewayRoutes aigv1a1.AIGatewayRouteList
+	err := c.client.List(ctx, &aiGatewayRoutes, client.InNamespace(namespace))
+	if err != nil {
+		return fmt.Errorf("failed to list AIGatewayRouteList: %w", err)
+	}
+	var errs []error
+	for _, aiGatewayRoute := range aiGatewayRoutes.Items {
+		if aiGatewayRoute.Spec.GatewayAPIKeys == nil {
+			continue
+		}
+		c.logger.Info("syncing AIGatewayRoute",
+			"namespace", aiGatewayRoute.Namespace, "name", aiGatewayRoute.Name,
+			"gateway_api_key", name, "gateway_api_key_namespace", namespace,
+		)
+		if err := c.syncRoute(ctx, &aiGatewayRoute); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", aiGatewayRoute.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fake2 "k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+func TestGatewayAPIKeyController_Reconcile(t *testing.T) {
+	fakeClient := requireNewFakeClientWithIndexes(t)
+	syncFn := internaltesting.NewSyncFnImpl[aigv1a1.AIGatewayRoute]()
+	c := NewGatewayAPIKeyController(fakeClient, fake2.NewClientset(), ctrl.Log, syncFn.Sync)
+	newRoute := func(name, namespace string, keys *aigv1a1.AIGatewayRouteGatewayAPIKeys) *aigv1a1.AIGatewayRoute {
+		return &aigv1a1.AIGatewayRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: aigv1a1.AIGatewayRouteSpec{
+				TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{
+					{
+						LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{
+							Name: "gtw", Kind: "Gateway", Group: "gateway.networking.k8s.io",
+						},
+					},
+				},
+				Rules: []aigv1a1.AIGatewayRouteRule{
+					{
+						Matches:     []aigv1a1.AIGatewayRouteRuleMatch{{}},
+						BackendRefs: []aigv1a1.AIGatewayRouteRuleBackendRef{{Name: "mybackend"}},
+					},
+				},
+				GatewayAPIKeys: keys,
+			},
+		}
+	}
+	authenticating := newRoute("myroute", "default", &aigv1a1.AIGatewayRouteGatewayAPIKeys{})
+	for _, route := range []*aigv1a1.AIGatewayRoute{
+		authenticating,
+		newRoute("myroute2", "default", nil),
+		newRoute("myroute3", "other", &aigv1a1.AIGatewayRouteGatewayAPIKeys{}),
+	} {
+		require.NoError(t, fakeClient.Create(t.Context(), route))
+	}
+
+	err := fakeClient.Create(t.Context(), &aigv1a1.GatewayAPIKey{
+		ObjectMeta: metav1.ObjectMeta{Name: "mykey", Namespace: "default"},
+		Spec:       aigv1a1.GatewayAPIKeySpec{KeyHash: strings.Repeat("a", 64), Owner: "alice"},
+	})
+	require.NoError(t, err)
+	_, err = c.Reconcile(t.Context(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "mykey"}})
+	require.NoError(t, err)
+	// Only the AIGatewayRoute with GatewayAPIKeys in the same namespace is synced.
+	require.Equal(t, []*aigv1a1.AIGatewayRoute{authenticating}, syncFn.GetItems())
+
+	// Test the case where the GatewayAPIKey is being deleted.
+	err = fakeClient.Delete(t.Context(), &aigv1a1.GatewayAPIKey{ObjectMeta: metav1.ObjectMeta{Name: "mykey", Namespace: "default"}})
+	require.NoError(t, err)
+	_, err = c.Reconcile(t.Context(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "mykey"}})
+	require.NoError(t, err)
+}
@@ -0,0 +1,74 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
+)
+
+// LLMPricingController implements [reconcile.TypedReconciler] for [aigv1a1.LLMPricing].
+//
+// Exported for testing purposes.
+type LLMPricingController struct {
+	client    client.Client
+	kube      kubernetes.Interface
+	logger    logr.Logger
+	syncRoute syncAIGatewayRouteFn
+}
+
+// NewLLMPricingController creates a new [reconcile.TypedReconciler] for [aigv1a1.LLMPricing].
+func NewLLMPricingController(client client.Client, kube kubernetes.Interface, logger logr.Logger, syncRoute syncAIGatewayRouteFn) *LLMPricingController {
+	return &LLMPricingController{
+		client:    client,
+		kube:      kube,
+		logger:    logger,
+		syncRoute: syncRoute,
+	}
+}
+
+// Reconcile implements the [reconcile.TypedReconciler] for [aigv1a1.LLMPricing].
+//
+// The prices are compiled into the filter config of the AIGatewayRoutes referencing the LLMPricing,
+// so this syncs them. This is also the case when the LLMPricing is deleted, which results in the
+// error on the AIGatewayRoutes still referencing it.
+func (c *LLMPricingController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	c.logger.Info("Reconciling LLMPricing", "namespace", req.Namespace, "name", req.Name)
+	return ctrl.Result{}, c.syncLLMPricing(ctx, req.Namespace, req.Name)
+}
+
+// syncLLMPricing syncs the AIGatewayRoutes referencing the LLMPricing of the given namespace and name.
+func (c *LLMPricingController) syncLLMPricing(ctx context.Context, namespace, name string) error {
+	key := fmt.Sprintf("%s.%s", name, namespace)
+	var aiGatewayRoutes aigv1a1.AIGatewayRouteList
+	err := c.client.List(ctx, &aiGatewayRoutes, client.MatchingFields{k8sClientIndexLLMPricingToReferencingAIGatewayRoute: key})
+	if err != nil {
+		return fmt.Errorf("failed to list AIGatewayRouteList: %w", err)
+	}
+	var errs []error
+	for _, aiGatewayRoute := range aiGatewayRoutes.Items {
+		c.logger.Info("syncing AIGatewayRoute",
+			"namespace", aiGatewayRoute.Namespace, "name", aiGatewayRoute.Name,
+			"referenced_llm_pricing", name, "referenced_llm_pricing_namespace", namespace,
+		)
+		if err := c.syncRoute(ctx, &aiGatewayRoute); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", aiGatewayRoute.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fake2 "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	aigv1a1 "github.com/envoyproxy/ai-gateway/api/v1alpha1"
+	internaltesting "github.com/envoyproxy/ai-gateway/internal/testing"
+)
+
+func TestLLMPricingController_Reconcile(t *testing.T) {
+	fakeClient := requireNewFakeClientWithIndexes(t)
+	syncFn := internaltesting.NewSyncFnImpl[aigv1a1.AIGatewayRoute]()
+	c := NewLLMPricingController(fakeClient, fake2.NewClientset(), ctrl.Log, syncFn.Sync)
+	newRoute := func(name string, costs ...aigv1a1.LLMRequestCost) *aigv1a1.AIGatewayRoute {
+		return &aigv1a1.AIGatewayRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: aigv1a1.AIGatewayRouteSpec{
+				TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{
+					{
+						LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{
+							Name: "gtw", Kind: "Gateway", Group: "gateway.networking.k8s.io",
+						},
+					},
+				},
+				Rules: []aigv1a1.AIGatewayRouteRule{
+					{
+						Matches:     []aigv1a1.AIGatewayRouteRuleMatch{{}},
+						BackendRefs: []aigv1a1.AIGatewayRouteRuleBackendRef{{Name: "mybackend"}},
+					},
+				},
+				LLMRequestCosts: costs,
+			},
+		}
+	}
+	referencing := newRoute("myroute", aigv1a1.LLMRequestCost{
+		MetadataKey: "usd", Type: aigv1a1.LLMRequestCostTypePrice, LLMPricingName: ptr.To("mypricing"),
+	})
+	for _, route := range []*aigv1a1.AIGatewayRoute{
+		referencing,
+		newRoute("myroute2", aigv1a1.LLMRequestCost{MetadataKey: "total", Type: aigv1a1.LLMRequestCostTypeTotalToken}),
+	} {
+		require.NoError(t, fakeClient.Create(t.Context(), route))
+	}
+
+	err := fakeClient.Create(t.Context(), &aigv1a1.LLMPricing{
+		ObjectMeta: metav1.ObjectMeta{Name: "mypricing", Namespace: "default"},
+		Spec: aigv1a1.LLMPricingSpec{Models: []aigv1a1.LLMModelPrice{
+			{ModelName: "gpt-4o", InputTokenPrice: "2.5", OutputTokenPrice: "10"},
+		}},
+	})
+	require.NoError(t, err)
+	_, err = c.Reconcile(t.Context(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "mypricing"}})
+	require.NoError(t, err)
+	// Only the AIGatewayRoute referencing the LLMPricing is synced.
+	require.Equal(t, []*aigv1a1.AIGatewayRoute{referencing}, syncFn.GetItems())
+
+	// Test the case where the LLMPricing is being deleted.
+	err = fakeClient.Delete(t.Context(), &aigv1a1.LLMPricing{ObjectMeta: metav1.ObjectMeta{Name: "mypricing", Namespace: "default"}})
+	require.NoError(t, err)
+	_, err = c.Reconcile(t.Context(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "mypricing"}})
+	require.NoError(t, err)
+}
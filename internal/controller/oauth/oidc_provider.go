@@ -25,7 +25,7 @@ type OIDCProvider struct {
 // NewOIDCProvider creates a new OIDC-aware provider.
 func NewOIDCProvider(client client.Client, oidcConfig egv1a1.OIDC) *OIDCProvider {
 	return &OIDCProvider{
-		tokenProvider: newClientCredentialsProvider(client, &oidcConfig),
+		tokenProvider: NewClientCredentialsProvider(client, &oidcConfig),
 		oidcConfig:    &oidcConfig,
 	}
 }
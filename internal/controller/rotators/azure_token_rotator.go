@@ -0,0 +1,129 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package rotators
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/envoyproxy/ai-gateway/internal/controller/oauth"
+)
+
+// azureAccessTokenKey is the key used to store the Azure access token in Kubernetes secrets.
+const azureAccessTokenKey = "azureAccessToken"
+
+// AzureTokenRotator implements the Rotator interface for the Microsoft Entra ID access token.
+// It obtains the access token of the application with the client credentials flow and stores
+// it in the secret of the backend security policy.
+type AzureTokenRotator struct {
+	// client is used for Kubernetes API operations.
+	client client.Client
+	// logger is used for structured logging.
+	logger logr.Logger
+	// tokenProvider obtains the access token from Microsoft Entra ID.
+	tokenProvider oauth.TokenProvider
+	// backendSecurityPolicyName provides name of backend security policy.
+	backendSecurityPolicyName string
+	// backendSecurityPolicyNamespace provides namespace of backend security policy.
+	backendSecurityPolicyNamespace string
+	// preRotationWindow specifies how long before expiry to rotate.
+	preRotationWindow time.Duration
+}
+
+// NewAzureTokenRotator creates a new Azure token rotator with the specified configuration.
+func NewAzureTokenRotator(
+	client client.Client,
+	tokenProvider oauth.TokenProvider,
+	logger logr.Logger,
+	backendSecurityPolicyNamespace string,
+	backendSecurityPolicyName string,
+	preRotationWindow time.Duration,
+) *AzureTokenRotator {
+	return &AzureTokenRotator{
+		client:                         client,
+		logger:                         logger.WithName("azure-token-rotator"),
+		tokenProvider:                  tokenProvider,
+		backendSecurityPolicyNamespace: backendSecurityPolicyNamespace,
+		backendSecurityPolicyName:      backendSecurityPolicyName,
+		preRotationWindow:              preRotationWindow,
+	}
+}
+
+// IsExpired checks if the preRotation time is before the current time.
+func (r *AzureTokenRotator) IsExpired(preRotationExpirationTime time.Time) bool {
+	return IsBufferedTimeExpired(0, preRotationExpirationTime)
+}
+
+// GetPreRotationTime gets the expiration time minus the preRotation interval or return zero value for time.
+func (r *AzureTokenRotator) GetPreRotationTime(ctx context.Context) (time.Time, error) {
+	secret, err := LookupSecret(ctx, r.client, r.backendSecurityPolicyNamespace, GetBSPSecretName(r.backendSecurityPolicyName))
+	if err != nil {
+		// return zero value for time if secret has not been created.
+		if apierrors.IsNotFound(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	expirationTime, err := GetExpirationSecretAnnotation(secret)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return expirationTime.Add(-r.preRotationWindow), nil
+}
+
+// Rotate obtains a new access token from Microsoft Entra ID and upserts it to the k8s secret store.
+// The given token is ignored since the rotator obtains the access token by itself.
+//
+// This implements [Rotator.Rotate].
+func (r *AzureTokenRotator) Rotate(ctx context.Context, _ string) error {
+	bspNamespace := r.backendSecurityPolicyNamespace
+	bspName := r.backendSecurityPolicyName
+	secretName := GetBSPSecretName(bspName)
+
+	r.logger.Info("rotating azure access token secret", "namespace", bspNamespace, "name", bspName)
+	token, err := r.tokenProvider.FetchToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch azure access token: %w", err)
+	}
+
+	secret, err := LookupSecret(ctx, r.client, bspNamespace, secretName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.logger.Info("creating a new azure access token secret", "namespace", bspNamespace, "name", bspName)
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secretName,
+					Namespace: bspNamespace,
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: make(map[string][]byte),
+			}
+			populateSecretWithAzureAccessToken(secret, token.AccessToken, token.Expiry)
+			return r.client.Create(ctx, secret)
+		}
+		r.logger.Error(err, "failed to lookup azure access token secret", "namespace", bspNamespace, "name", bspName)
+		return err
+	}
+	r.logger.Info("updating existing azure access token secret", "namespace", bspNamespace, "name", bspName)
+	populateSecretWithAzureAccessToken(secret, token.AccessToken, token.Expiry)
+	return r.client.Update(ctx, secret)
+}
+
+// populateSecretWithAzureAccessToken populates secret with the azure access token and its expiration time.
+func populateSecretWithAzureAccessToken(secret *corev1.Secret, accessToken string, expiration time.Time) {
+	updateExpirationSecretAnnotation(secret, expiration)
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[azureAccessTokenKey] = []byte(accessToken)
+}
@@ -0,0 +1,92 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package rotators
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// mockTokenProvider implements the oauth.TokenProvider interface for testing.
+type mockTokenProvider struct {
+	token *oauth2.Token
+	err   error
+}
+
+func (m *mockTokenProvider) FetchToken(context.Context) (*oauth2.Token, error) {
+	return m.token, m.err
+}
+
+func TestAzureTokenRotator_Rotate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Secret{})
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	t.Run("create when the secret does not exist", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+		provider := &mockTokenProvider{token: &oauth2.Token{AccessToken: "new-token", Expiry: expiry}}
+		r := NewAzureTokenRotator(cl, provider, logr.Discard(), policyNameSpace, policyName, time.Minute)
+		require.NoError(t, r.Rotate(t.Context(), ""))
+
+		secret, err := LookupSecret(t.Context(), cl, policyNameSpace, GetBSPSecretName(policyName))
+		require.NoError(t, err)
+		require.Equal(t, "new-token", string(secret.Data[azureAccessTokenKey]))
+		preRotationTime, err := r.GetPreRotationTime(t.Context())
+		require.NoError(t, err)
+		require.True(t, expiry.Add(-time.Minute).Equal(preRotationTime))
+		require.False(t, r.IsExpired(preRotationTime))
+	})
+
+	t.Run("update when the secret exists", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+		require.NoError(t, cl.Create(t.Context(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        GetBSPSecretName(policyName),
+				Namespace:   policyNameSpace,
+				Annotations: map[string]string{ExpirationTimeAnnotationKey: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+			},
+			Data: map[string][]byte{azureAccessTokenKey: []byte("old-token")},
+		}))
+		r := NewAzureTokenRotator(cl, &mockTokenProvider{token: &oauth2.Token{AccessToken: "new-token", Expiry: expiry}},
+			logr.Discard(), policyNameSpace, policyName, time.Minute)
+		preRotationTime, err := r.GetPreRotationTime(t.Context())
+		require.NoError(t, err)
+		require.True(t, r.IsExpired(preRotationTime))
+
+		require.NoError(t, r.Rotate(t.Context(), ""))
+		secret, err := LookupSecret(t.Context(), cl, policyNameSpace, GetBSPSecretName(policyName))
+		require.NoError(t, err)
+		require.Equal(t, "new-token", string(secret.Data[azureAccessTokenKey]))
+		expirationTime, err := GetExpirationSecretAnnotation(secret)
+		require.NoError(t, err)
+		require.True(t, expiry.Equal(expirationTime))
+	})
+
+	t.Run("token provider failure", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := NewAzureTokenRotator(cl, &mockTokenProvider{err: fmt.Errorf("invalid client secret")},
+			logr.Discard(), policyNameSpace, policyName, time.Minute)
+		require.ErrorContains(t, r.Rotate(t.Context(), ""), "invalid client secret")
+	})
+
+	t.Run("pre rotation time without the secret", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := NewAzureTokenRotator(cl, &mockTokenProvider{}, logr.Discard(), policyNameSpace, policyName, time.Minute)
+		preRotationTime, err := r.GetPreRotationTime(t.Context())
+		require.NoError(t, err)
+		require.True(t, preRotationTime.IsZero())
+	})
+}
@@ -27,6 +27,7 @@ type Rotator interface {
 	// GetPreRotationTime gets the time when the credentials need to be renewed.
 	GetPreRotationTime(ctx context.Context) (time.Time, error)
 	// Rotate will update the credential secret file with new credentials.
+	// The token is empty for the rotators that obtain the credentials by themselves.
 	Rotate(ctx context.Context, token string) error
 }
 
@@ -20,6 +20,8 @@ import (
 // apiKeyHandler implements [Handler] for api key authz.
 type apiKeyHandler struct {
 	apiKey string
+	// headerName is the header to set the raw api key to. When empty, "Authorization: Bearer <key>" is used.
+	headerName string
 }
 
 func newAPIKeyHandler(auth *filterapi.APIKeyAuth) (Handler, error) {
@@ -27,16 +29,22 @@ func newAPIKeyHandler(auth *filterapi.APIKeyAuth) (Handler, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read api key file: %w", err)
 	}
-	return &apiKeyHandler{apiKey: strings.TrimSpace(string(secret))}, nil
+	return &apiKeyHandler{apiKey: strings.TrimSpace(string(secret)), headerName: auth.HeaderName}, nil
 }
 
 // Do implements [Handler.Do].
 //
-// Extracts the api key from the local file and set it as an authorization header.
+// Extracts the api key from the local file and set it as an authorization header,
+// or as the configured header as-is if the header name is specified. The request headers are keyed by the lowercase
+// names as received from Envoy, so the client's header of the same name is replaced.
 func (a *apiKeyHandler) Do(_ context.Context, requestHeaders map[string]string, headerMut *extprocv3.HeaderMutation, _ *extprocv3.BodyMutation) error {
-	requestHeaders["Authorization"] = fmt.Sprintf("Bearer %s", a.apiKey)
+	key, value := "Authorization", fmt.Sprintf("Bearer %s", a.apiKey)
+	if a.headerName != "" {
+		key, value = a.headerName, a.apiKey
+	}
+	requestHeaders[strings.ToLower(key)] = value
 	headerMut.SetHeaders = append(headerMut.SetHeaders, &corev3.HeaderValueOption{
-		Header: &corev3.HeaderValue{Key: "Authorization", RawValue: []byte(requestHeaders["Authorization"])},
+		Header: &corev3.HeaderValue{Key: key, RawValue: []byte(value)},
 	})
 
 	return nil
@@ -70,7 +70,7 @@ func TestApiKeyHandler_Do(t *testing.T) {
 	err = handler.Do(t.Context(), requestHeaders, headerMut, bodyMut)
 	require.NoError(t, err)
 
-	bearerToken, ok := requestHeaders["Authorization"]
+	bearerToken, ok := requestHeaders["authorization"]
 	require.True(t, ok)
 	require.Equal(t, "Bearer test", bearerToken)
 
@@ -78,3 +78,21 @@ func TestApiKeyHandler_Do(t *testing.T) {
 	require.Equal(t, "Authorization", headerMut.SetHeaders[1].Header.Key)
 	require.Equal(t, []byte("Bearer test"), headerMut.SetHeaders[1].Header.GetRawValue())
 }
+
+func TestApiKeyHandler_Do_HeaderName(t *testing.T) {
+	apiKeyFile := t.TempDir() + "/test"
+	require.NoError(t, os.WriteFile(apiKeyFile, []byte("test"), 0o600))
+
+	handler, err := newAPIKeyHandler(&filterapi.APIKeyAuth{Filename: apiKeyFile, HeaderName: "X-Api-Key"})
+	require.NoError(t, err)
+
+	requestHeaders := map[string]string{":method": "POST", "x-api-key": "client"}
+	headerMut := &extprocv3.HeaderMutation{}
+	require.NoError(t, handler.Do(t.Context(), requestHeaders, headerMut, nil))
+
+	// The client's header is replaced in the lowercase key while the mutation keeps the configured name.
+	require.Equal(t, map[string]string{":method": "POST", "x-api-key": "test"}, requestHeaders)
+	require.Len(t, headerMut.SetHeaders, 1)
+	require.Equal(t, "X-Api-Key", headerMut.SetHeaders[0].Header.Key)
+	require.Equal(t, []byte("test"), headerMut.SetHeaders[0].Header.GetRawValue())
+}
@@ -27,6 +27,10 @@ aws_secret_access_key = test
 	err = os.WriteFile(apiKeyFile, []byte("TEST"), 0o600)
 	require.NoError(t, err)
 
+	azureTokenFile := t.TempDir() + "/azureAccessToken"
+	err = os.WriteFile(azureTokenFile, []byte("TEST"), 0o600)
+	require.NoError(t, err)
+
 	for _, tt := range []struct {
 		name   string
 		config *filterapi.BackendAuth
@@ -43,6 +47,12 @@ aws_secret_access_key = test
 				APIKey: &filterapi.APIKeyAuth{Filename: apiKeyFile},
 			},
 		},
+		{
+			name: "AzureAuth",
+			config: &filterapi.BackendAuth{
+				AzureAuth: &filterapi.AzureAuth{Filename: azureTokenFile},
+			},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := NewHandler(t.Context(), tt.config)
@@ -0,0 +1,77 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package backendauth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+)
+
+// azureHandler implements [Handler] for the Microsoft Entra ID access token of Azure OpenAI.
+//
+// The token file is refreshed by the controller before the token expires, so this re-reads the file
+// whenever its modification time changes.
+type azureHandler struct {
+	filename string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+func newAzureHandler(auth *filterapi.AzureAuth) (Handler, error) {
+	h := &azureHandler{filename: auth.Filename}
+	if _, err := h.accessToken(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// accessToken returns the access token in the file, reading it again if the file has been modified.
+func (a *azureHandler) accessToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	info, err := os.Stat(a.filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat azure access token file: %w", err)
+	}
+	if a.token != "" && info.ModTime().Equal(a.modTime) {
+		return a.token, nil
+	}
+	token, err := os.ReadFile(a.filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read azure access token file: %w", err)
+	}
+	a.token, a.modTime = strings.TrimSpace(string(token)), info.ModTime()
+	return a.token, nil
+}
+
+// Do implements [Handler.Do].
+//
+// Extracts the azure access token from the local file and set it as an authorization header.
+// The request headers are keyed by the lowercase names as received from Envoy, so the client's authorization
+// header is replaced.
+func (a *azureHandler) Do(_ context.Context, requestHeaders map[string]string, headerMut *extprocv3.HeaderMutation, _ *extprocv3.BodyMutation) error {
+	token, err := a.accessToken()
+	if err != nil {
+		return err
+	}
+	value := fmt.Sprintf("Bearer %s", token)
+	requestHeaders["authorization"] = value
+	headerMut.SetHeaders = append(headerMut.SetHeaders, &corev3.HeaderValueOption{
+		Header: &corev3.HeaderValue{Key: "Authorization", RawValue: []byte(value)},
+	})
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package backendauth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+)
+
+func TestNewAzureHandler(t *testing.T) {
+	_, err := newAzureHandler(&filterapi.AzureAuth{Filename: filepath.Join(t.TempDir(), "not-found")})
+	require.ErrorContains(t, err, "failed to stat azure access token file")
+}
+
+func TestAzureHandler_Do(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "azureAccessToken")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("old-token\n"), 0o600))
+
+	handler, err := newAzureHandler(&filterapi.AzureAuth{Filename: tokenFile})
+	require.NoError(t, err)
+
+	requestHeaders := map[string]string{":method": "POST", "authorization": "Bearer client"}
+	headerMut := &extprocv3.HeaderMutation{}
+	require.NoError(t, handler.Do(t.Context(), requestHeaders, headerMut, nil))
+	// The client's authorization header is replaced.
+	require.Equal(t, map[string]string{":method": "POST", "authorization": "Bearer old-token"}, requestHeaders)
+	require.Len(t, headerMut.SetHeaders, 1)
+	require.Equal(t, "Authorization", headerMut.SetHeaders[0].Header.Key)
+	require.Equal(t, []byte("Bearer old-token"), headerMut.SetHeaders[0].Header.GetRawValue())
+
+	// The refreshed token should be picked up once the file is modified.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("new-token"), 0o600))
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(tokenFile, modTime, modTime))
+
+	requestHeaders = map[string]string{":method": "POST"}
+	headerMut = &extprocv3.HeaderMutation{}
+	require.NoError(t, handler.Do(t.Context(), requestHeaders, headerMut, nil))
+	require.Equal(t, "Bearer new-token", requestHeaders["authorization"])
+	require.Equal(t, []byte("Bearer new-token"), headerMut.SetHeaders[0].Header.GetRawValue())
+
+	// The error is returned when the file is removed.
+	require.NoError(t, os.Remove(tokenFile))
+	require.Error(t, handler.Do(t.Context(), map[string]string{}, &extprocv3.HeaderMutation{}, nil))
+}
@@ -6,25 +6,24 @@
 package extproc
 
 import (
-	"bytes"
-	"compress/gzip"
+	"cmp"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"strconv"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
-	"google.golang.org/protobuf/types/known/structpb"
+	"github.com/tidwall/sjson"
 
 	"github.com/envoyproxy/ai-gateway/filterapi"
 	"github.com/envoyproxy/ai-gateway/filterapi/x"
 	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
 	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
-	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
 )
 
 // NewChatCompletionProcessor implements [Processor] for the /chat/completions endpoint.
@@ -32,48 +31,55 @@ func NewChatCompletionProcessor(config *processorConfig, requestHeaders map[stri
 	if config.schema.Name != filterapi.APISchemaOpenAI {
 		return nil, fmt.Errorf("unsupported API schema: %s", config.schema.Name)
 	}
-	return &chatCompletionProcessor{
+	return &chatCompletionProcessor{llmProcessor: llmProcessor{
 		config:         config,
 		requestHeaders: requestHeaders,
 		logger:         logger,
-	}, nil
+	}}, nil
 }
 
 // chatCompletionProcessor handles the processing of the request and response messages for a single stream.
 type chatCompletionProcessor struct {
-	logger           *slog.Logger
-	config           *processorConfig
-	requestHeaders   map[string]string
-	responseHeaders  map[string]string
-	responseEncoding string
-	translator       translator.OpenAIChatCompletionTranslator
-	// cost is the cost of the request that is accumulated during the processing of the response.
-	costs translator.LLMTokenUsage
+	llmProcessor
+	translator translator.OpenAIChatCompletionTranslator
+	// requestBody is kept to translate the request for the fallbacks.
+	requestBody *openai.ChatCompletionRequest
 }
 
-// selectTranslator selects the translator based on the output schema.
-func (c *chatCompletionProcessor) selectTranslator(out filterapi.VersionedAPISchema) error {
+// selectTranslator selects the translator based on the output schema and the model name override of the backend.
+func (c *chatCompletionProcessor) selectTranslator(out filterapi.VersionedAPISchema, modelNameOverride string) (err error) {
 	if c.translator != nil { // Prevents re-selection and allows translator injection in tests.
 		return nil
 	}
-	// TODO: currently, we ignore the LLMAPISchema."Version" field.
+	c.translator, err = newChatCompletionTranslator(out, modelNameOverride, c.config.forceStreamUsage)
+	return
+}
+
+// newChatCompletionTranslator creates the translator for the output schema.
+//
+// forceStreamUsage only applies to the OpenAI and AzureOpenAI schemas as the others always return the usage.
+func newChatCompletionTranslator(out filterapi.VersionedAPISchema, modelNameOverride string, forceStreamUsage bool) (translator.OpenAIChatCompletionTranslator, error) {
+	// TODO: currently, we ignore the LLMAPISchema."Version" field except for Anthropic, AzureOpenAI and GCPVertexAI.
 	switch out.Name {
 	case filterapi.APISchemaOpenAI:
-		c.translator = translator.NewChatCompletionOpenAIToOpenAITranslator()
+		return translator.NewChatCompletionOpenAIToOpenAITranslator(modelNameOverride, forceStreamUsage), nil
 	case filterapi.APISchemaAWSBedrock:
-		c.translator = translator.NewChatCompletionOpenAIToAWSBedrockTranslator()
+		return translator.NewChatCompletionOpenAIToAWSBedrockTranslator(modelNameOverride), nil
+	case filterapi.APISchemaAnthropic:
+		return translator.NewChatCompletionOpenAIToAnthropicTranslator(out.Version, modelNameOverride), nil
+	case filterapi.APISchemaAzureOpenAI:
+		return translator.NewChatCompletionOpenAIToAzureOpenAITranslator(out.Version, out.Deployments, modelNameOverride, forceStreamUsage), nil
+	case filterapi.APISchemaGCPVertexAI:
+		return translator.NewChatCompletionOpenAIToGCPVertexAITranslator(out.Version, modelNameOverride), nil
 	default:
-		return fmt.Errorf("unsupported API schema: backend=%s", out)
+		return nil, fmt.Errorf("unsupported API schema: backend={%s %s}", out.Name, out.Version)
 	}
-	return nil
 }
 
 // ProcessRequestHeaders implements [Processor.ProcessRequestHeaders].
 func (c *chatCompletionProcessor) ProcessRequestHeaders(_ context.Context, _ *corev3.HeaderMap) (res *extprocv3.ProcessingResponse, err error) {
 	// The request headers have already been at the time the processor was created
-	return &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_RequestHeaders{
-		RequestHeaders: &extprocv3.HeadersResponse{},
-	}}, nil
+	return authenticateAPIKey(c.config, c.requestHeaders, c.logger)
 }
 
 // ProcessRequestBody implements [Processor.ProcessRequestBody].
@@ -84,47 +90,61 @@ func (c *chatCompletionProcessor) ProcessRequestBody(ctx context.Context, rawBod
 	}
 	c.logger.Info("Processing request", "path", c.requestHeaders[":path"], "model", model)
 
-	c.requestHeaders[c.config.modelNameHeaderKey] = model
-	b, err := c.config.router.Calculate(c.requestHeaders)
-	if err != nil {
-		if errors.Is(err, x.ErrNoMatchingRule) {
-			return &extprocv3.ProcessingResponse{
-				Response: &extprocv3.ProcessingResponse_ImmediateResponse{
-					ImmediateResponse: &extprocv3.ImmediateResponse{
-						Status: &typev3.HttpStatus{Code: typev3.StatusCode_NotFound},
-						Body:   []byte(err.Error()),
-					},
-				},
-			}, nil
+	limits := c.config.requestLimits
+	var estimatedInputTokens int
+	if c.config.estimateInputTokens || (limits != nil && limits.MaxEstimatedInputTokens > 0) || len(c.config.requestCosts) > 0 {
+		estimatedInputTokens = tokenizer.EstimateChatCompletionInputTokens(c.tokenizer(), body)
+	}
+	c.initUsageEstimator(body.Stream, chatCompletionOutput, func(tokenizer.Tokenizer) int { return estimatedInputTokens })
+	if c.config.estimateInputTokens {
+		c.requestHeaders[router.EstimatedInputTokensKey] = strconv.Itoa(estimatedInputTokens)
+	}
+	var limited []string
+	if limits != nil {
+		var violation *openai.ErrorType
+		limited, violation = applyRequestLimits(limits, body, estimatedInputTokens)
+		if violation != nil {
+			c.logger.Info("Rejecting request violating the limits", "param", *violation.Param)
+			return openAIErrorResponse(typev3.StatusCode_BadRequest, violation)
+		}
+		// The limited request is sent to the backend as well as the fallbacks and the mirror in place of the original.
+		// Only the limited parameters are patched to keep the fields unknown to the parsed request.
+		for _, param := range limited {
+			if rawBody.Body, err = sjson.SetBytes(rawBody.Body, param, limits.MaxTokens); err != nil {
+				return nil, fmt.Errorf("failed to set %s in request body: %w", param, err)
+			}
 		}
-
-		return nil, fmt.Errorf("failed to calculate route: %w", err)
 	}
-	c.logger.Info("Selected backend", "backend", b.Name)
+	b, immediateResp, err := selectBackend(c.config, c.requestHeaders, chatCompletionRouterRequest(model, body))
+	if err != nil || immediateResp != nil {
+		return immediateResp, err
+	}
+	c.backendSelected(b, rawBody.Body, body.Stream)
+	c.requestBody, c.costs.user = body, body.User
+	c.costs.maxTokens = maxTokensOf(cmp.Or(body.MaxCompletionTokens, body.MaxTokens))
+	if m := c.config.mirrors[b]; m != nil {
+		c.mirror(ctx, m, body, rawBody.Body)
+	}
 
-	if err = c.selectTranslator(b.Schema); err != nil {
+	if err = c.selectTranslator(b.Schema, b.ModelNameOverride); err != nil {
 		return nil, fmt.Errorf("failed to select translator: %w", err)
 	}
 
-	headerMutation, bodyMutation, override, err := c.translator.RequestBody(body)
+	headerMutation, bodyMutation, override, err := c.translator.RequestBody(rawBody.Body, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transform request: %w", err)
 	}
-
-	if headerMutation == nil {
-		headerMutation = &extprocv3.HeaderMutation{}
+	if len(limited) > 0 && bodyMutation == nil {
+		if headerMutation == nil {
+			headerMutation = &extprocv3.HeaderMutation{}
+		}
+		setHeader(headerMutation, "content-length", strconv.Itoa(len(rawBody.Body)))
+		bodyMutation = &extprocv3.BodyMutation{Mutation: &extprocv3.BodyMutation_Body{Body: rawBody.Body}}
 	}
-	// Set the model name to the request header with the key `x-ai-gateway-llm-model-name`.
-	headerMutation.SetHeaders = append(headerMutation.SetHeaders, &corev3.HeaderValueOption{
-		Header: &corev3.HeaderValue{Key: c.config.modelNameHeaderKey, RawValue: []byte(model)},
-	}, &corev3.HeaderValueOption{
-		Header: &corev3.HeaderValue{Key: c.config.selectedBackendHeaderKey, RawValue: []byte(b.Name)},
-	})
 
-	if authHandler, ok := c.config.backendAuthHandlers[b.Name]; ok {
-		if err := authHandler.Do(ctx, c.requestHeaders, headerMutation, bodyMutation); err != nil {
-			return nil, fmt.Errorf("failed to do auth request: %w", err)
-		}
+	headerMutation, err = setBackendHeadersAndAuth(ctx, c.config, c.requestHeaders, model, b.Name, headerMutation, bodyMutation)
+	if err != nil {
+		return nil, err
 	}
 
 	resp := &extprocv3.ProcessingResponse{
@@ -143,74 +163,24 @@ func (c *chatCompletionProcessor) ProcessRequestBody(ctx context.Context, rawBod
 }
 
 // ProcessResponseHeaders implements [Processor.ProcessResponseHeaders].
-func (c *chatCompletionProcessor) ProcessResponseHeaders(_ context.Context, headers *corev3.HeaderMap) (res *extprocv3.ProcessingResponse, err error) {
-	c.responseHeaders = headersToMap(headers)
-	if enc := c.responseHeaders["content-encoding"]; enc != "" {
-		c.responseEncoding = enc
-	}
-	// The translator can be nil as there could be response event generated by previous ext proc without
-	// getting the request event.
-	if c.translator == nil {
-		return &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_ResponseHeaders{
-			ResponseHeaders: &extprocv3.HeadersResponse{},
-		}}, nil
-	}
-	headerMutation, err := c.translator.ResponseHeaders(c.responseHeaders)
-	if err != nil {
-		return nil, fmt.Errorf("failed to transform response headers: %w", err)
-	}
-	return &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_ResponseHeaders{
-		ResponseHeaders: &extprocv3.HeadersResponse{
-			Response: &extprocv3.CommonResponse{HeaderMutation: headerMutation},
-		},
-	}}, nil
+func (c *chatCompletionProcessor) ProcessResponseHeaders(ctx context.Context, headers *corev3.HeaderMap) (res *extprocv3.ProcessingResponse, err error) {
+	return c.processResponseHeaders(ctx, headers, c.translator, c.translateForFallback)
 }
 
 // ProcessResponseBody implements [Processor.ProcessResponseBody].
 func (c *chatCompletionProcessor) ProcessResponseBody(_ context.Context, body *extprocv3.HttpBody) (res *extprocv3.ProcessingResponse, err error) {
-	var br io.Reader
-	switch c.responseEncoding {
-	case "gzip":
-		br, err = gzip.NewReader(bytes.NewReader(body.Body))
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode gzip: %w", err)
-		}
-	default:
-		br = bytes.NewReader(body.Body)
-	}
-	// The translator can be nil as there could be response event generated by previous ext proc without
-	// getting the request event.
-	if c.translator == nil {
-		return &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_ResponseBody{}}, nil
-	}
-
-	headerMutation, bodyMutation, tokenUsage, err := c.translator.ResponseBody(c.responseHeaders, br, body.EndOfStream)
-	if err != nil {
-		return nil, fmt.Errorf("failed to transform response: %w", err)
-	}
-
-	resp := &extprocv3.ProcessingResponse{
-		Response: &extprocv3.ProcessingResponse_ResponseBody{
-			ResponseBody: &extprocv3.BodyResponse{
-				Response: &extprocv3.CommonResponse{
-					HeaderMutation: headerMutation,
-					BodyMutation:   bodyMutation,
-				},
-			},
-		},
-	}
+	return c.processResponseBody(body, c.translator)
+}
 
-	// TODO: this is coupled with "LLM" specific logic. Once we have another use case, we need to refactor this.
-	c.costs.InputTokens += tokenUsage.InputTokens
-	c.costs.OutputTokens += tokenUsage.OutputTokens
-	c.costs.TotalTokens += tokenUsage.TotalTokens
-	if body.EndOfStream && len(c.config.requestCosts) > 0 {
-		resp.DynamicMetadata, err = c.maybeBuildDynamicMetadata()
-		if err != nil {
-			return nil, fmt.Errorf("failed to build dynamic metadata: %w", err)
+// chatCompletionRouterRequest returns the view of the given request for the [x.RouterV2].
+func chatCompletionRouterRequest(model string, body *openai.ChatCompletionRequest) x.Request {
+	req := x.Request{Model: model, Stream: body.Stream, MessageCount: len(body.Messages), User: body.User}
+	for i := range body.Tools {
+		if f := body.Tools[i].Function; f != nil {
+			req.Tools = append(req.Tools, f.Name)
 		}
 	}
-	return resp, nil
+	return req
 }
 
 func parseOpenAIChatCompletionBody(body *extprocv3.HttpBody) (modelName string, rb *openai.ChatCompletionRequest, err error) {
@@ -221,47 +191,28 @@ func parseOpenAIChatCompletionBody(body *extprocv3.HttpBody) (modelName string,
 	return openAIReq.Model, &openAIReq, nil
 }
 
-func (c *chatCompletionProcessor) maybeBuildDynamicMetadata() (*structpb.Struct, error) {
-	metadata := make(map[string]*structpb.Value, len(c.config.requestCosts))
-	for i := range c.config.requestCosts {
-		rc := &c.config.requestCosts[i]
-		var cost uint32
-		switch rc.Type {
-		case filterapi.LLMRequestCostTypeInputToken:
-			cost = c.costs.InputTokens
-		case filterapi.LLMRequestCostTypeOutputToken:
-			cost = c.costs.OutputTokens
-		case filterapi.LLMRequestCostTypeTotalToken:
-			cost = c.costs.TotalTokens
-		case filterapi.LLMRequestCostTypeCEL:
-			costU64, err := llmcostcel.EvaluateProgram(
-				rc.celProg,
-				c.requestHeaders[c.config.modelNameHeaderKey],
-				c.requestHeaders[c.config.selectedBackendHeaderKey],
-				c.costs.InputTokens,
-				c.costs.OutputTokens,
-				c.costs.TotalTokens,
-			)
-			if err != nil {
-				return nil, fmt.Errorf("failed to evaluate CEL expression: %w", err)
-			}
-			cost = uint32(costU64) //nolint:gosec
-		default:
-			return nil, fmt.Errorf("unknown request cost kind: %s", rc.Type)
+// mirror sends the copy of the request translated for the mirror backend m in the background.
+// The failure to mirror the request is only logged since it must not affect the response to the client.
+func (c *chatCompletionProcessor) mirror(ctx context.Context, m *filterapi.Backend, body *openai.ChatCompletionRequest, rawBody []byte) {
+	tr, err := newChatCompletionTranslator(m.Schema, m.ModelNameOverride, c.config.forceStreamUsage)
+	if err == nil {
+		var headerMutation *extprocv3.HeaderMutation
+		var bodyMutation *extprocv3.BodyMutation
+		if headerMutation, bodyMutation, _, err = tr.RequestBody(rawBody, body); err == nil {
+			err = mirror(ctx, c.config, c.logger, c.originalRequestHeaders, rawBody, m, headerMutation, bodyMutation)
 		}
-		c.logger.Info("Setting request cost metadata", "type", rc.Type, "cost", cost, "metadataKey", rc.MetadataKey)
-		metadata[rc.MetadataKey] = &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(cost)}}
 	}
-	if len(metadata) == 0 {
-		return nil, nil
+	if err != nil {
+		c.logger.Error("failed to mirror request", "backend", m.Name, "error", err)
 	}
-	return &structpb.Struct{
-		Fields: map[string]*structpb.Value{
-			c.config.metadataNamespace: {
-				Kind: &structpb.Value_StructValue{
-					StructValue: &structpb.Struct{Fields: metadata},
-				},
-			},
-		},
-	}, nil
+}
+
+// translateForFallback implements [fallbackTranslateFn].
+func (c *chatCompletionProcessor) translateForFallback(b *filterapi.Backend) (fallbackTranslator, *extprocv3.HeaderMutation, *extprocv3.BodyMutation, error) {
+	tr, err := newChatCompletionTranslator(b.Schema, b.ModelNameOverride, c.config.forceStreamUsage)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	headerMutation, bodyMutation, _, err := tr.RequestBody(c.rawRequestBody, c.requestBody)
+	return tr, headerMutation, bodyMutation, err
 }
@@ -10,7 +10,12 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
@@ -20,6 +25,8 @@ import (
 	"github.com/envoyproxy/ai-gateway/filterapi"
 	"github.com/envoyproxy/ai-gateway/filterapi/x"
 	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
 	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
 	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
 )
@@ -38,31 +45,76 @@ func TestChatCompletion_Scheema(t *testing.T) {
 }
 
 func TestChatCompletion_SelectTranslator(t *testing.T) {
-	c := &chatCompletionProcessor{}
+	c := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{}}}
 	t.Run("unsupported", func(t *testing.T) {
-		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: "Bar", Version: "v123"})
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: "Bar", Version: "v123"}, "")
 		require.ErrorContains(t, err, "unsupported API schema: backend={Bar v123}")
 	})
 	t.Run("supported openai", func(t *testing.T) {
-		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI})
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}, "")
 		require.NoError(t, err)
 		require.NotNil(t, c.translator)
 	})
 	t.Run("supported aws bedrock", func(t *testing.T) {
-		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaAWSBedrock})
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaAWSBedrock}, "")
 		require.NoError(t, err)
 		require.NotNil(t, c.translator)
 	})
+	t.Run("supported anthropic", func(t *testing.T) {
+		c := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{}}}
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaAnthropic}, "")
+		require.NoError(t, err)
+		require.NotNil(t, c.translator)
+	})
+	t.Run("supported azure openai", func(t *testing.T) {
+		c := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{}}}
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaAzureOpenAI, Version: "2024-10-21"}, "")
+		require.NoError(t, err)
+		require.NotNil(t, c.translator)
+	})
+	t.Run("supported gcp vertex ai", func(t *testing.T) {
+		c := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{}}}
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaGCPVertexAI}, "")
+		require.NoError(t, err)
+		require.NotNil(t, c.translator)
+	})
+	t.Run("force stream usage", func(t *testing.T) {
+		c := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{forceStreamUsage: true}}}
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}, "")
+		require.NoError(t, err)
+		_, bm, _, err := c.translator.RequestBody([]byte(`{"model":"gpt-4o","stream":true}`),
+			&openai.ChatCompletionRequest{Model: "gpt-4o", Stream: true})
+		require.NoError(t, err)
+		require.Contains(t, string(bm.Mutation.(*extprocv3.BodyMutation_Body).Body), `"stream_options":{"include_usage":true}`)
+	})
 }
 
 func TestChatCompletion_ProcessRequestHeaders(t *testing.T) {
-	p := &chatCompletionProcessor{}
+	p := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{}}}
 	res, err := p.ProcessRequestHeaders(t.Context(), &corev3.HeaderMap{
 		Headers: []*corev3.HeaderValue{{Key: "foo", Value: "bar"}},
 	})
 	require.NoError(t, err)
 	_, ok := res.Response.(*extprocv3.ProcessingResponse_RequestHeaders)
 	require.True(t, ok)
+
+	t.Run("gateway api keys", func(t *testing.T) {
+		config := &processorConfig{apiKeys: newGatewayAPIKeys(&filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{
+			{Name: "alice", Hash: hashAPIKey("sk-alice"), Owner: "alice"},
+		}})}
+		requestHeaders := map[string]string{"authorization": "Bearer sk-alice"}
+		p := &chatCompletionProcessor{llmProcessor: llmProcessor{config: config, requestHeaders: requestHeaders, logger: slog.Default()}}
+		res, err := p.ProcessRequestHeaders(t.Context(), nil)
+		require.NoError(t, err)
+		// The client key is removed before the backend auth handler sets the credentials of the backend.
+		require.Equal(t, []string{"authorization"}, res.GetRequestHeaders().Response.HeaderMutation.RemoveHeaders)
+		require.Equal(t, "alice", requestHeaders[apiKeyNameKey])
+
+		p.requestHeaders = map[string]string{"authorization": "Bearer sk-bob"}
+		res, err = p.ProcessRequestHeaders(t.Context(), nil)
+		require.NoError(t, err)
+		require.Equal(t, typev3.StatusCode_Unauthorized, res.GetImmediateResponse().Status.Code)
+	})
 }
 
 func TestChatCompletion_ProcessResponseHeaders(t *testing.T) {
@@ -85,6 +137,36 @@ func TestChatCompletion_ProcessResponseHeaders(t *testing.T) {
 		commonRes := res.Response.(*extprocv3.ProcessingResponse_ResponseHeaders).ResponseHeaders.Response
 		require.Equal(t, mt.retHeaderMutation, commonRes.HeaderMutation)
 	})
+	t.Run("fallback", func(t *testing.T) {
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"model":"some-model","messages":[]}`, string(body))
+			w.Header().Set("content-type", "application/json")
+			_, _ = w.Write([]byte(`{"choices":[]}`))
+		}))
+		defer backend.Close()
+
+		p := &chatCompletionProcessor{
+			llmProcessor: llmProcessor{
+				config:         &processorConfig{modelNameHeaderKey: "x-model", selectedBackendHeaderKey: "x-backend"},
+				logger:         slog.Default(),
+				requestHeaders: map[string]string{":path": "/v1/chat/completions", "x-model": "some-model", "x-backend": "primary"},
+				rawRequestBody: []byte(`{"model":"some-model","messages":[]}`),
+				fallbacks:      []filterapi.Backend{{Name: "fallback", Endpoint: backend.URL, Schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}}},
+			},
+			translator:  &mockTranslator{t: t},
+			requestBody: &openai.ChatCompletionRequest{Model: "some-model"},
+		}
+		res, err := p.ProcessResponseHeaders(t.Context(), &corev3.HeaderMap{Headers: []*corev3.HeaderValue{{Key: ":status", Value: "429"}}})
+		require.NoError(t, err)
+		ir := res.GetImmediateResponse()
+		require.NotNil(t, ir)
+		require.Equal(t, typev3.StatusCode_OK, ir.Status.Code)
+		require.Equal(t, `{"choices":[]}`, string(ir.Body))
+		require.Equal(t, "fallback", p.requestHeaders["x-backend"])
+		require.Nil(t, p.fallbacks)
+	})
 }
 
 func TestChatCompletion_ProcessResponseBody(t *testing.T) {
@@ -102,28 +184,36 @@ func TestChatCompletion_ProcessResponseBody(t *testing.T) {
 		mt := &mockTranslator{
 			t: t, expResponseBody: inBody,
 			retBodyMutation: expBodyMut, retHeaderMutation: expHeadMut,
-			retUsedToken: translator.LLMTokenUsage{OutputTokens: 123, InputTokens: 1},
+			retUsedToken: translator.LLMTokenUsage{OutputTokens: 123, InputTokens: 1, CachedInputTokens: 1, ReasoningTokens: 100},
 		}
 
 		celProgInt, err := llmcostcel.NewProgram("54321")
 		require.NoError(t, err)
 		celProgUint, err := llmcostcel.NewProgram("uint(9999)")
 		require.NoError(t, err)
-		p := &chatCompletionProcessor{translator: mt, logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})), config: &processorConfig{
-			metadataNamespace: "ai_gateway_llm_ns",
-			requestCosts: []processorConfigRequestCost{
-				{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeOutputToken, MetadataKey: "output_token_usage"}},
-				{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeInputToken, MetadataKey: "input_token_usage"}},
-				{
-					celProg:        celProgInt,
-					LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeCEL, MetadataKey: "cel_int"},
-				},
-				{
-					celProg:        celProgUint,
-					LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeCEL, MetadataKey: "cel_uint"},
+		p := &chatCompletionProcessor{
+			llmProcessor: llmProcessor{
+				logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config: &processorConfig{
+					metadataNamespace: "ai_gateway_llm_ns",
+					requestCosts: []processorConfigRequestCost{
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeOutputToken, MetadataKey: "output_token_usage"}},
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeInputToken, MetadataKey: "input_token_usage"}},
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeCachedInputToken, MetadataKey: "cached_input_token_usage"}},
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeReasoningToken, MetadataKey: "reasoning_token_usage"}},
+						{
+							celProg:        celProgInt,
+							LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeCEL, MetadataKey: "cel_int"},
+						},
+						{
+							celProg:        celProgUint,
+							LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeCEL, MetadataKey: "cel_uint"},
+						},
+					},
 				},
 			},
-		}}
+			translator: mt,
+		}
 		res, err := p.ProcessResponseBody(t.Context(), inBody)
 		require.NoError(t, err)
 		commonRes := res.Response.(*extprocv3.ProcessingResponse_ResponseBody).ResponseBody.Response
@@ -136,11 +226,99 @@ func TestChatCompletion_ProcessResponseBody(t *testing.T) {
 			GetStructValue().Fields["output_token_usage"].GetNumberValue())
 		require.Equal(t, float64(1), md.Fields["ai_gateway_llm_ns"].
 			GetStructValue().Fields["input_token_usage"].GetNumberValue())
+		require.Equal(t, float64(1), md.Fields["ai_gateway_llm_ns"].
+			GetStructValue().Fields["cached_input_token_usage"].GetNumberValue())
+		require.Equal(t, float64(100), md.Fields["ai_gateway_llm_ns"].
+			GetStructValue().Fields["reasoning_token_usage"].GetNumberValue())
 		require.Equal(t, float64(54321), md.Fields["ai_gateway_llm_ns"].
 			GetStructValue().Fields["cel_int"].GetNumberValue())
 		require.Equal(t, float64(9999), md.Fields["ai_gateway_llm_ns"].
 			GetStructValue().Fields["cel_uint"].GetNumberValue())
 	})
+	t.Run("estimated usage", func(t *testing.T) {
+		for _, tc := range []struct {
+			name         string
+			status       string
+			body         string
+			expTotal     float64
+			expEstimated bool
+		}{
+			{
+				// "Hello world!" is 12 characters, which is estimated as 3 tokens.
+				name: "missing usage", status: "200", body: `{"choices":[{"message":{"role":"assistant","content":"Hello world!"}}]}`,
+				expTotal: 10 + 3, expEstimated: true,
+			},
+			{
+				name: "usage", status: "200", body: `{"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}`,
+				expTotal: 12,
+			},
+			{name: "error", status: "400", body: `{"error":{"message":"bad request"}}`},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				p := &chatCompletionProcessor{
+					llmProcessor: llmProcessor{
+						logger:          slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+						responseHeaders: map[string]string{":status": tc.status},
+						usageEstimator:  newUsageEstimator(tokenizer.Heuristic{}, false, 10, chatCompletionOutput),
+						config: &processorConfig{
+							metadataNamespace: "ai_gateway_llm_ns",
+							requestCosts: []processorConfigRequestCost{
+								{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+							},
+						},
+					},
+					translator: translator.NewChatCompletionOpenAIToOpenAITranslator("", false),
+				}
+				res, err := p.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{Body: []byte(tc.body), EndOfStream: true})
+				require.NoError(t, err)
+				fields := res.DynamicMetadata.Fields["ai_gateway_llm_ns"].GetStructValue().Fields
+				require.Equal(t, tc.expTotal, fields["total"].GetNumberValue())
+				require.Equal(t, tc.expEstimated, fields[usageEstimatedMetadataKey].GetBoolValue())
+			})
+		}
+	})
+	t.Run("terminated stream", func(t *testing.T) {
+		tr := translator.NewChatCompletionOpenAIToOpenAITranslator("", false)
+		_, _, _, err := tr.RequestBody(nil, &openai.ChatCompletionRequest{Model: "gpt-4o", Stream: true})
+		require.NoError(t, err)
+		config := &processorConfig{
+			metadataNamespace: "ai_gateway_llm_ns",
+			requestCosts: []processorConfigRequestCost{
+				{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+			},
+			tokenBudgets: newTokenBudgets([]filterapi.TokenBudget{{
+				Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser},
+				MetadataKey: "total", Limit: 1000, Window: time.Hour,
+			}}, nil, nil),
+		}
+		p := &chatCompletionProcessor{
+			llmProcessor: llmProcessor{
+				logger:          slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				requestHeaders:  map[string]string{router.UserKey: "alice"},
+				responseHeaders: map[string]string{":status": "200"},
+				usageEstimator:  newUsageEstimator(tokenizer.Heuristic{}, true, 10, chatCompletionOutput),
+				costs:           requestUsage{stream: true},
+				config:          config,
+			},
+			translator: tr,
+		}
+		// The costs so far are sent with each chunk, where "Hello" is estimated as 2 tokens.
+		for i, exp := range []float64{10 + 2, 10 + 2 + 2} {
+			res, err := p.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{
+				Body: []byte(`data: {"choices":[{"index":0,"delta":{"content":"Hello"}}]}` + "\n\n"),
+			})
+			require.NoError(t, err, i)
+			fields := res.DynamicMetadata.Fields["ai_gateway_llm_ns"].GetStructValue().Fields
+			require.Equal(t, exp, fields["total"].GetNumberValue(), i)
+			require.True(t, fields[usageEstimatedMetadataKey].GetBoolValue(), i)
+		}
+		require.Zero(t, config.tokenBudgets[0].consumption.consumed("alice"))
+
+		// The client disconnects without the end of the stream, and the costs so far are consumed once.
+		require.NoError(t, p.Close())
+		require.NoError(t, p.Close())
+		require.Equal(t, uint64(14), config.tokenBudgets[0].consumption.consumed("alice"))
+	})
 }
 
 func TestChatCompletion_ProcessRequestBody(t *testing.T) {
@@ -159,14 +337,25 @@ func TestChatCompletion_ProcessRequestBody(t *testing.T) {
 	t.Run("router error", func(t *testing.T) {
 		headers := map[string]string{":path": "/foo"}
 		rt := mockRouter{t: t, expHeaders: headers, retErr: errors.New("test error")}
-		p := &chatCompletionProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}
+		p := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}}
 		_, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "some-model")})
 		require.ErrorContains(t, err, "failed to calculate route: test error")
 	})
+	t.Run("router error 503", func(t *testing.T) {
+		headers := map[string]string{":path": "/foo"}
+		rt := mockRouter{t: t, expHeaders: headers, retErr: x.ErrNoAvailableBackend}
+		p := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}}
+		resp, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "some-model")})
+		require.NoError(t, err)
+		ir := resp.GetImmediateResponse()
+		require.NotNil(t, ir)
+		require.Equal(t, typev3.StatusCode_ServiceUnavailable, ir.GetStatus().GetCode())
+		require.Equal(t, x.ErrNoAvailableBackend.Error(), string(ir.GetBody()))
+	})
 	t.Run("router error 404", func(t *testing.T) {
 		headers := map[string]string{":path": "/foo"}
 		rt := mockRouter{t: t, expHeaders: headers, retErr: x.ErrNoMatchingRule}
-		p := &chatCompletionProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}
+		p := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}}
 		resp, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "some-model")})
 		require.NoError(t, err)
 		require.NotNil(t, resp)
@@ -181,7 +370,7 @@ func TestChatCompletion_ProcessRequestBody(t *testing.T) {
 			t: t, expHeaders: headers, retBackendName: "some-backend",
 			retVersionedAPISchema: filterapi.VersionedAPISchema{Name: "some-schema", Version: "v10.0"},
 		}
-		p := &chatCompletionProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}
+		p := &chatCompletionProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}}
 		_, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "some-model")})
 		require.ErrorContains(t, err, "unsupported API schema: backend={some-schema v10.0}")
 	})
@@ -196,8 +385,12 @@ func TestChatCompletion_ProcessRequestBody(t *testing.T) {
 		require.NoError(t, json.Unmarshal(someBody, &body))
 		tr := mockTranslator{t: t, retErr: errors.New("test error"), expRequestBody: &body}
 		p := &chatCompletionProcessor{
-			config:         &processorConfig{router: rt},
-			requestHeaders: headers, logger: slog.Default(), translator: tr,
+			llmProcessor: llmProcessor{
+				config:         &processorConfig{router: rt},
+				requestHeaders: headers,
+				logger:         slog.Default(),
+			},
+			translator: tr,
 		}
 		_, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: someBody})
 		require.ErrorContains(t, err, "failed to transform request: test error")
@@ -215,11 +408,18 @@ func TestChatCompletion_ProcessRequestBody(t *testing.T) {
 		var expBody openai.ChatCompletionRequest
 		require.NoError(t, json.Unmarshal(someBody, &expBody))
 		mt := mockTranslator{t: t, expRequestBody: &expBody, retHeaderMutation: headerMut, retBodyMutation: bodyMut}
-		p := &chatCompletionProcessor{config: &processorConfig{
-			router:                   rt,
-			selectedBackendHeaderKey: "x-ai-gateway-backend-key",
-			modelNameHeaderKey:       "x-ai-gateway-model-key",
-		}, requestHeaders: headers, logger: slog.Default(), translator: mt}
+		p := &chatCompletionProcessor{
+			llmProcessor: llmProcessor{
+				config: &processorConfig{
+					router:                   rt,
+					selectedBackendHeaderKey: "x-ai-gateway-backend-key",
+					modelNameHeaderKey:       "x-ai-gateway-model-key",
+				},
+				requestHeaders: headers,
+				logger:         slog.Default(),
+			},
+			translator: mt,
+		}
 		resp, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: someBody})
 		require.NoError(t, err)
 		require.Equal(t, mt, p.translator)
@@ -236,6 +436,138 @@ func TestChatCompletion_ProcessRequestBody(t *testing.T) {
 		require.Equal(t, "x-ai-gateway-backend-key", hdrs[1].Header.Key)
 		require.Equal(t, "some-backend", string(hdrs[1].Header.RawValue))
 	})
+	t.Run("model name override", func(t *testing.T) {
+		headers := map[string]string{":path": "/foo"}
+		rt := mockRouter{
+			t: t, expHeaders: headers, retBackendName: "some-backend",
+			retVersionedAPISchema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI},
+			retModelNameOverride:  "claude-3-5-sonnet-latest",
+		}
+		p := &chatCompletionProcessor{
+			llmProcessor: llmProcessor{
+				config: &processorConfig{
+					router:                   rt,
+					selectedBackendHeaderKey: "x-ai-gateway-backend-key",
+					modelNameHeaderKey:       "x-ai-gateway-model-key",
+				},
+				requestHeaders: headers,
+				logger:         slog.Default(),
+			},
+		}
+		resp, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "claude-sonnet")})
+		require.NoError(t, err)
+		commonRes := resp.Response.(*extprocv3.ProcessingResponse_RequestBody).RequestBody.Response
+
+		// The body sent to the backend has the overridden model.
+		var body openai.ChatCompletionRequest
+		require.NoError(t, json.Unmarshal(commonRes.BodyMutation.GetBody(), &body))
+		require.Equal(t, "claude-3-5-sonnet-latest", body.Model)
+		// The model name header used for the routing and costs still has the original model.
+		require.Equal(t, "claude-sonnet", headers["x-ai-gateway-model-key"])
+		var modelHeader string
+		for _, h := range commonRes.HeaderMutation.SetHeaders {
+			if h.Header.Key == "x-ai-gateway-model-key" {
+				modelHeader = string(h.Header.RawValue)
+			}
+		}
+		require.Equal(t, "claude-sonnet", modelHeader)
+	})
+	t.Run("user", func(t *testing.T) {
+		headers := map[string]string{":path": "/foo"}
+		rt := mockRouter{
+			t: t, expHeaders: headers, retBackendName: "some-backend",
+			retVersionedAPISchema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI},
+		}
+		p := &chatCompletionProcessor{
+			llmProcessor: llmProcessor{
+				config: &processorConfig{
+					router:                   rt,
+					selectedBackendHeaderKey: "x-ai-gateway-backend-key",
+					modelNameHeaderKey:       "x-ai-gateway-model-key",
+				},
+				requestHeaders: headers,
+				logger:         slog.Default(),
+			},
+		}
+		body, err := json.Marshal(openai.ChatCompletionRequest{Model: "some-model", User: "some-user"})
+		require.NoError(t, err)
+		_, err = p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: body})
+		require.NoError(t, err)
+		// The user is passed to the router as the session key of the consistent hashing.
+		require.Equal(t, "some-user", headers[router.UserKey])
+	})
+	t.Run("estimated input tokens", func(t *testing.T) {
+		headers := map[string]string{":path": "/foo"}
+		rt := mockRouter{
+			t: t, expHeaders: headers, retBackendName: "some-backend",
+			retVersionedAPISchema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI},
+		}
+		p := &chatCompletionProcessor{
+			llmProcessor: llmProcessor{
+				config: &processorConfig{
+					router:                   rt,
+					selectedBackendHeaderKey: "x-ai-gateway-backend-key",
+					modelNameHeaderKey:       "x-ai-gateway-model-key",
+					estimateInputTokens:      true,
+				},
+				requestHeaders: headers,
+				logger:         slog.Default(),
+			},
+		}
+		_, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: bodyFromModel(t, "some-model")})
+		require.NoError(t, err)
+		// The estimation is passed to the router to match the route rules.
+		require.Equal(t, "3", headers[router.EstimatedInputTokensKey])
+	})
+	t.Run("request limits", func(t *testing.T) {
+		newProcessor := func(headers map[string]string) *chatCompletionProcessor {
+			rt := mockRouter{
+				t: t, expHeaders: headers, retBackendName: "some-backend",
+				retVersionedAPISchema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI},
+			}
+			return &chatCompletionProcessor{
+				llmProcessor: llmProcessor{
+					config: &processorConfig{
+						router:                   rt,
+						selectedBackendHeaderKey: "x-ai-gateway-backend-key",
+						modelNameHeaderKey:       "x-ai-gateway-model-key",
+						requestLimits:            &filterapi.RequestLimits{MaxTokens: 100, MaxEstimatedInputTokens: 10},
+					},
+					requestHeaders: headers,
+					logger:         slog.Default(),
+				},
+			}
+		}
+		t.Run("clamped", func(t *testing.T) {
+			p := newProcessor(map[string]string{":path": "/foo"})
+			resp, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{
+				Body: []byte(`{"model":"some-model","max_tokens":1000,"reasoning_effort":"low"}`),
+			})
+			require.NoError(t, err)
+			commonRes := resp.Response.(*extprocv3.ProcessingResponse_RequestBody).RequestBody.Response
+			// The clamped body is sent to the backend as well as kept for the fallbacks, and the other fields are kept as is.
+			require.JSONEq(t, `{"model":"some-model","max_tokens":100,"reasoning_effort":"low"}`, string(commonRes.BodyMutation.GetBody()))
+			require.Equal(t, commonRes.BodyMutation.GetBody(), p.rawRequestBody)
+			var contentLength string
+			for _, h := range commonRes.HeaderMutation.SetHeaders {
+				if h.Header.Key == "content-length" {
+					contentLength = string(h.Header.RawValue)
+				}
+			}
+			require.Equal(t, strconv.Itoa(len(p.rawRequestBody)), contentLength)
+		})
+		t.Run("rejected", func(t *testing.T) {
+			p := newProcessor(map[string]string{":path": "/foo"})
+			// 100 ASCII characters are estimated as 25 tokens.
+			body := `{"model":"some-model","messages":[{"role":"user","content":"` + strings.Repeat("a", 100) + `"}]}`
+			resp, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(body)})
+			require.NoError(t, err)
+			ir := resp.GetImmediateResponse()
+			require.Equal(t, typev3.StatusCode_BadRequest, ir.GetStatus().GetCode())
+			require.JSONEq(t, `{"type":"error","error":{"type":"invalid_request_error","code":"context_length_exceeded","param":"messages",
+				"message":"The maximum number of the input tokens is 10, but the messages resulted in about 31 tokens."}}`, string(ir.Body))
+		})
+	})
 }
 
 func TestChatCompletion_ParseBody(t *testing.T) {
@@ -256,3 +588,14 @@ func TestChatCompletion_ParseBody(t *testing.T) {
 		require.Nil(t, rb)
 	})
 }
+
+func Test_chatCompletionRouterRequest(t *testing.T) {
+	var body openai.ChatCompletionRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"model":"some-model","stream":true,"user":"some-user",
+		"messages":[{"role":"system","content":"foo"},{"role":"user","content":"bar"}],
+		"tools":[{"type":"function","function":{"name":"get_weather"}},{"type":"function","function":{"name":"get_time"}}]
+	}`), &body))
+	require.Equal(t, x.Request{
+		Model: "some-model", Stream: true, MessageCount: 2, Tools: []string{"get_weather", "get_time"}, User: "some-user",
+	}, chatCompletionRouterRequest("some-model", &body))
+}
@@ -0,0 +1,143 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+// NewCompletionsProcessor implements [Processor] for the legacy /completions endpoint.
+func NewCompletionsProcessor(config *processorConfig, requestHeaders map[string]string, logger *slog.Logger) (Processor, error) {
+	if config.schema.Name != filterapi.APISchemaOpenAI {
+		return nil, fmt.Errorf("unsupported API schema: %s", config.schema.Name)
+	}
+	return &completionsProcessor{llmProcessor: llmProcessor{
+		config:         config,
+		requestHeaders: requestHeaders,
+		logger:         logger,
+	}}, nil
+}
+
+// completionsProcessor handles the processing of the request and response messages for a single stream.
+type completionsProcessor struct {
+	llmProcessor
+	translator translator.OpenAICompletionTranslator
+	// requestBody is kept to translate the request for the fallbacks.
+	requestBody *openai.CompletionRequest
+}
+
+// selectTranslator selects the translator based on the output schema and the model name override of the backend.
+func (c *completionsProcessor) selectTranslator(out filterapi.VersionedAPISchema, modelNameOverride string) (err error) {
+	if c.translator != nil { // Prevents re-selection and allows translator injection in tests.
+		return nil
+	}
+	c.translator, err = newCompletionTranslator(out, modelNameOverride)
+	return
+}
+
+// newCompletionTranslator creates the translator for the output schema.
+func newCompletionTranslator(out filterapi.VersionedAPISchema, modelNameOverride string) (translator.OpenAICompletionTranslator, error) {
+	switch out.Name {
+	case filterapi.APISchemaOpenAI:
+		return translator.NewCompletionOpenAIToOpenAITranslator(modelNameOverride), nil
+	case filterapi.APISchemaAWSBedrock:
+		return translator.NewCompletionOpenAIToAWSBedrockTranslator(modelNameOverride), nil
+	default:
+		return nil, fmt.Errorf("unsupported API schema for completions: backend={%s %s}", out.Name, out.Version)
+	}
+}
+
+// ProcessRequestHeaders implements [Processor.ProcessRequestHeaders].
+func (c *completionsProcessor) ProcessRequestHeaders(context.Context, *corev3.HeaderMap) (res *extprocv3.ProcessingResponse, err error) {
+	// The request headers have already been at the time the processor was created.
+	return authenticateAPIKey(c.config, c.requestHeaders, c.logger)
+}
+
+// ProcessRequestBody implements [Processor.ProcessRequestBody].
+func (c *completionsProcessor) ProcessRequestBody(ctx context.Context, rawBody *extprocv3.HttpBody) (res *extprocv3.ProcessingResponse, err error) {
+	model, body, err := parseOpenAICompletionBody(rawBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request body: %w", err)
+	}
+	c.logger.Info("Processing request", "path", c.requestHeaders[":path"], "model", model)
+
+	b, immediateResp, err := selectBackend(c.config, c.requestHeaders, x.Request{Model: model, Stream: body.Stream, User: body.User})
+	if err != nil || immediateResp != nil {
+		return immediateResp, err
+	}
+	c.backendSelected(b, rawBody.Body, body.Stream)
+	c.requestBody, c.costs.user, c.costs.maxTokens = body, body.User, maxTokensOf(body.MaxTokens)
+	c.initUsageEstimator(body.Stream, completionOutput, func(t tokenizer.Tokenizer) int {
+		return tokenizer.EstimatePromptTokens(t, body.Prompt)
+	})
+
+	if err = c.selectTranslator(b.Schema, b.ModelNameOverride); err != nil {
+		return nil, fmt.Errorf("failed to select translator: %w", err)
+	}
+
+	headerMutation, bodyMutation, override, err := c.translator.RequestBody(rawBody.Body, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform request: %w", err)
+	}
+
+	headerMutation, err = setBackendHeadersAndAuth(ctx, c.config, c.requestHeaders, model, b.Name, headerMutation, bodyMutation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &extprocv3.ProcessingResponse{
+		Response: &extprocv3.ProcessingResponse_RequestBody{
+			RequestBody: &extprocv3.BodyResponse{
+				Response: &extprocv3.CommonResponse{
+					HeaderMutation:  headerMutation,
+					BodyMutation:    bodyMutation,
+					ClearRouteCache: true,
+				},
+			},
+		},
+		ModeOverride: override,
+	}, nil
+}
+
+// ProcessResponseHeaders implements [Processor.ProcessResponseHeaders].
+func (c *completionsProcessor) ProcessResponseHeaders(ctx context.Context, headers *corev3.HeaderMap) (res *extprocv3.ProcessingResponse, err error) {
+	return c.processResponseHeaders(ctx, headers, c.translator, c.translateForFallback)
+}
+
+// ProcessResponseBody implements [Processor.ProcessResponseBody].
+func (c *completionsProcessor) ProcessResponseBody(_ context.Context, body *extprocv3.HttpBody) (res *extprocv3.ProcessingResponse, err error) {
+	return c.processResponseBody(body, c.translator)
+}
+
+func parseOpenAICompletionBody(body *extprocv3.HttpBody) (modelName string, rb *openai.CompletionRequest, err error) {
+	var openAIReq openai.CompletionRequest
+	if err := json.Unmarshal(body.Body, &openAIReq); err != nil {
+		return "", nil, fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+	return openAIReq.Model, &openAIReq, nil
+}
+
+// translateForFallback implements [fallbackTranslateFn].
+func (c *completionsProcessor) translateForFallback(b *filterapi.Backend) (fallbackTranslator, *extprocv3.HeaderMutation, *extprocv3.BodyMutation, error) {
+	tr, err := newCompletionTranslator(b.Schema, b.ModelNameOverride)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	headerMutation, bodyMutation, _, err := tr.RequestBody(c.rawRequestBody, c.requestBody)
+	return tr, headerMutation, bodyMutation, err
+}
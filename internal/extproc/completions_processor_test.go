@@ -0,0 +1,304 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+func TestCompletions_Schema(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		cfg := &processorConfig{schema: filterapi.VersionedAPISchema{Name: "Foo", Version: "v123"}}
+		_, err := NewCompletionsProcessor(cfg, nil, nil)
+		require.ErrorContains(t, err, "unsupported API schema: Foo")
+	})
+	t.Run("supported openai", func(t *testing.T) {
+		cfg := &processorConfig{schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}}
+		_, err := NewCompletionsProcessor(cfg, nil, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestCompletions_SelectTranslator(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		c := &completionsProcessor{}
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaGCPVertexAI, Version: "v1"}, "")
+		require.ErrorContains(t, err, "unsupported API schema for completions: backend={GCPVertexAI v1}")
+	})
+	t.Run("supported openai", func(t *testing.T) {
+		c := &completionsProcessor{}
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}, "")
+		require.NoError(t, err)
+		require.NotNil(t, c.translator)
+	})
+	t.Run("supported aws bedrock", func(t *testing.T) {
+		c := &completionsProcessor{}
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaAWSBedrock}, "")
+		require.NoError(t, err)
+		require.NotNil(t, c.translator)
+	})
+}
+
+func TestCompletions_ProcessResponseHeaders(t *testing.T) {
+	inHeaders := &corev3.HeaderMap{
+		Headers: []*corev3.HeaderValue{{Key: "foo", Value: "bar"}, {Key: "dog", RawValue: []byte("cat")}},
+	}
+	expHeaders := map[string]string{"foo": "bar", "dog": "cat"}
+	mt := &mockCompletionTranslator{t: t, expHeaders: expHeaders, retHeaderMutation: &extprocv3.HeaderMutation{}}
+	c := &completionsProcessor{translator: mt}
+	res, err := c.ProcessResponseHeaders(t.Context(), inHeaders)
+	require.NoError(t, err)
+	commonRes := res.Response.(*extprocv3.ProcessingResponse_ResponseHeaders).ResponseHeaders.Response
+	require.Equal(t, mt.retHeaderMutation, commonRes.HeaderMutation)
+}
+
+func TestCompletions_ProcessRequestBody(t *testing.T) {
+	const someBody = `{"model": "some-model", "prompt": "Say this is a test", "stream": true}`
+	t.Run("body parser error", func(t *testing.T) {
+		c := &completionsProcessor{}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte("nonjson")})
+		require.ErrorContains(t, err, "failed to parse request body")
+	})
+	t.Run("router error", func(t *testing.T) {
+		headers := map[string]string{":path": "/v1/completions"}
+		rt := mockRouter{t: t, expHeaders: headers, retErr: errors.New("test error")}
+		c := &completionsProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.ErrorContains(t, err, "failed to calculate route: test error")
+	})
+	t.Run("router error 404", func(t *testing.T) {
+		headers := map[string]string{":path": "/v1/completions"}
+		rt := mockRouter{t: t, expHeaders: headers, retErr: x.ErrNoMatchingRule}
+		c := &completionsProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}}
+		resp, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.NoError(t, err)
+		require.Equal(t, typev3.StatusCode_NotFound, resp.GetImmediateResponse().GetStatus().GetCode())
+	})
+	t.Run("translator error", func(t *testing.T) {
+		headers := map[string]string{":path": "/v1/completions"}
+		rt := mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"}
+		var body openai.CompletionRequest
+		require.NoError(t, json.Unmarshal([]byte(someBody), &body))
+		tr := mockCompletionTranslator{t: t, retErr: errors.New("test error"), expRequestBody: &body}
+		c := &completionsProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}, translator: tr}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.ErrorContains(t, err, "failed to transform request: test error")
+	})
+	t.Run("ok", func(t *testing.T) {
+		headers := map[string]string{":path": "/v1/completions"}
+		rt := mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"}
+		headerMut := &extprocv3.HeaderMutation{}
+		bodyMut := &extprocv3.BodyMutation{}
+		override := &extprocv3http.ProcessingMode{ResponseBodyMode: extprocv3http.ProcessingMode_STREAMED}
+		var body openai.CompletionRequest
+		require.NoError(t, json.Unmarshal([]byte(someBody), &body))
+		tr := mockCompletionTranslator{t: t, expRequestBody: &body, retHeaderMutation: headerMut, retBodyMutation: bodyMut, retOverride: override}
+		c := &completionsProcessor{
+			llmProcessor: llmProcessor{
+				config: &processorConfig{
+					router:                   rt,
+					selectedBackendHeaderKey: "x-ai-gateway-backend-key",
+					modelNameHeaderKey:       "x-ai-gateway-model-key",
+				},
+				requestHeaders: headers,
+				logger:         slog.Default(),
+			},
+			translator: tr,
+		}
+		resp, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.NoError(t, err)
+		require.Equal(t, override, resp.ModeOverride)
+		commonRes := resp.Response.(*extprocv3.ProcessingResponse_RequestBody).RequestBody.Response
+		require.Equal(t, headerMut, commonRes.HeaderMutation)
+		require.Equal(t, bodyMut, commonRes.BodyMutation)
+
+		hdrs := headerMut.SetHeaders
+		require.Len(t, hdrs, 2)
+		require.Equal(t, "x-ai-gateway-model-key", hdrs[0].Header.Key)
+		require.Equal(t, "some-model", string(hdrs[0].Header.RawValue))
+		require.Equal(t, "x-ai-gateway-backend-key", hdrs[1].Header.Key)
+		require.Equal(t, "some-backend", string(hdrs[1].Header.RawValue))
+	})
+}
+
+func TestCompletions_ProcessResponseBody(t *testing.T) {
+	t.Run("error translation", func(t *testing.T) {
+		mt := &mockCompletionTranslator{t: t, retErr: errors.New("test error")}
+		c := &completionsProcessor{translator: mt}
+		_, err := c.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{})
+		require.ErrorContains(t, err, "test error")
+	})
+	t.Run("ok", func(t *testing.T) {
+		inBody := &extprocv3.HttpBody{Body: []byte("some-body"), EndOfStream: true}
+		expBodyMut := &extprocv3.BodyMutation{}
+		expHeadMut := &extprocv3.HeaderMutation{}
+		mt := &mockCompletionTranslator{
+			t: t, expResponseBody: inBody,
+			retBodyMutation: expBodyMut, retHeaderMutation: expHeadMut,
+			retUsedToken: translator.LLMTokenUsage{OutputTokens: 123, InputTokens: 1},
+		}
+		c := &completionsProcessor{
+			llmProcessor: llmProcessor{
+				logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config: &processorConfig{
+					metadataNamespace: "ai_gateway_llm_ns",
+					requestCosts: []processorConfigRequestCost{
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeOutputToken, MetadataKey: "output_token_usage"}},
+					},
+				},
+			},
+			translator: mt,
+		}
+		res, err := c.ProcessResponseBody(t.Context(), inBody)
+		require.NoError(t, err)
+		commonRes := res.Response.(*extprocv3.ProcessingResponse_ResponseBody).ResponseBody.Response
+		require.Equal(t, expBodyMut, commonRes.BodyMutation)
+		require.Equal(t, expHeadMut, commonRes.HeaderMutation)
+
+		md := res.DynamicMetadata
+		require.NotNil(t, md)
+		require.Equal(t, float64(123), md.Fields["ai_gateway_llm_ns"].
+			GetStructValue().Fields["output_token_usage"].GetNumberValue())
+	})
+	t.Run("estimated usage", func(t *testing.T) {
+		const reqBody = `{"model": "some-model", "prompt": "Say this is a test", "stream": true}`
+		headers := map[string]string{":path": "/v1/completions"}
+		var body openai.CompletionRequest
+		require.NoError(t, json.Unmarshal([]byte(reqBody), &body))
+		c := &completionsProcessor{
+			llmProcessor: llmProcessor{
+				logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config: &processorConfig{
+					router:            mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"},
+					metadataNamespace: "ai_gateway_llm_ns",
+					requestCosts: []processorConfigRequestCost{
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+					},
+				},
+				requestHeaders: headers,
+			},
+			translator: mockCompletionTranslator{t: t, expRequestBody: &body},
+		}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(reqBody)})
+		require.NoError(t, err)
+		c.responseHeaders = map[string]string{":status": "200"}
+
+		// "Say this is a test" is estimated as 5 tokens and "Hello" as 2 tokens.
+		for _, chunk := range []*extprocv3.HttpBody{
+			{Body: []byte(`data: {"choices":[{"text":"Hello","index":0}]}` + "\n\n")},
+			{Body: []byte("data: [DONE]\n\n"), EndOfStream: true},
+		} {
+			res, err := c.ProcessResponseBody(t.Context(), chunk)
+			require.NoError(t, err)
+			fields := res.DynamicMetadata.Fields["ai_gateway_llm_ns"].GetStructValue().Fields
+			require.Equal(t, float64(5+2), fields["total"].GetNumberValue())
+			require.True(t, fields[usageEstimatedMetadataKey].GetBoolValue())
+		}
+	})
+	t.Run("terminated stream", func(t *testing.T) {
+		config := &processorConfig{
+			metadataNamespace: "ai_gateway_llm_ns",
+			requestCosts: []processorConfigRequestCost{
+				{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+			},
+			tokenBudgets: newTokenBudgets([]filterapi.TokenBudget{{
+				Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser},
+				MetadataKey: "total", Limit: 1000, Window: time.Hour,
+			}}, nil, nil),
+		}
+		c := &completionsProcessor{
+			llmProcessor: llmProcessor{
+				logger:          slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				requestHeaders:  map[string]string{router.UserKey: "alice"},
+				responseHeaders: map[string]string{":status": "200"},
+				costs:           requestUsage{stream: true},
+				config:          config,
+			},
+			translator: &mockCompletionTranslator{t: t, retUsedToken: translator.LLMTokenUsage{InputTokens: 5, OutputTokens: 7, TotalTokens: 12}},
+		}
+		res, err := c.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{Body: []byte("some-chunk")})
+		require.NoError(t, err)
+		require.Equal(t, float64(12), res.DynamicMetadata.Fields["ai_gateway_llm_ns"].
+			GetStructValue().Fields["total"].GetNumberValue())
+		require.Zero(t, config.tokenBudgets[0].consumption.consumed("alice"))
+
+		// The client disconnects without the end of the stream, and the costs so far are consumed once.
+		require.NoError(t, c.Close())
+		require.NoError(t, c.Close())
+		require.Equal(t, uint64(12), config.tokenBudgets[0].consumption.consumed("alice"))
+	})
+	t.Run("terminated stream without usage", func(t *testing.T) {
+		const reqBody = `{"model": "some-model", "prompt": "Say this is a test", "stream": true, "user": "alice"}`
+		headers := map[string]string{":path": "/v1/completions"}
+		var body openai.CompletionRequest
+		require.NoError(t, json.Unmarshal([]byte(reqBody), &body))
+		config := &processorConfig{
+			router:            mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"},
+			metadataNamespace: "ai_gateway_llm_ns",
+			requestCosts: []processorConfigRequestCost{
+				{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+			},
+			tokenBudgets: newTokenBudgets([]filterapi.TokenBudget{{
+				Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser},
+				MetadataKey: "total", Limit: 1000, Window: time.Hour,
+			}}, nil, nil),
+		}
+		c := &completionsProcessor{
+			llmProcessor: llmProcessor{
+				logger:         slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config:         config,
+				requestHeaders: headers,
+			},
+			translator: mockCompletionTranslator{t: t, expRequestBody: &body},
+		}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(reqBody)})
+		require.NoError(t, err)
+		c.responseHeaders = map[string]string{":status": "200"}
+
+		// The estimated costs so far are sent with each chunk, where "Say this is a test" is estimated as 5 tokens
+		// and each "Hello" as 2 tokens.
+		for i, exp := range []float64{5 + 2, 5 + 2 + 2} {
+			res, err := c.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{
+				Body: []byte(`data: {"choices":[{"text":"Hello","index":0}]}` + "\n\n"),
+			})
+			require.NoError(t, err, i)
+			fields := res.DynamicMetadata.Fields["ai_gateway_llm_ns"].GetStructValue().Fields
+			require.Equal(t, exp, fields["total"].GetNumberValue(), i)
+			require.True(t, fields[usageEstimatedMetadataKey].GetBoolValue(), i)
+		}
+		require.Zero(t, config.tokenBudgets[0].consumption.consumed("alice"))
+
+		// The client disconnects without the end of the stream, and the estimated costs so far are consumed once.
+		require.NoError(t, c.Close())
+		require.NoError(t, c.Close())
+		require.Equal(t, uint64(9), config.tokenBudgets[0].consumption.consumed("alice"))
+	})
+}
+
+func TestCompletions_ParseBody(t *testing.T) {
+	modelName, rb, err := parseOpenAICompletionBody(&extprocv3.HttpBody{Body: []byte(`{"model":"gpt-3.5-turbo-instruct","prompt":["a"],"stop":"\n"}`)})
+	require.NoError(t, err)
+	require.Equal(t, "gpt-3.5-turbo-instruct", modelName)
+	require.Equal(t, []string{"a"}, rb.Prompt.Value)
+	require.Equal(t, "\n", rb.Stop)
+}
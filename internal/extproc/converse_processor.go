@@ -0,0 +1,191 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+// NewConverseProcessor implements [Processor] for the /model/{modelId}/converse and /model/{modelId}/converse-stream
+// endpoints of AWS Bedrock.
+func NewConverseProcessor(config *processorConfig, requestHeaders map[string]string, logger *slog.Logger) (Processor, error) {
+	if config.schema.Name != filterapi.APISchemaAWSBedrock {
+		return nil, fmt.Errorf("unsupported API schema: %s", config.schema.Name)
+	}
+	return &converseProcessor{llmProcessor: llmProcessor{
+		config:         config,
+		requestHeaders: requestHeaders,
+		logger:         logger,
+	}}, nil
+}
+
+// converseProcessor handles the processing of the request and response messages for a single stream.
+type converseProcessor struct {
+	llmProcessor
+	translator translator.AWSBedrockConverseTranslator
+	// requestBody and stream are kept to translate the request for the fallbacks.
+	requestBody *awsbedrock.ConverseInput
+	stream      bool
+}
+
+// selectTranslator selects the translator based on the output schema and the model name override of the backend.
+func (c *converseProcessor) selectTranslator(out filterapi.VersionedAPISchema, modelNameOverride string) (err error) {
+	if c.translator != nil { // Prevents re-selection and allows translator injection in tests.
+		return nil
+	}
+	c.translator, err = newConverseTranslator(out, modelNameOverride)
+	return
+}
+
+// newConverseTranslator creates the translator for the output schema.
+func newConverseTranslator(out filterapi.VersionedAPISchema, modelNameOverride string) (translator.AWSBedrockConverseTranslator, error) {
+	switch out.Name {
+	case filterapi.APISchemaOpenAI:
+		return translator.NewConverseAWSBedrockToOpenAITranslator(modelNameOverride), nil
+	case filterapi.APISchemaAWSBedrock:
+		return translator.NewConverseAWSBedrockToAWSBedrockTranslator(modelNameOverride), nil
+	default:
+		return nil, fmt.Errorf("unsupported API schema for converse: backend={%s %s}", out.Name, out.Version)
+	}
+}
+
+// ProcessRequestHeaders implements [Processor.ProcessRequestHeaders].
+func (c *converseProcessor) ProcessRequestHeaders(_ context.Context, _ *corev3.HeaderMap) (res *extprocv3.ProcessingResponse, err error) {
+	// The request headers have already been at the time the processor was created
+	return authenticateAPIKey(c.config, c.requestHeaders, c.logger)
+}
+
+// ProcessRequestBody implements [Processor.ProcessRequestBody].
+func (c *converseProcessor) ProcessRequestBody(ctx context.Context, rawBody *extprocv3.HttpBody) (res *extprocv3.ProcessingResponse, err error) {
+	model, stream, err := parseConversePath(c.requestHeaders[":path"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request path: %w", err)
+	}
+	body, err := parseAWSBedrockConverseBody(rawBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request body: %w", err)
+	}
+	body.ModelID = &model
+	c.logger.Info("Processing request", "path", c.requestHeaders[":path"], "model", model)
+
+	b, immediateResp, err := selectBackend(c.config, c.requestHeaders, converseRouterRequest(model, stream, body))
+	if err != nil || immediateResp != nil {
+		return immediateResp, err
+	}
+	c.backendSelected(b, rawBody.Body, stream)
+	c.requestBody, c.stream = body, stream
+	c.initUsageEstimator(stream, converseOutput, func(t tokenizer.Tokenizer) int {
+		return tokenizer.EstimateConverseInputTokens(t, body)
+	})
+	if body.InferenceConfig != nil {
+		c.costs.maxTokens = maxTokensOf(body.InferenceConfig.MaxTokens)
+	}
+
+	if err = c.selectTranslator(b.Schema, b.ModelNameOverride); err != nil {
+		return nil, fmt.Errorf("failed to select translator: %w", err)
+	}
+
+	headerMutation, bodyMutation, override, err := c.translator.RequestBody(body, stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform request: %w", err)
+	}
+
+	headerMutation, err = setBackendHeadersAndAuth(ctx, c.config, c.requestHeaders, model, b.Name, headerMutation, bodyMutation)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &extprocv3.ProcessingResponse{
+		Response: &extprocv3.ProcessingResponse_RequestBody{
+			RequestBody: &extprocv3.BodyResponse{
+				Response: &extprocv3.CommonResponse{
+					HeaderMutation:  headerMutation,
+					BodyMutation:    bodyMutation,
+					ClearRouteCache: true,
+				},
+			},
+		},
+		ModeOverride: override,
+	}
+	return resp, nil
+}
+
+// ProcessResponseHeaders implements [Processor.ProcessResponseHeaders].
+func (c *converseProcessor) ProcessResponseHeaders(ctx context.Context, headers *corev3.HeaderMap) (res *extprocv3.ProcessingResponse, err error) {
+	return c.processResponseHeaders(ctx, headers, c.translator, c.translateForFallback)
+}
+
+// ProcessResponseBody implements [Processor.ProcessResponseBody].
+func (c *converseProcessor) ProcessResponseBody(_ context.Context, body *extprocv3.HttpBody) (res *extprocv3.ProcessingResponse, err error) {
+	return c.processResponseBody(body, c.translator)
+}
+
+// parseConversePath extracts the model ID from the path of the form /model/{modelId}/converse or
+// /model/{modelId}/converse-stream, and returns whether the response is streamed.
+func parseConversePath(path string) (modelID string, stream bool, err error) {
+	rest, ok := strings.CutPrefix(path, "/model/")
+	if !ok {
+		return "", false, fmt.Errorf("invalid path: %s", path)
+	}
+	var escaped string
+	if escaped, ok = strings.CutSuffix(rest, "/converse-stream"); ok {
+		stream = true
+	} else if escaped, ok = strings.CutSuffix(rest, "/converse"); !ok {
+		return "", false, fmt.Errorf("invalid path: %s", path)
+	}
+	// The model ID can be an ARN which is URL-encoded in the path.
+	if modelID, err = url.PathUnescape(escaped); err != nil {
+		return "", false, fmt.Errorf("invalid model ID %q: %w", escaped, err)
+	} else if modelID == "" {
+		return "", false, fmt.Errorf("missing model ID in path: %s", path)
+	}
+	return modelID, stream, nil
+}
+
+// converseRouterRequest returns the view of the given request for the [x.RouterV2].
+func converseRouterRequest(model string, stream bool, body *awsbedrock.ConverseInput) x.Request {
+	req := x.Request{Model: model, Stream: stream, MessageCount: len(body.Messages)}
+	if body.ToolConfig != nil {
+		for _, t := range body.ToolConfig.Tools {
+			if t != nil && t.ToolSpec != nil && t.ToolSpec.Name != nil {
+				req.Tools = append(req.Tools, *t.ToolSpec.Name)
+			}
+		}
+	}
+	return req
+}
+
+func parseAWSBedrockConverseBody(body *extprocv3.HttpBody) (*awsbedrock.ConverseInput, error) {
+	var bedrockReq awsbedrock.ConverseInput
+	if err := json.Unmarshal(body.Body, &bedrockReq); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+	return &bedrockReq, nil
+}
+
+// translateForFallback implements [fallbackTranslateFn].
+func (c *converseProcessor) translateForFallback(b *filterapi.Backend) (fallbackTranslator, *extprocv3.HeaderMutation, *extprocv3.BodyMutation, error) {
+	tr, err := newConverseTranslator(b.Schema, b.ModelNameOverride)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	headerMutation, bodyMutation, _, err := tr.RequestBody(c.requestBody, c.stream)
+	return tr, headerMutation, bodyMutation, err
+}
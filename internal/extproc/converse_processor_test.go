@@ -0,0 +1,321 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+func TestConverse_Schema(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		cfg := &processorConfig{schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}}
+		_, err := NewConverseProcessor(cfg, nil, nil)
+		require.ErrorContains(t, err, "unsupported API schema: OpenAI")
+	})
+	t.Run("supported aws bedrock", func(t *testing.T) {
+		cfg := &processorConfig{schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaAWSBedrock}}
+		_, err := NewConverseProcessor(cfg, nil, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestConverse_SelectTranslator(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		c := &converseProcessor{}
+		err := c.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaAnthropic}, "")
+		require.ErrorContains(t, err, "unsupported API schema for converse: backend={Anthropic }")
+	})
+	for _, name := range []filterapi.APISchemaName{filterapi.APISchemaOpenAI, filterapi.APISchemaAWSBedrock} {
+		t.Run(string(name), func(t *testing.T) {
+			c := &converseProcessor{}
+			require.NoError(t, c.selectTranslator(filterapi.VersionedAPISchema{Name: name}, ""))
+			require.NotNil(t, c.translator)
+		})
+	}
+}
+
+func TestConverse_ProcessRequestBody(t *testing.T) {
+	const someBody = `{"messages":[{"role":"user","content":[{"text":"hello"}]}]}`
+	t.Run("path parser error", func(t *testing.T) {
+		c := &converseProcessor{llmProcessor: llmProcessor{requestHeaders: map[string]string{":path": "/foo"}}}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.ErrorContains(t, err, "failed to parse request path: invalid path: /foo")
+	})
+	t.Run("body parser error", func(t *testing.T) {
+		c := &converseProcessor{llmProcessor: llmProcessor{requestHeaders: map[string]string{":path": "/model/some-model/converse"}}}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte("nonjson")})
+		require.ErrorContains(t, err, "failed to parse request body")
+	})
+	t.Run("router error 404", func(t *testing.T) {
+		headers := map[string]string{":path": "/model/some-model/converse"}
+		rt := mockRouter{t: t, expHeaders: headers, retErr: x.ErrNoMatchingRule}
+		c := &converseProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}}
+		resp, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.NoError(t, err)
+		ir := resp.GetImmediateResponse()
+		require.NotNil(t, ir)
+		require.Equal(t, typev3.StatusCode_NotFound, ir.GetStatus().GetCode())
+	})
+	t.Run("translator error", func(t *testing.T) {
+		headers := map[string]string{":path": "/model/some-model/converse"}
+		rt := mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"}
+		var body awsbedrock.ConverseInput
+		require.NoError(t, json.Unmarshal([]byte(someBody), &body))
+		body.ModelID = ptr.To("some-model")
+		tr := mockConverseTranslator{t: t, retErr: errors.New("test error"), expRequestBody: &body}
+		c := &converseProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}, translator: tr}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.ErrorContains(t, err, "failed to transform request: test error")
+	})
+	t.Run("ok stream", func(t *testing.T) {
+		headers := map[string]string{":path": "/model/arn%3Aaws%3Abedrock%3Amodel/converse-stream"}
+		rt := mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"}
+		headerMut := &extprocv3.HeaderMutation{}
+		bodyMut := &extprocv3.BodyMutation{}
+		override := &extprocv3http.ProcessingMode{ResponseBodyMode: extprocv3http.ProcessingMode_STREAMED}
+		var body awsbedrock.ConverseInput
+		require.NoError(t, json.Unmarshal([]byte(someBody), &body))
+		body.ModelID = ptr.To("arn:aws:bedrock:model")
+		tr := mockConverseTranslator{
+			t: t, expRequestBody: &body, expStream: true,
+			retHeaderMutation: headerMut, retBodyMutation: bodyMut, retOverride: override,
+		}
+		c := &converseProcessor{
+			llmProcessor: llmProcessor{
+				config: &processorConfig{
+					router:                   rt,
+					selectedBackendHeaderKey: "x-ai-gateway-backend-key",
+					modelNameHeaderKey:       "x-ai-gateway-model-key",
+				},
+				requestHeaders: headers,
+				logger:         slog.Default(),
+			},
+			translator: tr,
+		}
+		resp, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.NoError(t, err)
+		require.Equal(t, override, resp.ModeOverride)
+		commonRes := resp.Response.(*extprocv3.ProcessingResponse_RequestBody).RequestBody.Response
+		require.Equal(t, headerMut, commonRes.HeaderMutation)
+		require.Equal(t, bodyMut, commonRes.BodyMutation)
+		require.True(t, commonRes.ClearRouteCache)
+
+		hdrs := headerMut.SetHeaders
+		require.Len(t, hdrs, 2)
+		require.Equal(t, "x-ai-gateway-model-key", hdrs[0].Header.Key)
+		require.Equal(t, "arn:aws:bedrock:model", string(hdrs[0].Header.RawValue))
+		require.Equal(t, "x-ai-gateway-backend-key", hdrs[1].Header.Key)
+		require.Equal(t, "some-backend", string(hdrs[1].Header.RawValue))
+	})
+}
+
+func TestConverse_ProcessResponseBody(t *testing.T) {
+	t.Run("error translation", func(t *testing.T) {
+		tr := &mockConverseTranslator{t: t, retErr: errors.New("test error")}
+		c := &converseProcessor{translator: tr}
+		_, err := c.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{})
+		require.ErrorContains(t, err, "test error")
+	})
+	t.Run("ok", func(t *testing.T) {
+		inBody := &extprocv3.HttpBody{Body: []byte("some-body"), EndOfStream: true}
+		expBodyMut := &extprocv3.BodyMutation{}
+		expHeadMut := &extprocv3.HeaderMutation{}
+		tr := &mockConverseTranslator{
+			t: t, expResponseBody: inBody,
+			retBodyMutation: expBodyMut, retHeaderMutation: expHeadMut,
+			retUsedToken: translator.LLMTokenUsage{OutputTokens: 123, InputTokens: 1},
+		}
+		c := &converseProcessor{
+			llmProcessor: llmProcessor{
+				logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config: &processorConfig{
+					metadataNamespace: "ai_gateway_llm_ns",
+					requestCosts: []processorConfigRequestCost{
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeOutputToken, MetadataKey: "output_token_usage"}},
+					},
+				},
+			}, translator: tr,
+		}
+		res, err := c.ProcessResponseBody(t.Context(), inBody)
+		require.NoError(t, err)
+		commonRes := res.Response.(*extprocv3.ProcessingResponse_ResponseBody).ResponseBody.Response
+		require.Equal(t, expBodyMut, commonRes.BodyMutation)
+		require.Equal(t, expHeadMut, commonRes.HeaderMutation)
+		require.Equal(t, float64(123), res.DynamicMetadata.Fields["ai_gateway_llm_ns"].
+			GetStructValue().Fields["output_token_usage"].GetNumberValue())
+	})
+	t.Run("estimated usage", func(t *testing.T) {
+		const reqBody = `{"messages":[{"role":"user","content":[{"text":"hello"}]}]}`
+		headers := map[string]string{":path": "/model/some-model/converse-stream"}
+		var body awsbedrock.ConverseInput
+		require.NoError(t, json.Unmarshal([]byte(reqBody), &body))
+		body.ModelID = ptr.To("some-model")
+		c := &converseProcessor{
+			llmProcessor: llmProcessor{
+				logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config: &processorConfig{
+					router:            mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"},
+					metadataNamespace: "ai_gateway_llm_ns",
+					requestCosts: []processorConfigRequestCost{
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+					},
+				},
+				requestHeaders: headers,
+			},
+			translator: mockConverseTranslator{t: t, expRequestBody: &body, expStream: true},
+		}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(reqBody)})
+		require.NoError(t, err)
+		c.responseHeaders = map[string]string{":status": "200"}
+
+		// The input is estimated as 3 tokens priming the reply, 3 tokens of the message overhead and 2 tokens of "hello",
+		// and each "Hello" of the event stream as 2 tokens.
+		enc := eventstream.NewEncoder()
+		for i, exp := range []float64{8 + 2, 8 + 2 + 2} {
+			var chunk bytes.Buffer
+			require.NoError(t, enc.Encode(&chunk, eventstream.Message{Payload: []byte(`{"contentBlockIndex":0,"delta":{"text":"Hello"}}`)}))
+			res, err := c.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{Body: chunk.Bytes()})
+			require.NoError(t, err, i)
+			fields := res.DynamicMetadata.Fields["ai_gateway_llm_ns"].GetStructValue().Fields
+			require.Equal(t, exp, fields["total"].GetNumberValue(), i)
+			require.True(t, fields[usageEstimatedMetadataKey].GetBoolValue(), i)
+		}
+	})
+	t.Run("terminated stream", func(t *testing.T) {
+		config := &processorConfig{
+			metadataNamespace: "ai_gateway_llm_ns",
+			requestCosts: []processorConfigRequestCost{
+				{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+			},
+			tokenBudgets: newTokenBudgets([]filterapi.TokenBudget{{
+				Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser},
+				MetadataKey: "total", Limit: 1000, Window: time.Hour,
+			}}, nil, nil),
+		}
+		c := &converseProcessor{
+			llmProcessor: llmProcessor{
+				logger:          slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				requestHeaders:  map[string]string{router.UserKey: "alice"},
+				responseHeaders: map[string]string{":status": "200"},
+				costs:           requestUsage{stream: true},
+				config:          config,
+			},
+			translator: &mockConverseTranslator{t: t, retUsedToken: translator.LLMTokenUsage{InputTokens: 5, OutputTokens: 7, TotalTokens: 12}},
+		}
+		res, err := c.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{Body: []byte("some-chunk")})
+		require.NoError(t, err)
+		require.Equal(t, float64(12), res.DynamicMetadata.Fields["ai_gateway_llm_ns"].
+			GetStructValue().Fields["total"].GetNumberValue())
+		require.Zero(t, config.tokenBudgets[0].consumption.consumed("alice"))
+
+		// The client disconnects without the end of the stream, and the costs so far are consumed once.
+		require.NoError(t, c.Close())
+		require.NoError(t, c.Close())
+		require.Equal(t, uint64(12), config.tokenBudgets[0].consumption.consumed("alice"))
+	})
+	t.Run("terminated stream without usage", func(t *testing.T) {
+		const reqBody = `{"messages":[{"role":"user","content":[{"text":"hello"}]}]}`
+		headers := map[string]string{":path": "/model/some-model/converse-stream", router.UserKey: "alice"}
+		var body awsbedrock.ConverseInput
+		require.NoError(t, json.Unmarshal([]byte(reqBody), &body))
+		body.ModelID = ptr.To("some-model")
+		config := &processorConfig{
+			router:            mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"},
+			metadataNamespace: "ai_gateway_llm_ns",
+			requestCosts: []processorConfigRequestCost{
+				{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+			},
+			tokenBudgets: newTokenBudgets([]filterapi.TokenBudget{{
+				Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser},
+				MetadataKey: "total", Limit: 1000, Window: time.Hour,
+			}}, nil, nil),
+		}
+		c := &converseProcessor{
+			llmProcessor: llmProcessor{
+				logger:         slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config:         config,
+				requestHeaders: headers,
+			},
+			translator: mockConverseTranslator{t: t, expRequestBody: &body, expStream: true},
+		}
+		_, err := c.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(reqBody)})
+		require.NoError(t, err)
+		c.responseHeaders = map[string]string{":status": "200"}
+
+		// The estimated costs so far are sent with each event, where the input is estimated as 8 tokens
+		// and the text "Hello world!" split across the two events as 2 tokens each.
+		var stream bytes.Buffer
+		enc := eventstream.NewEncoder()
+		for _, payload := range []string{
+			`{"contentBlockIndex":0,"delta":{"text":"Hello"}}`,
+			`{"contentBlockIndex":0,"delta":{"text":" world!"}}`,
+		} {
+			require.NoError(t, enc.Encode(&stream, eventstream.Message{Payload: []byte(payload)}))
+		}
+		// The chunks of the response are not aligned with the events.
+		chunks := [][]byte{stream.Bytes()[:10], stream.Bytes()[10:]}
+		for i, exp := range []float64{8, 8 + 2 + 2} {
+			res, err := c.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{Body: chunks[i]})
+			require.NoError(t, err, i)
+			fields := res.DynamicMetadata.Fields["ai_gateway_llm_ns"].GetStructValue().Fields
+			require.Equal(t, exp, fields["total"].GetNumberValue(), i)
+			require.True(t, fields[usageEstimatedMetadataKey].GetBoolValue(), i)
+		}
+		require.Zero(t, config.tokenBudgets[0].consumption.consumed("alice"))
+
+		// The client disconnects without the end of the stream, and the estimated costs so far are consumed once.
+		require.NoError(t, c.Close())
+		require.NoError(t, c.Close())
+		require.Equal(t, uint64(12), config.tokenBudgets[0].consumption.consumed("alice"))
+	})
+}
+
+func TestConverse_ParsePath(t *testing.T) {
+	for _, tc := range []struct {
+		path      string
+		expModel  string
+		expStream bool
+		expErr    string
+	}{
+		{path: "/model/some-model/converse", expModel: "some-model"},
+		{path: "/model/some-model/converse-stream", expModel: "some-model", expStream: true},
+		{path: "/model/arn%3Aaws%3Abedrock%3Afoo%2Fbar/converse", expModel: "arn:aws:bedrock:foo/bar"},
+		{path: "/model//converse", expErr: "missing model ID in path: /model//converse"},
+		{path: "/model/%zz/converse", expErr: `invalid model ID "%zz"`},
+		{path: "/model/some-model/invoke", expErr: "invalid path: /model/some-model/invoke"},
+		{path: "/v1/chat/completions", expErr: "invalid path: /v1/chat/completions"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			model, stream, err := parseConversePath(tc.path)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expModel, model)
+			require.Equal(t, tc.expStream, stream)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+// NewEmbeddingsProcessor implements [Processor] for the /embeddings endpoint.
+func NewEmbeddingsProcessor(config *processorConfig, requestHeaders map[string]string, logger *slog.Logger) (Processor, error) {
+	if config.schema.Name != filterapi.APISchemaOpenAI {
+		return nil, fmt.Errorf("unsupported API schema: %s", config.schema.Name)
+	}
+	return &embeddingsProcessor{llmProcessor: llmProcessor{
+		config:         config,
+		requestHeaders: requestHeaders,
+		logger:         logger,
+	}}, nil
+}
+
+// embeddingsProcessor handles the processing of the request and response messages for a single stream.
+type embeddingsProcessor struct {
+	llmProcessor
+	translator translator.OpenAIEmbeddingTranslator
+	// requestBody is kept to translate the request for the fallbacks.
+	requestBody *openai.EmbeddingRequest
+}
+
+// selectTranslator selects the translator based on the output schema and the model name override of the backend.
+func (e *embeddingsProcessor) selectTranslator(out filterapi.VersionedAPISchema, modelNameOverride string) (err error) {
+	if e.translator != nil { // Prevents re-selection and allows translator injection in tests.
+		return nil
+	}
+	e.translator, err = newEmbeddingTranslator(out, modelNameOverride)
+	return
+}
+
+// newEmbeddingTranslator creates the translator for the output schema.
+func newEmbeddingTranslator(out filterapi.VersionedAPISchema, modelNameOverride string) (translator.OpenAIEmbeddingTranslator, error) {
+	switch out.Name {
+	case filterapi.APISchemaOpenAI:
+		return translator.NewEmbeddingOpenAIToOpenAITranslator(modelNameOverride), nil
+	case filterapi.APISchemaAWSBedrock:
+		return translator.NewEmbeddingOpenAIToAWSBedrockTranslator(modelNameOverride), nil
+	default:
+		return nil, fmt.Errorf("unsupported API schema for embeddings: backend={%s %s}", out.Name, out.Version)
+	}
+}
+
+// ProcessRequestHeaders implements [Processor.ProcessRequestHeaders].
+func (e *embeddingsProcessor) ProcessRequestHeaders(context.Context, *corev3.HeaderMap) (res *extprocv3.ProcessingResponse, err error) {
+	// The request headers have already been at the time the processor was created.
+	return authenticateAPIKey(e.config, e.requestHeaders, e.logger)
+}
+
+// ProcessRequestBody implements [Processor.ProcessRequestBody].
+func (e *embeddingsProcessor) ProcessRequestBody(ctx context.Context, rawBody *extprocv3.HttpBody) (res *extprocv3.ProcessingResponse, err error) {
+	model, body, err := parseOpenAIEmbeddingBody(rawBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request body: %w", err)
+	}
+	e.logger.Info("Processing request", "path", e.requestHeaders[":path"], "model", model)
+
+	b, immediateResp, err := selectBackend(e.config, e.requestHeaders, x.Request{Model: model, User: body.User})
+	if err != nil || immediateResp != nil {
+		return immediateResp, err
+	}
+	e.backendSelected(b, rawBody.Body, false)
+	e.requestBody, e.costs.user = body, body.User
+	// The embeddings do not have any output tokens.
+	e.initUsageEstimator(false, responseOutput{}, func(t tokenizer.Tokenizer) int {
+		return tokenizer.EstimatePromptTokens(t, body.Input)
+	})
+
+	if err = e.selectTranslator(b.Schema, b.ModelNameOverride); err != nil {
+		return nil, fmt.Errorf("failed to select translator: %w", err)
+	}
+
+	headerMutation, bodyMutation, err := e.translator.RequestBody(rawBody.Body, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform request: %w", err)
+	}
+	headerMutation, err = setBackendHeadersAndAuth(ctx, e.config, e.requestHeaders, model, b.Name, headerMutation, bodyMutation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &extprocv3.ProcessingResponse{
+		Response: &extprocv3.ProcessingResponse_RequestBody{
+			RequestBody: &extprocv3.BodyResponse{
+				Response: &extprocv3.CommonResponse{
+					HeaderMutation:  headerMutation,
+					BodyMutation:    bodyMutation,
+					ClearRouteCache: true,
+				},
+			},
+		},
+	}, nil
+}
+
+// ProcessResponseHeaders implements [Processor.ProcessResponseHeaders].
+func (e *embeddingsProcessor) ProcessResponseHeaders(ctx context.Context, headers *corev3.HeaderMap) (res *extprocv3.ProcessingResponse, err error) {
+	// The embeddings translators do not mutate the response headers.
+	return e.processResponseHeaders(ctx, headers, nil, e.translateForFallback)
+}
+
+// ProcessResponseBody implements [Processor.ProcessResponseBody].
+func (e *embeddingsProcessor) ProcessResponseBody(_ context.Context, body *extprocv3.HttpBody) (res *extprocv3.ProcessingResponse, err error) {
+	// The translator can be nil as there could be response event generated by previous ext proc without
+	// getting the request event.
+	var tr fallbackTranslator
+	if e.translator != nil {
+		tr = embeddingFallbackTranslator{e.translator}
+	}
+	return e.processResponseBody(body, tr)
+}
+
+func parseOpenAIEmbeddingBody(body *extprocv3.HttpBody) (modelName string, rb *openai.EmbeddingRequest, err error) {
+	var openAIReq openai.EmbeddingRequest
+	if err := json.Unmarshal(body.Body, &openAIReq); err != nil {
+		return "", nil, fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+	return openAIReq.Model, &openAIReq, nil
+}
+
+// translateForFallback implements [fallbackTranslateFn].
+func (e *embeddingsProcessor) translateForFallback(b *filterapi.Backend) (fallbackTranslator, *extprocv3.HeaderMutation, *extprocv3.BodyMutation, error) {
+	tr, err := newEmbeddingTranslator(b.Schema, b.ModelNameOverride)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	headerMutation, bodyMutation, err := tr.RequestBody(e.rawRequestBody, e.requestBody)
+	return embeddingFallbackTranslator{tr}, headerMutation, bodyMutation, err
+}
+
+// embeddingFallbackTranslator adapts [translator.OpenAIEmbeddingTranslator] to [fallbackTranslator], which is also used
+// to process the response of the selected backend.
+type embeddingFallbackTranslator struct {
+	translator.OpenAIEmbeddingTranslator
+}
+
+// ResponseHeaders implements [fallbackTranslator.ResponseHeaders].
+func (embeddingFallbackTranslator) ResponseHeaders(map[string]string) (*extprocv3.HeaderMutation, error) {
+	return nil, nil
+}
+
+// ResponseBody implements [fallbackTranslator.ResponseBody].
+func (e embeddingFallbackTranslator) ResponseBody(respHeaders map[string]string, body io.Reader, _ bool) (
+	*extprocv3.HeaderMutation, *extprocv3.BodyMutation, translator.LLMTokenUsage, error,
+) {
+	return e.OpenAIEmbeddingTranslator.ResponseBody(respHeaders, body)
+}
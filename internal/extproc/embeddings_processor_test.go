@@ -0,0 +1,210 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+func TestEmbeddings_Schema(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		cfg := &processorConfig{schema: filterapi.VersionedAPISchema{Name: "Foo", Version: "v123"}}
+		_, err := NewEmbeddingsProcessor(cfg, nil, nil)
+		require.ErrorContains(t, err, "unsupported API schema: Foo")
+	})
+	t.Run("supported openai", func(t *testing.T) {
+		cfg := &processorConfig{schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}}
+		_, err := NewEmbeddingsProcessor(cfg, nil, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestEmbeddings_SelectTranslator(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		e := &embeddingsProcessor{}
+		err := e.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaAnthropic}, "")
+		require.ErrorContains(t, err, "unsupported API schema for embeddings: backend={Anthropic }")
+	})
+	t.Run("supported openai", func(t *testing.T) {
+		e := &embeddingsProcessor{}
+		err := e.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}, "")
+		require.NoError(t, err)
+		require.NotNil(t, e.translator)
+	})
+	t.Run("supported aws bedrock", func(t *testing.T) {
+		e := &embeddingsProcessor{}
+		err := e.selectTranslator(filterapi.VersionedAPISchema{Name: filterapi.APISchemaAWSBedrock}, "")
+		require.NoError(t, err)
+		require.NotNil(t, e.translator)
+	})
+}
+
+func TestEmbeddings_ProcessRequestBody(t *testing.T) {
+	const someBody = `{"model": "some-model", "input": "hello"}`
+	t.Run("body parser error", func(t *testing.T) {
+		e := &embeddingsProcessor{}
+		_, err := e.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte("nonjson")})
+		require.ErrorContains(t, err, "failed to parse request body")
+	})
+	t.Run("router error 404", func(t *testing.T) {
+		headers := map[string]string{":path": "/v1/embeddings"}
+		rt := mockRouter{t: t, expHeaders: headers, retErr: x.ErrNoMatchingRule}
+		e := &embeddingsProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}}
+		resp, err := e.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.NoError(t, err)
+		ir := resp.GetImmediateResponse()
+		require.NotNil(t, ir)
+		require.Equal(t, typev3.StatusCode_NotFound, ir.GetStatus().GetCode())
+	})
+	t.Run("translator error", func(t *testing.T) {
+		headers := map[string]string{":path": "/v1/embeddings"}
+		rt := mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"}
+		var body openai.EmbeddingRequest
+		require.NoError(t, json.Unmarshal([]byte(someBody), &body))
+		tr := mockEmbeddingTranslator{t: t, retErr: errors.New("test error"), expRequestBody: &body}
+		e := &embeddingsProcessor{llmProcessor: llmProcessor{config: &processorConfig{router: rt}, requestHeaders: headers, logger: slog.Default()}, translator: tr}
+		_, err := e.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.ErrorContains(t, err, "failed to transform request: test error")
+	})
+	t.Run("ok", func(t *testing.T) {
+		headers := map[string]string{":path": "/v1/embeddings"}
+		rt := mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"}
+		headerMut := &extprocv3.HeaderMutation{}
+		bodyMut := &extprocv3.BodyMutation{}
+		var body openai.EmbeddingRequest
+		require.NoError(t, json.Unmarshal([]byte(someBody), &body))
+		tr := mockEmbeddingTranslator{t: t, expRequestBody: &body, retHeaderMutation: headerMut, retBodyMutation: bodyMut}
+		e := &embeddingsProcessor{
+			llmProcessor: llmProcessor{
+				config: &processorConfig{
+					router:                   rt,
+					selectedBackendHeaderKey: "x-ai-gateway-backend-key",
+					modelNameHeaderKey:       "x-ai-gateway-model-key",
+				},
+				requestHeaders: headers,
+				logger:         slog.Default(),
+			},
+			translator: tr,
+		}
+		resp, err := e.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(someBody)})
+		require.NoError(t, err)
+		commonRes := resp.Response.(*extprocv3.ProcessingResponse_RequestBody).RequestBody.Response
+		require.Equal(t, headerMut, commonRes.HeaderMutation)
+		require.Equal(t, bodyMut, commonRes.BodyMutation)
+		require.True(t, commonRes.ClearRouteCache)
+
+		hdrs := headerMut.SetHeaders
+		require.Len(t, hdrs, 2)
+		require.Equal(t, "x-ai-gateway-model-key", hdrs[0].Header.Key)
+		require.Equal(t, "some-model", string(hdrs[0].Header.RawValue))
+		require.Equal(t, "x-ai-gateway-backend-key", hdrs[1].Header.Key)
+		require.Equal(t, "some-backend", string(hdrs[1].Header.RawValue))
+	})
+}
+
+func TestEmbeddings_ProcessResponseBody(t *testing.T) {
+	t.Run("error translation", func(t *testing.T) {
+		tr := &mockEmbeddingTranslator{t: t, retErr: errors.New("test error")}
+		e := &embeddingsProcessor{translator: tr}
+		_, err := e.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{})
+		require.ErrorContains(t, err, "test error")
+	})
+	t.Run("ok", func(t *testing.T) {
+		inBody := &extprocv3.HttpBody{Body: []byte("some-body"), EndOfStream: true}
+		expBodyMut := &extprocv3.BodyMutation{}
+		expHeadMut := &extprocv3.HeaderMutation{}
+		tr := &mockEmbeddingTranslator{
+			t: t, expResponseBody: inBody,
+			retBodyMutation: expBodyMut, retHeaderMutation: expHeadMut,
+			retUsedToken: translator.LLMTokenUsage{InputTokens: 42, TotalTokens: 42},
+		}
+		e := &embeddingsProcessor{
+			llmProcessor: llmProcessor{
+				logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config: &processorConfig{
+					metadataNamespace: "ai_gateway_llm_ns",
+					requestCosts: []processorConfigRequestCost{
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeInputToken, MetadataKey: "input_token_usage"}},
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total_token_usage"}},
+					},
+				},
+			},
+			translator: tr,
+		}
+		res, err := e.ProcessResponseBody(t.Context(), inBody)
+		require.NoError(t, err)
+		commonRes := res.Response.(*extprocv3.ProcessingResponse_ResponseBody).ResponseBody.Response
+		require.Equal(t, expBodyMut, commonRes.BodyMutation)
+		require.Equal(t, expHeadMut, commonRes.HeaderMutation)
+
+		md := res.DynamicMetadata
+		require.NotNil(t, md)
+		require.Equal(t, float64(42), md.Fields["ai_gateway_llm_ns"].
+			GetStructValue().Fields["input_token_usage"].GetNumberValue())
+		require.Equal(t, float64(42), md.Fields["ai_gateway_llm_ns"].
+			GetStructValue().Fields["total_token_usage"].GetNumberValue())
+	})
+	t.Run("estimated usage", func(t *testing.T) {
+		const reqBody = `{"model": "some-model", "input": ["Hello", "world!"]}`
+		headers := map[string]string{":path": "/v1/embeddings"}
+		var body openai.EmbeddingRequest
+		require.NoError(t, json.Unmarshal([]byte(reqBody), &body))
+		e := &embeddingsProcessor{
+			llmProcessor: llmProcessor{
+				logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+				config: &processorConfig{
+					router:            mockRouter{t: t, expHeaders: headers, retBackendName: "some-backend"},
+					metadataNamespace: "ai_gateway_llm_ns",
+					requestCosts: []processorConfigRequestCost{
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeInputToken, MetadataKey: "input"}},
+						{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeOutputToken, MetadataKey: "output"}},
+					},
+				},
+				requestHeaders: headers,
+			},
+			translator: mockEmbeddingTranslator{t: t, expRequestBody: &body},
+		}
+		_, err := e.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(reqBody)})
+		require.NoError(t, err)
+		e.responseHeaders = map[string]string{":status": "200"}
+
+		// "Hello" and "world!" are estimated as 2 tokens each, and the embeddings do not have any output tokens.
+		res, err := e.ProcessResponseBody(t.Context(), &extprocv3.HttpBody{
+			Body: []byte(`{"object":"list","data":[{"object":"embedding","embedding":[0.1],"index":0}]}`), EndOfStream: true,
+		})
+		require.NoError(t, err)
+		fields := res.DynamicMetadata.Fields["ai_gateway_llm_ns"].GetStructValue().Fields
+		require.Equal(t, float64(2+2), fields["input"].GetNumberValue())
+		require.Zero(t, fields["output"].GetNumberValue())
+		require.True(t, fields[usageEstimatedMetadataKey].GetBoolValue())
+	})
+}
+
+func TestEmbeddings_ParseBody(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		modelName, rb, err := parseOpenAIEmbeddingBody(&extprocv3.HttpBody{Body: []byte(`{"model":"text-embedding-3-small","input":["a","b"]}`)})
+		require.NoError(t, err)
+		require.Equal(t, "text-embedding-3-small", modelName)
+		require.Equal(t, []string{"a", "b"}, rb.Input.Value)
+	})
+	t.Run("error", func(t *testing.T) {
+		_, _, err := parseOpenAIEmbeddingBody(&extprocv3.HttpBody{Body: []byte("nonjson")})
+		require.ErrorContains(t, err, "failed to unmarshal body")
+	})
+}
@@ -0,0 +1,278 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+const (
+	// backendDialTimeout bounds the connection and the TLS handshake to the fallback and mirror backends.
+	backendDialTimeout = 10 * time.Second
+	// backendRequestTimeout bounds the whole request to the fallback and mirror backends including reading the
+	// response body, on top of the deadline of the context of each request.
+	backendRequestTimeout = 5 * time.Minute
+)
+
+// defaultBackendHTTPClient is the HTTP client used for the fallback and mirror backends without the TLS configuration.
+var defaultBackendHTTPClient = mustNewBackendHTTPClient(nil)
+
+// newBackendHTTPClient creates the HTTP client used to send the requests directly to the fallback and mirror
+// backends, which connects to them over TLS with the given configuration when it is not nil.
+func newBackendHTTPClient(tlsConfig *filterapi.BackendTLS) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: backendDialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = backendDialTimeout
+	if tlsConfig != nil {
+		transport.TLSClientConfig = &tls.Config{ServerName: tlsConfig.Hostname, MinVersion: tls.VersionTLS12}
+		if tlsConfig.CACertificates != "" {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(tlsConfig.CACertificates)) {
+				return nil, errors.New("no valid CA certificate found")
+			}
+			transport.TLSClientConfig.RootCAs = pool
+		}
+	}
+	return &http.Client{Transport: transport, Timeout: backendRequestTimeout}, nil
+}
+
+func mustNewBackendHTTPClient(tlsConfig *filterapi.BackendTLS) *http.Client {
+	c, err := newBackendHTTPClient(tlsConfig)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// backendHTTPClient returns the HTTP client to send the requests directly to the backend b.
+func backendHTTPClient(config *processorConfig, b *filterapi.Backend) *http.Client {
+	if c, ok := config.backendHTTPClients[b.Name]; ok {
+		return c
+	}
+	return defaultBackendHTTPClient
+}
+
+// snapshotRequestHeaders returns the copy of the request headers with the lowercase keys. This is taken before the
+// auth of the selected backend is applied so that its credentials are not sent to the fallback and mirror backends.
+func snapshotRequestHeaders(requestHeaders map[string]string) map[string]string {
+	headers := make(map[string]string, len(requestHeaders))
+	for k, v := range requestHeaders {
+		headers[strings.ToLower(k)] = v
+	}
+	return headers
+}
+
+// fallbacksOf returns the fallbacks of the selected backend b. The streamed requests are never retried on the fallbacks
+// since the response of the fallback is buffered into the immediate response, so the failure of the selected backend
+// is sent to the client as is. This is documented in AIGatewayRouteRule.Fallbacks.
+func fallbacksOf(config *processorConfig, logger *slog.Logger, b *filterapi.Backend, stream bool) []filterapi.Backend {
+	fallbacks := config.fallbacks[b]
+	if stream && len(fallbacks) > 0 {
+		logger.Info("Skipping fallbacks for the streamed request", "backend", b.Name)
+		return nil
+	}
+	return fallbacks
+}
+
+// fallbackTranslator translates the response of a fallback backend. This is the common subset of the translators
+// of the processors supporting the fallback.
+type fallbackTranslator interface {
+	ResponseHeaders(headers map[string]string) (*extprocv3.HeaderMutation, error)
+	ResponseBody(respHeaders map[string]string, body io.Reader, endOfStream bool) (
+		*extprocv3.HeaderMutation, *extprocv3.BodyMutation, translator.LLMTokenUsage, error,
+	)
+}
+
+// fallbackTranslateFn creates the translator for the fallback backend and translates the original request for it.
+type fallbackTranslateFn func(b *filterapi.Backend) (fallbackTranslator, *extprocv3.HeaderMutation, *extprocv3.BodyMutation, error)
+
+// shouldFallback returns true if the fallback backends are configured and the response headers from the
+// selected backend indicate the failure that should be retried on them.
+func shouldFallback(fallbacks []filterapi.Backend, responseHeaders map[string]string) bool {
+	return len(fallbacks) > 0 && isBackendFailure(responseHeaders)
+}
+
+// fallback sends the original request to the fallback backends in order, translating and authenticating it for each
+// of them, until one of them responds with the status code that is not retried or all of them have been tried.
+// Each request is created from originalHeaders, the snapshot of the request headers taken by [snapshotRequestHeaders],
+// so that the auth of the selected backend and of the previous fallbacks is not carried over.
+//
+// Each attempt is observed as the request sent to the fallback, so that its result is taken into account by the
+// adaptive load balancing and the circuit breaker of the backend.
+//
+// The translated response of the last attempted fallback is returned as the immediate response with the token
+// usage accumulated into costs. When none of the fallbacks could be reached, this returns nil so that the
+// original response is processed as usual.
+func fallback(ctx context.Context, config *processorConfig, logger *slog.Logger, requestHeaders, originalHeaders map[string]string,
+	rawBody []byte, fallbacks []filterapi.Backend, costs *requestUsage, translate fallbackTranslateFn,
+) (*extprocv3.ProcessingResponse, error) {
+	model := originalHeaders[config.modelNameHeaderKey]
+	for i := range fallbacks {
+		b := &fallbacks[i]
+		tr, headerMutation, bodyMutation, err := translate(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform request for fallback %s: %w", b.Name, err)
+		}
+		headers := maps.Clone(originalHeaders)
+		headerMutation, err = setBackendHeadersAndAuth(ctx, config, headers, model, b.Name, headerMutation, bodyMutation)
+		if err != nil {
+			return nil, err
+		}
+		body := rawBody
+		if bodyMutation != nil {
+			body = bodyMutation.GetBody()
+		}
+		req, err := newBackendRequest(ctx, b.Endpoint, headers, headerMutation, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request for fallback %s: %w", b.Name, err)
+		}
+
+		logger.Info("Falling back", "backend", b.Name)
+		observation := observeBackend(config, b)
+		resp, err := backendHTTPClient(config, b).Do(req)
+		if err != nil {
+			observation.Done(true)
+			logger.Error("failed to send request to fallback", "backend", b.Name, "error", err)
+			continue
+		}
+		observation.FirstToken()
+		respBody, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		observation.Done(err != nil || isBackendFailureStatus(resp.StatusCode))
+		if err != nil {
+			logger.Error("failed to read response from fallback", "backend", b.Name, "error", err)
+			continue
+		}
+		if isBackendFailureStatus(resp.StatusCode) && i < len(fallbacks)-1 {
+			logger.Info("Fallback failed", "backend", b.Name, "status", resp.StatusCode)
+			continue
+		}
+		// The cost calculation refers to the selected backend in the request headers.
+		requestHeaders[config.selectedBackendHeaderKey] = b.Name
+		costs.schema = b.Schema.Name
+		return fallbackImmediateResponse(config, logger, requestHeaders, costs, tr, resp, respBody)
+	}
+	return nil, nil
+}
+
+// newBackendRequest creates the HTTP request to the endpoint from the original request headers with the header
+// mutation applied. The pseudo headers other than :method and :path as well as the hop-by-hop headers are dropped.
+//
+// The header keys are lowercased before the mutation is applied so that the mutation deterministically replaces
+// the original header regardless of the case of the keys.
+func newBackendRequest(ctx context.Context, endpoint string, requestHeaders map[string]string,
+	headerMutation *extprocv3.HeaderMutation, body []byte,
+) (*http.Request, error) {
+	headers := snapshotRequestHeaders(requestHeaders)
+	for _, h := range headerMutation.GetSetHeaders() {
+		headers[strings.ToLower(h.Header.Key)] = headerValue(h.Header)
+	}
+	for _, k := range headerMutation.GetRemoveHeaders() {
+		delete(headers, strings.ToLower(k))
+	}
+	method := headers[":method"]
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(endpoint, "/")+headers[":path"], bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		switch k {
+		// The content-length is set from the body, and the encoding is negotiated by the client.
+		case "host", "content-length", "accept-encoding", "connection", "transfer-encoding":
+			continue
+		}
+		if strings.HasPrefix(k, ":") {
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+// fallbackImmediateResponse translates the response of the fallback backend into the immediate response.
+func fallbackImmediateResponse(config *processorConfig, logger *slog.Logger, requestHeaders map[string]string,
+	costs *requestUsage, tr fallbackTranslator, resp *http.Response, body []byte,
+) (*extprocv3.ProcessingResponse, error) {
+	responseHeaders := map[string]string{":status": strconv.Itoa(resp.StatusCode)}
+	for k := range resp.Header {
+		responseHeaders[strings.ToLower(k)] = resp.Header.Get(k)
+	}
+	headerMutation, err := tr.ResponseHeaders(responseHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform response headers: %w", err)
+	}
+	bodyHeaderMutation, bodyMutation, tokenUsage, err := tr.ResponseBody(responseHeaders, bytes.NewReader(body), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform response: %w", err)
+	}
+	if bodyMutation != nil {
+		body = bodyMutation.GetBody()
+	}
+
+	headers := maps.Clone(responseHeaders)
+	for _, h := range append(headerMutation.GetSetHeaders(), bodyHeaderMutation.GetSetHeaders()...) {
+		headers[h.Header.Key] = headerValue(h.Header)
+	}
+	immediate := &extprocv3.ImmediateResponse{
+		Status:  &typev3.HttpStatus{Code: typev3.StatusCode(resp.StatusCode)}, //nolint:gosec
+		Headers: &extprocv3.HeaderMutation{},
+		Body:    body,
+	}
+	for _, k := range slices.Sorted(maps.Keys(headers)) {
+		switch k {
+		// The response body is already decoded by the HTTP client, and Envoy sets the length of the immediate response.
+		case "content-length", "content-encoding", "transfer-encoding", "connection":
+			continue
+		}
+		if strings.HasPrefix(k, ":") {
+			continue
+		}
+		immediate.Headers.SetHeaders = append(immediate.Headers.SetHeaders, &corev3.HeaderValueOption{
+			Header: &corev3.HeaderValue{Key: k, RawValue: []byte(headers[k])},
+		})
+	}
+
+	res := &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_ImmediateResponse{ImmediateResponse: immediate}}
+	costs.add(tokenUsage)
+	if len(config.requestCosts) > 0 {
+		if res.DynamicMetadata, err = buildDynamicMetadata(config, costs, requestHeaders, logger, true); err != nil {
+			return nil, fmt.Errorf("failed to build dynamic metadata: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// headerValue returns the value of the header which is set in either Value or RawValue.
+func headerValue(h *corev3.HeaderValue) string {
+	if len(h.RawValue) > 0 {
+		return string(h.RawValue)
+	}
+	return h.Value
+}
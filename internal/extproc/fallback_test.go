@@ -0,0 +1,275 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"encoding/pem"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+func Test_shouldFallback(t *testing.T) {
+	fallbacks := []filterapi.Backend{{Name: "foo"}}
+	for _, tc := range []struct {
+		status    string
+		fallbacks []filterapi.Backend
+		exp       bool
+	}{
+		{status: "200", fallbacks: fallbacks},
+		{status: "400", fallbacks: fallbacks},
+		{status: "429", fallbacks: fallbacks, exp: true},
+		{status: "500", fallbacks: fallbacks, exp: true},
+		{status: "503", fallbacks: fallbacks, exp: true},
+		{status: "503"},
+		{status: "", fallbacks: fallbacks},
+	} {
+		t.Run(tc.status, func(t *testing.T) {
+			require.Equal(t, tc.exp, shouldFallback(tc.fallbacks, map[string]string{":status": tc.status}))
+		})
+	}
+}
+
+func Test_fallbacksOf(t *testing.T) {
+	b := &filterapi.Backend{Name: "primary"}
+	config := &processorConfig{fallbacks: map[*filterapi.Backend][]filterapi.Backend{b: {{Name: "foo"}}}}
+	require.Equal(t, []filterapi.Backend{{Name: "foo"}}, fallbacksOf(config, slog.Default(), b, false))
+	// The streamed requests are not retried on the fallbacks.
+	require.Nil(t, fallbacksOf(config, slog.Default(), b, true))
+	require.Nil(t, fallbacksOf(config, slog.Default(), &filterapi.Backend{Name: "other"}, false))
+}
+
+func Test_newBackendHTTPClient(t *testing.T) {
+	c, err := newBackendHTTPClient(nil)
+	require.NoError(t, err)
+	require.Equal(t, backendRequestTimeout, c.Timeout)
+
+	_, err = newBackendHTTPClient(&filterapi.BackendTLS{Hostname: "example.com", CACertificates: "invalid"})
+	require.ErrorContains(t, err, "no valid CA certificate found")
+
+	t.Run("tls", func(t *testing.T) {
+		backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer backend.Close()
+		caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: backend.Certificate().Raw})
+		// The certificate of the test server is issued for example.com.
+		c, err := newBackendHTTPClient(&filterapi.BackendTLS{Hostname: "example.com", CACertificates: string(caCert)})
+		require.NoError(t, err)
+		resp, err := c.Get(backend.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// The system CA certificates do not trust the test server.
+		c, err = newBackendHTTPClient(&filterapi.BackendTLS{Hostname: "example.com"})
+		require.NoError(t, err)
+		_, err = c.Get(backend.URL)
+		require.ErrorContains(t, err, "certificate")
+	})
+}
+
+func Test_newBackendRequest(t *testing.T) {
+	req, err := newBackendRequest(t.Context(), "http://example.com/", map[string]string{
+		":method": "POST", ":path": "/v1/chat/completions", ":authority": "gateway", "host": "gateway",
+		"content-length": "3", "accept-encoding": "br", "x-foo": "foo", "x-removed": "bar", "authorization": "Bearer client",
+	}, &extprocv3.HeaderMutation{
+		SetHeaders: []*corev3.HeaderValueOption{
+			{Header: &corev3.HeaderValue{Key: ":path", RawValue: []byte("/model/foo/converse")}},
+			// The mutation replaces the original header regardless of the case of the key.
+			{Header: &corev3.HeaderValue{Key: "Authorization", Value: "Bearer key"}},
+		},
+		RemoveHeaders: []string{"X-Removed"},
+	}, []byte("body"))
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Equal(t, "http://example.com/model/foo/converse", req.URL.String())
+	require.Equal(t, http.Header{"Authorization": {"Bearer key"}, "X-Foo": {"foo"}}, req.Header)
+	require.Equal(t, int64(4), req.ContentLength)
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, "body", string(body))
+}
+
+func Test_fallback(t *testing.T) {
+	newConfig := func() *processorConfig {
+		return &processorConfig{
+			modelNameHeaderKey:       "x-model",
+			selectedBackendHeaderKey: "x-backend",
+			metadataNamespace:        "ns",
+			requestCosts: []processorConfigRequestCost{
+				{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeOutputToken, MetadataKey: "output"}},
+			},
+		}
+	}
+	translate := func(b *filterapi.Backend) (fallbackTranslator, *extprocv3.HeaderMutation, *extprocv3.BodyMutation, error) {
+		return translator.NewChatCompletionOpenAIToOpenAITranslator("", false), nil, nil, nil
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		var calls []string
+		unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "unavailable")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer unavailable.Close()
+		ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "ok")
+			require.Equal(t, "/v1/chat/completions", r.URL.Path)
+			require.Equal(t, "ok", r.Header.Get("x-backend"))
+			require.Empty(t, r.Header.Get("authorization"))
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, `{"model":"gpt"}`, string(body))
+			w.Header().Set("content-type", "application/json")
+			w.Header().Set("x-upstream", "ok")
+			_, _ = w.Write([]byte(`{"usage":{"completion_tokens":5}}`))
+		}))
+		defer ok.Close()
+
+		headers := map[string]string{":method": "POST", ":path": "/v1/chat/completions", "x-model": "gpt", "x-backend": "primary"}
+		original := snapshotRequestHeaders(headers)
+		// The credential of the selected backend set after the snapshot is not sent to the fallbacks.
+		headers["Authorization"] = "Bearer primary"
+		var costs requestUsage
+		res, err := fallback(t.Context(), newConfig(), slog.Default(), headers, original, []byte(`{"model":"gpt"}`), []filterapi.Backend{
+			{Name: "unreachable", Endpoint: "http://127.0.0.1:1"},
+			{Name: "unavailable", Endpoint: unavailable.URL},
+			{Name: "ok", Endpoint: ok.URL},
+		}, &costs, translate)
+		require.NoError(t, err)
+		require.Equal(t, []string{"unavailable", "ok"}, calls)
+		require.Equal(t, "ok", headers["x-backend"])
+		require.Equal(t, uint32(5), costs.OutputTokens)
+
+		ir := res.GetImmediateResponse()
+		require.NotNil(t, ir)
+		require.Equal(t, typev3.StatusCode_OK, ir.Status.Code)
+		require.Equal(t, `{"usage":{"completion_tokens":5}}`, string(ir.Body))
+		setHeaders := map[string]string{}
+		for _, h := range ir.Headers.SetHeaders {
+			setHeaders[h.Header.Key] = string(h.Header.RawValue)
+		}
+		require.Equal(t, "application/json", setHeaders["content-type"])
+		require.Equal(t, "ok", setHeaders["x-upstream"])
+		require.NotContains(t, setHeaders, "content-length")
+		require.Equal(t, float64(5), res.DynamicMetadata.Fields["ns"].GetStructValue().Fields["output"].GetNumberValue())
+	})
+	t.Run("last fallback fails", func(t *testing.T) {
+		unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"slow down"}}`))
+		}))
+		defer unavailable.Close()
+		var costs requestUsage
+		headers := map[string]string{":path": "/v1/chat/completions"}
+		res, err := fallback(t.Context(), newConfig(), slog.Default(), headers, headers, nil,
+			[]filterapi.Backend{{Name: "unavailable", Endpoint: unavailable.URL}}, &costs, translate)
+		require.NoError(t, err)
+		require.Equal(t, typev3.StatusCode_TooManyRequests, res.GetImmediateResponse().Status.Code)
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		var costs requestUsage
+		headers := map[string]string{":path": "/v1/chat/completions"}
+		res, err := fallback(t.Context(), newConfig(), slog.Default(), headers, headers, nil,
+			[]filterapi.Backend{{Name: "unreachable", Endpoint: "http://127.0.0.1:1"}}, &costs, translate)
+		require.NoError(t, err)
+		require.Nil(t, res)
+	})
+	t.Run("translation error", func(t *testing.T) {
+		var costs requestUsage
+		_, err := fallback(t.Context(), newConfig(), slog.Default(), map[string]string{}, map[string]string{}, nil,
+			[]filterapi.Backend{{Name: "foo"}}, &costs,
+			func(*filterapi.Backend) (fallbackTranslator, *extprocv3.HeaderMutation, *extprocv3.BodyMutation, error) {
+				return nil, nil, nil, errors.New("test error")
+			})
+		require.ErrorContains(t, err, "failed to transform request for fallback foo: test error")
+	})
+}
+
+func Test_fallback_processor(t *testing.T) {
+	var fallbackCalls []string
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fallbackCalls = append(fallbackCalls, "flaky")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer flaky.Close()
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fallbackCalls = append(fallbackCalls, "ok")
+		w.Header().Set("content-type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[]}`))
+	}))
+	defer ok.Close()
+
+	openAI := filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}
+	s, err := NewServer(slog.Default())
+	require.NoError(t, err)
+	require.NoError(t, s.LoadConfig(t.Context(), &filterapi.Config{
+		Schema:                   openAI,
+		ModelNameHeaderKey:       "x-model",
+		SelectedBackendHeaderKey: "x-backend",
+		Rules: []filterapi.RouteRule{
+			{
+				Headers:  []filterapi.HeaderMatch{{Name: "x-model", Value: "primary-model"}},
+				Backends: []filterapi.Backend{{Name: "primary", Schema: openAI}},
+				Fallbacks: []filterapi.Backend{
+					{Name: "flaky", Schema: openAI, Endpoint: flaky.URL},
+					{Name: "ok", Schema: openAI, Endpoint: ok.URL},
+				},
+			},
+			{
+				Headers:        []filterapi.HeaderMatch{{Name: "x-model", Value: "balanced-model"}},
+				Backends:       []filterapi.Backend{{Name: "flaky", Schema: openAI}, {Name: "ok", Schema: openAI}},
+				CircuitBreaker: &filterapi.CircuitBreaker{ConsecutiveFailures: 1, OpenDuration: time.Hour},
+			},
+		},
+	}))
+	throttled := &corev3.HeaderMap{Headers: []*corev3.HeaderValue{{Key: ":status", Value: "429"}}}
+
+	t.Run("streamed request is not retried", func(t *testing.T) {
+		fallbackCalls = nil
+		p, err := NewChatCompletionProcessor(s.config, map[string]string{":path": "/v1/chat/completions"}, slog.Default())
+		require.NoError(t, err)
+		_, err = p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(`{"model":"primary-model","stream":true}`)})
+		require.NoError(t, err)
+		res, err := p.ProcessResponseHeaders(t.Context(), throttled)
+		require.NoError(t, err)
+		// The 429 of the selected backend is sent to the client as is.
+		require.Nil(t, res.GetImmediateResponse())
+		require.NotNil(t, res.GetResponseHeaders())
+		require.Empty(t, fallbackCalls)
+	})
+	t.Run("fallback attempts are observed", func(t *testing.T) {
+		fallbackCalls = nil
+		p, err := NewChatCompletionProcessor(s.config, map[string]string{":path": "/v1/chat/completions"}, slog.Default())
+		require.NoError(t, err)
+		_, err = p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{Body: []byte(`{"model":"primary-model"}`)})
+		require.NoError(t, err)
+		res, err := p.ProcessResponseHeaders(t.Context(), throttled)
+		require.NoError(t, err)
+		require.Equal(t, typev3.StatusCode_OK, res.GetImmediateResponse().GetStatus().GetCode())
+		require.Equal(t, []string{"flaky", "ok"}, fallbackCalls)
+
+		// The failure of the fallback opened the circuit of the backend, so it is no longer selected.
+		for range 20 {
+			b, err := s.config.router.Calculate(map[string]string{"x-model": "balanced-model"})
+			require.NoError(t, err)
+			require.Equal(t, "ok", b.Name)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+// apiKeyNameKey is the pseudo header key of the request headers set to the name of the GatewayAPIKey the request
+// is authenticated with. Like [router.UserKey], this is never sent to the backends.
+const apiKeyNameKey = ":ai-eg-api-key"
+
+// gatewayAPIKeys are the accepted [filterapi.GatewayAPIKey]s indexed by their hashes and names.
+type gatewayAPIKeys struct {
+	byHash, byName map[string]*filterapi.GatewayAPIKey
+}
+
+// newGatewayAPIKeys creates the gatewayAPIKeys of the given config, or returns nil if the requests are not authenticated.
+func newGatewayAPIKeys(keys *filterapi.GatewayAPIKeys) *gatewayAPIKeys {
+	if keys == nil {
+		return nil
+	}
+	ret := &gatewayAPIKeys{
+		byHash: make(map[string]*filterapi.GatewayAPIKey, len(keys.Keys)),
+		byName: make(map[string]*filterapi.GatewayAPIKey, len(keys.Keys)),
+	}
+	for i := range keys.Keys {
+		k := &keys.Keys[i]
+		ret.byHash[k.Hash], ret.byName[k.Name] = k, k
+	}
+	return ret
+}
+
+// authenticateAPIKey returns the response to the request headers, which authenticates the request with the key in the
+// "Authorization: Bearer <key>" header when the GatewayAPIKeys are configured.
+//
+// On success, the name of the key is set to the requestHeaders as apiKeyNameKey, and the Authorization header is
+// removed so that the client key never reaches the backends, which get their own credentials from the backendauth
+// handlers. Otherwise, this returns the immediate response with the status code 401.
+func authenticateAPIKey(config *processorConfig, requestHeaders map[string]string, logger *slog.Logger) (*extprocv3.ProcessingResponse, error) {
+	headersResponse := &extprocv3.HeadersResponse{}
+	resp := &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_RequestHeaders{RequestHeaders: headersResponse}}
+	if config.apiKeys == nil {
+		return resp, nil
+	}
+	scheme, token, _ := strings.Cut(requestHeaders["authorization"], " ")
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return invalidAPIKeyResponse("You didn't provide an API key. You need to provide your API key in an Authorization header using Bearer auth.")
+	}
+	sum := sha256.Sum256([]byte(token))
+	key, ok := config.apiKeys.byHash[hex.EncodeToString(sum[:])]
+	if !ok {
+		return invalidAPIKeyResponse("Incorrect API key provided.")
+	}
+	if key.ExpiresAt != nil && !time.Now().Before(*key.ExpiresAt) {
+		return invalidAPIKeyResponse(fmt.Sprintf("API key expired at %s.", key.ExpiresAt.Format(time.RFC3339)))
+	}
+	logger.Info("Authenticated request", "api_key", key.Name, "owner", key.Owner)
+	delete(requestHeaders, "authorization")
+	requestHeaders[apiKeyNameKey] = key.Name
+	headersResponse.Response = &extprocv3.CommonResponse{
+		HeaderMutation: &extprocv3.HeaderMutation{RemoveHeaders: []string{"authorization"}},
+	}
+	return resp, nil
+}
+
+// allowedModel returns true if the GatewayAPIKey of the request can be used for the model. This is always true when
+// the requests are not authenticated, and false when the key of the authenticated request is no longer configured.
+func allowedModel(config *processorConfig, requestHeaders map[string]string, model string) bool {
+	if config.apiKeys == nil {
+		return true
+	}
+	key, ok := config.apiKeys.byName[requestHeaders[apiKeyNameKey]]
+	if !ok {
+		return false
+	}
+	return len(key.AllowedModels) == 0 || slices.Contains(key.AllowedModels, model)
+}
+
+// invalidAPIKeyResponse returns the immediate response with the status code 401 and the OpenAI error body.
+func invalidAPIKeyResponse(message string) (*extprocv3.ProcessingResponse, error) {
+	code := "invalid_api_key"
+	return openAIErrorResponse(typev3.StatusCode_Unauthorized, &openai.ErrorType{
+		Type: "invalid_request_error", Code: &code, Message: message,
+	})
+}
+
+// modelNotFoundResponse returns the immediate response with the status code 404 and the OpenAI error body for the model
+// that the GatewayAPIKey of the request cannot be used for, which is indistinguishable from the model that does not exist.
+func modelNotFoundResponse(model string) (*extprocv3.ProcessingResponse, error) {
+	code := "model_not_found"
+	return openAIErrorResponse(typev3.StatusCode_NotFound, &openai.ErrorType{
+		Type: "invalid_request_error", Code: &code,
+		Message: fmt.Sprintf("The model `%s` does not exist or you do not have access to it.", model),
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"testing"
+	"time"
+
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+)
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	config := &processorConfig{apiKeys: newGatewayAPIKeys(&filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{
+		{Name: "alice", Hash: hashAPIKey("sk-alice"), Owner: "alice"},
+		{Name: "expired", Hash: hashAPIKey("sk-expired"), Owner: "bob", ExpiresAt: ptr.To(time.Now().Add(-time.Minute))},
+		{Name: "expiring", Hash: hashAPIKey("sk-expiring"), Owner: "bob", ExpiresAt: ptr.To(time.Now().Add(time.Hour))},
+	}})}
+
+	t.Run("not configured", func(t *testing.T) {
+		headers := map[string]string{"authorization": "Bearer sk-unknown"}
+		res, err := authenticateAPIKey(&processorConfig{}, headers, slog.Default())
+		require.NoError(t, err)
+		require.NotNil(t, res.GetRequestHeaders())
+		require.Nil(t, res.GetRequestHeaders().Response)
+		require.Equal(t, map[string]string{"authorization": "Bearer sk-unknown"}, headers)
+	})
+	for _, key := range []string{"sk-alice", "sk-expiring"} {
+		t.Run("valid "+key, func(t *testing.T) {
+			headers := map[string]string{"authorization": "Bearer " + key, ":path": "/v1/chat/completions"}
+			res, err := authenticateAPIKey(config, headers, slog.Default())
+			require.NoError(t, err)
+			require.Equal(t, []string{"authorization"}, res.GetRequestHeaders().Response.HeaderMutation.RemoveHeaders)
+			require.Equal(t, map[string]string{":path": "/v1/chat/completions", apiKeyNameKey: config.apiKeys.byHash[hashAPIKey(key)].Name}, headers)
+		})
+	}
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		expMessage    string
+	}{
+		{name: "missing", expMessage: "You didn't provide an API key."},
+		{name: "not bearer", authorization: "Basic sk-alice", expMessage: "You didn't provide an API key."},
+		{name: "empty", authorization: "Bearer ", expMessage: "You didn't provide an API key."},
+		{name: "unknown", authorization: "Bearer sk-unknown", expMessage: "Incorrect API key provided."},
+		{name: "expired", authorization: "Bearer sk-expired", expMessage: "API key expired at "},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tc.authorization != "" {
+				headers["authorization"] = tc.authorization
+			}
+			res, err := authenticateAPIKey(config, headers, slog.Default())
+			require.NoError(t, err)
+			require.Equal(t, typev3.StatusCode_Unauthorized, res.GetImmediateResponse().Status.Code)
+			require.Contains(t, string(res.GetImmediateResponse().Body), `"code":"invalid_api_key"`)
+			require.Contains(t, string(res.GetImmediateResponse().Body), tc.expMessage)
+			require.NotContains(t, headers, apiKeyNameKey)
+		})
+	}
+}
+
+func TestAllowedModel(t *testing.T) {
+	config := &processorConfig{apiKeys: newGatewayAPIKeys(&filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{
+		{Name: "alice", Owner: "alice", AllowedModels: []string{"gpt-4o", "gpt-4o-mini"}},
+		{Name: "bob", Owner: "bob"},
+	}})}
+	alice := map[string]string{apiKeyNameKey: "alice"}
+	require.True(t, allowedModel(config, alice, "gpt-4o"))
+	require.False(t, allowedModel(config, alice, "o3"))
+	require.True(t, allowedModel(config, map[string]string{apiKeyNameKey: "bob"}, "o3"))
+	require.True(t, allowedModel(&processorConfig{}, alice, "o3"))
+	// The request without the known key is not allowed.
+	require.False(t, allowedModel(config, map[string]string{}, "gpt-4o"))
+	require.False(t, allowedModel(config, map[string]string{apiKeyNameKey: "carol"}, "gpt-4o"))
+}
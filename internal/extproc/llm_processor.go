@@ -0,0 +1,209 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
+)
+
+// llmProcessor is the state of a single stream common to the processors of the LLM endpoints, i.e. the chat completions,
+// the completions, the embeddings and the converse. It implements the processing of the response, the fallback and
+// the cost calculation shared by them, and each processor embeds it with its own translator.
+type llmProcessor struct {
+	logger           *slog.Logger
+	config           *processorConfig
+	requestHeaders   map[string]string
+	responseHeaders  map[string]string
+	responseEncoding string
+	// rawRequestBody and originalRequestHeaders are kept to retry the request on the fallbacks.
+	// originalRequestHeaders is the snapshot of the request headers before the auth of the selected backend is applied.
+	rawRequestBody         []byte
+	originalRequestHeaders map[string]string
+	// fallbacks is the ordered list of the backends to retry the request on when the selected backend fails.
+	fallbacks []filterapi.Backend
+	// observation tracks the request sent to the selected backend for the adaptive load balancing.
+	observation *router.Observation
+	// costs is the cost of the request that is accumulated during the processing of the response.
+	costs requestUsage
+	// usageEstimator estimates the token usage when the backend does not return it. This is nil when there is no request cost.
+	usageEstimator *usageEstimator
+}
+
+// backendSelected records the backend selected for the request with the given raw body, which is kept to retry
+// the request on the fallbacks of the backend.
+func (p *llmProcessor) backendSelected(b *filterapi.Backend, rawBody []byte, stream bool) {
+	p.logger.Info("Selected backend", "backend", b.Name)
+	p.rawRequestBody, p.fallbacks = rawBody, fallbacksOf(p.config, p.logger, b, stream)
+	p.originalRequestHeaders = snapshotRequestHeaders(p.requestHeaders)
+	p.observation = observeBackend(p.config, b)
+	p.costs.stream, p.costs.schema = stream, b.Schema.Name
+}
+
+// tokenizer returns the tokenizer used to estimate the number of tokens.
+func (p *llmProcessor) tokenizer() tokenizer.Tokenizer {
+	return cmp.Or[tokenizer.Tokenizer](p.config.tokenizer, tokenizer.Heuristic{})
+}
+
+// initUsageEstimator sets up the estimation of the token usage missing in the response when there is any request cost.
+// The input tokens are estimated by estimateInput, and the output tokens are counted from the response with output.
+func (p *llmProcessor) initUsageEstimator(stream bool, output responseOutput, estimateInput func(tokenizer.Tokenizer) int) {
+	if len(p.config.requestCosts) == 0 {
+		return
+	}
+	tk := p.tokenizer()
+	p.usageEstimator = newUsageEstimator(tk, stream, estimateInput(tk), output)
+}
+
+// processResponseHeaders implements [Processor.ProcessResponseHeaders] with the translator of the selected backend.
+// When the backend failed, the request is retried on the fallbacks with the translator created by translate.
+//
+// The translator can be nil as there could be response event generated by previous ext proc without
+// getting the request event.
+func (p *llmProcessor) processResponseHeaders(ctx context.Context, headers *corev3.HeaderMap, tr fallbackTranslator,
+	translate fallbackTranslateFn,
+) (res *extprocv3.ProcessingResponse, err error) {
+	p.responseHeaders = headersToMap(headers)
+	if enc := p.responseHeaders["content-encoding"]; enc != "" {
+		p.responseEncoding = enc
+	}
+	if isBackendFailure(p.responseHeaders) {
+		p.observation.Done(true)
+	}
+	if shouldFallback(p.fallbacks, p.responseHeaders) {
+		// The fallbacks are only tried once per request.
+		fallbacks := p.fallbacks
+		p.fallbacks = nil
+		res, err = fallback(ctx, p.config, p.logger, p.requestHeaders, p.originalRequestHeaders, p.rawRequestBody,
+			fallbacks, &p.costs, translate)
+		if err != nil || res != nil {
+			return res, err
+		}
+	}
+	if tr == nil {
+		return &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_ResponseHeaders{
+			ResponseHeaders: &extprocv3.HeadersResponse{},
+		}}, nil
+	}
+	headerMutation, err := tr.ResponseHeaders(p.responseHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform response headers: %w", err)
+	}
+	return &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_ResponseHeaders{
+		ResponseHeaders: &extprocv3.HeadersResponse{
+			Response: &extprocv3.CommonResponse{HeaderMutation: headerMutation},
+		},
+	}}, nil
+}
+
+// processResponseBody implements [Processor.ProcessResponseBody] with the translator of the selected backend,
+// and accumulates the token usage of the response into the costs.
+//
+// The translator can be nil as there could be response event generated by previous ext proc without
+// getting the request event.
+func (p *llmProcessor) processResponseBody(body *extprocv3.HttpBody, tr fallbackTranslator) (res *extprocv3.ProcessingResponse, err error) {
+	br, err := responseBodyReader(p.responseEncoding, body.Body)
+	if err != nil {
+		return nil, err
+	}
+	if tr == nil {
+		return &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_ResponseBody{}}, nil
+	}
+
+	p.observation.FirstToken()
+	var observed bytes.Buffer
+	if p.usageEstimator != nil {
+		br = io.TeeReader(br, &observed)
+	}
+	headerMutation, bodyMutation, tokenUsage, err := tr.ResponseBody(p.responseHeaders, br, body.EndOfStream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform response: %w", err)
+	}
+	if p.usageEstimator != nil {
+		// The estimator observes the body sent to the client, which is in the input schema.
+		if m, ok := bodyMutation.GetMutation().(*extprocv3.BodyMutation_Body); ok {
+			p.usageEstimator.observeResponseBody(m.Body)
+		} else {
+			_, _ = io.Copy(io.Discard, br)
+			p.usageEstimator.observeResponseBody(observed.Bytes())
+		}
+	}
+
+	resp := &extprocv3.ProcessingResponse{
+		Response: &extprocv3.ProcessingResponse_ResponseBody{
+			ResponseBody: &extprocv3.BodyResponse{
+				Response: &extprocv3.CommonResponse{
+					HeaderMutation: headerMutation,
+					BodyMutation:   bodyMutation,
+				},
+			},
+		},
+	}
+
+	// TODO: this is coupled with "LLM" specific logic. Once we have another use case, we need to refactor this.
+	p.costs.add(tokenUsage)
+	if body.EndOfStream && len(p.config.requestCosts) > 0 {
+		p.estimateMissingUsage(&p.costs)
+		if p.costs.estimated {
+			p.logger.Info("Estimated the token usage missing in the response",
+				"input_tokens", p.costs.InputTokens, "output_tokens", p.costs.OutputTokens)
+		}
+		resp.DynamicMetadata, err = buildDynamicMetadata(p.config, &p.costs, p.requestHeaders, p.logger, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build dynamic metadata: %w", err)
+		}
+	} else if p.costs.stream && len(p.config.requestCosts) > 0 {
+		// The costs of the stream so far are sent with each chunk so that Envoy has them even when the stream
+		// is terminated in the middle, e.g. the client disconnects, since no more metadata can be sent after that.
+		partial := p.costs
+		p.estimateMissingUsage(&partial)
+		resp.DynamicMetadata, err = buildDynamicMetadata(p.config, &partial, p.requestHeaders, p.logger, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build dynamic metadata: %w", err)
+		}
+	}
+	if body.EndOfStream {
+		p.observation.Done(false)
+	}
+	return resp, nil
+}
+
+// Close implements [io.Closer]. This is called when the stream ends, and makes sure the request is no longer
+// counted as in-flight even when the response did not complete, e.g. the client canceled the request.
+// The request is counted as a failure if the backend did not respond at all.
+//
+// When the response did not complete, the costs of the response processed so far are consumed from the token budgets.
+// Envoy already has them in the metadata sent with the last chunk.
+func (p *llmProcessor) Close() error {
+	p.observation.Done(p.responseHeaders == nil)
+	if p.responseHeaders != nil && !p.costs.finalized && len(p.config.requestCosts) > 0 {
+		p.estimateMissingUsage(&p.costs)
+		if _, err := buildDynamicMetadata(p.config, &p.costs, p.requestHeaders, p.logger, true); err != nil {
+			p.logger.Error("failed to calculate the costs of the incomplete response", "error", err)
+		}
+	}
+	return nil
+}
+
+// estimateMissingUsage fills the token usage missing in the costs with the estimation when the backend successfully
+// responded, e.g. without any usage or in the middle of the stream.
+func (p *llmProcessor) estimateMissingUsage(costs *requestUsage) {
+	if p.usageEstimator == nil || !isSuccess(p.responseHeaders) {
+		return
+	}
+	costs.LLMTokenUsage, costs.estimated = p.usageEstimator.fillMissing(costs.LLMTokenUsage)
+}
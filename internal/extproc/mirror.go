@@ -0,0 +1,87 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"time"
+
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+)
+
+const (
+	// mirrorTimeout is the timeout of a mirrored request, which is not bound to the external processing stream
+	// so that it is not canceled when the response of the selected backend has been sent to the client.
+	mirrorTimeout = 30 * time.Second
+	// maxInFlightMirrors is the maximum number of the mirrored requests in flight. The requests to mirror beyond
+	// this are dropped so that a slow mirror backend does not pile up the goroutines and the connections.
+	maxInFlightMirrors = 64
+)
+
+var (
+	// mirrorSlots is the semaphore bounding the number of the mirrored requests in flight.
+	mirrorSlots = make(chan struct{}, maxInFlightMirrors)
+	// errMirrorDropped is returned when the request is not mirrored since too many mirrored requests are in flight.
+	errMirrorDropped = errors.New("too many mirrored requests in flight")
+)
+
+// mirror sends the copy of the original request to the mirror backend b in the background and discards its response.
+// The headerMutation and bodyMutation are the result of translating the original request for b, and the auth of b
+// is applied here on a copy of originalHeaders, the snapshot of the request headers taken by [snapshotRequestHeaders],
+// so the request to the selected backend is not affected.
+//
+// This returns an error when the mirrored request cannot be created or is dropped since [maxInFlightMirrors] requests
+// are already in flight. The failure of the mirrored request itself is only logged.
+func mirror(ctx context.Context, config *processorConfig, logger *slog.Logger, originalHeaders map[string]string,
+	rawBody []byte, b *filterapi.Backend, headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation,
+) error {
+	headers := maps.Clone(originalHeaders)
+	headerMutation, err := setBackendHeadersAndAuth(ctx, config, headers, headers[config.modelNameHeaderKey], b.Name,
+		headerMutation, bodyMutation)
+	if err != nil {
+		return err
+	}
+	body := rawBody
+	if bodyMutation != nil {
+		body = bodyMutation.GetBody()
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mirrorTimeout)
+	req, err := newBackendRequest(ctx, b.Endpoint, headers, headerMutation, body)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create request for mirror %s: %w", b.Name, err)
+	}
+	slots := mirrorSlots
+	select {
+	case slots <- struct{}{}:
+	default:
+		cancel()
+		return errMirrorDropped
+	}
+	go func() {
+		defer func() {
+			cancel()
+			<-slots
+		}()
+		resp, err := backendHTTPClient(config, b).Do(req)
+		if err != nil {
+			logger.Error("failed to send request to mirror", "backend", b.Name, "error", err)
+			return
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		logger.Info("Mirrored request", "backend", b.Name, "status", resp.StatusCode)
+	}()
+	return nil
+}
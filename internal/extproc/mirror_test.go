@@ -0,0 +1,99 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func TestChatCompletion_mirror(t *testing.T) {
+	type received struct {
+		path, backend string
+		body          []byte
+	}
+	receivedCh := make(chan received, 1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		receivedCh <- received{path: r.URL.Path, backend: r.Header.Get("x-backend"), body: body}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer backend.Close()
+
+	requestHeaders := map[string]string{":path": "/v1/chat/completions", "x-model": "some-model"}
+	p := &chatCompletionProcessor{
+		llmProcessor: llmProcessor{
+			config:         &processorConfig{modelNameHeaderKey: "x-model", selectedBackendHeaderKey: "x-backend"},
+			logger:         slog.Default(),
+			requestHeaders: requestHeaders,
+			// The mirror is created from the snapshot taken before the auth of the selected backend is applied.
+			originalRequestHeaders: snapshotRequestHeaders(requestHeaders),
+		},
+	}
+	m := &filterapi.Backend{
+		Name: "mirror", Endpoint: backend.URL, ModelNameOverride: "another-model",
+		Schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaAWSBedrock},
+	}
+	// The mirrored request outlives the context of the stream.
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	p.mirror(ctx, m, &openai.ChatCompletionRequest{Model: "some-model"}, []byte(`{"model":"some-model"}`))
+
+	select {
+	case r := <-receivedCh:
+		// The request is translated for the mirror's own schema.
+		require.Equal(t, "/model/another-model/converse", r.path)
+		require.Equal(t, "mirror", r.backend)
+		require.JSONEq(t, `{"inferenceConfig":{},"messages":[],"modelId":null}`, string(r.body))
+	case <-time.After(5 * time.Second):
+		t.Fatal("mirrored request not received")
+	}
+	// The request headers for the selected backend are not affected.
+	require.Equal(t, map[string]string{":path": "/v1/chat/completions", "x-model": "some-model"}, requestHeaders)
+
+	t.Run("unreachable", func(_ *testing.T) {
+		// The failure is only logged.
+		p.mirror(t.Context(), &filterapi.Backend{
+			Name: "unreachable", Endpoint: "http://127.0.0.1:1",
+			Schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI},
+		}, &openai.ChatCompletionRequest{Model: "some-model"}, []byte(`{"model":"some-model"}`))
+	})
+	t.Run("dropped when saturated", func(t *testing.T) {
+		defer func(slots chan struct{}) { mirrorSlots = slots }(mirrorSlots)
+		mirrorSlots = make(chan struct{}, 1)
+		mirrorSlots <- struct{}{}
+		err := mirror(t.Context(), p.config, p.logger, p.originalRequestHeaders, []byte(`{"model":"some-model"}`),
+			&filterapi.Backend{Name: "mirror", Endpoint: backend.URL}, nil, nil)
+		require.ErrorIs(t, err, errMirrorDropped)
+		select {
+		case <-receivedCh:
+			t.Fatal("dropped request received")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		// The request is mirrored again once the slot is released.
+		<-mirrorSlots
+		require.NoError(t, mirror(t.Context(), p.config, p.logger, p.originalRequestHeaders, []byte(`{"model":"some-model"}`),
+			&filterapi.Backend{Name: "mirror", Endpoint: backend.URL}, nil, nil))
+		select {
+		case r := <-receivedCh:
+			require.Equal(t, "/v1/chat/completions", r.path)
+		case <-time.After(5 * time.Second):
+			t.Fatal("mirrored request not received")
+		}
+	})
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/envoyproxy/ai-gateway/filterapi"
 	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
 	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
 	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
 )
@@ -26,6 +27,9 @@ import (
 var (
 	_ Processor                                 = &mockProcessor{}
 	_ translator.OpenAIChatCompletionTranslator = &mockTranslator{}
+	_ translator.OpenAIEmbeddingTranslator      = &mockEmbeddingTranslator{}
+	_ translator.OpenAICompletionTranslator     = &mockCompletionTranslator{}
+	_ translator.AWSBedrockConverseTranslator   = &mockConverseTranslator{}
 	_ x.Router                                  = &mockRouter{}
 )
 
@@ -80,7 +84,7 @@ type mockTranslator struct {
 }
 
 // RequestBody implements [translator.OpenAIChatCompletionTranslator].
-func (m mockTranslator) RequestBody(body *openai.ChatCompletionRequest) (headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error) {
+func (m mockTranslator) RequestBody(_ []byte, body *openai.ChatCompletionRequest) (headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error) {
 	require.Equal(m.t, m.expRequestBody, body)
 	return m.retHeaderMutation, m.retBodyMutation, m.retOverride, m.retErr
 }
@@ -111,22 +115,137 @@ func (m mockTranslator) ResponseBody(_ map[string]string, body io.Reader, _ bool
 	return m.retHeaderMutation, m.retBodyMutation, m.retUsedToken, m.retErr
 }
 
+// mockEmbeddingTranslator implements [translator.OpenAIEmbeddingTranslator] for testing.
+type mockEmbeddingTranslator struct {
+	t                 *testing.T
+	expRequestBody    *openai.EmbeddingRequest
+	expResponseBody   *extprocv3.HttpBody
+	retHeaderMutation *extprocv3.HeaderMutation
+	retBodyMutation   *extprocv3.BodyMutation
+	retUsedToken      translator.LLMTokenUsage
+	retErr            error
+}
+
+// RequestBody implements [translator.OpenAIEmbeddingTranslator].
+func (m mockEmbeddingTranslator) RequestBody(_ []byte, body *openai.EmbeddingRequest) (headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error) {
+	require.Equal(m.t, m.expRequestBody, body)
+	return m.retHeaderMutation, m.retBodyMutation, m.retErr
+}
+
+// ResponseBody implements [translator.OpenAIEmbeddingTranslator].
+func (m mockEmbeddingTranslator) ResponseBody(_ map[string]string, body io.Reader) (headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage translator.LLMTokenUsage, err error) {
+	if m.expResponseBody != nil {
+		buf, err := io.ReadAll(body)
+		require.NoError(m.t, err)
+		require.Equal(m.t, m.expResponseBody.Body, buf)
+	}
+	return m.retHeaderMutation, m.retBodyMutation, m.retUsedToken, m.retErr
+}
+
+// mockCompletionTranslator implements [translator.OpenAICompletionTranslator] for testing.
+type mockCompletionTranslator struct {
+	t                 *testing.T
+	expHeaders        map[string]string
+	expRequestBody    *openai.CompletionRequest
+	expResponseBody   *extprocv3.HttpBody
+	retHeaderMutation *extprocv3.HeaderMutation
+	retBodyMutation   *extprocv3.BodyMutation
+	retOverride       *extprocv3http.ProcessingMode
+	retUsedToken      translator.LLMTokenUsage
+	retErr            error
+}
+
+// RequestBody implements [translator.OpenAICompletionTranslator].
+func (m mockCompletionTranslator) RequestBody(_ []byte, body *openai.CompletionRequest) (headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error) {
+	require.Equal(m.t, m.expRequestBody, body)
+	return m.retHeaderMutation, m.retBodyMutation, m.retOverride, m.retErr
+}
+
+// ResponseHeaders implements [translator.OpenAICompletionTranslator].
+func (m mockCompletionTranslator) ResponseHeaders(headers map[string]string) (headerMutation *extprocv3.HeaderMutation, err error) {
+	require.Equal(m.t, m.expHeaders, headers)
+	return m.retHeaderMutation, m.retErr
+}
+
+// ResponseBody implements [translator.OpenAICompletionTranslator].
+func (m mockCompletionTranslator) ResponseBody(_ map[string]string, body io.Reader, _ bool) (headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage translator.LLMTokenUsage, err error) {
+	if m.expResponseBody != nil {
+		buf, err := io.ReadAll(body)
+		require.NoError(m.t, err)
+		require.Equal(m.t, m.expResponseBody.Body, buf)
+	}
+	return m.retHeaderMutation, m.retBodyMutation, m.retUsedToken, m.retErr
+}
+
+// mockConverseTranslator implements [translator.AWSBedrockConverseTranslator] for testing.
+type mockConverseTranslator struct {
+	t                 *testing.T
+	expHeaders        map[string]string
+	expRequestBody    *awsbedrock.ConverseInput
+	expStream         bool
+	expResponseBody   *extprocv3.HttpBody
+	retHeaderMutation *extprocv3.HeaderMutation
+	retBodyMutation   *extprocv3.BodyMutation
+	retOverride       *extprocv3http.ProcessingMode
+	retUsedToken      translator.LLMTokenUsage
+	retErr            error
+}
+
+// RequestBody implements [translator.AWSBedrockConverseTranslator].
+func (m mockConverseTranslator) RequestBody(body *awsbedrock.ConverseInput, stream bool) (headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error) {
+	require.Equal(m.t, m.expRequestBody, body)
+	require.Equal(m.t, m.expStream, stream)
+	return m.retHeaderMutation, m.retBodyMutation, m.retOverride, m.retErr
+}
+
+// ResponseHeaders implements [translator.AWSBedrockConverseTranslator].
+func (m mockConverseTranslator) ResponseHeaders(headers map[string]string) (headerMutation *extprocv3.HeaderMutation, err error) {
+	require.Equal(m.t, m.expHeaders, headers)
+	return m.retHeaderMutation, m.retErr
+}
+
+// ResponseBody implements [translator.AWSBedrockConverseTranslator].
+func (m mockConverseTranslator) ResponseBody(_ map[string]string, body io.Reader, _ bool) (headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage translator.LLMTokenUsage, err error) {
+	if m.expResponseBody != nil {
+		buf, err := io.ReadAll(body)
+		require.NoError(m.t, err)
+		require.Equal(m.t, m.expResponseBody.Body, buf)
+	}
+	return m.retHeaderMutation, m.retBodyMutation, m.retUsedToken, m.retErr
+}
+
 // mockRouter implements [router.Router] for testing.
 type mockRouter struct {
 	t                     *testing.T
 	expHeaders            map[string]string
 	retBackendName        string
 	retVersionedAPISchema filterapi.VersionedAPISchema
+	retModelNameOverride  string
 	retErr                error
 }
 
 // Calculate implements [router.Router.Calculate].
 func (m mockRouter) Calculate(headers map[string]string) (*filterapi.Backend, error) {
 	require.Equal(m.t, m.expHeaders, headers)
-	b := &filterapi.Backend{Name: m.retBackendName, Schema: m.retVersionedAPISchema}
+	b := &filterapi.Backend{Name: m.retBackendName, Schema: m.retVersionedAPISchema, ModelNameOverride: m.retModelNameOverride}
 	return b, m.retErr
 }
 
+// mockRouterV2 implements [x.RouterV2] for testing.
+type mockRouterV2 struct {
+	t              *testing.T
+	expHeaders     map[string]string
+	expRequest     x.Request
+	retBackendName string
+}
+
+// Calculate implements [x.RouterV2.Calculate].
+func (m mockRouterV2) Calculate(headers map[string]string, request x.Request) (*filterapi.Backend, error) {
+	require.Equal(m.t, m.expHeaders, headers)
+	require.Equal(m.t, m.expRequest, request)
+	return &filterapi.Backend{Name: m.retBackendName}, nil
+}
+
 // mockExternalProcessingStream implements [extprocv3.ExternalProcessor_ProcessServer] for testing.
 type mockExternalProcessingStream struct {
 	t                 *testing.T
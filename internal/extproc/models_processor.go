@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
@@ -27,14 +28,16 @@ import (
 // Since it returns an immediate response after processing the headers, the rest of the methods of the
 // Processor are not implemented. Those should never be called.
 type modelsProcessor struct {
-	logger *slog.Logger
-	models openai.ModelList
+	config         *processorConfig
+	requestHeaders map[string]string
+	logger         *slog.Logger
+	models         openai.ModelList
 }
 
 var _ Processor = (*modelsProcessor)(nil)
 
 // NewModelsProcessor creates a new processor that returns the list of declared models
-func NewModelsProcessor(config *processorConfig, _ map[string]string, logger *slog.Logger) (Processor, error) {
+func NewModelsProcessor(config *processorConfig, requestHeaders map[string]string, logger *slog.Logger) (Processor, error) {
 	models := openai.ModelList{
 		Object: "list",
 		Data:   make([]openai.Model, 0, len(config.declaredModels)),
@@ -47,14 +50,22 @@ func NewModelsProcessor(config *processorConfig, _ map[string]string, logger *sl
 			Created: openai.JSONUNIXTime(time.Now()), // TODO(nacx): does this really matter here?
 		})
 	}
-	return &modelsProcessor{logger: logger, models: models}, nil
+	return &modelsProcessor{config: config, requestHeaders: requestHeaders, logger: logger, models: models}, nil
 }
 
 // ProcessRequestHeaders implements [Processor.ProcessRequestHeaders].
 func (m *modelsProcessor) ProcessRequestHeaders(_ context.Context, _ *corev3.HeaderMap) (*extprocv3.ProcessingResponse, error) {
+	if res, err := authenticateAPIKey(m.config, m.requestHeaders, m.logger); err != nil || res.GetImmediateResponse() != nil {
+		return res, err
+	}
 	m.logger.Info("Serving list of declared models")
 
-	body, err := json.Marshal(m.models)
+	// Only the models the GatewayAPIKey of the request can be used for are listed.
+	models := m.models
+	models.Data = slices.DeleteFunc(slices.Clone(models.Data), func(model openai.Model) bool {
+		return !allowedModel(m.config, m.requestHeaders, model.ID)
+	})
+	body, err := json.Marshal(models)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal body: %w", err)
 	}
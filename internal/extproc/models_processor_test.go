@@ -16,6 +16,7 @@ import (
 	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/stretchr/testify/require"
 
+	"github.com/envoyproxy/ai-gateway/filterapi"
 	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
 )
 
@@ -47,6 +48,31 @@ func TestModels_ProcessRequestHeaders(t *testing.T) {
 	}
 }
 
+func TestModels_ProcessRequestHeaders_GatewayAPIKeys(t *testing.T) {
+	cfg := &processorConfig{
+		declaredModels: []string{"gpt-4o", "gpt-4o-mini", "o3"},
+		apiKeys: newGatewayAPIKeys(&filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{
+			{Name: "alice", Hash: hashAPIKey("sk-alice"), Owner: "alice", AllowedModels: []string{"gpt-4o-mini", "o3"}},
+		}}),
+	}
+	p, err := NewModelsProcessor(cfg, map[string]string{"authorization": "Bearer sk-alice"}, slog.Default())
+	require.NoError(t, err)
+	res, err := p.ProcessRequestHeaders(t.Context(), nil)
+	require.NoError(t, err)
+	require.Equal(t, typev3.StatusCode_OK, res.GetImmediateResponse().Status.Code)
+	var models openai.ModelList
+	require.NoError(t, json.Unmarshal(res.GetImmediateResponse().Body, &models))
+	require.Len(t, models.Data, 2)
+	require.Equal(t, "gpt-4o-mini", models.Data[0].ID)
+	require.Equal(t, "o3", models.Data[1].ID)
+
+	p, err = NewModelsProcessor(cfg, map[string]string{}, slog.Default())
+	require.NoError(t, err)
+	res, err = p.ProcessRequestHeaders(t.Context(), nil)
+	require.NoError(t, err)
+	require.Equal(t, typev3.StatusCode_Unauthorized, res.GetImmediateResponse().Status.Code)
+}
+
 func TestModels_UnimplementedMethods(t *testing.T) {
 	p := &modelsProcessor{}
 	_, err := p.ProcessRequestBody(t.Context(), &extprocv3.HttpBody{})
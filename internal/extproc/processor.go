@@ -6,29 +6,73 @@
 package extproc
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/envoyproxy/ai-gateway/filterapi"
 	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
 	"github.com/envoyproxy/ai-gateway/internal/extproc/backendauth"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
 )
 
 // processorConfig is the configuration for the processor.
 // This will be created by the server and passed to the processor when it detects a new configuration.
 type processorConfig struct {
-	uuid                                         string
-	schema                                       filterapi.VersionedAPISchema
-	router                                       x.Router
+	uuid   string
+	schema filterapi.VersionedAPISchema
+	router x.Router
+	// routerV2 is the custom [x.RouterV2] used instead of the router when set. The router is then the default router.
+	routerV2 x.RouterV2
+	// observer is the default router observing the backends for the adaptive load balancing and the circuit breaker,
+	// which is used even when a custom router is set so that the observation is never silently disabled.
+	observer                                     router.Observer
 	modelNameHeaderKey, selectedBackendHeaderKey string
 	backendAuthHandlers                          map[string]backendauth.Handler
 	metadataNamespace                            string
 	requestCosts                                 []processorConfigRequestCost
 	declaredModels                               []string
+	// fallbacks maps the backends in the route rules to the fallbacks of the rule. This is keyed by the pointer
+	// to the backend returned by the router, so backends created by a custom router have no fallbacks.
+	fallbacks map[*filterapi.Backend][]filterapi.Backend
+	// mirrors maps the backends in the route rules to the mirror of the rule in the same way as fallbacks.
+	mirrors map[*filterapi.Backend]*filterapi.Backend
+	// backendHTTPClients are the HTTP clients keyed by the name of the fallback and mirror backends with the TLS
+	// configuration. The other backends use the default client.
+	backendHTTPClients map[string]*http.Client
+	// estimateInputTokens is true if any of the route rules matches the estimated number of the input tokens.
+	estimateInputTokens bool
+	// tokenBudgets are the budgets of the request costs enforced in memory.
+	tokenBudgets []*tokenBudget
+	// requestLimits limits the chat completion requests. This can be nil.
+	requestLimits *filterapi.RequestLimits
+	// forceStreamUsage is true if the streamed chat completion requests always ask for the usage.
+	forceStreamUsage bool
+	// tokenizer is used to estimate the number of tokens.
+	tokenizer tokenizer.Tokenizer
+	// apiKeys are the GatewayAPIKeys the requests are authenticated with. This is nil if the requests are not authenticated.
+	apiKeys *gatewayAPIKeys
+	// sensitiveHeaderKeys are the lowercase keys of the headers redacted in the logs, including the header names of
+	// the api keys configured for the backends.
+	sensitiveHeaderKeys []string
 }
 
 // processorConfigRequestCost is the configuration for the request cost.
@@ -75,3 +119,270 @@ func (p passThroughProcessor) ProcessResponseHeaders(context.Context, *corev3.He
 func (p passThroughProcessor) ProcessResponseBody(context.Context, *extprocv3.HttpBody) (*extprocv3.ProcessingResponse, error) {
 	return &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_ResponseBody{}}, nil
 }
+
+// selectBackend sets the model name to the request headers and calculates the backend to route the request to.
+// The user of the request, if any, is passed to the router as the [router.UserKey].
+// When the GatewayAPIKey of the request cannot be used for the model, any of the token budgets of the request
+// is exhausted or no rule matches the request, this returns the immediate response to be sent back to the client instead.
+func selectBackend(config *processorConfig, requestHeaders map[string]string, request x.Request) (
+	*filterapi.Backend, *extprocv3.ProcessingResponse, error,
+) {
+	requestHeaders[config.modelNameHeaderKey] = request.Model
+	if request.User != "" {
+		requestHeaders[router.UserKey] = request.User
+	}
+	if !allowedModel(config, requestHeaders, request.Model) {
+		resp, err := modelNotFoundResponse(request.Model)
+		return nil, resp, err
+	}
+	if resp, err := checkTokenBudgets(config, requestHeaders); err != nil || resp != nil {
+		return nil, resp, err
+	}
+	var b *filterapi.Backend
+	var err error
+	if config.routerV2 != nil {
+		b, err = config.routerV2.Calculate(requestHeaders, request)
+	} else {
+		b, err = config.router.Calculate(requestHeaders)
+	}
+	if err != nil {
+		var status typev3.StatusCode
+		switch {
+		case errors.Is(err, x.ErrNoMatchingRule):
+			status = typev3.StatusCode_NotFound
+		case errors.Is(err, x.ErrNoAvailableBackend):
+			status = typev3.StatusCode_ServiceUnavailable
+		default:
+			return nil, nil, fmt.Errorf("failed to calculate route: %w", err)
+		}
+		return nil, &extprocv3.ProcessingResponse{
+			Response: &extprocv3.ProcessingResponse_ImmediateResponse{
+				ImmediateResponse: &extprocv3.ImmediateResponse{
+					Status: &typev3.HttpStatus{Code: status},
+					Body:   []byte(err.Error()),
+				},
+			},
+		}, nil
+	}
+	return b, nil, nil
+}
+
+// openAIErrorResponse returns the immediate response with the given status code and the OpenAI error body.
+func openAIErrorResponse(status typev3.StatusCode, e *openai.ErrorType) (*extprocv3.ProcessingResponse, error) {
+	body, err := json.Marshal(openai.Error{Type: "error", Error: *e})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal error body: %w", err)
+	}
+	headerMutation := &extprocv3.HeaderMutation{}
+	setHeader(headerMutation, "content-type", "application/json")
+	return &extprocv3.ProcessingResponse{
+		Response: &extprocv3.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extprocv3.ImmediateResponse{
+				Status:  &typev3.HttpStatus{Code: status},
+				Headers: headerMutation,
+				Body:    body,
+			},
+		},
+	}, nil
+}
+
+// setBackendHeadersAndAuth sets the model name and the selected backend name to the request headers via headerMutation,
+// and then applies the auth handler of the backend if configured. headerMutation can be nil, and the non-nil one is returned.
+func setBackendHeadersAndAuth(ctx context.Context, config *processorConfig, requestHeaders map[string]string,
+	model, backendName string, headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation,
+) (*extprocv3.HeaderMutation, error) {
+	if headerMutation == nil {
+		headerMutation = &extprocv3.HeaderMutation{}
+	}
+	// Set the model name to the request header with the key `x-ai-gateway-llm-model-name`.
+	headerMutation.SetHeaders = append(headerMutation.SetHeaders, &corev3.HeaderValueOption{
+		Header: &corev3.HeaderValue{Key: config.modelNameHeaderKey, RawValue: []byte(model)},
+	}, &corev3.HeaderValueOption{
+		Header: &corev3.HeaderValue{Key: config.selectedBackendHeaderKey, RawValue: []byte(backendName)},
+	})
+
+	if authHandler, ok := config.backendAuthHandlers[backendName]; ok {
+		if err := authHandler.Do(ctx, requestHeaders, headerMutation, bodyMutation); err != nil {
+			return nil, fmt.Errorf("failed to do auth request: %w", err)
+		}
+	}
+	return headerMutation, nil
+}
+
+// isBackendFailureStatus returns true if the given status code indicates that the backend failed to serve the request,
+// i.e. 429 or 5xx. Such responses are retried on the fallbacks and counted as failures by the adaptive load balancing.
+func isBackendFailureStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// isBackendFailure returns true if the status code in the given response headers is [isBackendFailureStatus].
+func isBackendFailure(responseHeaders map[string]string) bool {
+	status, err := strconv.Atoi(responseHeaders[":status"])
+	return err == nil && isBackendFailureStatus(status)
+}
+
+// isSuccess returns true if the status code in the given response headers is 2xx.
+func isSuccess(responseHeaders map[string]string) bool {
+	status, err := strconv.Atoi(responseHeaders[":status"])
+	return err == nil && status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+// observeBackend starts observing the request sent to the selected backend when the router adapts the backend selection
+// to the observed performance of the backends. The returned observation can be nil, on which all the methods are no-op.
+func observeBackend(config *processorConfig, b *filterapi.Backend) *router.Observation {
+	if config.observer == nil {
+		return nil
+	}
+	return config.observer.Observe(b)
+}
+
+// responseBodyReader returns the reader of the response body decoded according to the content-encoding.
+func responseBodyReader(encoding string, body []byte) (io.Reader, error) {
+	switch encoding {
+	case "gzip":
+		br, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode gzip: %w", err)
+		}
+		return br, nil
+	default:
+		return bytes.NewReader(body), nil
+	}
+}
+
+// requestUsage is the token usage accumulated during the processing of the response, along with the parameters
+// of the request and the backend that are available to the CEL expressions of the request costs.
+type requestUsage struct {
+	translator.LLMTokenUsage
+	// user is the "user" field of the request body, if any.
+	user string
+	// stream is true if the response is streamed.
+	stream bool
+	// maxTokens is the maximum number of the output tokens set in the request, or zero if not set.
+	maxTokens uint32
+	// schema is the API schema of the backend that served the request.
+	schema filterapi.APISchemaName
+	// estimated is true if the token usage is estimated by the filter since the backend did not return it.
+	estimated bool
+	// finalized is true once the final costs are calculated and consumed from the token budgets.
+	finalized bool
+}
+
+// add accumulates the token usage of a part of the response, e.g. a chunk of the streamed response.
+func (u *requestUsage) add(usage translator.LLMTokenUsage) {
+	u.InputTokens += usage.InputTokens
+	u.OutputTokens += usage.OutputTokens
+	u.TotalTokens += usage.TotalTokens
+	u.CachedInputTokens += usage.CachedInputTokens
+	u.CacheWriteInputTokens += usage.CacheWriteInputTokens
+	u.ReasoningTokens += usage.ReasoningTokens
+}
+
+// usageEstimatedMetadataKey is the key of the dynamic metadata set to true when the token usage is estimated.
+const usageEstimatedMetadataKey = "llm_usage_estimated"
+
+// maxTokensOf returns the given maximum number of the output tokens set in the request, or zero if not set.
+func maxTokensOf(maxTokens *int64) uint32 {
+	if maxTokens == nil || *maxTokens < 0 {
+		return 0
+	}
+	return uint32(min(*maxTokens, math.MaxUint32)) //nolint:gosec
+}
+
+// priceOf calculates the monetary cost of the token usage in millionths of the currency unit with the first price
+// matching the model and the backend. This returns zero if no price matches.
+func priceOf(prices []filterapi.ModelPrice, model, backend string, usage *translator.LLMTokenUsage) float64 {
+	for i := range prices {
+		p := &prices[i]
+		if p.Model != model || (p.Backend != "" && p.Backend != backend) {
+			continue
+		}
+		cached, cacheWrite, reasoning := float64(usage.CachedInputTokens), float64(usage.CacheWriteInputTokens), float64(usage.ReasoningTokens)
+		input := max(0, float64(usage.InputTokens)-cached-cacheWrite)
+		output := max(0, float64(usage.OutputTokens)-reasoning)
+		return input*p.Input + cached*p.CachedInput + cacheWrite*p.CacheWriteInput + output*p.Output + reasoning*p.Reasoning
+	}
+	return 0
+}
+
+// buildDynamicMetadata builds the dynamic metadata of the request costs configured in the processorConfig
+// from the token usage accumulated during the processing of the response. This returns nil if no cost is configured.
+//
+// final is true when the response is complete or no more of it will be processed, on which the costs are consumed
+// from the token budgets. Otherwise, the metadata is of the response processed so far in the middle of the stream,
+// which is overwritten by the subsequent ones.
+func buildDynamicMetadata(config *processorConfig, costs *requestUsage, requestHeaders map[string]string,
+	logger *slog.Logger, final bool,
+) (*structpb.Struct, error) {
+	metadata := make(map[string]*structpb.Value, len(config.requestCosts))
+	for i := range config.requestCosts {
+		rc := &config.requestCosts[i]
+		var cost uint64
+		switch rc.Type {
+		case filterapi.LLMRequestCostTypeInputToken:
+			cost = uint64(costs.InputTokens)
+		case filterapi.LLMRequestCostTypeOutputToken:
+			cost = uint64(costs.OutputTokens)
+		case filterapi.LLMRequestCostTypeTotalToken:
+			cost = uint64(costs.TotalTokens)
+		case filterapi.LLMRequestCostTypeCachedInputToken:
+			cost = uint64(costs.CachedInputTokens)
+		case filterapi.LLMRequestCostTypeCacheWriteInputToken:
+			cost = uint64(costs.CacheWriteInputTokens)
+		case filterapi.LLMRequestCostTypeReasoningToken:
+			cost = uint64(costs.ReasoningTokens)
+		case filterapi.LLMRequestCostTypeCEL:
+			var err error
+			cost, err = llmcostcel.EvaluateProgram(rc.celProg, &llmcostcel.Variables{
+				Model:                 requestHeaders[config.modelNameHeaderKey],
+				Backend:               requestHeaders[config.selectedBackendHeaderKey],
+				Schema:                string(costs.schema),
+				Headers:               requestHeaders,
+				User:                  costs.user,
+				Stream:                costs.stream,
+				MaxTokens:             costs.maxTokens,
+				InputTokens:           costs.InputTokens,
+				OutputTokens:          costs.OutputTokens,
+				TotalTokens:           costs.TotalTokens,
+				CachedInputTokens:     costs.CachedInputTokens,
+				CacheWriteInputTokens: costs.CacheWriteInputTokens,
+				ReasoningTokens:       costs.ReasoningTokens,
+			}, rc.Scale, rc.Rounding)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate CEL expression: %w", err)
+			}
+		case filterapi.LLMRequestCostTypePrice:
+			var err error
+			cost, err = llmcostcel.ScaleDouble(priceOf(rc.Prices, requestHeaders[config.modelNameHeaderKey],
+				requestHeaders[config.selectedBackendHeaderKey], &costs.LLMTokenUsage), rc.Scale, rc.Rounding)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate the price: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown request cost kind: %s", rc.Type)
+		}
+		if final {
+			logger.Info("Setting request cost metadata", "type", rc.Type, "cost", cost, "metadataKey", rc.MetadataKey)
+			consumeTokenBudgets(config, requestHeaders, rc.MetadataKey, cost)
+		}
+		metadata[rc.MetadataKey] = &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(cost)}}
+	}
+	if final {
+		costs.finalized = true
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	if costs.estimated {
+		metadata[usageEstimatedMetadataKey] = &structpb.Value{Kind: &structpb.Value_BoolValue{BoolValue: true}}
+	}
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			config.metadataNamespace: {
+				Kind: &structpb.Value_StructValue{
+					StructValue: &structpb.Struct{Fields: metadata},
+				},
+			},
+		},
+	}, nil
+}
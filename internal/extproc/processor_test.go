@@ -6,10 +6,23 @@
 package extproc
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
 	"testing"
+	"time"
 
 	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
 )
 
 func Test_passThroughProcessor(t *testing.T) { // This is mostly for coverage.
@@ -38,3 +51,155 @@ func Test_passThroughProcessor(t *testing.T) { // This is mostly for coverage.
 	_, ok = resp.Response.(*extprocv3.ProcessingResponse_ResponseBody)
 	require.True(t, ok)
 }
+
+func Test_responseBodyReader(t *testing.T) {
+	t.Run("identity", func(t *testing.T) {
+		r, err := responseBodyReader("", []byte("foo"))
+		require.NoError(t, err)
+		buf, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "foo", string(buf))
+	})
+	t.Run("gzip", func(t *testing.T) {
+		var b bytes.Buffer
+		w := gzip.NewWriter(&b)
+		_, err := w.Write([]byte("foo"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		r, err := responseBodyReader("gzip", b.Bytes())
+		require.NoError(t, err)
+		buf, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "foo", string(buf))
+	})
+	t.Run("invalid gzip", func(t *testing.T) {
+		_, err := responseBodyReader("gzip", []byte("foo"))
+		require.ErrorContains(t, err, "failed to decode gzip")
+	})
+}
+
+func Test_selectBackend(t *testing.T) {
+	request := x.Request{Model: "some-model", Stream: true, MessageCount: 2, Tools: []string{"get_weather"}, User: "some-user"}
+	expHeaders := map[string]string{"x-model": "some-model", router.UserKey: "some-user"}
+	t.Run("router", func(t *testing.T) {
+		config := &processorConfig{modelNameHeaderKey: "x-model", router: mockRouter{t: t, expHeaders: expHeaders, retBackendName: "foo"}}
+		b, res, err := selectBackend(config, map[string]string{}, request)
+		require.NoError(t, err)
+		require.Nil(t, res)
+		require.Equal(t, "foo", b.Name)
+	})
+	t.Run("router v2", func(t *testing.T) {
+		config := &processorConfig{
+			modelNameHeaderKey: "x-model",
+			router:             mockRouter{t: t, retErr: errors.New("must not be called")},
+			routerV2:           mockRouterV2{t: t, expHeaders: expHeaders, expRequest: request, retBackendName: "bar"},
+		}
+		b, res, err := selectBackend(config, map[string]string{}, request)
+		require.NoError(t, err)
+		require.Nil(t, res)
+		require.Equal(t, "bar", b.Name)
+	})
+	t.Run("no matching rule", func(t *testing.T) {
+		config := &processorConfig{modelNameHeaderKey: "x-model", router: mockRouter{t: t, expHeaders: expHeaders, retErr: x.ErrNoMatchingRule}}
+		b, res, err := selectBackend(config, map[string]string{}, request)
+		require.NoError(t, err)
+		require.Nil(t, b)
+		require.Equal(t, typev3.StatusCode_NotFound, res.GetImmediateResponse().Status.Code)
+	})
+	t.Run("model not allowed", func(t *testing.T) {
+		config := &processorConfig{
+			modelNameHeaderKey: "x-model",
+			apiKeys: newGatewayAPIKeys(&filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{
+				{Name: "alice", AllowedModels: []string{"gpt-4o-mini"}},
+			}}),
+			router: mockRouter{t: t, retErr: errors.New("must not be called")},
+		}
+		b, res, err := selectBackend(config, map[string]string{apiKeyNameKey: "alice"}, request)
+		require.NoError(t, err)
+		require.Nil(t, b)
+		require.Equal(t, typev3.StatusCode_NotFound, res.GetImmediateResponse().Status.Code)
+		require.Contains(t, string(res.GetImmediateResponse().Body), `"code":"model_not_found"`)
+	})
+	t.Run("token budget exhausted", func(t *testing.T) {
+		budgets := newTokenBudgets([]filterapi.TokenBudget{
+			{Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser}, MetadataKey: "total", Limit: 100, Window: time.Hour},
+		}, nil, nil)
+		budgets[0].consumption.consume("some-user", 100)
+		config := &processorConfig{
+			modelNameHeaderKey: "x-model", tokenBudgets: budgets,
+			router: mockRouter{t: t, retErr: errors.New("must not be called")},
+		}
+		b, res, err := selectBackend(config, map[string]string{}, request)
+		require.NoError(t, err)
+		require.Nil(t, b)
+		require.Equal(t, typev3.StatusCode_TooManyRequests, res.GetImmediateResponse().Status.Code)
+	})
+}
+
+func Test_buildDynamicMetadata(t *testing.T) {
+	prog, err := llmcostcel.NewProgram("schema == 'AWSBedrock' && stream && user == 'some-user' && " +
+		"headers['x-tenant'] == 'some-tenant' && model == 'some-model' && backend == 'some-backend' ? " +
+		"double(input_tokens) * 3.0 + double(output_tokens) * 15.0 + double(max_tokens) : 0.0")
+	require.NoError(t, err)
+	config := &processorConfig{
+		metadataNamespace: "ns", modelNameHeaderKey: "x-model", selectedBackendHeaderKey: "x-backend",
+		requestCosts: []processorConfigRequestCost{
+			{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+			{
+				LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeCEL, MetadataKey: "price", Scale: 10},
+				celProg:        prog,
+			},
+			{LLMRequestCost: &filterapi.LLMRequestCost{
+				Type: filterapi.LLMRequestCostTypePrice, MetadataKey: "usd", Rounding: filterapi.LLMRequestCostRoundingCeil,
+				Prices: []filterapi.ModelPrice{{Model: "some-model", Input: 0.15, Output: 0.6, CachedInput: 0.15, CacheWriteInput: 0.15, Reasoning: 0.6}},
+			}},
+		},
+	}
+	costs := &requestUsage{
+		LLMTokenUsage: translator.LLMTokenUsage{InputTokens: 10, OutputTokens: 20, TotalTokens: 30},
+		user:          "some-user", stream: true, maxTokens: 100, schema: filterapi.APISchemaAWSBedrock,
+	}
+	requestHeaders := map[string]string{"x-model": "some-model", "x-backend": "some-backend", "x-tenant": "some-tenant"}
+	for _, final := range []bool{false, true} {
+		md, err := buildDynamicMetadata(config, costs, requestHeaders, slog.Default(), final)
+		require.NoError(t, err)
+		fields := md.Fields["ns"].GetStructValue().Fields
+		require.Equal(t, float64(30), fields["total"].GetNumberValue())
+		require.Equal(t, float64((10*3+20*15+100)*10), fields["price"].GetNumberValue())
+		// 10*0.15 + 20*0.6 = 13.5 micro-dollars rounded up.
+		require.Equal(t, float64(14), fields["usd"].GetNumberValue())
+		require.Equal(t, final, costs.finalized)
+	}
+}
+
+func Test_priceOf(t *testing.T) {
+	prices := []filterapi.ModelPrice{
+		{Model: "gpt-4o", Backend: "azure.default", Input: 1, Output: 2, CachedInput: 1, CacheWriteInput: 1, Reasoning: 2},
+		{Model: "gpt-4o", Input: 2.5, Output: 10, CachedInput: 1.25, CacheWriteInput: 3, Reasoning: 20},
+	}
+	usage := &translator.LLMTokenUsage{
+		InputTokens: 1000, OutputTokens: 500, TotalTokens: 1500,
+		CachedInputTokens: 200, CacheWriteInputTokens: 100, ReasoningTokens: 300,
+	}
+	for _, tc := range []struct {
+		name, model, backend string
+		exp                  float64
+	}{
+		{name: "backend specific", model: "gpt-4o", backend: "azure.default", exp: 1000*1 + 500*2},
+		{name: "any backend", model: "gpt-4o", backend: "openai.default", exp: 700*2.5 + 200*1.25 + 100*3 + 200*10 + 300*20},
+		{name: "no match", model: "gpt-4o-mini", backend: "openai.default", exp: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.InDelta(t, tc.exp, priceOf(prices, tc.model, tc.backend, usage), 1e-9)
+		})
+	}
+}
+
+func Test_requestUsage_add(t *testing.T) {
+	var u requestUsage
+	u.add(translator.LLMTokenUsage{InputTokens: 10, TotalTokens: 10, CachedInputTokens: 4, CacheWriteInputTokens: 2})
+	u.add(translator.LLMTokenUsage{OutputTokens: 5, TotalTokens: 5, ReasoningTokens: 3})
+	require.Equal(t, translator.LLMTokenUsage{
+		InputTokens: 10, OutputTokens: 5, TotalTokens: 15, CachedInputTokens: 4, CacheWriteInputTokens: 2, ReasoningTokens: 3,
+	}, u.LLMTokenUsage)
+}
@@ -0,0 +1,69 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"fmt"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+// applyRequestLimits applies the request limits to the chat completion request whose input tokens are estimated
+// as estimatedInputTokens. This returns the parameters of the maximum number of the output tokens that are set to
+// the [filterapi.RequestLimits.MaxTokens], or the OpenAI error describing the violated limit with which the request
+// is rejected.
+func applyRequestLimits(limits *filterapi.RequestLimits, req *openai.ChatCompletionRequest, estimatedInputTokens int) (
+	modified []string, violation *openai.ErrorType,
+) {
+	if limits.MaxMessages > 0 && len(req.Messages) > limits.MaxMessages {
+		return nil, invalidRequestError("messages", "array_above_max_length", fmt.Sprintf(
+			"Invalid 'messages': expected an array with maximum length %d, but got an array with length %d instead.",
+			limits.MaxMessages, len(req.Messages)))
+	}
+	if limits.MaxTools != nil && len(req.Tools) > *limits.MaxTools {
+		return nil, invalidRequestError("tools", "array_above_max_length", fmt.Sprintf(
+			"Invalid 'tools': expected an array with maximum length %d, but got an array with length %d instead.",
+			*limits.MaxTools, len(req.Tools)))
+	}
+	if limits.MaxEstimatedInputTokens > 0 && estimatedInputTokens > limits.MaxEstimatedInputTokens {
+		return nil, invalidRequestError("messages", "context_length_exceeded", fmt.Sprintf(
+			"The maximum number of the input tokens is %d, but the messages resulted in about %d tokens.",
+			limits.MaxEstimatedInputTokens, estimatedInputTokens))
+	}
+	if limits.MaxTokens <= 0 {
+		return nil, nil
+	}
+	for _, m := range []struct {
+		param string
+		value *int64
+	}{
+		{param: "max_tokens", value: req.MaxTokens},
+		{param: "max_completion_tokens", value: req.MaxCompletionTokens},
+	} {
+		if m.value == nil || *m.value <= limits.MaxTokens {
+			continue
+		}
+		if limits.RejectMaxTokens {
+			return nil, invalidRequestError(m.param, "integer_above_max_value", fmt.Sprintf(
+				"Invalid '%s': integer above maximum value. Expected a value <= %d, but got %d instead.",
+				m.param, limits.MaxTokens, *m.value))
+		}
+		*m.value = limits.MaxTokens
+		modified = append(modified, m.param)
+	}
+	if req.MaxTokens == nil && req.MaxCompletionTokens == nil {
+		maxTokens := limits.MaxTokens
+		req.MaxCompletionTokens = &maxTokens
+		modified = append(modified, "max_completion_tokens")
+	}
+	return modified, nil
+}
+
+// invalidRequestError returns the OpenAI error of the invalid request for the given parameter.
+func invalidRequestError(param, code, message string) *openai.ErrorType {
+	return &openai.ErrorType{Type: "invalid_request_error", Param: &param, Code: &code, Message: message}
+}
@@ -0,0 +1,85 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func Test_applyRequestLimits(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		limits          filterapi.RequestLimits
+		req             openai.ChatCompletionRequest
+		estimated       int
+		expReq          openai.ChatCompletionRequest
+		expModified     []string
+		expViolationMsg string
+	}{
+		{
+			name:   "within limits",
+			limits: filterapi.RequestLimits{MaxTokens: 100, MaxMessages: 1, MaxTools: ptr.To(1), MaxEstimatedInputTokens: 10},
+			req:    openai.ChatCompletionRequest{MaxTokens: ptr.To[int64](100), Messages: make([]openai.ChatCompletionMessageParamUnion, 1)},
+			expReq: openai.ChatCompletionRequest{MaxTokens: ptr.To[int64](100), Messages: make([]openai.ChatCompletionMessageParamUnion, 1)},
+		},
+		{
+			name:        "clamped",
+			limits:      filterapi.RequestLimits{MaxTokens: 100},
+			req:         openai.ChatCompletionRequest{MaxTokens: ptr.To[int64](1000), MaxCompletionTokens: ptr.To[int64](200)},
+			expReq:      openai.ChatCompletionRequest{MaxTokens: ptr.To[int64](100), MaxCompletionTokens: ptr.To[int64](100)},
+			expModified: []string{"max_tokens", "max_completion_tokens"},
+		},
+		{
+			name:        "unset",
+			limits:      filterapi.RequestLimits{MaxTokens: 100},
+			expReq:      openai.ChatCompletionRequest{MaxCompletionTokens: ptr.To[int64](100)},
+			expModified: []string{"max_completion_tokens"},
+		},
+		{
+			name:            "max tokens rejected",
+			limits:          filterapi.RequestLimits{MaxTokens: 100, RejectMaxTokens: true},
+			req:             openai.ChatCompletionRequest{MaxCompletionTokens: ptr.To[int64](200)},
+			expViolationMsg: "Invalid 'max_completion_tokens': integer above maximum value. Expected a value <= 100, but got 200 instead.",
+		},
+		{
+			name:            "too many messages",
+			limits:          filterapi.RequestLimits{MaxMessages: 1},
+			req:             openai.ChatCompletionRequest{Messages: make([]openai.ChatCompletionMessageParamUnion, 2)},
+			expViolationMsg: "Invalid 'messages': expected an array with maximum length 1, but got an array with length 2 instead.",
+		},
+		{
+			name:            "no tools allowed",
+			limits:          filterapi.RequestLimits{MaxTools: ptr.To(0)},
+			req:             openai.ChatCompletionRequest{Tools: make([]openai.Tool, 1)},
+			expViolationMsg: "Invalid 'tools': expected an array with maximum length 0, but got an array with length 1 instead.",
+		},
+		{
+			name:            "too many input tokens",
+			limits:          filterapi.RequestLimits{MaxEstimatedInputTokens: 10},
+			estimated:       11,
+			expViolationMsg: "The maximum number of the input tokens is 10, but the messages resulted in about 11 tokens.",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			modified, violation := applyRequestLimits(&tc.limits, &tc.req, tc.estimated)
+			if tc.expViolationMsg != "" {
+				require.NotNil(t, violation)
+				require.Equal(t, "invalid_request_error", violation.Type)
+				require.Equal(t, tc.expViolationMsg, violation.Message)
+				return
+			}
+			require.Nil(t, violation)
+			require.Equal(t, tc.expModified, modified)
+			require.Equal(t, tc.expReq, tc.req)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package router
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+)
+
+const (
+	// adaptiveEWMAAlpha is the smoothing factor of the EWMAs of the time to first token and the error rate.
+	adaptiveEWMAAlpha = 0.2
+	// adaptiveMinSuccessFactor is the lower bound of the factor derived from the error rate, so that a failing
+	// backend still receives a small share of the traffic to observe its recovery.
+	adaptiveMinSuccessFactor = 0.05
+)
+
+// Observer is implemented by the [x.Router] that adapts the backend selection to the observed performance of the backends,
+// i.e. the adaptive load balancing and the circuit breaker.
+type Observer interface {
+	// Observe starts observing the request sent to the given backend.
+	// This returns nil if the backend is not observed, and all the methods of [*Observation] are no-op on nil.
+	Observe(backend *filterapi.Backend) *Observation
+}
+
+// Observation tracks a single request sent to a backend. The methods are not safe for concurrent use,
+// which is fine as each request is processed by a single goroutine.
+type Observation struct {
+	// stats is nil if the backend is not subject to the adaptive load balancing.
+	stats *backendStats
+	// breakers is empty if the backend has no circuit breaker.
+	breakers   []*circuitBreaker
+	start      time.Time
+	firstToken bool
+	done       bool
+}
+
+// FirstToken records the time to first token, i.e. the elapsed time until the first chunk of the response body.
+// Only the first call per observation is recorded.
+func (o *Observation) FirstToken() {
+	if o == nil || o.firstToken || o.done {
+		return
+	}
+	o.firstToken = true
+	if o.stats != nil {
+		o.stats.recordTTFT(time.Since(o.start))
+	}
+}
+
+// Done records the completion of the request. failed is true when the backend failed to serve the request.
+// Only the first call per observation is recorded, so this can be called again to make sure the request is
+// no longer counted as in-flight.
+func (o *Observation) Done(failed bool) {
+	if o == nil || o.done {
+		return
+	}
+	o.done = true
+	if o.stats != nil {
+		o.stats.inFlight.Add(-1)
+		o.stats.recordResult(failed)
+	}
+	for _, cb := range o.breakers {
+		cb.recordResult(failed)
+	}
+}
+
+// backendStats holds the observed performance of a backend for the adaptive load balancing.
+type backendStats struct {
+	inFlight atomic.Int64
+
+	mu sync.Mutex
+	// ttft is the EWMA of the time to first token in seconds. Zero means no observation yet.
+	ttft float64
+	// errorRate is the EWMA of the failures where 1 is a failure and 0 is a success.
+	errorRate float64
+}
+
+func (s *backendStats) recordTTFT(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := d.Seconds()
+	if s.ttft == 0 {
+		s.ttft = v
+	} else {
+		s.ttft = adaptiveEWMAAlpha*v + (1-adaptiveEWMAAlpha)*s.ttft
+	}
+}
+
+func (s *backendStats) recordResult(failed bool) {
+	var v float64
+	if failed {
+		v = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errorRate = adaptiveEWMAAlpha*v + (1-adaptiveEWMAAlpha)*s.errorRate
+}
+
+func (s *backendStats) snapshot() (ttft, errorRate float64, inFlight int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ttft, s.errorRate, s.inFlight.Load()
+}
+
+// Observe implements [Observer.Observe].
+//
+// The result is recorded by the circuit breaker of the rule the backend was selected from. When the backend is
+// not the one in the rules, e.g. a fallback, it is recorded by the circuit breakers of all the rules with the backend.
+func (r *router) Observe(backend *filterapi.Backend) *Observation {
+	s := r.stats[backend.Name]
+	cbs := r.breakersByName[backend.Name]
+	if cb, ok := r.breakers[backend]; ok {
+		cbs = []*circuitBreaker{cb}
+	}
+	if s == nil && len(cbs) == 0 {
+		return nil
+	}
+	if s != nil {
+		s.inFlight.Add(1)
+	}
+	return &Observation{stats: s, breakers: cbs, start: time.Now()}
+}
+
+// selectBackendAdaptively selects one of the given backends biased away from the slow or failing backends.
+// Precondition: len(backends) > 1.
+//
+// Each backend is scored by its weight divided by its expected load, i.e. the EWMA of the time to first token
+// multiplied by the number of in-flight requests plus one, and then multiplied by the squared success rate.
+// The backend is then randomly selected with the probability proportional to the score.
+func (r *router) selectBackendAdaptively(backends []*filterapi.Backend) *filterapi.Backend {
+	totalWeight := 0
+	for _, b := range backends {
+		totalWeight += b.Weight
+	}
+
+	type observed struct {
+		ttft, errorRate float64
+		inFlight        int64
+	}
+	obs := make([]observed, len(backends))
+	// The backends without the time to first token yet are assumed to be as fast as the average,
+	// so that they are neither starved nor flooded until observed.
+	var sumTTFT float64
+	var numTTFT int
+	for i, b := range backends {
+		if s, ok := r.stats[b.Name]; ok {
+			obs[i].ttft, obs[i].errorRate, obs[i].inFlight = s.snapshot()
+			if obs[i].ttft > 0 {
+				sumTTFT += obs[i].ttft
+				numTTFT++
+			}
+		}
+	}
+	avgTTFT := 1.0
+	if numTTFT > 0 {
+		avgTTFT = sumTTFT / float64(numTTFT)
+	}
+
+	scores := make([]float64, len(backends))
+	var totalScore float64
+	for i, b := range backends {
+		weight := float64(b.Weight)
+		if totalWeight == 0 {
+			weight = 1
+		}
+		ttft := obs[i].ttft
+		if ttft == 0 {
+			ttft = avgTTFT
+		}
+		success := math.Max(1-obs[i].errorRate, adaptiveMinSuccessFactor)
+		scores[i] = weight / (ttft * float64(obs[i].inFlight+1)) * success * success
+		totalScore += scores[i]
+	}
+	if totalScore == 0 {
+		return backends[0]
+	}
+
+	rng := rand.New(rand.NewSource(uint64(time.Now().UnixNano()))) // nolint:gosec
+	selected := rng.Float64() * totalScore
+	for i, b := range backends {
+		if selected < scores[i] {
+			return b
+		}
+		selected -= scores[i]
+	}
+	return backends[len(backends)-1]
+}
@@ -0,0 +1,151 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+)
+
+func newAdaptiveRouter(t *testing.T, backends ...filterapi.Backend) *router {
+	_r, err := New(&filterapi.Config{
+		Rules: []filterapi.RouteRule{
+			{
+				Backends:            backends,
+				Headers:             []filterapi.HeaderMatch{{Name: "x-model-name", Value: "adaptive"}},
+				LoadBalancingPolicy: &filterapi.LoadBalancingPolicy{Type: filterapi.LoadBalancingPolicyTypeAdaptive},
+			},
+			{
+				Backends: []filterapi.Backend{{Name: "static"}},
+				Headers:  []filterapi.HeaderMatch{{Name: "x-model-name", Value: "static"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	r, ok := _r.(*router)
+	require.True(t, ok)
+	return r
+}
+
+func TestRouter_Observe(t *testing.T) {
+	r := newAdaptiveRouter(t, filterapi.Backend{Name: "foo"}, filterapi.Backend{Name: "bar"})
+
+	t.Run("not adaptive", func(t *testing.T) {
+		o := r.Observe(&filterapi.Backend{Name: "static"})
+		require.Nil(t, o)
+		// The methods are no-op on nil.
+		o.FirstToken()
+		o.Done(true)
+	})
+	t.Run("success", func(t *testing.T) {
+		o := r.Observe(&filterapi.Backend{Name: "foo"})
+		require.NotNil(t, o)
+		_, _, inFlight := r.stats["foo"].snapshot()
+		require.Equal(t, int64(1), inFlight)
+
+		o.FirstToken()
+		ttft, _, _ := r.stats["foo"].snapshot()
+		require.Positive(t, ttft)
+
+		o.Done(false)
+		o.Done(true) // Only the first call is recorded.
+		_, errorRate, inFlight := r.stats["foo"].snapshot()
+		require.Zero(t, errorRate)
+		require.Zero(t, inFlight)
+	})
+	t.Run("failure", func(t *testing.T) {
+		o := r.Observe(&filterapi.Backend{Name: "bar"})
+		o.Done(true)
+		o.FirstToken() // Ignored after done.
+		ttft, errorRate, inFlight := r.stats["bar"].snapshot()
+		require.Zero(t, ttft)
+		require.InDelta(t, adaptiveEWMAAlpha, errorRate, 1e-9)
+		require.Zero(t, inFlight)
+	})
+}
+
+func TestBackendStats_EWMA(t *testing.T) {
+	s := &backendStats{}
+	s.recordTTFT(time.Second)
+	ttft, _, _ := s.snapshot()
+	require.InDelta(t, 1.0, ttft, 1e-9)
+	s.recordTTFT(3 * time.Second)
+	ttft, _, _ = s.snapshot()
+	require.InDelta(t, 1.4, ttft, 1e-9)
+
+	for range 100 {
+		s.recordResult(true)
+	}
+	_, errorRate, _ := s.snapshot()
+	require.InDelta(t, 1.0, errorRate, 1e-6)
+	s.recordResult(false)
+	_, errorRate, _ = s.snapshot()
+	require.InDelta(t, 0.8, errorRate, 1e-6)
+}
+
+func TestRouter_selectBackendAdaptively(t *testing.T) {
+	count := func(r *router) map[string]int {
+		counts := make(map[string]int)
+		for range 2000 {
+			b, err := r.Calculate(map[string]string{"x-model-name": "adaptive"})
+			require.NoError(t, err)
+			counts[b.Name]++
+		}
+		return counts
+	}
+
+	t.Run("no observation", func(t *testing.T) {
+		r := newAdaptiveRouter(t, filterapi.Backend{Name: "foo", Weight: 1}, filterapi.Backend{Name: "bar", Weight: 3})
+		counts := count(r)
+		require.InDelta(t, 500, counts["foo"], 100)
+		require.InDelta(t, 1500, counts["bar"], 100)
+	})
+	t.Run("zero weights", func(t *testing.T) {
+		r := newAdaptiveRouter(t, filterapi.Backend{Name: "foo"}, filterapi.Backend{Name: "bar"})
+		counts := count(r)
+		require.InDelta(t, 1000, counts["foo"], 100)
+		require.InDelta(t, 1000, counts["bar"], 100)
+	})
+	t.Run("slow backend", func(t *testing.T) {
+		r := newAdaptiveRouter(t, filterapi.Backend{Name: "foo", Weight: 1}, filterapi.Backend{Name: "bar", Weight: 1})
+		r.stats["foo"].recordTTFT(100 * time.Millisecond)
+		r.stats["bar"].recordTTFT(900 * time.Millisecond)
+		counts := count(r)
+		require.InDelta(t, 1800, counts["foo"], 100)
+	})
+	t.Run("failing backend", func(t *testing.T) {
+		r := newAdaptiveRouter(t, filterapi.Backend{Name: "foo", Weight: 1}, filterapi.Backend{Name: "bar", Weight: 1})
+		for range 100 {
+			r.stats["bar"].recordResult(true)
+		}
+		counts := count(r)
+		require.Less(t, counts["bar"], 50)
+	})
+	t.Run("in-flight requests", func(t *testing.T) {
+		r := newAdaptiveRouter(t, filterapi.Backend{Name: "foo", Weight: 1}, filterapi.Backend{Name: "bar", Weight: 1})
+		for range 3 {
+			r.Observe(&filterapi.Backend{Name: "bar"})
+		}
+		counts := count(r)
+		require.InDelta(t, 1600, counts["foo"], 100)
+	})
+	t.Run("unobserved backend is assumed average", func(t *testing.T) {
+		r := newAdaptiveRouter(t,
+			filterapi.Backend{Name: "foo", Weight: 1}, filterapi.Backend{Name: "bar", Weight: 1}, filterapi.Backend{Name: "baz", Weight: 1},
+		)
+		r.stats["foo"].recordTTFT(time.Second)
+		r.stats["bar"].recordTTFT(3 * time.Second)
+		// baz is assumed to take 2 seconds, so the scores are 1, 1/3 and 1/2.
+		counts := count(r)
+		require.InDelta(t, 2000*6/11, counts["foo"], 100)
+		require.InDelta(t, 2000*2/11, counts["bar"], 100)
+		require.InDelta(t, 2000*3/11, counts["baz"], 100)
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package router
+
+import (
+	"sync"
+	"time"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+)
+
+// circuitBreakerState is the state of a [circuitBreaker].
+type circuitBreakerState int
+
+const (
+	// circuitBreakerClosed is the state where the backend is selectable.
+	circuitBreakerClosed circuitBreakerState = iota
+	// circuitBreakerOpen is the state where the backend is excluded from the selection until the open duration elapses.
+	circuitBreakerOpen
+	// circuitBreakerHalfOpen is the state where a single probe request is in flight to the backend. Another probe is
+	// given when the result of the probe has not been recorded within the open duration.
+	circuitBreakerHalfOpen
+)
+
+// circuitBreaker is the circuit breaker of a backend.
+type circuitBreaker struct {
+	consecutiveFailuresThreshold int
+	openDuration                 time.Duration
+	// now is the function returning the current time, which can be replaced in tests.
+	now func() time.Time
+
+	mu                  sync.Mutex
+	state               circuitBreakerState
+	consecutiveFailures int
+	openedAt            time.Time
+	// probedAt is the time when the probe was given in the half-open state.
+	probedAt time.Time
+}
+
+func newCircuitBreaker(config *filterapi.CircuitBreaker) *circuitBreaker {
+	return &circuitBreaker{
+		consecutiveFailuresThreshold: config.ConsecutiveFailures,
+		openDuration:                 config.OpenDuration,
+		now:                          time.Now,
+	}
+}
+
+// allows returns true if the backend can be selected, and probe is true if the caller is given the probe request.
+//
+// The backend is selectable when the circuit is closed. Otherwise, once the open duration has elapsed, the circuit
+// moves to half-open and exactly one caller is given the probe. The probe is given again to the next caller when
+// its result has not been recorded within the open duration, e.g. when the probe was not sent after all.
+func (b *circuitBreaker) allows() (allowed, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	switch b.state {
+	case circuitBreakerClosed:
+		return true, false
+	case circuitBreakerOpen:
+		if now.Sub(b.openedAt) < b.openDuration {
+			return false, false
+		}
+	case circuitBreakerHalfOpen:
+		if now.Sub(b.probedAt) < b.openDuration {
+			return false, false
+		}
+	}
+	b.state, b.probedAt = circuitBreakerHalfOpen, now
+	return true, true
+}
+
+// recordResult records the result of a request sent to the backend.
+func (b *circuitBreaker) recordResult(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case circuitBreakerClosed:
+		if !failed {
+			b.consecutiveFailures = 0
+			return
+		}
+		b.consecutiveFailures++
+		if b.consecutiveFailures >= b.consecutiveFailuresThreshold {
+			b.open()
+		}
+	case circuitBreakerHalfOpen:
+		if failed {
+			b.open()
+			return
+		}
+		b.state, b.consecutiveFailures = circuitBreakerClosed, 0
+	case circuitBreakerOpen:
+		// The results of the requests sent before the circuit was opened are ignored.
+	}
+}
+
+// open opens the circuit. The caller must hold the lock.
+func (b *circuitBreaker) open() {
+	b.state, b.openedAt = circuitBreakerOpen, b.now()
+}
+
+// selectableBackends returns the backends of the given rule whose circuit breaker allows the selection.
+// Only the backend is returned when the request is given the probe to it, and none is returned when the circuits
+// of all the backends are open so that the failing backends are not sent the full traffic.
+func (r *router) selectableBackends(rule *filterapi.RouteRule) []*filterapi.Backend {
+	all := make([]*filterapi.Backend, len(rule.Backends))
+	for i := range rule.Backends {
+		all[i] = &rule.Backends[i]
+	}
+	if rule.CircuitBreaker == nil {
+		return all
+	}
+	selectable := make([]*filterapi.Backend, 0, len(all))
+	for _, b := range all {
+		cb, ok := r.breakers[b]
+		if !ok {
+			selectable = append(selectable, b)
+			continue
+		}
+		allowed, probe := cb.allows()
+		if probe {
+			return []*filterapi.Backend{b}
+		}
+		if allowed {
+			selectable = append(selectable, b)
+		}
+	}
+	return selectable
+}
@@ -0,0 +1,257 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package router
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/filterapi/x"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(&filterapi.CircuitBreaker{ConsecutiveFailures: 3, OpenDuration: time.Minute})
+	b.now = func() time.Time { return now }
+
+	// A success resets the consecutive failures.
+	b.recordResult(true)
+	b.recordResult(true)
+	b.recordResult(false)
+	b.recordResult(true)
+	b.recordResult(true)
+	require.Equal(t, circuitBreakerClosed, b.state)
+	requireAllows(t, b, true, false)
+
+	b.recordResult(true)
+	require.Equal(t, circuitBreakerOpen, b.state)
+	requireAllows(t, b, false, false)
+	// The results of the requests sent before the circuit was opened are ignored.
+	b.recordResult(false)
+	require.Equal(t, circuitBreakerOpen, b.state)
+
+	// Exactly one caller is given the probe after the open duration.
+	now = now.Add(time.Minute)
+	requireAllows(t, b, true, true)
+	require.Equal(t, circuitBreakerHalfOpen, b.state)
+	requireAllows(t, b, false, false)
+
+	// The failed probe opens the circuit again.
+	b.recordResult(true)
+	require.Equal(t, circuitBreakerOpen, b.state)
+	requireAllows(t, b, false, false)
+
+	// The probe is given again when its result is not recorded within the open duration.
+	now = now.Add(time.Minute)
+	requireAllows(t, b, true, true)
+	now = now.Add(time.Minute - time.Second)
+	requireAllows(t, b, false, false)
+	now = now.Add(time.Second)
+	requireAllows(t, b, true, true)
+
+	// The successful probe closes the circuit.
+	b.recordResult(false)
+	require.Equal(t, circuitBreakerClosed, b.state)
+	require.Zero(t, b.consecutiveFailures)
+	requireAllows(t, b, true, false)
+}
+
+func TestCircuitBreaker_concurrentProbe(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(&filterapi.CircuitBreaker{ConsecutiveFailures: 1, OpenDuration: time.Minute})
+	b.now = func() time.Time { return now }
+	b.recordResult(true)
+	now = now.Add(time.Minute)
+
+	var wg sync.WaitGroup
+	var probes atomic.Int32
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if allowed, probe := b.allows(); allowed && probe {
+				probes.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), probes.Load())
+}
+
+func requireAllows(t *testing.T, b *circuitBreaker, expAllowed, expProbe bool) {
+	t.Helper()
+	allowed, probe := b.allows()
+	require.Equal(t, expAllowed, allowed)
+	require.Equal(t, expProbe, probe)
+}
+
+func TestRouter_CircuitBreaker(t *testing.T) {
+	_r, err := New(&filterapi.Config{
+		Rules: []filterapi.RouteRule{
+			{
+				Backends: []filterapi.Backend{{Name: "foo", Weight: 1}, {Name: "bar", Weight: 1}},
+				Headers:  []filterapi.HeaderMatch{{Name: "x-model-name", Value: "cb"}},
+				CircuitBreaker: &filterapi.CircuitBreaker{
+					ConsecutiveFailures: 2, OpenDuration: time.Minute,
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	r, ok := _r.(*router)
+	require.True(t, ok)
+	now := time.Now()
+	for _, b := range r.breakers {
+		b.now = func() time.Time { return now }
+	}
+
+	calculate := func() map[string]int {
+		counts := make(map[string]int)
+		for range 100 {
+			b, err := r.Calculate(map[string]string{"x-model-name": "cb"})
+			require.NoError(t, err)
+			counts[b.Name]++
+		}
+		return counts
+	}
+	fail := func(name string) {
+		o := r.Observe(&filterapi.Backend{Name: name})
+		require.NotNil(t, o)
+		o.Done(true)
+	}
+
+	fail("foo")
+	require.Len(t, calculate(), 2)
+
+	// foo is excluded after the consecutive failures.
+	fail("foo")
+	require.Equal(t, map[string]int{"bar": 100}, calculate())
+
+	// The requests are rejected when all the backends are excluded, so that the failing backends do not get
+	// the full traffic.
+	fail("bar")
+	fail("bar")
+	_, err = r.Calculate(map[string]string{"x-model-name": "cb"})
+	require.ErrorIs(t, err, x.ErrNoAvailableBackend)
+
+	// Each request after the open duration is given the probe of one of the backends until both are probed.
+	now = now.Add(time.Minute)
+	b, err := r.Calculate(map[string]string{"x-model-name": "cb"})
+	require.NoError(t, err)
+	require.Equal(t, "foo", b.Name)
+	b, err = r.Calculate(map[string]string{"x-model-name": "cb"})
+	require.NoError(t, err)
+	require.Equal(t, "bar", b.Name)
+	_, err = r.Calculate(map[string]string{"x-model-name": "cb"})
+	require.ErrorIs(t, err, x.ErrNoAvailableBackend)
+
+	// foo is closed after the successful probe while bar is still excluded after the failed probe.
+	r.Observe(&filterapi.Backend{Name: "foo"}).Done(false)
+	fail("bar")
+	require.Equal(t, map[string]int{"foo": 100}, calculate())
+}
+
+func TestRouter_CircuitBreaker_PerRule(t *testing.T) {
+	_r, err := New(&filterapi.Config{
+		Rules: []filterapi.RouteRule{
+			{
+				Backends:       []filterapi.Backend{{Name: "foo", Weight: 1}, {Name: "bar", Weight: 1}},
+				Headers:        []filterapi.HeaderMatch{{Name: "x-model-name", Value: "strict"}},
+				CircuitBreaker: &filterapi.CircuitBreaker{ConsecutiveFailures: 1, OpenDuration: time.Minute},
+			},
+			{
+				Backends:       []filterapi.Backend{{Name: "foo", Weight: 1}, {Name: "bar", Weight: 1}},
+				Headers:        []filterapi.HeaderMatch{{Name: "x-model-name", Value: "lenient"}},
+				CircuitBreaker: &filterapi.CircuitBreaker{ConsecutiveFailures: 3, OpenDuration: time.Minute},
+			},
+		},
+	})
+	require.NoError(t, err)
+	r, ok := _r.(*router)
+	require.True(t, ok)
+
+	calculate := func(model string) map[string]int {
+		counts := make(map[string]int)
+		for range 100 {
+			b, err := r.Calculate(map[string]string{"x-model-name": model})
+			require.NoError(t, err)
+			counts[b.Name]++
+		}
+		return counts
+	}
+	foo := func(model string) *filterapi.Backend {
+		for {
+			b, err := r.Calculate(map[string]string{"x-model-name": model})
+			require.NoError(t, err)
+			if b.Name == "foo" {
+				return b
+			}
+		}
+	}
+
+	// The failure of foo selected from the strict rule only opens the circuit of the strict rule.
+	r.Observe(foo("strict")).Done(true)
+	require.Equal(t, map[string]int{"bar": 100}, calculate("strict"))
+	require.Len(t, calculate("lenient"), 2)
+
+	// The failures of foo selected from the lenient rule open its circuit with the settings of the lenient rule.
+	lenientFoo := foo("lenient")
+	r.Observe(lenientFoo).Done(true)
+	r.Observe(lenientFoo).Done(true)
+	require.Len(t, calculate("lenient"), 2)
+	r.Observe(lenientFoo).Done(true)
+	require.Equal(t, map[string]int{"bar": 100}, calculate("lenient"))
+
+	// The backend not in the rules, e.g. a fallback, is observed by the circuit breakers of all the rules.
+	o := r.Observe(&filterapi.Backend{Name: "bar"})
+	require.Len(t, o.breakers, 2)
+	o.Done(true)
+	// All the backends of the strict rule are excluded.
+	_, err = r.Calculate(map[string]string{"x-model-name": "strict"})
+	require.ErrorIs(t, err, x.ErrNoAvailableBackend)
+	require.Equal(t, map[string]int{"bar": 100}, calculate("lenient"))
+}
+
+func TestRouter_CircuitBreaker_SingleBackend(t *testing.T) {
+	_r, err := New(&filterapi.Config{
+		Rules: []filterapi.RouteRule{
+			{
+				Backends:       []filterapi.Backend{{Name: "foo"}},
+				Headers:        []filterapi.HeaderMatch{{Name: "x-model-name", Value: "cb"}},
+				CircuitBreaker: &filterapi.CircuitBreaker{ConsecutiveFailures: 1, OpenDuration: time.Minute},
+			},
+		},
+	})
+	require.NoError(t, err)
+	r, ok := _r.(*router)
+	require.True(t, ok)
+	now := time.Now()
+	for _, b := range r.breakers {
+		b.now = func() time.Time { return now }
+	}
+	headers := map[string]string{"x-model-name": "cb"}
+
+	b, err := r.Calculate(headers)
+	require.NoError(t, err)
+	r.Observe(b).Done(true)
+	_, err = r.Calculate(headers)
+	require.ErrorIs(t, err, x.ErrNoAvailableBackend)
+
+	// Only the probe is sent after the open duration, and the successful probe closes the circuit.
+	now = now.Add(time.Minute)
+	b, err = r.Calculate(headers)
+	require.NoError(t, err)
+	_, err = r.Calculate(headers)
+	require.ErrorIs(t, err, x.ErrNoAvailableBackend)
+	r.Observe(b).Done(false)
+	_, err = r.Calculate(headers)
+	require.NoError(t, err)
+}
@@ -0,0 +1,76 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package router
+
+import (
+	"hash/fnv"
+	"math"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+)
+
+// UserKey is the key in the headers passed to [x.Router.Calculate] holding the "user" field of the OpenAI request body,
+// which is used as the session key of the [filterapi.ConsistentHashTypeUser]. This is a pseudo header so that it
+// cannot be set by the client.
+const UserKey = ":ai-eg-user"
+
+// sessionKey returns the session key of the consistent hashing of the given rule from the headers,
+// or an empty string if the rule does not use the consistent hashing or the key is missing.
+func sessionKey(rule *filterapi.RouteRule, headers map[string]string) string {
+	lb := rule.LoadBalancingPolicy
+	if lb == nil || lb.Type != filterapi.LoadBalancingPolicyTypeConsistentHash || lb.ConsistentHash == nil {
+		return ""
+	}
+	switch lb.ConsistentHash.Type {
+	case filterapi.ConsistentHashTypeHeader:
+		return headers[lb.ConsistentHash.Header]
+	case filterapi.ConsistentHashTypeUser:
+		return headers[UserKey]
+	default:
+		return ""
+	}
+}
+
+// selectBackendByHash selects one of the given backends by the weighted rendezvous hashing of the session key.
+// Precondition: len(backends) > 0.
+//
+// Each backend is scored by -weight / ln(h) where h is the hash of the key and the backend name uniformly distributed
+// in (0, 1), and the backend with the highest score is selected. The probability of a backend being selected is
+// proportional to its weight, and since the score only depends on the key and the backend itself, adding or removing
+// a backend, e.g. on the configuration reload or by the circuit breaker, only moves the keys from or to that backend.
+func selectBackendByHash(backends []*filterapi.Backend, key string) *filterapi.Backend {
+	totalWeight := 0
+	for _, b := range backends {
+		totalWeight += b.Weight
+	}
+	selected, maxScore := backends[0], -1.0
+	for _, b := range backends {
+		weight := float64(b.Weight)
+		if totalWeight == 0 {
+			weight = 1
+		}
+		if score := -weight / math.Log(unitHash(key, b.Name)); score > maxScore {
+			selected, maxScore = b, score
+		}
+	}
+	return selected
+}
+
+// unitHash returns the hash of the given key and backend name uniformly distributed in the open interval (0, 1).
+func unitHash(key, backendName string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(backendName))
+	// Finalize with the mixer of SplitMix64 since FNV does not distribute the similar inputs well in the higher bits.
+	v := h.Sum64()
+	v ^= v >> 30
+	v *= 0xbf58476d1ce4e5b9
+	v ^= v >> 27
+	v *= 0x94d049bb133111eb
+	v ^= v >> 31
+	return (float64(v>>11) + 0.5) / (1 << 53)
+}
@@ -0,0 +1,101 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package router
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+)
+
+func newConsistentHashRouter(t *testing.T, ch *filterapi.ConsistentHash, backends ...filterapi.Backend) *router {
+	_r, err := New(&filterapi.Config{
+		Rules: []filterapi.RouteRule{
+			{
+				Backends: backends,
+				Headers:  []filterapi.HeaderMatch{{Name: "x-model-name", Value: "sticky"}},
+				LoadBalancingPolicy: &filterapi.LoadBalancingPolicy{
+					Type: filterapi.LoadBalancingPolicyTypeConsistentHash, ConsistentHash: ch,
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	r, ok := _r.(*router)
+	require.True(t, ok)
+	return r
+}
+
+func TestRouter_ConsistentHash(t *testing.T) {
+	backends := []filterapi.Backend{{Name: "foo", Weight: 1}, {Name: "bar", Weight: 3}, {Name: "baz", Weight: 0}}
+	calculate := func(r *router, headers map[string]string) string {
+		headers["x-model-name"] = "sticky"
+		b, err := r.Calculate(headers)
+		require.NoError(t, err)
+		return b.Name
+	}
+
+	t.Run("header", func(t *testing.T) {
+		r := newConsistentHashRouter(t, &filterapi.ConsistentHash{Type: filterapi.ConsistentHashTypeHeader, Header: "x-session-id"}, backends...)
+		counts := make(map[string]int)
+		for i := range 2000 {
+			session := strconv.Itoa(i)
+			selected := calculate(r, map[string]string{"x-session-id": session})
+			// The same session always goes to the same backend.
+			for range 3 {
+				require.Equal(t, selected, calculate(r, map[string]string{"x-session-id": session}))
+			}
+			counts[selected]++
+		}
+		// The sessions are distributed according to the weights.
+		require.InDelta(t, 500, counts["foo"], 100)
+		require.InDelta(t, 1500, counts["bar"], 100)
+		require.Zero(t, counts["baz"])
+	})
+	t.Run("user", func(t *testing.T) {
+		r := newConsistentHashRouter(t, &filterapi.ConsistentHash{Type: filterapi.ConsistentHashTypeUser}, backends...)
+		selected := calculate(r, map[string]string{UserKey: "some-user"})
+		for range 10 {
+			require.Equal(t, selected, calculate(r, map[string]string{UserKey: "some-user"}))
+		}
+	})
+	t.Run("no key", func(t *testing.T) {
+		r := newConsistentHashRouter(t, &filterapi.ConsistentHash{Type: filterapi.ConsistentHashTypeHeader, Header: "x-session-id"}, backends...)
+		// Falls back to the weighted random selection.
+		counts := make(map[string]int)
+		for range 2000 {
+			counts[calculate(r, map[string]string{})]++
+		}
+		require.InDelta(t, 500, counts["foo"], 100)
+		require.InDelta(t, 1500, counts["bar"], 100)
+	})
+	t.Run("backend removed", func(t *testing.T) {
+		ch := &filterapi.ConsistentHash{Type: filterapi.ConsistentHashTypeHeader, Header: "x-session-id"}
+		before := newConsistentHashRouter(t, ch,
+			filterapi.Backend{Name: "foo", Weight: 1}, filterapi.Backend{Name: "bar", Weight: 1}, filterapi.Backend{Name: "baz", Weight: 1},
+		)
+		// The config is reloaded without bar.
+		after := newConsistentHashRouter(t, ch, filterapi.Backend{Name: "foo", Weight: 1}, filterapi.Backend{Name: "baz", Weight: 1})
+		for i := range 1000 {
+			headers := map[string]string{"x-session-id": strconv.Itoa(i)}
+			if b := calculate(before, headers); b != "bar" {
+				// Only the sessions on the removed backend move.
+				require.Equal(t, b, calculate(after, headers))
+			}
+		}
+	})
+}
+
+func TestUnitHash(t *testing.T) {
+	for i := range 1000 {
+		h := unitHash(strconv.Itoa(i), "foo")
+		require.Greater(t, h, 0.0)
+		require.Less(t, h, 1.0)
+	}
+}
@@ -6,25 +6,86 @@
 package router
 
 import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/exp/rand"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/envoyproxy/ai-gateway/filterapi"
 	"github.com/envoyproxy/ai-gateway/filterapi/x"
 )
 
+// EstimatedInputTokensKey is the key in the headers passed to [x.Router.Calculate] holding the estimated number of
+// the input tokens of the request body, which is matched by [filterapi.RouteRuleMatch.EstimatedInputTokens].
+// This is a pseudo header so that it cannot be set by the client.
+const EstimatedInputTokensKey = ":ai-eg-estimated-input-tokens"
+
 // router implements [x.Router].
 type router struct {
 	rules []filterapi.RouteRule
+	// regexps is the compiled regular expressions of the RegularExpression header matches in the rules,
+	// keyed by the value of the header match.
+	regexps map[string]*regexp.Regexp
+	// stats is the observed performance of the backends in the rules with the adaptive load balancing policy,
+	// keyed by the backend name. This is populated at the construction and never modified afterward.
+	stats map[string]*backendStats
+	// breakers is the circuit breakers of the backends in the rules with the circuit breaker configured,
+	// keyed by the backend in the rule, so that each rule has its own breaker of the backends shared across rules.
+	// This is populated at the construction and never modified afterward.
+	breakers map[*filterapi.Backend]*circuitBreaker
+	// breakersByName is the same circuit breakers keyed by the backend name, which are used to observe the
+	// backends not in the rules, e.g. the fallbacks.
+	breakersByName map[string][]*circuitBreaker
 }
 
-// New creates a new [x.Router] implementation for the given config.
-func New(config *filterapi.Config, newCustomFn x.NewCustomRouterFn) (x.Router, error) {
-	r := &router{rules: config.Rules}
-	if newCustomFn != nil {
-		customRouter := newCustomFn(r, config)
-		return customRouter, nil
+// New creates the default [x.Router] implementation for the given config, which implements [Observer] as well.
+func New(config *filterapi.Config) (x.Router, error) {
+	r := &router{
+		rules:          config.Rules,
+		regexps:        make(map[string]*regexp.Regexp),
+		stats:          make(map[string]*backendStats),
+		breakers:       make(map[*filterapi.Backend]*circuitBreaker),
+		breakersByName: make(map[string][]*circuitBreaker),
+	}
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if isAdaptive(rule) {
+			for j := range rule.Backends {
+				if _, ok := r.stats[rule.Backends[j].Name]; !ok {
+					r.stats[rule.Backends[j].Name] = &backendStats{}
+				}
+			}
+		}
+		if rule.CircuitBreaker != nil {
+			for j := range rule.Backends {
+				b := &rule.Backends[j]
+				cb := newCircuitBreaker(rule.CircuitBreaker)
+				r.breakers[b] = cb
+				r.breakersByName[b.Name] = append(r.breakersByName[b.Name], cb)
+			}
+		}
+		hdrs := rule.Headers
+		for _, m := range rule.Matches {
+			hdrs = slices.Concat(hdrs, m.Headers)
+		}
+		for _, hdr := range hdrs {
+			if hdr.Type == nil || *hdr.Type != gwapiv1.HeaderMatchRegularExpression {
+				continue
+			}
+			if _, ok := r.regexps[hdr.Value]; ok {
+				continue
+			}
+			re, err := regexp.Compile("^(?:" + hdr.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q in the header match %s: %w", hdr.Value, hdr.Name, err)
+			}
+			r.regexps[hdr.Value] = re
+		}
 	}
 	return r, nil
 }
@@ -33,48 +94,111 @@ func New(config *filterapi.Config, newCustomFn x.NewCustomRouterFn) (x.Router, e
 func (r *router) Calculate(headers map[string]string) (backend *filterapi.Backend, err error) {
 	var rule *filterapi.RouteRule
 	for i := range r.rules {
-		_rule := &r.rules[i]
-		for _, hdr := range _rule.Headers {
-			v, ok := headers[string(hdr.Name)]
-			// Currently, we only do the exact matching.
-			if ok && v == hdr.Value {
-				rule = _rule
-				break
-			}
+		if r.ruleMatches(&r.rules[i], headers) {
+			rule = &r.rules[i]
 		}
 	}
 	if rule == nil || len(rule.Backends) == 0 {
 		return nil, x.ErrNoMatchingRule
 	}
-	return r.selectBackendFromRule(rule), nil
+	return r.selectBackendFromRule(rule, headers)
 }
 
-// selectBackendFromRule selects a backend from the given rule. Precondition: len(rule.Backends) > 0.
-func (r *router) selectBackendFromRule(rule *filterapi.RouteRule) (backend *filterapi.Backend) {
-	if len(rule.Backends) == 1 {
-		return &rule.Backends[0]
+// ruleMatches returns true if any of the headers or the matches of the given rule matches the request headers.
+func (r *router) ruleMatches(rule *filterapi.RouteRule, headers map[string]string) bool {
+	for i := range rule.Headers {
+		if r.headerMatches(&rule.Headers[i], headers) {
+			return true
+		}
+	}
+	for _, m := range rule.Matches {
+		matched := true
+		for i := range m.Headers {
+			if !r.headerMatches(&m.Headers[i], headers) {
+				matched = false
+				break
+			}
+		}
+		if matched && m.EstimatedInputTokens != nil {
+			matched = estimatedInputTokensMatch(m.EstimatedInputTokens, headers)
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// estimatedInputTokensMatch returns true if the [EstimatedInputTokensKey] in the request headers is within the given range.
+func estimatedInputTokensMatch(r *filterapi.TokenRange, headers map[string]string) bool {
+	tokens, err := strconv.ParseInt(headers[EstimatedInputTokensKey], 10, 64)
+	if err != nil {
+		return false
+	}
+	return (r.Min == nil || tokens >= *r.Min) && (r.Max == nil || tokens < *r.Max)
+}
+
+// headerMatches returns true if the given header match matches the request headers.
+func (r *router) headerMatches(hdr *filterapi.HeaderMatch, headers map[string]string) bool {
+	v, ok := headers[strings.ToLower(string(hdr.Name))]
+	if !ok {
+		return false
+	}
+	if hdr.Type == nil {
+		return v == hdr.Value
+	}
+	switch *hdr.Type {
+	case filterapi.HeaderMatchPrefix:
+		return strings.HasPrefix(v, hdr.Value)
+	case gwapiv1.HeaderMatchRegularExpression:
+		return r.regexps[hdr.Value].MatchString(v)
+	default:
+		return v == hdr.Value
+	}
+}
+
+// isAdaptive returns true if the given rule uses the adaptive load balancing policy.
+func isAdaptive(rule *filterapi.RouteRule) bool {
+	return rule.LoadBalancingPolicy != nil && rule.LoadBalancingPolicy.Type == filterapi.LoadBalancingPolicyTypeAdaptive
+}
+
+// selectBackendFromRule selects a backend from the given rule for the request with the given headers.
+// This returns [x.ErrNoAvailableBackend] if none of the backends is selectable.
+// Precondition: len(rule.Backends) > 0.
+func (r *router) selectBackendFromRule(rule *filterapi.RouteRule, headers map[string]string) (backend *filterapi.Backend, err error) {
+	backends := r.selectableBackends(rule)
+	switch len(backends) {
+	case 0:
+		return nil, x.ErrNoAvailableBackend
+	case 1:
+		return backends[0], nil
+	}
+	if isAdaptive(rule) {
+		return r.selectBackendAdaptively(backends), nil
+	}
+	if key := sessionKey(rule, headers); key != "" {
+		return selectBackendByHash(backends, key), nil
 	}
 
 	// Each backend has a weight, so we randomly select depending on the weight.
 	// This is a pretty naive implementation and can be buggy, so fix it later.
 	totalWeight := 0
-	for _, b := range rule.Backends {
+	for _, b := range backends {
 		totalWeight += b.Weight
 	}
 
 	rng := rand.New(rand.NewSource(uint64(time.Now().UnixNano()))) // nolint:gosec
 	// Pick a random backend if none of them have a weight.
 	if totalWeight == 0 {
-		return &rule.Backends[rng.Intn(len(rule.Backends))]
+		return backends[rng.Intn(len(backends))], nil
 	}
 
 	selected := rng.Intn(totalWeight)
-	for i := range rule.Backends {
-		b := &rule.Backends[i]
+	for _, b := range backends {
 		if selected < b.Weight {
-			return b
+			return b, nil
 		}
 		selected -= b.Weight
 	}
-	return &rule.Backends[0]
+	return backends[0], nil
 }
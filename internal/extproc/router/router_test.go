@@ -11,35 +11,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/envoyproxy/ai-gateway/filterapi"
 	"github.com/envoyproxy/ai-gateway/filterapi/x"
 )
 
-// dummyCustomRouter implements [filterapi.Router].
-type dummyCustomRouter struct{ called bool }
-
-func (c *dummyCustomRouter) Calculate(map[string]string) (*filterapi.Backend, error) {
-	c.called = true
-	return nil, nil
-}
-
-func TestRouter_NewRouter_Custom(t *testing.T) {
-	r, err := New(&filterapi.Config{}, func(defaultRouter x.Router, _ *filterapi.Config) x.Router {
-		require.NotNil(t, defaultRouter)
-		_, ok := defaultRouter.(*router)
-		require.True(t, ok) // Checking if the default router is correctly passed.
-		return &dummyCustomRouter{}
-	})
-	require.NoError(t, err)
-	_, ok := r.(*dummyCustomRouter)
-	require.True(t, ok)
-
-	_, err = r.Calculate(nil)
-	require.NoError(t, err)
-	require.True(t, r.(*dummyCustomRouter).called)
-}
-
 func TestRouter_Calculate(t *testing.T) {
 	outSchema := filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}
 	_r, err := New(&filterapi.Config{
@@ -71,7 +49,7 @@ func TestRouter_Calculate(t *testing.T) {
 				},
 			},
 		},
-	}, nil)
+	})
 	require.NoError(t, err)
 	r, ok := _r.(*router)
 	require.True(t, ok)
@@ -141,8 +119,156 @@ func TestRouter_Calculate(t *testing.T) {
 	})
 }
 
+func TestRouter_Calculate_Matches(t *testing.T) {
+	outSchema := filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}
+	prefix, regex := filterapi.HeaderMatchPrefix, gwapiv1.HeaderMatchRegularExpression
+	r, err := New(&filterapi.Config{
+		Rules: []filterapi.RouteRule{
+			{
+				Backends: []filterapi.Backend{{Name: "llama", Schema: outSchema}},
+				Matches: []filterapi.RouteRuleMatch{
+					{Headers: []filterapi.HeaderMatch{{Name: "x-model-name", Value: "llama3-.*", Type: &regex}}},
+					{Headers: []filterapi.HeaderMatch{{Name: "x-model-name", Value: "mistral-", Type: &prefix}}},
+				},
+			},
+			{
+				Backends: []filterapi.Backend{{Name: "tenant", Schema: outSchema}},
+				Matches: []filterapi.RouteRuleMatch{
+					{Headers: []filterapi.HeaderMatch{
+						{Name: "x-model-name", Value: "llama3-.*", Type: &regex},
+						{Name: "X-Tenant", Value: "apple"},
+					}},
+				},
+			},
+			{
+				Backends: []filterapi.Backend{{Name: "legacy", Schema: outSchema}},
+				Headers:  []filterapi.HeaderMatch{{Name: "x-model-name", Value: "gpt4"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		exp     string
+	}{
+		{name: "all headers match", headers: map[string]string{"x-model-name": "llama3-70b", "x-tenant": "apple"}, exp: "tenant"},
+		{name: "one of the headers does not match", headers: map[string]string{"x-model-name": "llama3-70b", "x-tenant": "orange"}, exp: "llama"},
+		{name: "regex", headers: map[string]string{"x-model-name": "llama3-8b"}, exp: "llama"},
+		{name: "regex matches the whole value", headers: map[string]string{"x-model-name": "my-llama3-8b"}},
+		{name: "prefix", headers: map[string]string{"x-model-name": "mistral-large"}, exp: "llama"},
+		{name: "prefix mismatch", headers: map[string]string{"x-model-name": "mistra"}},
+		{name: "legacy headers", headers: map[string]string{"x-model-name": "gpt4"}, exp: "legacy"},
+		{name: "no headers", headers: map[string]string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := r.Calculate(tc.headers)
+			if tc.exp == "" {
+				require.ErrorIs(t, err, x.ErrNoMatchingRule)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, b.Name)
+		})
+	}
+}
+
+func TestRouter_Calculate_LastMatchingRule(t *testing.T) {
+	outSchema := filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}
+	prefix := filterapi.HeaderMatchPrefix
+	r, err := New(&filterapi.Config{
+		Rules: []filterapi.RouteRule{
+			{
+				Backends: []filterapi.Backend{{Name: "shadowed", Schema: outSchema}},
+				Headers:  []filterapi.HeaderMatch{{Name: "x-model-name", Value: "gpt-4o"}},
+			},
+			{
+				Backends: []filterapi.Backend{{Name: "catch-all", Schema: outSchema}},
+				Headers:  []filterapi.HeaderMatch{{Name: "x-model-name", Value: "gpt-", Type: &prefix}},
+			},
+			{
+				Backends: []filterapi.Backend{{Name: "specific", Schema: outSchema}},
+				Headers:  []filterapi.HeaderMatch{{Name: "x-model-name", Value: "gpt-4o"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// The last matching rule in the list is used even when the earlier ones match as well.
+	b, err := r.Calculate(map[string]string{"x-model-name": "gpt-4o"})
+	require.NoError(t, err)
+	require.Equal(t, "specific", b.Name)
+	b, err = r.Calculate(map[string]string{"x-model-name": "gpt-4o-mini"})
+	require.NoError(t, err)
+	require.Equal(t, "catch-all", b.Name)
+}
+
+func TestRouter_Calculate_EstimatedInputTokens(t *testing.T) {
+	outSchema := filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}
+	modelHeader := []filterapi.HeaderMatch{{Name: "x-model-name", Value: "auto"}}
+	r, err := New(&filterapi.Config{
+		Rules: []filterapi.RouteRule{
+			{
+				Backends: []filterapi.Backend{{Name: "long", Schema: outSchema}},
+				Matches: []filterapi.RouteRuleMatch{
+					{Headers: modelHeader, EstimatedInputTokens: &filterapi.TokenRange{Min: ptr.To[int64](100000)}},
+				},
+			},
+			{
+				Backends: []filterapi.Backend{{Name: "medium", Schema: outSchema}},
+				Matches: []filterapi.RouteRuleMatch{
+					{Headers: modelHeader, EstimatedInputTokens: &filterapi.TokenRange{Min: ptr.To[int64](1000), Max: ptr.To[int64](100000)}},
+				},
+			},
+			{
+				Backends: []filterapi.Backend{{Name: "short", Schema: outSchema}},
+				Matches: []filterapi.RouteRuleMatch{
+					{Headers: modelHeader, EstimatedInputTokens: &filterapi.TokenRange{Max: ptr.To[int64](1000)}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		tokens string
+		exp    string
+	}{
+		{tokens: "0", exp: "short"},
+		{tokens: "999", exp: "short"},
+		{tokens: "1000", exp: "medium"},
+		{tokens: "99999", exp: "medium"},
+		{tokens: "100000", exp: "long"},
+		{tokens: ""},
+		{tokens: "invalid"},
+	} {
+		t.Run(tc.tokens, func(t *testing.T) {
+			b, err := r.Calculate(map[string]string{"x-model-name": "auto", EstimatedInputTokensKey: tc.tokens})
+			if tc.exp == "" {
+				require.ErrorIs(t, err, x.ErrNoMatchingRule)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, b.Name)
+		})
+	}
+}
+
+func TestRouter_New_InvalidRegex(t *testing.T) {
+	regex := gwapiv1.HeaderMatchRegularExpression
+	_, err := New(&filterapi.Config{
+		Rules: []filterapi.RouteRule{{
+			Matches: []filterapi.RouteRuleMatch{
+				{Headers: []filterapi.HeaderMatch{{Name: "x-model-name", Value: "llama(", Type: &regex}}},
+			},
+		}},
+	})
+	require.ErrorContains(t, err, `invalid regular expression "llama(" in the header match x-model-name`)
+}
+
 func TestRouter_selectBackendFromRule(t *testing.T) {
-	_r, err := New(&filterapi.Config{}, nil)
+	_r, err := New(&filterapi.Config{})
 	require.NoError(t, err)
 	r, ok := _r.(*router)
 	require.True(t, ok)
@@ -158,7 +284,8 @@ func TestRouter_selectBackendFromRule(t *testing.T) {
 
 	chosenNames := make(map[string]int)
 	for i := 0; i < 1000; i++ {
-		b := r.selectBackendFromRule(rule)
+		b, err := r.selectBackendFromRule(rule, nil)
+		require.NoError(t, err)
 		chosenNames[b.Name]++
 	}
 
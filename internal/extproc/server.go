@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"slices"
 	"strings"
 	"unicode/utf8"
@@ -27,12 +28,15 @@ import (
 	"github.com/envoyproxy/ai-gateway/filterapi/x"
 	"github.com/envoyproxy/ai-gateway/internal/extproc/backendauth"
 	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
 	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
 )
 
 var (
 	sensitiveHeaderRedactedValue = []byte("[REDACTED]")
-	sensitiveHeaderKeys          = []string{"authorization"}
+	// sensitiveHeaderKeys are the lowercase keys of the headers redacted in the logs. The header names of the
+	// api keys configured for the backends are redacted in addition to these.
+	sensitiveHeaderKeys = []string{"authorization", "api-key", "x-api-key"}
 )
 
 // Server implements the external processor server.
@@ -40,6 +44,16 @@ type Server struct {
 	logger     *slog.Logger
 	config     *processorConfig
 	processors map[string]ProcessorFactory
+	// patternProcessors are the processors registered for the paths with "{param}" segments, in the registration order.
+	patternProcessors []patternProcessor
+	// tokenizer is used to estimate the number of tokens.
+	tokenizer tokenizer.Tokenizer
+}
+
+// patternProcessor is a processor registered for the path pattern, split into the segments.
+type patternProcessor struct {
+	segments     []string
+	newProcessor ProcessorFactory
 }
 
 // NewServer creates a new external processor server.
@@ -47,35 +61,98 @@ func NewServer(logger *slog.Logger) (*Server, error) {
 	srv := &Server{
 		logger:     logger,
 		processors: make(map[string]ProcessorFactory),
+		tokenizer:  tokenizer.Heuristic{},
 	}
 	return srv, nil
 }
 
+// SetTokenizer sets the tokenizer used to estimate the number of tokens, which is [tokenizer.Heuristic] by default.
+// This must be called before the configuration is loaded.
+func (s *Server) SetTokenizer(t tokenizer.Tokenizer) {
+	s.tokenizer = t
+}
+
+// newCustomRouter creates the router used for the given config, which is the custom router created by
+// [x.NewCustomRouterV2] or [x.NewCustomRouter] if set, and the defaultRouter otherwise. The custom [x.RouterV2]
+// is returned as rtV2 with the defaultRouter as rt.
+func newCustomRouter(defaultRouter x.Router, config *filterapi.Config) (rt x.Router, rtV2 x.RouterV2) {
+	switch {
+	case x.NewCustomRouterV2 != nil:
+		return defaultRouter, x.NewCustomRouterV2(defaultRouter, config)
+	case x.NewCustomRouter != nil:
+		return x.NewCustomRouter(defaultRouter, config), nil
+	default:
+		return defaultRouter, nil
+	}
+}
+
 // LoadConfig updates the configuration of the external processor.
 func (s *Server) LoadConfig(ctx context.Context, config *filterapi.Config) error {
-	rt, err := router.New(config, x.NewCustomRouter)
+	defaultRouter, err := router.New(config)
 	if err != nil {
 		return fmt.Errorf("cannot create router: %w", err)
 	}
+	rt, rtV2 := newCustomRouter(defaultRouter, config)
+	observer, ok := defaultRouter.(router.Observer)
+	if !ok {
+		panic("BUG: the default router must implement router.Observer")
+	}
 
 	var (
 		backendAuthHandlers = make(map[string]backendauth.Handler)
 		declaredModels      []string
+		fallbacks           = make(map[*filterapi.Backend][]filterapi.Backend)
+		sensitiveKeys       = slices.Clone(sensitiveHeaderKeys)
+		mirrors             = make(map[*filterapi.Backend]*filterapi.Backend)
+		backendHTTPClients  = make(map[string]*http.Client)
+		estimateInputTokens bool
 	)
-	for _, r := range config.Rules {
-		for _, b := range r.Backends {
+	for i := range config.Rules {
+		r := &config.Rules[i]
+		if len(r.Fallbacks) > 0 {
+			for j := range r.Backends {
+				fallbacks[&r.Backends[j]] = r.Fallbacks
+			}
+		}
+		withAuth := slices.Concat(r.Backends, r.Fallbacks)
+		if r.Mirror != nil {
+			for j := range r.Backends {
+				mirrors[&r.Backends[j]] = r.Mirror
+			}
+			withAuth = append(withAuth, *r.Mirror)
+		}
+		for _, b := range withAuth {
+			if b.EndpointTLS != nil {
+				backendHTTPClients[b.Name], err = newBackendHTTPClient(b.EndpointTLS)
+				if err != nil {
+					return fmt.Errorf("cannot create HTTP client for backend %s: %w", b.Name, err)
+				}
+			}
 			if b.Auth != nil {
 				backendAuthHandlers[b.Name], err = backendauth.NewHandler(ctx, b.Auth)
 				if err != nil {
 					return fmt.Errorf("cannot create backend auth handler: %w", err)
 				}
+				if b.Auth.APIKey != nil && b.Auth.APIKey.HeaderName != "" {
+					if key := strings.ToLower(b.Auth.APIKey.HeaderName); !slices.Contains(sensitiveKeys, key) {
+						sensitiveKeys = append(sensitiveKeys, key)
+					}
+				}
 			}
 		}
 		// Collect declared models from configured header routes. These will be used to
-		// serve requests to the /v1/models endpoint.
-		// TODO(nacx): note that currently we only support exact matching in the headers. When
-		// header matching is extended, this will need to be updated.
-		for _, h := range r.Headers {
+		// serve requests to the /v1/models endpoint. Only the exact matches of the model name header are listed
+		// since the prefix and regular expression matches do not declare a concrete model name, and the other
+		// headers, e.g. the tenant AND-ed with the model name, are not models.
+		hdrs := r.Headers
+		for _, m := range r.Matches {
+			hdrs = slices.Concat(hdrs, m.Headers)
+			estimateInputTokens = estimateInputTokens || m.EstimatedInputTokens != nil
+		}
+		for _, h := range hdrs {
+			if string(h.Name) != config.ModelNameHeaderKey {
+				continue
+			}
 			// If explicitly set to something that is not an exact match, skip.
 			// If not set, we assume it's an exact match.
 			if h.Type != nil && *h.Type != gwapiv1.HeaderMatchExact {
@@ -98,37 +175,88 @@ func (s *Server) LoadConfig(ctx context.Context, config *filterapi.Config) error
 		costs = append(costs, processorConfigRequestCost{LLMRequestCost: c, celProg: prog})
 	}
 
+	var previousBudgets []*tokenBudget
+	if s.config != nil {
+		previousBudgets = s.config.tokenBudgets
+	}
 	newConfig := &processorConfig{
 		uuid:                     config.UUID,
 		schema:                   config.Schema,
 		router:                   rt,
+		routerV2:                 rtV2,
+		observer:                 observer,
 		selectedBackendHeaderKey: config.SelectedBackendHeaderKey,
 		modelNameHeaderKey:       config.ModelNameHeaderKey,
 		backendAuthHandlers:      backendAuthHandlers,
 		metadataNamespace:        config.MetadataNamespace,
 		requestCosts:             costs,
 		declaredModels:           declaredModels,
+		fallbacks:                fallbacks,
+		mirrors:                  mirrors,
+		backendHTTPClients:       backendHTTPClients,
+		estimateInputTokens:      estimateInputTokens,
+		tokenBudgets:             newTokenBudgets(config.TokenBudgets, config.GatewayAPIKeys, previousBudgets),
+		requestLimits:            config.RequestLimits,
+		forceStreamUsage:         config.ForceStreamUsage,
+		tokenizer:                s.tokenizer,
+		apiKeys:                  newGatewayAPIKeys(config.GatewayAPIKeys),
+		sensitiveHeaderKeys:      sensitiveKeys,
 	}
 	s.config = newConfig // This is racey, but we don't care.
 	return nil
 }
 
 // Register a new processor for the given request path.
+//
+// The path can contain segments of the form "{param}", e.g. "/model/{modelId}/converse", each of which
+// matches any single non-empty path segment.
 func (s *Server) Register(path string, newProcessor ProcessorFactory) {
-	s.processors[path] = newProcessor
+	if !strings.Contains(path, "{") {
+		s.processors[path] = newProcessor
+		return
+	}
+	s.patternProcessors = append(s.patternProcessors, patternProcessor{
+		segments: strings.Split(path, "/"), newProcessor: newProcessor,
+	})
 }
 
 // processorForPath returns the processor for the given path.
-// Only exact path matching is supported currently
+// The exact path match takes precedence over the path patterns.
 func (s *Server) processorForPath(requestHeaders map[string]string) (Processor, error) {
 	path := requestHeaders[":path"]
 	newProcessor, ok := s.processors[path]
+	if !ok {
+		segments := strings.Split(path, "/")
+		for i := range s.patternProcessors {
+			if p := &s.patternProcessors[i]; pathSegmentsMatch(p.segments, segments) {
+				newProcessor, ok = p.newProcessor, true
+				break
+			}
+		}
+	}
 	if !ok {
 		return nil, fmt.Errorf("no processor defined for path: %v", path)
 	}
 	return newProcessor(s.config, requestHeaders, s.logger)
 }
 
+// pathSegmentsMatch returns true if the path segments match the pattern segments.
+func pathSegmentsMatch(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i, p := range pattern {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			if path[i] == "" {
+				return false
+			}
+		} else if p != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // Process implements [extprocv3.ExternalProcessorServer].
 func (s *Server) Process(stream extprocv3.ExternalProcessor_ProcessServer) error {
 	s.logger.Debug("handling a new stream", slog.Any("config_uuid", s.config.uuid))
@@ -142,6 +270,12 @@ func (s *Server) Process(stream extprocv3.ExternalProcessor_ProcessServer) error
 	// the request by sending an immediate response. In this case, we will use the passThroughProcessor
 	// to pass the request through without any processing as there would be nothing to process from AI Gateway's perspective.
 	var p Processor = passThroughProcessor{}
+	defer func() {
+		// Some processors hold the resources for the request, e.g. the in-flight request of the adaptive load balancing.
+		if c, ok := p.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}()
 
 	for {
 		select {
@@ -191,7 +325,7 @@ func (s *Server) processMsg(ctx context.Context, p Processor, req *extprocv3.Pro
 		requestHdrs := req.GetRequestHeaders().Headers
 		// If DEBUG log level is enabled, filter sensitive headers before logging.
 		if s.logger.Enabled(ctx, slog.LevelDebug) {
-			filteredHdrs := filterSensitiveHeadersForLogging(requestHdrs, sensitiveHeaderKeys)
+			filteredHdrs := filterSensitiveHeadersForLogging(requestHdrs, s.sensitiveHeaderKeys())
 			s.logger.Debug("request headers processing", slog.Any("request_headers", filteredHdrs))
 		}
 		resp, err := p.ProcessRequestHeaders(ctx, requestHdrs)
@@ -205,7 +339,7 @@ func (s *Server) processMsg(ctx context.Context, p Processor, req *extprocv3.Pro
 		resp, err := p.ProcessRequestBody(ctx, value.RequestBody)
 		// If DEBUG log level is enabled, filter sensitive body before logging.
 		if s.logger.Enabled(ctx, slog.LevelDebug) {
-			filteredBody := filterSensitiveBodyForLogging(resp, s.logger, sensitiveHeaderKeys)
+			filteredBody := filterSensitiveBodyForLogging(resp, s.logger, s.sensitiveHeaderKeys())
 			s.logger.Debug("request body processed", slog.Any("response", filteredBody))
 		}
 		if err != nil {
@@ -245,8 +379,16 @@ func (s *Server) Watch(*grpc_health_v1.HealthCheckRequest, grpc_health_v1.Health
 	return status.Error(codes.Unimplemented, "Watch is not implemented")
 }
 
+// sensitiveHeaderKeys returns the lowercase keys of the headers redacted in the logs.
+func (s *Server) sensitiveHeaderKeys() []string {
+	if s.config == nil || len(s.config.sensitiveHeaderKeys) == 0 {
+		return sensitiveHeaderKeys
+	}
+	return s.config.sensitiveHeaderKeys
+}
+
 // filterSensitiveHeadersForLogging filters out sensitive headers from the provided HeaderMap for logging.
-// Specifically, it redacts the value of the "authorization" header and logs this action.
+// Specifically, it redacts the value of the sensitive headers such as "authorization" and logs this action.
 // This returns a slice of [slog.Attr] of headers, where the value of sensitive headers is redacted.
 func filterSensitiveHeadersForLogging(headers *corev3.HeaderMap, sensitiveKeys []string) []slog.Attr {
 	if headers == nil {
@@ -272,11 +414,17 @@ func filterSensitiveHeadersForLogging(headers *corev3.HeaderMap, sensitiveKeys [
 // It creates a copy of the response body to avoid modifying the original body,
 // as the API Key is needed for the request. The function returns a new
 // ProcessingResponse with the filtered body for logging.
+//
+// The response other than the request body response, e.g. the immediate response rejecting the request, carries no
+// credentials and is returned as is.
 func filterSensitiveBodyForLogging(resp *extprocv3.ProcessingResponse, logger *slog.Logger, sensitiveKeys []string) *extprocv3.ProcessingResponse {
 	if resp == nil {
 		return &extprocv3.ProcessingResponse{}
 	}
-	original := resp.Response.(*extprocv3.ProcessingResponse_RequestBody)
+	original, ok := resp.Response.(*extprocv3.ProcessingResponse_RequestBody)
+	if !ok {
+		return resp
+	}
 	originalHeaderMutation := original.RequestBody.Response.GetHeaderMutation()
 	redactedHeaderMutation := &extprocv3.HeaderMutation{
 		RemoveHeaders: originalHeaderMutation.GetRemoveHeaders(),
@@ -10,17 +10,25 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/filterapi/x"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
 	"github.com/envoyproxy/ai-gateway/internal/llmcostcel"
 )
 
@@ -64,6 +72,12 @@ func TestServer_LoadConfig(t *testing.T) {
 					Backends: []filterapi.Backend{
 						{Name: "openai", Schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}},
 					},
+					Fallbacks: []filterapi.Backend{
+						{Name: "azure", Schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaAzureOpenAI}, Endpoint: "https://azure:443"},
+					},
+					Mirror: &filterapi.Backend{
+						Name: "bedrock", Schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaAWSBedrock}, Endpoint: "https://bedrock:443",
+					},
 					Headers: []filterapi.HeaderMatch{
 						{
 							Name:  "x-model-name",
@@ -71,6 +85,24 @@ func TestServer_LoadConfig(t *testing.T) {
 						},
 					},
 				},
+				{
+					Backends: []filterapi.Backend{
+						{Name: "mistral", Schema: filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}},
+					},
+					Matches: []filterapi.RouteRuleMatch{
+						{Headers: []filterapi.HeaderMatch{
+							{Name: "x-model-name", Value: "mistral-", Type: ptr.To(filterapi.HeaderMatchPrefix)},
+						}},
+						{Headers: []filterapi.HeaderMatch{
+							{Name: "x-model-name", Value: "mixtral", Type: ptr.To(gwapiv1.HeaderMatchExact)},
+							{Name: "x-tenant", Value: "foo-", Type: ptr.To(filterapi.HeaderMatchPrefix)},
+						}},
+						{Headers: []filterapi.HeaderMatch{
+							{Name: "x-model-name", Value: "mistral-large"},
+							{Name: "x-tenant", Value: "acme"},
+						}},
+					},
+				},
 			},
 		}
 		s, _ := requireNewServerWithMockProcessor(t)
@@ -91,9 +123,84 @@ func TestServer_LoadConfig(t *testing.T) {
 		require.Equal(t, "1 + 1", s.config.requestCosts[1].CEL)
 		prog := s.config.requestCosts[1].celProg
 		require.NotNil(t, prog)
-		val, err := llmcostcel.EvaluateProgram(prog, "", "", 1, 1, 1)
+		val, err := llmcostcel.EvaluateProgram(prog, &llmcostcel.Variables{InputTokens: 1, OutputTokens: 1, TotalTokens: 1}, 1, "")
 		require.NoError(t, err)
 		require.Equal(t, uint64(2), val)
+
+		// The exact match of the tenant AND-ed with the model name is not a model.
+		require.Equal(t, []string{"llama3.3333", "gpt4.4444", "mixtral", "mistral-large"}, s.config.declaredModels)
+
+		require.Len(t, s.config.fallbacks, 1)
+		require.Equal(t, config.Rules[1].Fallbacks, s.config.fallbacks[&config.Rules[1].Backends[0]])
+		require.Nil(t, s.config.fallbacks[&config.Rules[0].Backends[0]])
+		require.Len(t, s.config.mirrors, 1)
+		require.Equal(t, config.Rules[1].Mirror, s.config.mirrors[&config.Rules[1].Backends[0]])
+		require.Nil(t, s.config.routerV2)
+		require.Equal(t, tokenizer.Heuristic{}, s.config.tokenizer)
+
+		bpe, err := tokenizer.NewBPE(strings.NewReader("aA== 0\n"))
+		require.NoError(t, err)
+		s.SetTokenizer(bpe)
+		require.NoError(t, s.LoadConfig(t.Context(), config))
+		require.Same(t, bpe, s.config.tokenizer)
+	})
+	t.Run("sensitive header keys", func(t *testing.T) {
+		apiKeyFile := t.TempDir() + "/apiKey"
+		require.NoError(t, os.WriteFile(apiKeyFile, []byte("key"), 0o600))
+		s, _ := requireNewServerWithMockProcessor(t)
+		require.Equal(t, sensitiveHeaderKeys, s.sensitiveHeaderKeys())
+		require.NoError(t, s.LoadConfig(t.Context(), &filterapi.Config{Rules: []filterapi.RouteRule{{
+			Backends: []filterapi.Backend{
+				{Name: "azure", Auth: &filterapi.BackendAuth{APIKey: &filterapi.APIKeyAuth{Filename: apiKeyFile, HeaderName: "api-key"}}},
+			},
+			Fallbacks: []filterapi.Backend{
+				{Name: "apim", Auth: &filterapi.BackendAuth{APIKey: &filterapi.APIKeyAuth{Filename: apiKeyFile, HeaderName: "Ocp-Apim-Subscription-Key"}}},
+			},
+		}}}))
+		require.Equal(t, []string{"authorization", "api-key", "x-api-key", "ocp-apim-subscription-key"}, s.sensitiveHeaderKeys())
+		filtered := filterSensitiveHeadersForLogging(&corev3.HeaderMap{Headers: []*corev3.HeaderValue{
+			{Key: "Ocp-Apim-Subscription-Key", Value: "sensitive"}, {Key: "api-key", Value: "sensitive"},
+		}}, s.sensitiveHeaderKeys())
+		require.Equal(t, []slog.Attr{
+			slog.String("Ocp-Apim-Subscription-Key", "[REDACTED]"),
+			slog.String("api-key", "[REDACTED]"),
+		}, filtered)
+	})
+	t.Run("custom router", func(t *testing.T) {
+		var defaultRouter x.Router
+		x.NewCustomRouter = func(r x.Router, _ *filterapi.Config) x.Router {
+			defaultRouter = r
+			return mockRouter{}
+		}
+		defer func() { x.NewCustomRouter = nil }()
+
+		s, err := NewServer(slog.Default())
+		require.NoError(t, err)
+		require.NoError(t, s.LoadConfig(t.Context(), &filterapi.Config{}))
+		require.Equal(t, mockRouter{}, s.config.router)
+		require.Nil(t, s.config.routerV2)
+		// The backends are observed by the default router even though it is not used for the routing.
+		require.NotNil(t, defaultRouter)
+		require.Equal(t, defaultRouter, s.config.observer)
+	})
+	t.Run("custom router v2", func(t *testing.T) {
+		var defaultRouter x.Router
+		x.NewCustomRouterV2 = func(r x.Router, _ *filterapi.Config) x.RouterV2 {
+			defaultRouter = r
+			return mockRouterV2{}
+		}
+		// NewCustomRouterV2 takes precedence over NewCustomRouter.
+		x.NewCustomRouter = func(x.Router, *filterapi.Config) x.Router { return mockRouter{} }
+		defer func() { x.NewCustomRouter, x.NewCustomRouterV2 = nil, nil }()
+
+		s, err := NewServer(slog.Default())
+		require.NoError(t, err)
+		require.NoError(t, s.LoadConfig(t.Context(), &filterapi.Config{}))
+		require.Equal(t, mockRouterV2{}, s.config.routerV2)
+		// The default router is passed to the custom router and kept for the observation of the backends.
+		require.NotNil(t, defaultRouter)
+		require.Equal(t, defaultRouter, s.config.router)
+		require.Equal(t, defaultRouter, s.config.observer)
 	})
 }
 
@@ -202,6 +309,18 @@ func TestServer_processMsg(t *testing.T) {
 	})
 }
 
+// closingProcessor is a [Processor] implementing [io.Closer] for testing.
+type closingProcessor struct {
+	passThroughProcessor
+	closed bool
+}
+
+// Close implements [io.Closer].
+func (c *closingProcessor) Close() error {
+	c.closed = true
+	return nil
+}
+
 func TestServer_Process(t *testing.T) {
 	t.Run("context done", func(t *testing.T) {
 		s, _ := requireNewServerWithMockProcessor(t)
@@ -250,6 +369,24 @@ func TestServer_Process(t *testing.T) {
 		err := s.Process(ms)
 		require.ErrorContains(t, err, "context deadline exceeded")
 	})
+	t.Run("close processor", func(t *testing.T) {
+		s, err := NewServer(slog.Default())
+		require.NoError(t, err)
+		s.config = &processorConfig{}
+		p := &closingProcessor{}
+		s.Register("/", func(*processorConfig, map[string]string, *slog.Logger) (Processor, error) { return p, nil })
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		req := &extprocv3.ProcessingRequest{Request: &extprocv3.ProcessingRequest_RequestHeaders{
+			RequestHeaders: &extprocv3.HttpHeaders{Headers: &corev3.HeaderMap{Headers: []*corev3.HeaderValue{{Key: ":path", Value: "/"}}}},
+		}}
+		expResponse := &extprocv3.ProcessingResponse{Response: &extprocv3.ProcessingResponse_ResponseHeaders{}}
+		ms := &mockExternalProcessingStream{t: t, ctx: ctx, retRecv: req, expResponseOnSend: expResponse}
+		err = s.Process(ms)
+		require.ErrorContains(t, err, "context deadline exceeded")
+		require.True(t, p.closed)
+	})
 	t.Run("without going through request headers phase", func(t *testing.T) {
 		// This is a regression test as in #419.
 		s, _ := requireNewServerWithMockProcessor(t)
@@ -321,6 +458,41 @@ func TestServer_ProcessorSelection(t *testing.T) {
 	})
 }
 
+func TestServer_processorForPath(t *testing.T) {
+	s, err := NewServer(slog.Default())
+	require.NoError(t, err)
+	s.config = &processorConfig{}
+	newFactory := func(name string) ProcessorFactory {
+		return func(*processorConfig, map[string]string, *slog.Logger) (Processor, error) {
+			return &mockProcessor{expBody: &extprocv3.HttpBody{Body: []byte(name)}}, nil
+		}
+	}
+	s.Register("/model/{modelId}/converse", newFactory("converse"))
+	s.Register("/model/{modelId}/converse-stream", newFactory("converse-stream"))
+	s.Register("/model/exact/converse", newFactory("exact"))
+
+	for _, tc := range []struct {
+		path, exp string
+	}{
+		{path: "/model/foo/converse", exp: "converse"},
+		{path: "/model/foo/converse-stream", exp: "converse-stream"},
+		{path: "/model/exact/converse", exp: "exact"},
+		{path: "/model//converse"},
+		{path: "/model/foo/bar/converse"},
+		{path: "/model/foo/invoke"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			p, err := s.processorForPath(map[string]string{":path": tc.path})
+			if tc.exp == "" {
+				require.ErrorContains(t, err, "no processor defined for path: "+tc.path)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, string(p.(*mockProcessor).expBody.Body))
+		})
+	}
+}
+
 func Test_filterSensitiveHeadersForLogging(t *testing.T) {
 	hm := &corev3.HeaderMap{
 		Headers: []*corev3.HeaderValue{
@@ -382,6 +554,75 @@ func Test_filterSensitiveBodyForLogging(t *testing.T) {
 	require.Contains(t, buf.String(), "filtering sensitive header")
 }
 
+func TestServer_processMsg_debugImmediateResponse(t *testing.T) {
+	rt, err := router.New(&filterapi.Config{})
+	require.NoError(t, err)
+	budgets := newTokenBudgets([]filterapi.TokenBudget{
+		{Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser}, MetadataKey: "total", Limit: 100, Window: time.Hour},
+	}, nil, nil)
+	budgets[0].consumption.consume("some-user", 100)
+	apiKeys := newGatewayAPIKeys(&filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{
+		{Name: "alice", AllowedModels: []string{"gpt-4o-mini"}},
+	}})
+	const body = `{"model":"gpt-4o","user":"some-user","messages":[{"role":"user","content":"a"},{"role":"user","content":"b"}]}`
+
+	for _, tc := range []struct {
+		name           string
+		config         *processorConfig
+		requestHeaders map[string]string
+		exp            typev3.StatusCode
+	}{
+		{name: "no matching rule", config: &processorConfig{}, exp: typev3.StatusCode_NotFound},
+		{name: "token budget exhausted", config: &processorConfig{tokenBudgets: budgets}, exp: typev3.StatusCode_TooManyRequests},
+		{
+			name:   "request limits",
+			config: &processorConfig{requestLimits: &filterapi.RequestLimits{MaxMessages: 1}},
+			exp:    typev3.StatusCode_BadRequest,
+		},
+		{
+			name:           "model not found",
+			config:         &processorConfig{apiKeys: apiKeys},
+			requestHeaders: map[string]string{apiKeyNameKey: "alice"},
+			exp:            typev3.StatusCode_NotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			logger, buf := newTestLoggerWithBuffer()
+			s, err := NewServer(logger)
+			require.NoError(t, err)
+			tc.config.schema = filterapi.VersionedAPISchema{Name: filterapi.APISchemaOpenAI}
+			tc.config.modelNameHeaderKey, tc.config.router = "x-model-name", rt
+			s.config = tc.config
+			requestHeaders := map[string]string{":path": "/v1/chat/completions"}
+			for k, v := range tc.requestHeaders {
+				requestHeaders[k] = v
+			}
+			p, err := NewChatCompletionProcessor(tc.config, requestHeaders, logger)
+			require.NoError(t, err)
+
+			resp, err := s.processMsg(t.Context(), p, &extprocv3.ProcessingRequest{
+				Request: &extprocv3.ProcessingRequest_RequestBody{RequestBody: &extprocv3.HttpBody{Body: []byte(body)}},
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, resp.GetImmediateResponse().Status.Code)
+			require.Contains(t, buf.String(), "request body processed")
+		})
+	}
+	t.Run("error", func(t *testing.T) {
+		logger, buf := newTestLoggerWithBuffer()
+		s, err := NewServer(logger)
+		require.NoError(t, err)
+		s.config = &processorConfig{}
+		reqBody := &extprocv3.HttpBody{}
+		p := &mockProcessor{t: t, expBody: reqBody, retErr: errors.New("some error")}
+		_, err = s.processMsg(t.Context(), p, &extprocv3.ProcessingRequest{
+			Request: &extprocv3.ProcessingRequest_RequestBody{RequestBody: reqBody},
+		})
+		require.ErrorContains(t, err, "some error")
+		require.Contains(t, buf.String(), "request body processed")
+	})
+}
+
 func Test_headersToMap(t *testing.T) {
 	hm := &corev3.HeaderMap{
 		Headers: []*corev3.HeaderValue{
@@ -0,0 +1,198 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+)
+
+// tokenBudget enforces the [filterapi.TokenBudget] with the consumption kept in memory.
+type tokenBudget struct {
+	filterapi.TokenBudget
+	// apiKey is the name of the GatewayAPIKey the budget belongs to. When set, the budget only applies to the requests
+	// authenticated with the key.
+	apiKey      string
+	consumption *slidingWindows
+}
+
+// key returns the key of the budget for the request, or empty if the request does not have the key.
+func (b *tokenBudget) key(requestHeaders map[string]string) string {
+	if b.apiKey != "" && requestHeaders[apiKeyNameKey] != b.apiKey {
+		return ""
+	}
+	switch b.Key.Type {
+	case filterapi.TokenBudgetKeyTypeHeader:
+		return requestHeaders[b.Key.Header]
+	case filterapi.TokenBudgetKeyTypeUser:
+		return requestHeaders[router.UserKey]
+	case filterapi.TokenBudgetKeyTypeAPIKey:
+		return requestHeaders[apiKeyNameKey]
+	default:
+		return ""
+	}
+}
+
+// newTokenBudgets creates the token budgets of the given config including those of the GatewayAPIKeys, if any.
+// The consumption of the budget in the previous budgets with the same name and window is carried over so that
+// the configuration updates do not reset it.
+func newTokenBudgets(budgets []filterapi.TokenBudget, keys *filterapi.GatewayAPIKeys, previous []*tokenBudget) []*tokenBudget {
+	ret := make([]*tokenBudget, 0, len(budgets))
+	add := func(b filterapi.TokenBudget, apiKey string) {
+		tb := &tokenBudget{TokenBudget: b, apiKey: apiKey}
+		for _, p := range previous {
+			if p.Name == b.Name && p.Window == b.Window && p.apiKey == apiKey {
+				tb.consumption = p.consumption
+				break
+			}
+		}
+		if tb.consumption == nil {
+			tb.consumption = newSlidingWindows(b.Window)
+		}
+		ret = append(ret, tb)
+	}
+	for _, b := range budgets {
+		add(b, "")
+	}
+	if keys != nil {
+		for _, k := range keys.Keys {
+			for _, b := range k.TokenBudgets {
+				add(b, k.Name)
+			}
+		}
+	}
+	return ret
+}
+
+// checkTokenBudgets returns the immediate response with the status code 429 if any of the token budgets of the
+// request is exhausted. Otherwise, this returns nil.
+func checkTokenBudgets(config *processorConfig, requestHeaders map[string]string) (*extprocv3.ProcessingResponse, error) {
+	for _, b := range config.tokenBudgets {
+		key := b.key(requestHeaders)
+		if key == "" {
+			continue
+		}
+		if consumed := b.consumption.consumed(key); consumed >= b.Limit {
+			return tokenBudgetExhaustedResponse(b, consumed)
+		}
+	}
+	return nil, nil
+}
+
+// consumeTokenBudgets consumes the cost of the request stored in the metadataKey from the token budgets of the request.
+func consumeTokenBudgets(config *processorConfig, requestHeaders map[string]string, metadataKey string, cost uint64) {
+	for _, b := range config.tokenBudgets {
+		if b.MetadataKey != metadataKey {
+			continue
+		}
+		if key := b.key(requestHeaders); key != "" {
+			b.consumption.consume(key, cost)
+		}
+	}
+}
+
+// tokenBudgetExhaustedResponse returns the immediate response with the status code 429 and the OpenAI error body
+// for the exhausted token budget.
+func tokenBudgetExhaustedResponse(b *tokenBudget, consumed uint64) (*extprocv3.ProcessingResponse, error) {
+	code := "rate_limit_exceeded"
+	return openAIErrorResponse(typev3.StatusCode_TooManyRequests, &openai.ErrorType{
+		Type: "tokens",
+		Code: &code,
+		Message: fmt.Sprintf("Token budget %s exhausted: limit %d per %s, used %d. Please try again later.",
+			b.Name, b.Limit, b.Window, consumed),
+	})
+}
+
+// slidingWindows is the consumption per key over the sliding window. This approximates the consumption within the
+// sliding window by the sum of the consumption in the current fixed window and that in the previous fixed window
+// weighted by the portion of the previous window overlapping the sliding window, so that it only takes two counters
+// per key.
+type slidingWindows struct {
+	window time.Duration
+	// now is the function to get the current time, which can be replaced in the tests.
+	now func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]*fixedWindows
+	lastSweep time.Time
+}
+
+// fixedWindows is the consumption of a key in the current and the previous fixed windows.
+type fixedWindows struct {
+	// start is the start time of the current window.
+	start             time.Time
+	current, previous uint64
+}
+
+func newSlidingWindows(window time.Duration) *slidingWindows {
+	return &slidingWindows{window: window, now: time.Now, keys: make(map[string]*fixedWindows)}
+}
+
+// consumed returns the consumption of the key within the sliding window ending now.
+func (s *slidingWindows) consumed(key string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	w, ok := s.keys[key]
+	if !ok {
+		return 0
+	}
+	s.advance(w, now)
+	overlap := 1 - float64(now.Sub(w.start))/float64(s.window)
+	return w.current + uint64(float64(w.previous)*overlap)
+}
+
+// consume adds the cost to the consumption of the key.
+func (s *slidingWindows) consume(key string, cost uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+	w, ok := s.keys[key]
+	if !ok {
+		w = &fixedWindows{start: now.Truncate(s.window)}
+		s.keys[key] = w
+	}
+	s.advance(w, now)
+	w.current += cost
+}
+
+// advance moves the fixed windows of the key forward so that the current window contains now.
+func (s *slidingWindows) advance(w *fixedWindows, now time.Time) {
+	start := now.Truncate(s.window)
+	if !start.After(w.start) {
+		return
+	}
+	if start.Sub(w.start) == s.window {
+		w.previous = w.current
+	} else {
+		w.previous = 0
+	}
+	w.current, w.start = 0, start
+}
+
+// sweep removes the keys without any consumption within the sliding window at most once per window
+// so that the memory does not grow with the keys seen in the past.
+func (s *slidingWindows) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.window {
+		return
+	}
+	s.lastSweep = now
+	for key, w := range s.keys {
+		s.advance(w, now)
+		if w.current == 0 && w.previous == 0 {
+			delete(s.keys, key)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/router"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+func TestTokenBudgets_GatewayAPIKeys(t *testing.T) {
+	keys := &filterapi.GatewayAPIKeys{Keys: []filterapi.GatewayAPIKey{
+		{Name: "alice", TokenBudgets: []filterapi.TokenBudget{{
+			Name: "alice/total", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeAPIKey},
+			MetadataKey: "total", Limit: 100, Window: time.Hour,
+		}}},
+		{Name: "bob"},
+	}}
+	config := &processorConfig{
+		tokenBudgets: newTokenBudgets([]filterapi.TokenBudget{{
+			Name: "per-api-key", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeAPIKey},
+			MetadataKey: "total", Limit: 1000, Window: time.Hour,
+		}}, keys, nil),
+	}
+	alice, bob := map[string]string{apiKeyNameKey: "alice"}, map[string]string{apiKeyNameKey: "bob"}
+	consumeTokenBudgets(config, alice, "total", 100)
+	res, err := checkTokenBudgets(config, alice)
+	require.NoError(t, err)
+	require.Contains(t, string(res.GetImmediateResponse().Body), "Token budget alice/total exhausted")
+	// The budget of alice does not apply to bob, who only has the budget of the route.
+	res, err = checkTokenBudgets(config, bob)
+	require.NoError(t, err)
+	require.Nil(t, res)
+	consumeTokenBudgets(config, bob, "total", 1000)
+	res, err = checkTokenBudgets(config, bob)
+	require.NoError(t, err)
+	require.Contains(t, string(res.GetImmediateResponse().Body), "Token budget per-api-key exhausted")
+
+	// The consumption of the budget of the key is carried over with the same key, name and window.
+	reloaded := newTokenBudgets(nil, keys, config.tokenBudgets)
+	require.Same(t, config.tokenBudgets[1].consumption, reloaded[0].consumption)
+	// The budget of the route with the same name does not take over the consumption of the budget of the key.
+	reloaded = newTokenBudgets([]filterapi.TokenBudget{{
+		Name: "alice/total", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeAPIKey},
+		MetadataKey: "total", Limit: 100, Window: time.Hour,
+	}}, nil, config.tokenBudgets)
+	require.NotSame(t, config.tokenBudgets[1].consumption, reloaded[0].consumption)
+}
+
+func TestSlidingWindows(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newSlidingWindows(time.Minute)
+	s.now = func() time.Time { return now }
+
+	require.Zero(t, s.consumed("foo"))
+	s.consume("foo", 60)
+	s.consume("bar", 10)
+	now = now.Add(30 * time.Second)
+	s.consume("foo", 40)
+	require.Equal(t, uint64(100), s.consumed("foo"))
+	require.Equal(t, uint64(10), s.consumed("bar"))
+
+	// Three quarters of the sliding window overlaps the previous window.
+	now = now.Add(45 * time.Second)
+	require.Equal(t, uint64(75), s.consumed("foo"))
+	s.consume("foo", 5)
+	require.Equal(t, uint64(80), s.consumed("foo"))
+
+	// Both windows have slid out.
+	now = now.Add(2 * time.Minute)
+	require.Zero(t, s.consumed("foo"))
+
+	// The keys without the consumption within the sliding window are swept.
+	s.consume("baz", 1)
+	require.Len(t, s.keys, 1)
+	require.Contains(t, s.keys, "baz")
+}
+
+func TestTokenBudgets(t *testing.T) {
+	budgets := []filterapi.TokenBudget{
+		{
+			Name: "per-api-key", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeHeader, Header: "x-api-key"},
+			MetadataKey: "total", Limit: 100, Window: time.Hour,
+		},
+		{Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser}, MetadataKey: "output", Limit: 10, Window: time.Minute},
+	}
+	config := &processorConfig{
+		metadataNamespace: "ns",
+		requestCosts: []processorConfigRequestCost{
+			{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeTotalToken, MetadataKey: "total"}},
+			{LLMRequestCost: &filterapi.LLMRequestCost{Type: filterapi.LLMRequestCostTypeOutputToken, MetadataKey: "output"}},
+		},
+		tokenBudgets: newTokenBudgets(budgets, nil, nil),
+	}
+	consume := func(requestHeaders map[string]string, total, output uint32) {
+		_, err := buildDynamicMetadata(config, &requestUsage{
+			LLMTokenUsage: translator.LLMTokenUsage{TotalTokens: total, OutputTokens: output},
+		}, requestHeaders, slog.Default(), true)
+		require.NoError(t, err)
+	}
+	requireStatus := func(requestHeaders map[string]string, exp typev3.StatusCode) {
+		res, err := checkTokenBudgets(config, requestHeaders)
+		require.NoError(t, err)
+		if exp == 0 {
+			require.Nil(t, res)
+			return
+		}
+		require.Equal(t, exp, res.GetImmediateResponse().Status.Code)
+	}
+
+	alice := map[string]string{"x-api-key": "alice"}
+	consume(alice, 60, 5)
+	requireStatus(alice, 0)
+	consume(alice, 40, 5)
+	requireStatus(alice, typev3.StatusCode_TooManyRequests)
+	res, err := checkTokenBudgets(config, alice)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"error","error":{"type":"tokens","code":"rate_limit_exceeded",
+		"message":"Token budget per-api-key exhausted: limit 100 per 1h0m0s, used 100. Please try again later."}}`,
+		string(res.GetImmediateResponse().Body))
+	// The other keys and the requests without the key are not affected.
+	requireStatus(map[string]string{"x-api-key": "bob"}, 0)
+	requireStatus(map[string]string{}, 0)
+
+	carol := map[string]string{router.UserKey: "carol"}
+	// The metadata in the middle of the stream does not consume the budgets.
+	_, err = buildDynamicMetadata(config, &requestUsage{
+		LLMTokenUsage: translator.LLMTokenUsage{TotalTokens: 10, OutputTokens: 10},
+	}, carol, slog.Default(), false)
+	require.NoError(t, err)
+	requireStatus(carol, 0)
+	consume(carol, 10, 10)
+	requireStatus(carol, typev3.StatusCode_TooManyRequests)
+
+	t.Run("reload", func(t *testing.T) {
+		reloaded := newTokenBudgets([]filterapi.TokenBudget{
+			// The consumption is carried over with the same name and window.
+			{Name: "per-api-key", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeHeader, Header: "x-api-key"}, Limit: 200, Window: time.Hour},
+			// The consumption is reset with the different window.
+			{Name: "per-user", Key: filterapi.TokenBudgetKey{Type: filterapi.TokenBudgetKeyTypeUser}, Limit: 10, Window: time.Hour},
+		}, nil, config.tokenBudgets)
+		require.Same(t, config.tokenBudgets[0].consumption, reloaded[0].consumption)
+		require.Equal(t, uint64(200), reloaded[0].Limit)
+		require.NotSame(t, config.tokenBudgets[1].consumption, reloaded[1].consumption)
+		require.Zero(t, reloaded[1].consumption.consumed("carol"))
+	})
+}
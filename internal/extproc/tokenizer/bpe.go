@@ -0,0 +1,111 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package tokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// bpePreTokenizePattern splits the text into the pieces that are encoded separately. This is the pattern of
+// the cl100k_base encoding without the negative lookahead that is not supported by the regexp package, so
+// the trailing whitespaces before a word are split slightly differently.
+var bpePreTokenizePattern = regexp.MustCompile(
+	`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`,
+)
+
+// BPE is the [Tokenizer] of the byte pair encoding with the mergeable ranks in the tiktoken format,
+// e.g. the "cl100k_base.tiktoken" or the "o200k_base.tiktoken" files of OpenAI.
+type BPE struct {
+	// ranks maps the byte sequence of each token to its rank, where the lower rank is merged first.
+	ranks map[string]int
+}
+
+// LoadBPE loads the [BPE] from the vocabulary file at the given path.
+func LoadBPE(path string) (*BPE, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the vocabulary file: %w", err)
+	}
+	defer f.Close()
+	return NewBPE(f)
+}
+
+// NewBPE creates the [BPE] from the vocabulary in the tiktoken format, where each line is a token encoded in
+// base64 followed by its rank separated by a space.
+func NewBPE(r io.Reader) (*BPE, error) {
+	b := &BPE{ranks: make(map[string]int)}
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		encoded, rank, ok := bytes.Cut(line, []byte(" "))
+		if !ok {
+			return nil, fmt.Errorf("invalid vocabulary at line %d: expected a token and its rank", lineNum)
+		}
+		token, err := base64.StdEncoding.DecodeString(string(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid token at line %d: %w", lineNum, err)
+		}
+		r, err := strconv.Atoi(string(rank))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank at line %d: %w", lineNum, err)
+		}
+		b.ranks[string(token)] = r
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the vocabulary: %w", err)
+	}
+	if len(b.ranks) == 0 {
+		return nil, fmt.Errorf("vocabulary is empty")
+	}
+	return b, nil
+}
+
+// CountTokens implements [Tokenizer.CountTokens].
+func (b *BPE) CountTokens(text string) int {
+	var tokens int
+	for _, piece := range bpePreTokenizePattern.FindAllString(text, -1) {
+		tokens += b.countPieceTokens(piece)
+	}
+	return tokens
+}
+
+// countPieceTokens returns the number of tokens of the piece by merging the adjacent parts with the lowest rank
+// until no more merge is possible. Each byte not in the vocabulary is counted as a token.
+func (b *BPE) countPieceTokens(piece string) int {
+	if _, ok := b.ranks[piece]; ok {
+		return 1
+	}
+	// bounds are the start offsets of the parts followed by the end of the piece.
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	for len(bounds) > 2 {
+		minRank, minIndex := math.MaxInt, -1
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank, ok := b.ranks[piece[bounds[i]:bounds[i+2]]]; ok && rank < minRank {
+				minRank, minIndex = rank, i
+			}
+		}
+		if minIndex < 0 {
+			break
+		}
+		bounds = slices.Delete(bounds, minIndex+1, minIndex+2)
+	}
+	return len(bounds) - 1
+}
@@ -0,0 +1,60 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package tokenizer
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBPE_CountTokens(t *testing.T) {
+	// The vocabulary has the tokens "h", "e", "l", "o", " ", "he", "ll", "hell", "hello" and " h" in this order of the ranks.
+	b, err := LoadBPE("testdata/test.tiktoken")
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		text string
+		exp  int
+	}{
+		{text: "", exp: 0},
+		{text: "hello", exp: 1},
+		// "he" is merged first, then no pair of the parts "he", "l" and "o" is in the vocabulary.
+		{text: "helo", exp: 3},
+		// " hello" is not in the vocabulary, and is merged into " " and "hello" since "he" is ranked higher than " h".
+		{text: "hello hello", exp: 1 + 2},
+		// Each byte not in the vocabulary is a token.
+		{text: "xyz", exp: 3},
+		{text: "こ", exp: 3},
+	} {
+		t.Run(tc.text, func(t *testing.T) {
+			require.Equal(t, tc.exp, b.CountTokens(tc.text))
+		})
+	}
+}
+
+func TestNewBPE(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		vocab  string
+		expErr string
+	}{
+		{name: "empty", vocab: "\n", expErr: "vocabulary is empty"},
+		{name: "no rank", vocab: "aGU=\n", expErr: "invalid vocabulary at line 1: expected a token and its rank"},
+		{name: "invalid token", vocab: "aA== 0\n!!! 1\n", expErr: "invalid token at line 2"},
+		{name: "invalid rank", vocab: "aA== zero\n", expErr: "invalid rank at line 1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewBPE(strings.NewReader(tc.vocab))
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+	t.Run("not found", func(t *testing.T) {
+		_, err := LoadBPE(filepath.Join(t.TempDir(), "not-found.tiktoken"))
+		require.ErrorContains(t, err, "failed to open the vocabulary file")
+	})
+}
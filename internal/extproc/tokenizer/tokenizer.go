@@ -0,0 +1,243 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package tokenizer provides the estimation of the number of tokens of the requests and the responses without
+// relying on the backends.
+package tokenizer
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+const (
+	// charsPerToken is the average number of the ASCII characters per token of the English text.
+	charsPerToken = 4
+	// messageOverheadTokens is the number of tokens added per message for the role and the delimiters.
+	messageOverheadTokens = 3
+	// replyPrimingTokens is the number of tokens priming the reply of the assistant.
+	replyPrimingTokens = 3
+	// toolOverheadTokens is the number of tokens added per tool definition.
+	toolOverheadTokens = 8
+	// lowDetailImageTokens is the number of tokens of an image with the low detail.
+	lowDetailImageTokens = 85
+	// highDetailImageTokens is the number of tokens of a 1024x1024 image with the high or auto detail, since the actual
+	// size of the image is not known without fetching or decoding it.
+	highDetailImageTokens = 765
+)
+
+// Tokenizer counts the tokens of the text.
+type Tokenizer interface {
+	// CountTokens returns the number of tokens of the given text.
+	CountTokens(text string) int
+}
+
+// Heuristic is the [Tokenizer] that does not depend on the model: the ASCII text is counted as 4 characters
+// per token, and each non-ASCII character as a token. This is used when no vocabulary is available.
+type Heuristic struct{}
+
+// CountTokens implements [Tokenizer.CountTokens].
+func (Heuristic) CountTokens(text string) int {
+	var ascii, nonASCII int
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			nonASCII++
+		}
+	}
+	return (ascii+charsPerToken-1)/charsPerToken + nonASCII
+}
+
+// EstimateChatCompletionInputTokens estimates the number of input tokens of the given chat completion request,
+// i.e. the messages, the images in them and the tool definitions, with the text counted by the given tokenizer.
+//
+// This is a rough approximation since the tokens added by the backend around the messages and the tools
+// as well as those of the images depend on the model.
+func EstimateChatCompletionInputTokens(t Tokenizer, req *openai.ChatCompletionRequest) int {
+	tokens := replyPrimingTokens
+	for i := range req.Messages {
+		tokens += messageOverheadTokens + estimateMessageTokens(t, &req.Messages[i])
+	}
+	for i := range req.Tools {
+		tokens += toolOverheadTokens
+		if f := req.Tools[i].Function; f != nil {
+			tokens += t.CountTokens(f.Name) + t.CountTokens(f.Description)
+			if f.Parameters != nil {
+				tokens += estimateJSONTokens(t, f.Parameters)
+			}
+		}
+	}
+	return tokens
+}
+
+// EstimatePromptTokens estimates the number of tokens of the prompt of the completion request or the input of the
+// embedding request. The token arrays are counted as they are.
+func EstimatePromptTokens(t Tokenizer, prompt openai.EmbeddingRequestInput) int {
+	switch v := prompt.Value.(type) {
+	case string:
+		return t.CountTokens(v)
+	case []string:
+		var tokens int
+		for _, s := range v {
+			tokens += t.CountTokens(s)
+		}
+		return tokens
+	case []int64:
+		return len(v)
+	case [][]int64:
+		var tokens int
+		for _, arr := range v {
+			tokens += len(arr)
+		}
+		return tokens
+	default:
+		return 0
+	}
+}
+
+// EstimateConverseInputTokens estimates the number of input tokens of the given AWS Bedrock Converse request
+// in the same way as [EstimateChatCompletionInputTokens]. The documents are not counted since their size is not known
+// without decoding them.
+func EstimateConverseInputTokens(t Tokenizer, req *awsbedrock.ConverseInput) int {
+	tokens := replyPrimingTokens
+	for _, s := range req.System {
+		if s != nil {
+			tokens += t.CountTokens(s.Text)
+		}
+	}
+	for _, m := range req.Messages {
+		if m == nil {
+			continue
+		}
+		tokens += messageOverheadTokens
+		for _, block := range m.Content {
+			if block != nil {
+				tokens += estimateConverseContentBlockTokens(t, block)
+			}
+		}
+	}
+	if req.ToolConfig != nil {
+		for _, tool := range req.ToolConfig.Tools {
+			tokens += toolOverheadTokens
+			if tool == nil || tool.ToolSpec == nil {
+				continue
+			}
+			spec := tool.ToolSpec
+			if spec.Name != nil {
+				tokens += t.CountTokens(*spec.Name)
+			}
+			if spec.Description != nil {
+				tokens += t.CountTokens(*spec.Description)
+			}
+			if spec.InputSchema != nil && spec.InputSchema.JSON != nil {
+				tokens += estimateJSONTokens(t, spec.InputSchema.JSON)
+			}
+		}
+	}
+	return tokens
+}
+
+// estimateConverseContentBlockTokens estimates the number of tokens of the given content block of a Converse message.
+func estimateConverseContentBlockTokens(t Tokenizer, block *awsbedrock.ContentBlock) int {
+	switch {
+	case block.Text != nil:
+		return t.CountTokens(*block.Text)
+	case block.Image != nil:
+		return highDetailImageTokens
+	case block.ToolUse != nil:
+		return t.CountTokens(block.ToolUse.Name) + estimateJSONTokens(t, block.ToolUse.Input)
+	case block.ToolResult != nil:
+		var tokens int
+		for _, c := range block.ToolResult.Content {
+			switch {
+			case c == nil:
+			case c.Text != nil:
+				tokens += t.CountTokens(*c.Text)
+			case c.JSON != nil:
+				tokens += estimateJSONTokens(t, c.JSON)
+			case c.Image != nil:
+				tokens += highDetailImageTokens
+			}
+		}
+		return tokens
+	default:
+		return 0
+	}
+}
+
+// estimateJSONTokens estimates the number of tokens of the given value encoded in JSON.
+func estimateJSONTokens(t Tokenizer, v any) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return t.CountTokens(string(b))
+}
+
+// estimateMessageTokens estimates the number of tokens of the content of the given message.
+func estimateMessageTokens(t Tokenizer, m *openai.ChatCompletionMessageParamUnion) int {
+	switch msg := m.Value.(type) {
+	case openai.ChatCompletionUserMessageParam:
+		tokens := t.CountTokens(msg.Name)
+		switch content := msg.Content.Value.(type) {
+		case string:
+			tokens += t.CountTokens(content)
+		case []openai.ChatCompletionContentPartUserUnionParam:
+			for _, part := range content {
+				switch {
+				case part.TextContent != nil:
+					tokens += t.CountTokens(part.TextContent.Text)
+				case part.ImageContent != nil:
+					if part.ImageContent.ImageURL.Detail == openai.ChatCompletionContentPartImageImageURLDetailLow {
+						tokens += lowDetailImageTokens
+					} else {
+						tokens += highDetailImageTokens
+					}
+				}
+			}
+		}
+		return tokens
+	case openai.ChatCompletionSystemMessageParam:
+		return t.CountTokens(msg.Name) + estimateStringOrArrayTokens(t, msg.Content)
+	case openai.ChatCompletionDeveloperMessageParam:
+		return t.CountTokens(msg.Name) + estimateStringOrArrayTokens(t, msg.Content)
+	case openai.ChatCompletionToolMessageParam:
+		return estimateStringOrArrayTokens(t, msg.Content)
+	case openai.ChatCompletionAssistantMessageParam:
+		tokens := t.CountTokens(msg.Name) + t.CountTokens(msg.Refusal)
+		if msg.Content.Text != nil {
+			tokens += t.CountTokens(*msg.Content.Text)
+		}
+		if msg.Content.Refusal != nil {
+			tokens += t.CountTokens(*msg.Content.Refusal)
+		}
+		for _, call := range msg.ToolCalls {
+			tokens += t.CountTokens(call.Function.Name) + t.CountTokens(call.Function.Arguments)
+		}
+		return tokens
+	default:
+		return 0
+	}
+}
+
+// estimateStringOrArrayTokens estimates the number of tokens of the content that is either a string or text parts.
+func estimateStringOrArrayTokens(t Tokenizer, content openai.StringOrArray) int {
+	switch v := content.Value.(type) {
+	case string:
+		return t.CountTokens(v)
+	case []openai.ChatCompletionContentPartTextParam:
+		var tokens int
+		for _, part := range v {
+			tokens += t.CountTokens(part.Text)
+		}
+		return tokens
+	default:
+		return 0
+	}
+}
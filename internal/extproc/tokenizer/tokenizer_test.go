@@ -0,0 +1,122 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package tokenizer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func TestEstimateChatCompletionInputTokens(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		exp  int
+	}{
+		{name: "empty", body: `{"model":"m","messages":[]}`, exp: 3},
+		{
+			name: "text",
+			// "You are helpful" is 15 ASCII characters, "bob" is 3 and "こんにちは" is 5 non-ASCII characters.
+			body: `{"model":"m","messages":[{"role":"system","content":"You are helpful"},{"role":"user","name":"bob","content":"こんにちは"}]}`,
+			exp:  3 + (3 + 4) + (3 + 1 + 5),
+		},
+		{
+			name: "images",
+			body: `{"model":"m","messages":[{"role":"user","content":[
+				{"type":"text","text":"what"},
+				{"type":"image_url","image_url":{"url":"https://example.com/a.png","detail":"low"}},
+				{"type":"image_url","image_url":{"url":"https://example.com/b.png"}}
+			]}]}`,
+			exp: 3 + (3 + 1 + 85 + 765),
+		},
+		{
+			name: "assistant and tool",
+			body: `{"model":"m","messages":[
+				{"role":"assistant","content":"ok","tool_calls":[{"id":"1","type":"function","function":{"name":"get","arguments":"{}"}}]},
+				{"role":"tool","tool_call_id":"1","content":[{"type":"text","text":"sunny"}]}
+			]}`,
+			exp: 3 + (3 + 1 + 1 + 1) + (3 + 2),
+		},
+		{
+			name: "tools",
+			body: `{"model":"m","messages":[],"tools":[{"type":"function","function":{"name":"get","description":"gets it","parameters":{"type":"object"}}}]}`,
+			// {"type":"object"} is 17 characters.
+			exp: 3 + (8 + 1 + 2 + 5),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var req openai.ChatCompletionRequest
+			require.NoError(t, json.Unmarshal([]byte(tc.body), &req))
+			require.Equal(t, tc.exp, EstimateChatCompletionInputTokens(Heuristic{}, &req))
+		})
+	}
+}
+
+func TestEstimatePromptTokens(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		prompt string
+		exp    int
+	}{
+		{name: "string", prompt: `"Hello world!"`, exp: 3},
+		{name: "strings", prompt: `["Hello", "world!"]`, exp: 2 + 2},
+		{name: "tokens", prompt: `[1, 2, 3]`, exp: 3},
+		{name: "token arrays", prompt: `[[1, 2], [3]]`, exp: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var prompt openai.EmbeddingRequestInput
+			require.NoError(t, json.Unmarshal([]byte(tc.prompt), &prompt))
+			require.Equal(t, tc.exp, EstimatePromptTokens(Heuristic{}, prompt))
+		})
+	}
+}
+
+func TestEstimateConverseInputTokens(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		exp  int
+	}{
+		{name: "empty", body: `{"messages":[]}`, exp: 3},
+		{
+			name: "text",
+			// "You are helpful" is 15 characters and "こんにちは" is 5 non-ASCII characters.
+			body: `{"system":[{"text":"You are helpful"}],"messages":[{"role":"user","content":[{"text":"こんにちは"}]}]}`,
+			exp:  3 + 4 + (3 + 5),
+		},
+		{
+			name: "image",
+			body: `{"messages":[{"role":"user","content":[{"text":"what"},{"image":{"format":"png","source":{"bytes":"AA=="}}}]}]}`,
+			exp:  3 + (3 + 1 + 765),
+		},
+		{
+			name: "tool use and result",
+			// {"city":"Tokyo"} is 16 characters and {"weather":"sunny"} is 19 characters.
+			body: `{"messages":[
+				{"role":"assistant","content":[{"toolUse":{"name":"get","toolUseId":"1","input":{"city":"Tokyo"}}}]},
+				{"role":"user","content":[{"toolResult":{"toolUseId":"1","content":[{"text":"ok"},{"json":{"weather":"sunny"}}]}}]}
+			]}`,
+			exp: 3 + (3 + 1 + 4) + (3 + 1 + 5),
+		},
+		{
+			name: "tools",
+			body: `{"messages":[],"toolConfig":{"tools":[{"toolSpec":{"name":"get","description":"gets it","inputSchema":{"json":{"type":"object"}}}}]}}`,
+			// {"type":"object"} is 17 characters.
+			exp: 3 + (8 + 1 + 2 + 5),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var req awsbedrock.ConverseInput
+			require.NoError(t, json.Unmarshal([]byte(tc.body), &req))
+			require.Equal(t, tc.exp, EstimateConverseInputTokens(Heuristic{}, &req))
+		})
+	}
+}
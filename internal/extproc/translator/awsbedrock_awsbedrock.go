@@ -0,0 +1,105 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+)
+
+// NewConverseAWSBedrockToAWSBedrockTranslator implements [Factory] for AWS Bedrock Converse to AWS Bedrock translation.
+//
+// When modelNameOverride is not empty, the model ID in the request path is rewritten to it.
+func NewConverseAWSBedrockToAWSBedrockTranslator(modelNameOverride string) AWSBedrockConverseTranslator {
+	return &awsBedrockToAWSBedrockTranslatorV1Converse{
+		openAIToAWSBedrockTranslatorV1ChatCompletion: openAIToAWSBedrockTranslatorV1ChatCompletion{modelNameOverride: modelNameOverride},
+	}
+}
+
+// awsBedrockToAWSBedrockTranslatorV1Converse implements [AWSBedrockConverseTranslator] for /model/{modelId}/converse(-stream).
+//
+// This doesn't modify the request nor the response except for the model name override, but only extracts the token usage
+// from the response.
+// The event stream decoding is shared with the OpenAI to AWS Bedrock chat completion translator.
+type awsBedrockToAWSBedrockTranslatorV1Converse struct {
+	openAIToAWSBedrockTranslatorV1ChatCompletion
+}
+
+// RequestBody implements [AWSBedrockConverseTranslator.RequestBody].
+func (a *awsBedrockToAWSBedrockTranslatorV1Converse) RequestBody(_ *awsbedrock.ConverseInput, stream bool) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error,
+) {
+	if stream {
+		a.stream = true
+		override = &extprocv3http.ProcessingMode{
+			ResponseHeaderMode: extprocv3http.ProcessingMode_SEND,
+			ResponseBodyMode:   extprocv3http.ProcessingMode_STREAMED,
+		}
+	}
+	if a.modelNameOverride != "" {
+		pathTemplate := "/model/%s/converse"
+		if stream {
+			pathTemplate = "/model/%s/converse-stream"
+		}
+		headerMutation = &extprocv3.HeaderMutation{
+			SetHeaders: []*corev3.HeaderValueOption{
+				{Header: &corev3.HeaderValue{Key: ":path", RawValue: []byte(fmt.Sprintf(pathTemplate, a.modelNameOverride))}},
+			},
+		}
+	}
+	return headerMutation, nil, override, nil
+}
+
+// ResponseHeaders implements [AWSBedrockConverseTranslator.ResponseHeaders].
+func (a *awsBedrockToAWSBedrockTranslatorV1Converse) ResponseHeaders(map[string]string) (
+	headerMutation *extprocv3.HeaderMutation, err error,
+) {
+	return nil, nil
+}
+
+// ResponseBody implements [AWSBedrockConverseTranslator.ResponseBody].
+func (a *awsBedrockToAWSBedrockTranslatorV1Converse) ResponseBody(respHeaders map[string]string, body io.Reader, _ bool) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage LLMTokenUsage, err error,
+) {
+	if v, ok := respHeaders[statusHeaderName]; ok {
+		if v, err := strconv.Atoi(v); err == nil && !isGoodStatusCode(v) {
+			// The error response is already in the AWS Bedrock format.
+			return nil, nil, tokenUsage, nil
+		}
+	}
+	if a.stream {
+		var buf []byte
+		buf, err = io.ReadAll(body)
+		if err != nil {
+			return nil, nil, tokenUsage, fmt.Errorf("failed to read body: %w", err)
+		}
+		a.bufferedBody = append(a.bufferedBody, buf...)
+		a.extractAmazonEventStreamEvents()
+		for i := range a.events {
+			if usage := a.events[i].Usage; usage != nil {
+				tokenUsage = bedrockUsageToLLMTokenUsage(usage)
+			}
+		}
+		return nil, nil, tokenUsage, nil
+	}
+
+	var resp awsbedrock.ConverseResponse
+	if err = json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+	if resp.Usage != nil {
+		tokenUsage = bedrockUsageToLLMTokenUsage(resp.Usage)
+	}
+	return nil, nil, tokenUsage, nil
+}
@@ -0,0 +1,87 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+)
+
+func TestAWSBedrockToAWSBedrockTranslatorV1Converse_RequestBody(t *testing.T) {
+	a := NewConverseAWSBedrockToAWSBedrockTranslator("")
+	hm, bm, override, err := a.RequestBody(&awsbedrock.ConverseInput{}, false)
+	require.NoError(t, err)
+	require.Nil(t, hm)
+	require.Nil(t, bm)
+	require.Nil(t, override)
+
+	_, _, override, err = a.RequestBody(&awsbedrock.ConverseInput{}, true)
+	require.NoError(t, err)
+	require.Equal(t, extprocv3http.ProcessingMode_STREAMED, override.ResponseBodyMode)
+
+	t.Run("model name override", func(t *testing.T) {
+		for stream, expPath := range map[bool]string{
+			false: "/model/anthropic.claude-3-5-sonnet-20241022-v2:0/converse",
+			true:  "/model/anthropic.claude-3-5-sonnet-20241022-v2:0/converse-stream",
+		} {
+			a := NewConverseAWSBedrockToAWSBedrockTranslator("anthropic.claude-3-5-sonnet-20241022-v2:0")
+			hm, bm, _, err := a.RequestBody(&awsbedrock.ConverseInput{ModelID: ptr.To("claude-sonnet")}, stream)
+			require.NoError(t, err)
+			require.Nil(t, bm)
+			require.Len(t, hm.SetHeaders, 1)
+			require.Equal(t, ":path", hm.SetHeaders[0].Header.Key)
+			require.Equal(t, expPath, string(hm.SetHeaders[0].Header.RawValue))
+		}
+	})
+}
+
+func TestAWSBedrockToAWSBedrockTranslatorV1Converse_ResponseBody(t *testing.T) {
+	t.Run("non-streaming", func(t *testing.T) {
+		a := NewConverseAWSBedrockToAWSBedrockTranslator("")
+		hm, bm, usage, err := a.ResponseBody(nil,
+			strings.NewReader(`{"usage":{"inputTokens":1,"outputTokens":2,"totalTokens":3}}`), true)
+		require.NoError(t, err)
+		require.Nil(t, hm)
+		require.Nil(t, bm)
+		require.Equal(t, LLMTokenUsage{InputTokens: 1, OutputTokens: 2, TotalTokens: 3}, usage)
+	})
+	t.Run("streaming", func(t *testing.T) {
+		a := NewConverseAWSBedrockToAWSBedrockTranslator("")
+		_, _, _, err := a.RequestBody(&awsbedrock.ConverseInput{}, true)
+		require.NoError(t, err)
+
+		buf := bytes.NewBuffer(nil)
+		enc := eventstream.NewEncoder()
+		for _, event := range []awsbedrock.ConverseStreamEvent{
+			{Role: ptr.To(awsbedrock.ConversationRoleAssistant)},
+			{Usage: &awsbedrock.TokenUsage{InputTokens: 4, OutputTokens: 5, TotalTokens: 9}},
+		} {
+			payload, err := json.Marshal(event)
+			require.NoError(t, err)
+			require.NoError(t, enc.Encode(buf, eventstream.Message{Payload: payload}))
+		}
+		_, bm, usage, err := a.ResponseBody(nil, buf, true)
+		require.NoError(t, err)
+		require.Nil(t, bm)
+		require.Equal(t, LLMTokenUsage{InputTokens: 4, OutputTokens: 5, TotalTokens: 9}, usage)
+	})
+	t.Run("error", func(t *testing.T) {
+		a := NewConverseAWSBedrockToAWSBedrockTranslator("")
+		_, bm, usage, err := a.ResponseBody(map[string]string{":status": "400"}, strings.NewReader(`{"message":"bad"}`), true)
+		require.NoError(t, err)
+		require.Nil(t, bm)
+		require.Equal(t, LLMTokenUsage{}, usage)
+	})
+}
@@ -0,0 +1,534 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+// awsEventStreamContentType is the content type of the responses of the ConverseStream API.
+const awsEventStreamContentType = "application/vnd.amazon.eventstream"
+
+// NewConverseAWSBedrockToOpenAITranslator implements [Factory] for AWS Bedrock Converse to OpenAI translation.
+//
+// The Converse request is translated into the OpenAI chat completion request, and the response is translated back
+// into the Converse response. For the ConverseStream API, the server-sent events of OpenAI are encoded into
+// the AWS event stream. When modelNameOverride is not empty, it is used as the model instead of the model ID in the request.
+func NewConverseAWSBedrockToOpenAITranslator(modelNameOverride string) AWSBedrockConverseTranslator {
+	return &awsBedrockToOpenAITranslatorV1Converse{modelNameOverride: modelNameOverride}
+}
+
+// awsBedrockToOpenAITranslatorV1Converse implements [AWSBedrockConverseTranslator] for /model/{modelId}/converse(-stream).
+type awsBedrockToOpenAITranslatorV1Converse struct {
+	modelNameOverride string
+	stream            bool
+	buffered          []byte
+	// The following fields track the state of the event stream sent back to the client.
+	messageStarted bool
+	blockIndex     int
+	blockOpen      bool
+}
+
+// RequestBody implements [AWSBedrockConverseTranslator.RequestBody].
+func (a *awsBedrockToOpenAITranslatorV1Converse) RequestBody(bedrockReq *awsbedrock.ConverseInput, stream bool) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error,
+) {
+	if bedrockReq.ModelID == nil || *bedrockReq.ModelID == "" {
+		return nil, nil, nil, fmt.Errorf("model ID is required")
+	}
+	openAIReq := openai.ChatCompletionRequest{Model: *bedrockReq.ModelID}
+	if a.modelNameOverride != "" {
+		openAIReq.Model = a.modelNameOverride
+	}
+	if stream {
+		a.stream = true
+		openAIReq.Stream = true
+		// The usage is needed to send the metadata event at the end of the event stream.
+		openAIReq.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+		override = &extprocv3http.ProcessingMode{
+			ResponseHeaderMode: extprocv3http.ProcessingMode_SEND,
+			ResponseBodyMode:   extprocv3http.ProcessingMode_STREAMED,
+		}
+	}
+	if cfg := bedrockReq.InferenceConfig; cfg != nil {
+		openAIReq.MaxTokens = cfg.MaxTokens
+		openAIReq.Stop = cfg.StopSequences
+		openAIReq.Temperature = cfg.Temperature
+		openAIReq.TopP = cfg.TopP
+	}
+
+	if len(bedrockReq.System) > 0 {
+		texts := make([]string, 0, len(bedrockReq.System))
+		for _, s := range bedrockReq.System {
+			if s.Text != "" {
+				texts = append(texts, s.Text)
+			}
+		}
+		openAIReq.Messages = append(openAIReq.Messages, openai.ChatCompletionMessageParamUnion{
+			Type: openai.ChatMessageRoleSystem,
+			Value: openai.ChatCompletionSystemMessageParam{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: openai.StringOrArray{Value: strings.Join(texts, "\n")},
+			},
+		})
+	}
+	for i, msg := range bedrockReq.Messages {
+		var msgs []openai.ChatCompletionMessageParamUnion
+		switch msg.Role {
+		case awsbedrock.ConversationRoleUser:
+			msgs, err = bedrockUserMessageToOpenAIMessages(msg)
+		case awsbedrock.ConversationRoleAssistant:
+			msgs, err = bedrockAssistantMessageToOpenAIMessages(msg)
+		default:
+			err = fmt.Errorf("unknown role %q", msg.Role)
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to convert message at index %d: %w", i, err)
+		}
+		openAIReq.Messages = append(openAIReq.Messages, msgs...)
+	}
+	if bedrockReq.ToolConfig != nil {
+		bedrockToolConfigToOpenAITools(bedrockReq.ToolConfig, &openAIReq)
+	}
+
+	mut := &extprocv3.BodyMutation_Body{}
+	if mut.Body, err = json.Marshal(openAIReq); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{
+		SetHeaders: []*corev3.HeaderValueOption{
+			{Header: &corev3.HeaderValue{Key: ":path", RawValue: []byte("/v1/chat/completions")}},
+		},
+	}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, override, nil
+}
+
+// bedrockUserMessageToOpenAIMessages converts the user message of Converse into OpenAI messages.
+//
+// The tool results are carried by the user message in Converse, but they are separate tool messages in OpenAI,
+// so they are returned first followed by the user message with the rest of the content, if any.
+func bedrockUserMessageToOpenAIMessages(msg *awsbedrock.Message) ([]openai.ChatCompletionMessageParamUnion, error) {
+	var ret []openai.ChatCompletionMessageParamUnion
+	var parts []openai.ChatCompletionContentPartUserUnionParam
+	for _, block := range msg.Content {
+		switch {
+		case block.Text != nil:
+			parts = append(parts, openai.ChatCompletionContentPartUserUnionParam{
+				TextContent: &openai.ChatCompletionContentPartTextParam{
+					Type: string(openai.ChatCompletionContentPartTextTypeText), Text: *block.Text,
+				},
+			})
+		case block.Image != nil:
+			parts = append(parts, openai.ChatCompletionContentPartUserUnionParam{
+				ImageContent: &openai.ChatCompletionContentPartImageParam{
+					Type: openai.ChatCompletionContentPartImageTypeImageURL,
+					ImageURL: openai.ChatCompletionContentPartImageImageURLParam{
+						URL: fmt.Sprintf("data:image/%s;base64,%s", block.Image.Format, base64.StdEncoding.EncodeToString(block.Image.Source.Bytes)),
+					},
+				},
+			})
+		case block.ToolResult != nil:
+			content, err := bedrockToolResultToText(block.ToolResult)
+			if err != nil {
+				return nil, err
+			}
+			var toolCallID string
+			if block.ToolResult.ToolUseID != nil {
+				toolCallID = *block.ToolResult.ToolUseID
+			}
+			ret = append(ret, openai.ChatCompletionMessageParamUnion{
+				Type: openai.ChatMessageRoleTool,
+				Value: openai.ChatCompletionToolMessageParam{
+					Role:       openai.ChatMessageRoleTool,
+					ToolCallID: toolCallID,
+					Content:    openai.StringOrArray{Value: content},
+				},
+			})
+		default:
+			return nil, fmt.Errorf("unsupported content block in user message: only text, image and toolResult are supported")
+		}
+	}
+	if len(parts) == 0 {
+		return ret, nil
+	}
+	user := openai.ChatCompletionUserMessageParam{Role: openai.ChatMessageRoleUser}
+	if len(parts) == 1 && parts[0].TextContent != nil {
+		user.Content = openai.StringOrUserRoleContentUnion{Value: parts[0].TextContent.Text}
+	} else {
+		user.Content = openai.StringOrUserRoleContentUnion{Value: parts}
+	}
+	return append(ret, openai.ChatCompletionMessageParamUnion{Type: openai.ChatMessageRoleUser, Value: user}), nil
+}
+
+// bedrockToolResultToText flattens the content of the tool result into the text since OpenAI tool messages only accept text.
+func bedrockToolResultToText(result *awsbedrock.ToolResultBlock) (string, error) {
+	var text strings.Builder
+	for _, c := range result.Content {
+		switch {
+		case c.Text != nil:
+			text.WriteString(*c.Text)
+		case c.JSON != nil:
+			b, err := json.Marshal(c.JSON)
+			if err != nil {
+				return "", fmt.Errorf("failed to marshal tool result JSON: %w", err)
+			}
+			text.Write(b)
+		default:
+			return "", fmt.Errorf("unsupported tool result content: only text and json are supported")
+		}
+	}
+	return text.String(), nil
+}
+
+// bedrockAssistantMessageToOpenAIMessages converts the assistant message of Converse into an OpenAI message.
+func bedrockAssistantMessageToOpenAIMessages(msg *awsbedrock.Message) ([]openai.ChatCompletionMessageParamUnion, error) {
+	assistant := openai.ChatCompletionAssistantMessageParam{Role: openai.ChatMessageRoleAssistant}
+	var text *string
+	for _, block := range msg.Content {
+		switch {
+		case block.Text != nil:
+			if text == nil {
+				text = new(string)
+			}
+			*text += *block.Text
+		case block.ToolUse != nil:
+			args, err := json.Marshal(block.ToolUse.Input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal tool use input: %w", err)
+			}
+			assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+				ID:   block.ToolUse.ToolUseID,
+				Type: openai.ChatCompletionMessageToolCallTypeFunction,
+				Function: openai.ChatCompletionMessageToolCallFunctionParam{
+					Name:      block.ToolUse.Name,
+					Arguments: string(args),
+				},
+			})
+		default:
+			return nil, fmt.Errorf("unsupported content block in assistant message: only text and toolUse are supported")
+		}
+	}
+	if text != nil {
+		assistant.Content = openai.ChatCompletionAssistantMessageParamContent{
+			Type: openai.ChatCompletionAssistantMessageParamContentTypeText, Text: text,
+		}
+	}
+	return []openai.ChatCompletionMessageParamUnion{{Type: openai.ChatMessageRoleAssistant, Value: assistant}}, nil
+}
+
+// bedrockToolConfigToOpenAITools converts the tool configuration of Converse into the OpenAI tools and tool choice.
+func bedrockToolConfigToOpenAITools(toolConfig *awsbedrock.ToolConfiguration, openAIReq *openai.ChatCompletionRequest) {
+	for _, tool := range toolConfig.Tools {
+		if tool.ToolSpec == nil || tool.ToolSpec.Name == nil {
+			continue
+		}
+		fn := &openai.FunctionDefinition{Name: *tool.ToolSpec.Name}
+		if tool.ToolSpec.Description != nil {
+			fn.Description = *tool.ToolSpec.Description
+		}
+		if tool.ToolSpec.InputSchema != nil {
+			fn.Parameters = tool.ToolSpec.InputSchema.JSON
+		}
+		openAIReq.Tools = append(openAIReq.Tools, openai.Tool{Type: openai.ToolTypeFunction, Function: fn})
+	}
+	if tc := toolConfig.ToolChoice; tc != nil {
+		switch {
+		case tc.Auto != nil:
+			openAIReq.ToolChoice = "auto"
+		case tc.Any != nil:
+			openAIReq.ToolChoice = "required"
+		case tc.Tool != nil && tc.Tool.Name != nil:
+			openAIReq.ToolChoice = openai.ToolChoice{Type: openai.ToolTypeFunction, Function: openai.ToolFunction{Name: *tc.Tool.Name}}
+		}
+	}
+}
+
+// ResponseHeaders implements [AWSBedrockConverseTranslator.ResponseHeaders].
+func (a *awsBedrockToOpenAITranslatorV1Converse) ResponseHeaders(headers map[string]string) (
+	headerMutation *extprocv3.HeaderMutation, err error,
+) {
+	if a.stream && headers[contentTypeHeaderName] == "text/event-stream" {
+		// We need to change the content-type to the AWS event stream for streaming responses.
+		return &extprocv3.HeaderMutation{
+			SetHeaders: []*corev3.HeaderValueOption{
+				{Header: &corev3.HeaderValue{Key: contentTypeHeaderName, Value: awsEventStreamContentType}},
+			},
+		}, nil
+	}
+	return nil, nil
+}
+
+// ResponseBody implements [AWSBedrockConverseTranslator.ResponseBody].
+func (a *awsBedrockToOpenAITranslatorV1Converse) ResponseBody(respHeaders map[string]string, body io.Reader, endOfStream bool) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage LLMTokenUsage, err error,
+) {
+	if statusStr, ok := respHeaders[statusHeaderName]; ok {
+		var status int
+		if status, err = strconv.Atoi(statusStr); err == nil {
+			if !isGoodStatusCode(status) {
+				headerMutation, bodyMutation, err = openAIErrorToAWSBedrockError(status, respHeaders, body)
+				return headerMutation, bodyMutation, LLMTokenUsage{}, err
+			}
+		}
+	}
+	mut := &extprocv3.BodyMutation_Body{}
+	if a.stream {
+		var buf []byte
+		buf, err = io.ReadAll(body)
+		if err != nil {
+			return nil, nil, tokenUsage, fmt.Errorf("failed to read body: %w", err)
+		}
+		a.buffered = append(a.buffered, buf...)
+		out := &bytes.Buffer{}
+		if tokenUsage, err = a.convertBufferedEvents(out); err != nil {
+			return nil, nil, tokenUsage, err
+		}
+		mut.Body = out.Bytes()
+		return nil, &extprocv3.BodyMutation{Mutation: mut}, tokenUsage, nil
+	}
+
+	var openAIResp openai.ChatCompletionResponse
+	if err = json.NewDecoder(body).Decode(&openAIResp); err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+	tokenUsage = openAIUsageToLLMTokenUsage(&openAIResp.Usage)
+	bedrockResp := awsbedrock.ConverseResponse{
+		Output: &awsbedrock.ConverseOutput{Message: awsbedrock.Message{
+			Role:    awsbedrock.ConversationRoleAssistant,
+			Content: []*awsbedrock.ContentBlock{},
+		}},
+		Usage: openAIUsageToBedrockUsage(&openAIResp.Usage),
+	}
+	// Converse has only one output message, so only the first choice is used.
+	if len(openAIResp.Choices) > 0 {
+		choice := &openAIResp.Choices[0]
+		content := &bedrockResp.Output.Message.Content
+		if choice.Message.Content != nil && *choice.Message.Content != "" {
+			*content = append(*content, &awsbedrock.ContentBlock{Text: choice.Message.Content})
+		}
+		for i := range choice.Message.ToolCalls {
+			toolCall := &choice.Message.ToolCalls[i]
+			var input map[string]interface{}
+			if input, err = unmarshalToolCallArguments(toolCall.Function.Arguments); err != nil {
+				return nil, nil, tokenUsage, err
+			}
+			*content = append(*content, &awsbedrock.ContentBlock{ToolUse: &awsbedrock.ToolUseBlock{
+				Name: toolCall.Function.Name, ToolUseID: toolCall.ID, Input: input,
+			}})
+		}
+		stopReason := openAIFinishReasonToBedrockStopReason(choice.FinishReason)
+		bedrockResp.StopReason = &stopReason
+	}
+
+	if mut.Body, err = json.Marshal(bedrockResp); err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, tokenUsage, nil
+}
+
+// convertBufferedEvents converts the complete server-sent events in the buffer into the AWS event stream
+// written to out, and keeps the incomplete one in the buffer.
+func (a *awsBedrockToOpenAITranslatorV1Converse) convertBufferedEvents(out *bytes.Buffer) (tokenUsage LLMTokenUsage, err error) {
+	enc := eventstream.NewEncoder()
+	for {
+		i := bytes.IndexByte(a.buffered, '\n')
+		if i == -1 {
+			return
+		}
+		line := bytes.TrimSuffix(a.buffered[:i], []byte("\r"))
+		a.buffered = a.buffered[i+1:]
+		if !bytes.HasPrefix(line, dataPrefix) {
+			continue
+		}
+		data := bytes.TrimPrefix(line, dataPrefix)
+		if string(data) == "[DONE]" {
+			continue
+		}
+		var chunk openai.ChatCompletionResponseChunk
+		if err = json.Unmarshal(data, &chunk); err != nil {
+			return tokenUsage, fmt.Errorf("failed to unmarshal event: %w", err)
+		}
+		if chunk.Usage != nil {
+			tokenUsage = openAIUsageToLLMTokenUsage(chunk.Usage)
+		}
+		for _, event := range a.convertChunk(&chunk) {
+			if err = encodeConverseStreamEvent(enc, out, event.eventType, &event.ConverseStreamEvent); err != nil {
+				return tokenUsage, err
+			}
+		}
+	}
+}
+
+// converseStreamEvent is a [awsbedrock.ConverseStreamEvent] with the event type sent in the ":event-type" header.
+type converseStreamEvent struct {
+	eventType string
+	awsbedrock.ConverseStreamEvent
+}
+
+// convertChunk converts an OpenAI chat completion chunk into the ConverseStream events.
+func (a *awsBedrockToOpenAITranslatorV1Converse) convertChunk(chunk *openai.ChatCompletionResponseChunk) (events []converseStreamEvent) {
+	for i := range chunk.Choices {
+		choice := &chunk.Choices[i]
+		if !a.messageStarted {
+			a.messageStarted = true
+			events = append(events, converseStreamEvent{"messageStart", awsbedrock.ConverseStreamEvent{
+				Role: ptr.To(awsbedrock.ConversationRoleAssistant),
+			}})
+		}
+		if delta := choice.Delta; delta != nil {
+			if delta.Content != nil && *delta.Content != "" {
+				a.blockOpen = true
+				events = append(events, converseStreamEvent{"contentBlockDelta", awsbedrock.ConverseStreamEvent{
+					ContentBlockIndex: ptr.To(a.blockIndex),
+					Delta:             &awsbedrock.ConverseStreamEventContentBlockDelta{Text: delta.Content},
+				}})
+			}
+			for j := range delta.ToolCalls {
+				toolCall := &delta.ToolCalls[j]
+				if toolCall.ID != "" {
+					// A new tool call starts a new content block.
+					events = a.closeBlock(events)
+					a.blockOpen = true
+					events = append(events, converseStreamEvent{"contentBlockStart", awsbedrock.ConverseStreamEvent{
+						ContentBlockIndex: ptr.To(a.blockIndex),
+						Start: &awsbedrock.ContentBlockStart{ToolUse: &awsbedrock.ToolUseBlockStart{
+							Name: toolCall.Function.Name, ToolUseID: toolCall.ID,
+						}},
+					}})
+				}
+				if toolCall.Function.Arguments != "" {
+					events = append(events, converseStreamEvent{"contentBlockDelta", awsbedrock.ConverseStreamEvent{
+						ContentBlockIndex: ptr.To(a.blockIndex),
+						Delta: &awsbedrock.ConverseStreamEventContentBlockDelta{
+							ToolUse: &awsbedrock.ToolUseBlockDelta{Input: toolCall.Function.Arguments},
+						},
+					}})
+				}
+			}
+		}
+		if choice.FinishReason != "" {
+			events = a.closeBlock(events)
+			stopReason := openAIFinishReasonToBedrockStopReason(choice.FinishReason)
+			events = append(events, converseStreamEvent{"messageStop", awsbedrock.ConverseStreamEvent{StopReason: &stopReason}})
+		}
+	}
+	if chunk.Usage != nil {
+		events = append(events, converseStreamEvent{"metadata", awsbedrock.ConverseStreamEvent{Usage: openAIUsageToBedrockUsage(chunk.Usage)}})
+	}
+	return
+}
+
+// closeBlock appends the contentBlockStop event if a content block is open, and advances the block index.
+func (a *awsBedrockToOpenAITranslatorV1Converse) closeBlock(events []converseStreamEvent) []converseStreamEvent {
+	if !a.blockOpen {
+		return events
+	}
+	events = append(events, converseStreamEvent{"contentBlockStop", awsbedrock.ConverseStreamEvent{
+		ContentBlockIndex: ptr.To(a.blockIndex),
+	}})
+	a.blockOpen = false
+	a.blockIndex++
+	return events
+}
+
+// encodeConverseStreamEvent writes the event to out in the AWS event stream encoding.
+func encodeConverseStreamEvent(enc *eventstream.Encoder, out io.Writer, eventType string, event *awsbedrock.ConverseStreamEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return enc.Encode(out, eventstream.Message{
+		Headers: eventstream.Headers{
+			{Name: ":event-type", Value: eventstream.StringValue(eventType)},
+			{Name: ":content-type", Value: eventstream.StringValue(jsonContentType)},
+			{Name: ":message-type", Value: eventstream.StringValue("event")},
+		},
+		Payload: payload,
+	})
+}
+
+// openAIFinishReasonToBedrockStopReason is the inverse of bedrockStopReasonToOpenAIStopReason.
+func openAIFinishReasonToBedrockStopReason(reason openai.ChatCompletionChoicesFinishReason) string {
+	switch reason {
+	case openai.ChatCompletionChoicesFinishReasonLength:
+		return awsbedrock.StopReasonMaxTokens
+	case openai.ChatCompletionChoicesFinishReasonToolCalls:
+		return awsbedrock.StopReasonToolUse
+	case openai.ChatCompletionChoicesFinishReasonContentFilter:
+		return awsbedrock.StopReasonContentFiltered
+	default:
+		return awsbedrock.StopReasonEndTurn
+	}
+}
+
+// openAIErrorToAWSBedrockError translates the error response of the OpenAI backend into the AWS Bedrock error, which
+// has the message in the body and the error type in the "x-amzn-errortype" header.
+func openAIErrorToAWSBedrockError(status int, respHeaders map[string]string, body io.Reader) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error,
+) {
+	buf, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read error body: %w", err)
+	}
+	message := string(buf)
+	if respHeaders[contentTypeHeaderName] == jsonContentType {
+		var openAIError openai.Error
+		if json.Unmarshal(buf, &openAIError) == nil && openAIError.Error.Message != "" {
+			message = openAIError.Error.Message
+		}
+	}
+	mut := &extprocv3.BodyMutation_Body{}
+	if mut.Body, err = json.Marshal(awsbedrock.BedrockException{Message: message}); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal error body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{
+		SetHeaders: []*corev3.HeaderValueOption{
+			{Header: &corev3.HeaderValue{Key: awsErrorTypeHeaderName, RawValue: []byte(httpStatusToAWSBedrockErrorType(status))}},
+			{Header: &corev3.HeaderValue{Key: contentTypeHeaderName, RawValue: []byte(jsonContentType)}},
+		},
+	}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, nil
+}
+
+// httpStatusToAWSBedrockErrorType returns the AWS Bedrock exception name corresponding to the HTTP status code.
+// https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_Converse.html#API_runtime_Converse_Errors
+func httpStatusToAWSBedrockErrorType(status int) string {
+	switch {
+	case status == 400:
+		return "ValidationException"
+	case status == 401 || status == 403:
+		return "AccessDeniedException"
+	case status == 404:
+		return "ResourceNotFoundException"
+	case status == 429:
+		return "ThrottlingException"
+	case status == 503:
+		return "ServiceUnavailableException"
+	case status >= 500:
+		return "InternalServerException"
+	default:
+		return "ValidationException"
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+)
+
+func TestAWSBedrockToOpenAITranslatorV1Converse_RequestBody(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		input   string
+		stream  bool
+		expBody string
+		expErr  string
+	}{
+		{
+			name: "text",
+			input: `{"modelId":"gpt-4o","system":[{"text":"be nice"},{"text":"be brief"}],
+"inferenceConfig":{"maxTokens":10,"stopSequences":["x"],"temperature":0.5,"topP":0.9},
+"messages":[{"role":"user","content":[{"text":"hi"}]},{"role":"assistant","content":[{"text":"hello"}]}]}`,
+			expBody: `{"model":"gpt-4o","max_tokens":10,"stop":["x"],"temperature":0.5,"top_p":0.9,"messages":[
+{"role":"system","content":"be nice\nbe brief"},{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]}`,
+		},
+		{
+			name: "image and tools streaming",
+			input: `{"modelId":"gpt-4o","messages":[
+{"role":"user","content":[{"text":"what is this?"},{"image":{"format":"png","source":{"bytes":"aGVsbG8="}}}]},
+{"role":"assistant","content":[{"toolUse":{"toolUseId":"call_1","name":"lookup","input":{"q":"cat"}}}]},
+{"role":"user","content":[{"toolResult":{"toolUseId":"call_1","content":[{"text":"a "},{"json":{"kind":"cat"}}]}}]}],
+"toolConfig":{"tools":[{"toolSpec":{"name":"lookup","description":"look up","inputSchema":{"json":{"type":"object"}}}}],
+"toolChoice":{"tool":{"name":"lookup"}}}}`,
+			stream: true,
+			expBody: `{"model":"gpt-4o","stream":true,"stream_options":{"include_usage":true},"messages":[
+{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,aGVsbG8="}}]},
+{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"q\":\"cat\"}"}}]},
+{"role":"tool","tool_call_id":"call_1","content":"a {\"kind\":\"cat\"}"}],
+"tools":[{"type":"function","function":{"name":"lookup","description":"look up","parameters":{"type":"object"}}}],
+"tool_choice":{"type":"function","function":{"name":"lookup"}}}`,
+		},
+		{
+			name:   "missing model",
+			input:  `{"messages":[]}`,
+			expErr: "model ID is required",
+		},
+		{
+			name:   "document",
+			input:  `{"modelId":"gpt-4o","messages":[{"role":"user","content":[{"document":{"format":"pdf","name":"a","source":{"bytes":""}}}]}]}`,
+			expErr: "failed to convert message at index 0: unsupported content block in user message",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var input awsbedrock.ConverseInput
+			require.NoError(t, json.Unmarshal([]byte(tc.input), &input))
+			a := NewConverseAWSBedrockToOpenAITranslator("")
+			hm, bm, override, err := a.RequestBody(&input, tc.stream)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, ":path", hm.SetHeaders[0].Header.Key)
+			require.Equal(t, "/v1/chat/completions", string(hm.SetHeaders[0].Header.RawValue))
+			require.Equal(t, "content-length", hm.SetHeaders[1].Header.Key)
+			require.JSONEq(t, tc.expBody, string(bm.GetBody()))
+			if tc.stream {
+				require.Equal(t, extprocv3http.ProcessingMode_STREAMED, override.ResponseBodyMode)
+			} else {
+				require.Nil(t, override)
+			}
+		})
+	}
+}
+
+func TestAWSBedrockToOpenAITranslatorV1Converse_ResponseHeaders(t *testing.T) {
+	a := &awsBedrockToOpenAITranslatorV1Converse{}
+	hm, err := a.ResponseHeaders(map[string]string{"content-type": "text/event-stream"})
+	require.NoError(t, err)
+	require.Nil(t, hm)
+
+	a.stream = true
+	hm, err = a.ResponseHeaders(map[string]string{"content-type": "text/event-stream"})
+	require.NoError(t, err)
+	require.Equal(t, "content-type", hm.SetHeaders[0].Header.Key)
+	require.Equal(t, awsEventStreamContentType, hm.SetHeaders[0].Header.Value)
+}
+
+func TestAWSBedrockToOpenAITranslatorV1Converse_ResponseBody(t *testing.T) {
+	t.Run("non-streaming", func(t *testing.T) {
+		a := &awsBedrockToOpenAITranslatorV1Converse{}
+		body := `{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"finish_reason":"tool_calls",
+"message":{"role":"assistant","content":"let me check","tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"q\":\"cat\"}"}}]}}],
+"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`
+		hm, bm, usage, err := a.ResponseBody(nil, strings.NewReader(body), true)
+		require.NoError(t, err)
+		require.Equal(t, LLMTokenUsage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}, usage)
+		require.Equal(t, "content-length", hm.SetHeaders[0].Header.Key)
+		require.JSONEq(t, `{"output":{"message":{"role":"assistant","content":[{"text":"let me check"},
+{"toolUse":{"toolUseId":"call_1","name":"lookup","input":{"q":"cat"}}}]}},"stopReason":"tool_use",
+"usage":{"inputTokens":10,"outputTokens":5,"totalTokens":15}}`, string(bm.GetBody()))
+	})
+	t.Run("streaming", func(t *testing.T) {
+		a := &awsBedrockToOpenAITranslatorV1Converse{stream: true}
+		chunks := []string{
+			`{"choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup","arguments":""}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"q\":1}"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+		}
+		var sse string
+		for _, c := range chunks {
+			sse += "data: " + c + "\n\n"
+		}
+		sse += "data: [DONE]\n\n"
+
+		// Split the body in the middle of an event to verify the buffering.
+		var out []byte
+		var usage LLMTokenUsage
+		for _, part := range []string{sse[:50], sse[50:]} {
+			_, bm, u, err := a.ResponseBody(nil, strings.NewReader(part), false)
+			require.NoError(t, err)
+			out = append(out, bm.GetBody()...)
+			if u != (LLMTokenUsage{}) {
+				usage = u
+			}
+		}
+		require.Equal(t, LLMTokenUsage{InputTokens: 3, OutputTokens: 4, TotalTokens: 7}, usage)
+
+		var events []string
+		dec := eventstream.NewDecoder()
+		r := bytes.NewReader(out)
+		for r.Len() > 0 {
+			msg, err := dec.Decode(r, nil)
+			require.NoError(t, err)
+			events = append(events, msg.Headers.Get(":event-type").String()+" "+string(msg.Payload))
+		}
+		require.Equal(t, []string{
+			`messageStart {"role":"assistant"}`,
+			`contentBlockDelta {"contentBlockIndex":0,"delta":{"text":"Hel"}}`,
+			`contentBlockDelta {"contentBlockIndex":0,"delta":{"text":"lo"}}`,
+			`contentBlockStop {"contentBlockIndex":0}`,
+			`contentBlockStart {"contentBlockIndex":1,"start":{"toolUse":{"name":"lookup","toolUseId":"call_1"}}}`,
+			`contentBlockDelta {"contentBlockIndex":1,"delta":{"toolUse":{"input":"{\"q\":1}"}}}`,
+			`contentBlockStop {"contentBlockIndex":1}`,
+			`messageStop {"stopReason":"tool_use"}`,
+			`metadata {"usage":{"inputTokens":3,"outputTokens":4,"totalTokens":7}}`,
+		}, events)
+	})
+	t.Run("error", func(t *testing.T) {
+		a := &awsBedrockToOpenAITranslatorV1Converse{}
+		hm, bm, _, err := a.ResponseBody(map[string]string{":status": "429", "content-type": "application/json"},
+			strings.NewReader(`{"error":{"message":"slow down","type":"rate_limit"}}`), true)
+		require.NoError(t, err)
+		require.Equal(t, awsErrorTypeHeaderName, hm.SetHeaders[0].Header.Key)
+		require.Equal(t, "ThrottlingException", string(hm.SetHeaders[0].Header.RawValue))
+		require.JSONEq(t, `{"message":"slow down"}`, string(bm.GetBody()))
+	})
+}
+
+func Test_httpStatusToAWSBedrockErrorType(t *testing.T) {
+	for status, exp := range map[int]string{
+		400: "ValidationException",
+		401: "AccessDeniedException",
+		403: "AccessDeniedException",
+		404: "ResourceNotFoundException",
+		429: "ThrottlingException",
+		500: "InternalServerException",
+		503: "ServiceUnavailableException",
+	} {
+		require.Equal(t, exp, httpStatusToAWSBedrockErrorType(status))
+	}
+}
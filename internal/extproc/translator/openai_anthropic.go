@@ -0,0 +1,615 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/anthropic"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+const (
+	// anthropicDefaultAPIVersion is the value of the anthropic-version header used when the schema version is not specified.
+	anthropicDefaultAPIVersion = "2023-06-01"
+	// anthropicDefaultMaxTokens is used when the OpenAI request doesn't specify max_tokens since it is required by Anthropic.
+	anthropicDefaultMaxTokens  = 4096
+	anthropicVersionHeaderName = "anthropic-version"
+	anthropicBackendError      = "AnthropicBackendError"
+)
+
+// NewChatCompletionOpenAIToAnthropicTranslator implements [Factory] for OpenAI to Anthropic translation.
+// The apiVersion is sent as the anthropic-version header, and defaults to anthropicDefaultAPIVersion when empty.
+// When modelNameOverride is not empty, it is used as the model instead of the model in the request.
+func NewChatCompletionOpenAIToAnthropicTranslator(apiVersion, modelNameOverride string) OpenAIChatCompletionTranslator {
+	if apiVersion == "" {
+		apiVersion = anthropicDefaultAPIVersion
+	}
+	return &openAIToAnthropicTranslatorV1ChatCompletion{apiVersion: apiVersion, modelNameOverride: modelNameOverride}
+}
+
+// openAIToAnthropicTranslatorV1ChatCompletion implements [Translator] for /v1/chat/completions.
+type openAIToAnthropicTranslatorV1ChatCompletion struct {
+	apiVersion        string
+	modelNameOverride string
+	stream            bool
+	bufferedBody      []byte
+	// inputUsage is from the message_start event, and used for the usage in the message_delta event.
+	inputUsage anthropic.Usage
+}
+
+// RequestBody implements [Translator.RequestBody].
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) RequestBody(_ []byte, openAIReq *openai.ChatCompletionRequest) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error,
+) {
+	if o.modelNameOverride != "" {
+		// Shallow copy the request not to modify the request of the caller.
+		r := *openAIReq
+		r.Model = o.modelNameOverride
+		openAIReq = &r
+	}
+	if openAIReq.Stream {
+		o.stream = true
+		// We need to change the processing mode for streaming requests.
+		// TODO: We can delete this explicit setting of ResponseHeaderMode below as it is the default value we use
+		// 	after https://github.com/envoyproxy/envoy/pull/38254 this is released.
+		override = &extprocv3http.ProcessingMode{
+			ResponseHeaderMode: extprocv3http.ProcessingMode_SEND,
+			ResponseBodyMode:   extprocv3http.ProcessingMode_STREAMED,
+		}
+	}
+
+	headerMutation = &extprocv3.HeaderMutation{
+		SetHeaders: []*corev3.HeaderValueOption{
+			{Header: &corev3.HeaderValue{Key: ":path", RawValue: []byte("/v1/messages")}},
+			{Header: &corev3.HeaderValue{Key: anthropicVersionHeaderName, RawValue: []byte(o.apiVersion)}},
+		},
+	}
+
+	anthropicReq := anthropic.MessagesRequest{
+		Model:       openAIReq.Model,
+		MaxTokens:   anthropicDefaultMaxTokens,
+		Stream:      openAIReq.Stream,
+		Temperature: openAIReq.Temperature,
+		TopP:        openAIReq.TopP,
+	}
+	if maxTokens := chatCompletionMaxTokens(openAIReq); maxTokens != nil {
+		anthropicReq.MaxTokens = *maxTokens
+	}
+	for _, stop := range openAIReq.Stop {
+		if stop != nil {
+			anthropicReq.StopSequences = append(anthropicReq.StopSequences, *stop)
+		}
+	}
+	if openAIReq.User != "" {
+		anthropicReq.Metadata = &anthropic.Metadata{UserID: openAIReq.User}
+	}
+	if err = o.openAIMessagesToAnthropicMessages(openAIReq, &anthropicReq); err != nil {
+		return nil, nil, nil, err
+	}
+	if err = o.openAIToolsToAnthropicTools(openAIReq, &anthropicReq); err != nil {
+		return nil, nil, nil, err
+	}
+
+	mut := &extprocv3.BodyMutation_Body{}
+	if mut.Body, err = json.Marshal(anthropicReq); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, override, nil
+}
+
+// openAIMessagesToAnthropicMessages converts openai ChatCompletion messages to anthropic messages.
+// The system and developer messages are hoisted to the top-level system prompt, and the consecutive
+// tool messages are merged into a single user message with multiple tool_result blocks.
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) openAIMessagesToAnthropicMessages(openAIReq *openai.ChatCompletionRequest,
+	anthropicReq *anthropic.MessagesRequest,
+) error {
+	anthropicReq.Messages = make([]anthropic.Message, 0, len(openAIReq.Messages))
+	for i := range openAIReq.Messages {
+		msg := &openAIReq.Messages[i]
+		switch msg.Type {
+		case openai.ChatMessageRoleUser:
+			userMessage := msg.Value.(openai.ChatCompletionUserMessageParam)
+			content, err := o.openAIUserContentToAnthropicContent(&userMessage)
+			if err != nil {
+				return err
+			}
+			anthropicReq.Messages = append(anthropicReq.Messages, anthropic.Message{Role: anthropic.MessageRoleUser, Content: content})
+		case openai.ChatMessageRoleAssistant:
+			assistantMessage := msg.Value.(openai.ChatCompletionAssistantMessageParam)
+			content, err := o.openAIAssistantContentToAnthropicContent(&assistantMessage)
+			if err != nil {
+				return err
+			}
+			anthropicReq.Messages = append(anthropicReq.Messages, anthropic.Message{Role: anthropic.MessageRoleAssistant, Content: content})
+		case openai.ChatMessageRoleSystem:
+			systemMessage := msg.Value.(openai.ChatCompletionSystemMessageParam)
+			texts, err := stringOrArrayToTexts(&systemMessage.Content)
+			if err != nil {
+				return fmt.Errorf("unexpected content type for system message: %w", err)
+			}
+			anthropicReq.System = append(anthropicReq.System, texts...)
+		case openai.ChatMessageRoleDeveloper:
+			developerMessage := msg.Value.(openai.ChatCompletionDeveloperMessageParam)
+			texts, err := stringOrArrayToTexts(&developerMessage.Content)
+			if err != nil {
+				return fmt.Errorf("unexpected content type for developer message: %w", err)
+			}
+			anthropicReq.System = append(anthropicReq.System, texts...)
+		case openai.ChatMessageRoleTool:
+			toolMessage := msg.Value.(openai.ChatCompletionToolMessageParam)
+			texts, err := stringOrArrayToTexts(&toolMessage.Content)
+			if err != nil {
+				return fmt.Errorf("unexpected content type for tool message: %w", err)
+			}
+			block := anthropic.ContentBlock{
+				Type:      anthropic.ContentBlockTypeToolResult,
+				ToolUseID: toolMessage.ToolCallID,
+				Content:   texts,
+			}
+			// Anthropic does not support tool role, so the results are sent as the user message. Multiple results
+			// for the parallel tool calls must be in the same user message.
+			if l := len(anthropicReq.Messages); l > 0 && i > 0 && openAIReq.Messages[i-1].Type == openai.ChatMessageRoleTool {
+				anthropicReq.Messages[l-1].Content = append(anthropicReq.Messages[l-1].Content, block)
+			} else {
+				anthropicReq.Messages = append(anthropicReq.Messages, anthropic.Message{
+					Role: anthropic.MessageRoleUser, Content: []anthropic.ContentBlock{block},
+				})
+			}
+		default:
+			return fmt.Errorf("unexpected role: %s", msg.Type)
+		}
+	}
+	return nil
+}
+
+// stringOrArrayToTexts converts the content of the system, developer and tool messages to the anthropic text blocks.
+func stringOrArrayToTexts(content *openai.StringOrArray) ([]anthropic.ContentBlock, error) {
+	switch v := content.Value.(type) {
+	case string:
+		return []anthropic.ContentBlock{{Type: anthropic.ContentBlockTypeText, Text: v}}, nil
+	case []openai.ChatCompletionContentPartTextParam:
+		blocks := make([]anthropic.ContentBlock, 0, len(v))
+		for i := range v {
+			blocks = append(blocks, anthropic.ContentBlock{Type: anthropic.ContentBlockTypeText, Text: v[i].Text})
+		}
+		return blocks, nil
+	default:
+		return nil, fmt.Errorf("unexpected type: %T", content.Value)
+	}
+}
+
+// openAIUserContentToAnthropicContent converts openai user role message content.
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) openAIUserContentToAnthropicContent(
+	openAIMessage *openai.ChatCompletionUserMessageParam,
+) ([]anthropic.ContentBlock, error) {
+	switch v := openAIMessage.Content.Value.(type) {
+	case string:
+		return []anthropic.ContentBlock{{Type: anthropic.ContentBlockTypeText, Text: v}}, nil
+	case []openai.ChatCompletionContentPartUserUnionParam:
+		blocks := make([]anthropic.ContentBlock, 0, len(v))
+		for i := range v {
+			contentPart := &v[i]
+			if contentPart.TextContent != nil {
+				blocks = append(blocks, anthropic.ContentBlock{
+					Type: anthropic.ContentBlockTypeText, Text: contentPart.TextContent.Text,
+				})
+			} else if contentPart.ImageContent != nil {
+				source, err := openAIImageURLToAnthropicImageSource(contentPart.ImageContent.ImageURL.URL)
+				if err != nil {
+					return nil, err
+				}
+				blocks = append(blocks, anthropic.ContentBlock{Type: anthropic.ContentBlockTypeImage, Source: source})
+			}
+		}
+		return blocks, nil
+	default:
+		return nil, fmt.Errorf("unexpected content type")
+	}
+}
+
+// openAIImageURLToAnthropicImageSource converts the image URL which is either a data URI or a http(s) URL.
+func openAIImageURLToAnthropicImageSource(url string) (*anthropic.ImageSource, error) {
+	if !strings.HasPrefix(url, "data:") {
+		return &anthropic.ImageSource{Type: anthropic.ImageSourceTypeURL, URL: url}, nil
+	}
+	contentType, b, err := parseDataURI(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image URL: %s %w", url, err)
+	}
+	switch contentType {
+	case "image/png", "image/jpeg", "image/gif", "image/webp":
+	default:
+		return nil, fmt.Errorf("unsupported image type: %s please use one of [png, jpeg, gif, webp]", contentType)
+	}
+	return &anthropic.ImageSource{
+		Type:      anthropic.ImageSourceTypeBase64,
+		MediaType: contentType,
+		Data:      base64.StdEncoding.EncodeToString(b),
+	}, nil
+}
+
+// openAIAssistantContentToAnthropicContent converts openai assistant role message content.
+// The tool calls are appended as the tool_use blocks.
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) openAIAssistantContentToAnthropicContent(
+	openAIMessage *openai.ChatCompletionAssistantMessageParam,
+) ([]anthropic.ContentBlock, error) {
+	var blocks []anthropic.ContentBlock
+	text := openAIMessage.Content.Text
+	if openAIMessage.Content.Type == openai.ChatCompletionAssistantMessageParamContentTypeRefusal {
+		text = openAIMessage.Content.Refusal
+	}
+	if text != nil && *text != "" {
+		blocks = append(blocks, anthropic.ContentBlock{Type: anthropic.ContentBlockTypeText, Text: *text})
+	}
+	for i := range openAIMessage.ToolCalls {
+		toolCall := &openAIMessage.ToolCalls[i]
+		input := map[string]interface{}{}
+		if toolCall.Function.Arguments != "" {
+			var err error
+			if input, err = unmarshalToolCallArguments(toolCall.Function.Arguments); err != nil {
+				return nil, err
+			}
+		}
+		blocks = append(blocks, anthropic.ContentBlock{
+			Type:  anthropic.ContentBlockTypeToolUse,
+			ID:    toolCall.ID,
+			Name:  toolCall.Function.Name,
+			Input: input,
+		})
+	}
+	return blocks, nil
+}
+
+// openAIToolsToAnthropicTools converts openai ChatCompletion tools and tool_choice to anthropic ones.
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) openAIToolsToAnthropicTools(openAIReq *openai.ChatCompletionRequest,
+	anthropicReq *anthropic.MessagesRequest,
+) error {
+	for i := range openAIReq.Tools {
+		tool := &openAIReq.Tools[i]
+		if tool.Function == nil {
+			continue
+		}
+		inputSchema := tool.Function.Parameters
+		if inputSchema == nil {
+			// input_schema is required by Anthropic.
+			inputSchema = map[string]interface{}{"type": "object"}
+		}
+		anthropicReq.Tools = append(anthropicReq.Tools, anthropic.Tool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: inputSchema,
+		})
+	}
+
+	switch toolChoice := openAIReq.ToolChoice.(type) {
+	case nil:
+	case string:
+		switch toolChoice {
+		case "auto":
+			anthropicReq.ToolChoice = &anthropic.ToolChoice{Type: anthropic.ToolChoiceTypeAuto}
+		case "required":
+			anthropicReq.ToolChoice = &anthropic.ToolChoice{Type: anthropic.ToolChoiceTypeAny}
+		case "none":
+			anthropicReq.ToolChoice = &anthropic.ToolChoice{Type: anthropic.ToolChoiceTypeNone}
+		default:
+			return fmt.Errorf("unexpected tool_choice: %s", toolChoice)
+		}
+	case openai.ToolChoice:
+		anthropicReq.ToolChoice = &anthropic.ToolChoice{Type: anthropic.ToolChoiceTypeTool, Name: toolChoice.Function.Name}
+	case map[string]interface{}:
+		// This is the case when the request body is unmarshalled from JSON.
+		function, _ := toolChoice["function"].(map[string]interface{})
+		name, _ := function["name"].(string)
+		if name == "" {
+			return fmt.Errorf("tool_choice function name is required")
+		}
+		anthropicReq.ToolChoice = &anthropic.ToolChoice{Type: anthropic.ToolChoiceTypeTool, Name: name}
+	default:
+		return fmt.Errorf("unexpected type: %T", openAIReq.ToolChoice)
+	}
+	return nil
+}
+
+// ResponseHeaders implements [Translator.ResponseHeaders].
+//
+// Anthropic streams the response as text/event-stream, so there's nothing to do here.
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) ResponseHeaders(map[string]string) (
+	headerMutation *extprocv3.HeaderMutation, err error,
+) {
+	return nil, nil
+}
+
+// ResponseError implements [Translator.ResponseError].
+// Translate Anthropic errors to OpenAI error type.
+// If the Anthropic connection fails the error body is translated to OpenAI error type for events such as HTTP 503 or 504.
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) ResponseError(respHeaders map[string]string, body io.Reader) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error,
+) {
+	statusCode := respHeaders[statusHeaderName]
+	var openaiError openai.Error
+	if v, ok := respHeaders[contentTypeHeaderName]; ok && v == jsonContentType {
+		var anthropicError anthropic.Error
+		if err = json.NewDecoder(body).Decode(&anthropicError); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal error body: %w", err)
+		}
+		openaiError = openai.Error{
+			Type: "error",
+			Error: openai.ErrorType{
+				Type:    anthropicError.Error.Type,
+				Message: anthropicError.Error.Message,
+				Code:    &statusCode,
+			},
+		}
+	} else {
+		var buf []byte
+		buf, err = io.ReadAll(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read error body: %w", err)
+		}
+		openaiError = openai.Error{
+			Type: "error",
+			Error: openai.ErrorType{
+				Type:    anthropicBackendError,
+				Message: string(buf),
+				Code:    &statusCode,
+			},
+		}
+	}
+	mut := &extprocv3.BodyMutation_Body{}
+	mut.Body, err = json.Marshal(openaiError)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal error body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, nil
+}
+
+// ResponseBody implements [Translator.ResponseBody].
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) ResponseBody(respHeaders map[string]string, body io.Reader, endOfStream bool) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage LLMTokenUsage, err error,
+) {
+	if statusStr, ok := respHeaders[statusHeaderName]; ok {
+		var status int
+		if status, err = strconv.Atoi(statusStr); err == nil {
+			if !isGoodStatusCode(status) {
+				headerMutation, bodyMutation, err = o.ResponseError(respHeaders, body)
+				return headerMutation, bodyMutation, LLMTokenUsage{}, err
+			}
+		}
+	}
+	mut := &extprocv3.BodyMutation_Body{}
+	if o.stream {
+		var buf []byte
+		buf, err = io.ReadAll(body)
+		if err != nil {
+			return nil, nil, tokenUsage, fmt.Errorf("failed to read body: %w", err)
+		}
+		o.bufferedBody = append(o.bufferedBody, buf...)
+		for _, event := range o.extractServerSentEvents() {
+			if event.Type == anthropic.StreamEventTypeMessageDelta && event.Usage != nil {
+				tokenUsage = anthropicUsageToLLMTokenUsage(&o.inputUsage, event.Usage.OutputTokens)
+			}
+			for _, oaiEvent := range o.convertEvent(event) {
+				var oaiEventBytes []byte
+				oaiEventBytes, err = json.Marshal(oaiEvent)
+				if err != nil {
+					panic(fmt.Errorf("failed to marshal event: %w", err))
+				}
+				mut.Body = append(mut.Body, dataPrefix...)
+				mut.Body = append(mut.Body, oaiEventBytes...)
+				mut.Body = append(mut.Body, []byte("\n\n")...)
+			}
+		}
+		if endOfStream {
+			mut.Body = append(mut.Body, []byte("data: [DONE]\n")...)
+		}
+		return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, tokenUsage, nil
+	}
+
+	var anthropicResp anthropic.MessagesResponse
+	if err = json.NewDecoder(body).Decode(&anthropicResp); err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+	usage := &anthropicResp.Usage
+	tokenUsage = anthropicUsageToLLMTokenUsage(usage, usage.OutputTokens)
+	openAIResp := openai.ChatCompletionResponse{
+		Object: "chat.completion",
+		Usage:  anthropicUsageToOpenAIUsage(usage, usage.OutputTokens),
+	}
+	// Anthropic does not support N(multiple choices) > 0, so there could be only one choice.
+	choice := openai.ChatCompletionResponseChoice{
+		Message:      openai.ChatCompletionResponseChoiceMessage{Role: anthropicResp.Role},
+		FinishReason: anthropicStopReasonToOpenAIStopReason(anthropicResp.StopReason),
+	}
+	var text strings.Builder
+	for i := range anthropicResp.Content {
+		block := &anthropicResp.Content[i]
+		switch block.Type {
+		case anthropic.ContentBlockTypeText:
+			text.WriteString(block.Text)
+		case anthropic.ContentBlockTypeToolUse:
+			arguments, err := json.Marshal(block.Input)
+			if err != nil {
+				return nil, nil, tokenUsage, fmt.Errorf("failed to marshal tool use input: %w", err)
+			}
+			choice.Message.ToolCalls = append(choice.Message.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+				ID:   block.ID,
+				Type: openai.ChatCompletionMessageToolCallTypeFunction,
+				Function: openai.ChatCompletionMessageToolCallFunctionParam{
+					Name:      block.Name,
+					Arguments: string(arguments),
+				},
+			})
+		}
+	}
+	if text.Len() > 0 {
+		choice.Message.Content = ptr.To(text.String())
+	}
+	openAIResp.Choices = append(openAIResp.Choices, choice)
+
+	mut.Body, err = json.Marshal(openAIResp)
+	if err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, tokenUsage, nil
+}
+
+// extractServerSentEvents extracts [anthropic.StreamEvent] from the buffered body.
+// The incomplete event at the end of the buffer is kept for the next call.
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) extractServerSentEvents() (events []*anthropic.StreamEvent) {
+	for {
+		i := bytes.Index(o.bufferedBody, []byte("\n\n"))
+		if i == -1 {
+			return
+		}
+		raw := o.bufferedBody[:i]
+		o.bufferedBody = o.bufferedBody[i+2:]
+		for _, line := range bytes.Split(raw, []byte("\n")) {
+			// The event type is duplicated in the "event:" line and the data, so only the data line is used.
+			if !bytes.HasPrefix(line, dataPrefix) {
+				continue
+			}
+			var event anthropic.StreamEvent
+			if err := json.Unmarshal(bytes.TrimPrefix(line, dataPrefix), &event); err == nil {
+				events = append(events, &event)
+			}
+		}
+	}
+}
+
+// convertEvent converts an [anthropic.StreamEvent] to zero or more [openai.ChatCompletionResponseChunk].
+func (o *openAIToAnthropicTranslatorV1ChatCompletion) convertEvent(event *anthropic.StreamEvent) []openai.ChatCompletionResponseChunk {
+	const object = "chat.completion.chunk"
+	switch event.Type {
+	case anthropic.StreamEventTypeMessageStart:
+		if event.Message == nil {
+			return nil
+		}
+		o.inputUsage = event.Message.Usage
+		return []openai.ChatCompletionResponseChunk{{
+			Object: object,
+			Choices: []openai.ChatCompletionResponseChunkChoice{{
+				Delta: &openai.ChatCompletionResponseChunkChoiceDelta{Role: openai.ChatMessageRoleAssistant, Content: &emptyString},
+			}},
+		}}
+	case anthropic.StreamEventTypeContentBlockStart:
+		if event.ContentBlock == nil || event.ContentBlock.Type != anthropic.ContentBlockTypeToolUse {
+			return nil
+		}
+		return []openai.ChatCompletionResponseChunk{{
+			Object: object,
+			Choices: []openai.ChatCompletionResponseChunkChoice{{
+				Delta: &openai.ChatCompletionResponseChunkChoiceDelta{
+					Role: openai.ChatMessageRoleAssistant,
+					ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+						ID:       event.ContentBlock.ID,
+						Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: event.ContentBlock.Name},
+						Type:     openai.ChatCompletionMessageToolCallTypeFunction,
+					}},
+				},
+			}},
+		}}
+	case anthropic.StreamEventTypeContentBlockDelta:
+		if event.Delta == nil {
+			return nil
+		}
+		delta := &openai.ChatCompletionResponseChunkChoiceDelta{Role: openai.ChatMessageRoleAssistant}
+		switch event.Delta.Type {
+		case anthropic.DeltaTypeText:
+			delta.Content = ptr.To(event.Delta.Text)
+		case anthropic.DeltaTypeInputJSON:
+			delta.ToolCalls = []openai.ChatCompletionMessageToolCallParam{{
+				Function: openai.ChatCompletionMessageToolCallFunctionParam{Arguments: event.Delta.PartialJSON},
+				Type:     openai.ChatCompletionMessageToolCallTypeFunction,
+			}}
+		default:
+			return nil
+		}
+		return []openai.ChatCompletionResponseChunk{{
+			Object:  object,
+			Choices: []openai.ChatCompletionResponseChunkChoice{{Delta: delta}},
+		}}
+	case anthropic.StreamEventTypeMessageDelta:
+		var chunks []openai.ChatCompletionResponseChunk
+		if event.Delta != nil && event.Delta.StopReason != nil {
+			chunks = append(chunks, openai.ChatCompletionResponseChunk{
+				Object: object,
+				Choices: []openai.ChatCompletionResponseChunkChoice{{
+					Delta:        &openai.ChatCompletionResponseChunkChoiceDelta{Role: openai.ChatMessageRoleAssistant, Content: ptr.To(emptyString)},
+					FinishReason: anthropicStopReasonToOpenAIStopReason(event.Delta.StopReason),
+				}},
+			})
+		}
+		if event.Usage != nil {
+			usage := anthropicUsageToOpenAIUsage(&o.inputUsage, event.Usage.OutputTokens)
+			chunks = append(chunks, openai.ChatCompletionResponseChunk{Object: object, Usage: &usage})
+		}
+		return chunks
+	default:
+		// content_block_stop, message_stop, ping and error events have no equivalent in the OpenAI chunks.
+		return nil
+	}
+}
+
+// anthropicStopReasonToOpenAIStopReason converts the anthropic stop reason to the openai finish reason.
+func anthropicStopReasonToOpenAIStopReason(stopReason *string) openai.ChatCompletionChoicesFinishReason {
+	if stopReason == nil {
+		return openai.ChatCompletionChoicesFinishReasonStop
+	}
+	switch *stopReason {
+	case anthropic.StopReasonMaxTokens:
+		return openai.ChatCompletionChoicesFinishReasonLength
+	case anthropic.StopReasonToolUse:
+		return openai.ChatCompletionChoicesFinishReasonToolCalls
+	default:
+		return openai.ChatCompletionChoicesFinishReasonStop
+	}
+}
+
+// anthropicUsageToLLMTokenUsage converts the usage of the input in the Anthropic response and the number of
+// the output tokens to the LLMTokenUsage. The input tokens of Anthropic do not include the cache read and
+// creation tokens unlike OpenAI, so they are added.
+func anthropicUsageToLLMTokenUsage(input *anthropic.Usage, outputTokens int) LLMTokenUsage {
+	inputTokens := input.InputTokens + input.CacheReadInputTokens + input.CacheCreationInputTokens
+	return LLMTokenUsage{
+		InputTokens:           uint32(inputTokens),                    //nolint:gosec
+		OutputTokens:          uint32(outputTokens),                   //nolint:gosec
+		TotalTokens:           uint32(inputTokens + outputTokens),     //nolint:gosec
+		CachedInputTokens:     uint32(input.CacheReadInputTokens),     //nolint:gosec
+		CacheWriteInputTokens: uint32(input.CacheCreationInputTokens), //nolint:gosec
+	}
+}
+
+// anthropicUsageToOpenAIUsage converts the usage of the input in the Anthropic response and the number of
+// the output tokens to the usage in the OpenAI response.
+func anthropicUsageToOpenAIUsage(input *anthropic.Usage, outputTokens int) openai.ChatCompletionResponseUsage {
+	inputTokens := input.InputTokens + input.CacheReadInputTokens + input.CacheCreationInputTokens
+	usage := openai.ChatCompletionResponseUsage{
+		PromptTokens:     inputTokens,
+		CompletionTokens: outputTokens,
+		TotalTokens:      inputTokens + outputTokens,
+	}
+	if input.CacheReadInputTokens > 0 {
+		usage.PromptTokensDetails = &openai.PromptTokensDetails{CachedTokens: input.CacheReadInputTokens}
+	}
+	return usage
+}
@@ -0,0 +1,297 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/anthropic"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func TestOpenAIToAnthropicTranslatorV1ChatCompletion_RequestBody(t *testing.T) {
+	t.Run("messages", func(t *testing.T) {
+		const body = `{
+  "model": "claude-3-5-sonnet-latest",
+  "max_tokens": 100,
+  "stop": ["STOP"],
+  "user": "some-user",
+  "messages": [
+    {"role": "system", "content": "from-system"},
+    {"role": "developer", "content": [{"type": "text", "text": "from-developer"}]},
+    {"role": "user", "content": [
+      {"type": "text", "text": "what is in this image?"},
+      {"type": "image_url", "image_url": {"url": "data:image/png;base64,aGVsbG8="}},
+      {"type": "image_url", "image_url": {"url": "https://example.com/cat.png"}}
+    ]},
+    {"role": "assistant", "content": {"type": "text", "text": "let me check"}, "tool_calls": [
+      {"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Tokyo\"}"}},
+      {"id": "call_2", "type": "function", "function": {"name": "get_time", "arguments": ""}}
+    ]},
+    {"role": "tool", "tool_call_id": "call_1", "content": "sunny"},
+    {"role": "tool", "tool_call_id": "call_2", "content": [{"type": "text", "text": "noon"}]},
+    {"role": "user", "content": "thanks"}
+  ],
+  "tools": [{"type": "function", "function": {"name": "get_weather", "description": "weather", "parameters": {"type": "object"}}}],
+  "tool_choice": {"type": "function", "function": {"name": "get_weather"}}
+}`
+		var req openai.ChatCompletionRequest
+		require.NoError(t, json.Unmarshal([]byte(body), &req))
+
+		o := NewChatCompletionOpenAIToAnthropicTranslator("", "")
+		hm, bm, mode, err := o.RequestBody(nil, &req)
+		require.NoError(t, err)
+		require.Nil(t, mode)
+		require.Len(t, hm.SetHeaders, 3)
+		require.Equal(t, ":path", hm.SetHeaders[0].Header.Key)
+		require.Equal(t, "/v1/messages", string(hm.SetHeaders[0].Header.RawValue))
+		require.Equal(t, "anthropic-version", hm.SetHeaders[1].Header.Key)
+		require.Equal(t, "2023-06-01", string(hm.SetHeaders[1].Header.RawValue))
+		require.Equal(t, "content-length", hm.SetHeaders[2].Header.Key)
+
+		var actual anthropic.MessagesRequest
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+		expected := anthropic.MessagesRequest{
+			Model:         "claude-3-5-sonnet-latest",
+			MaxTokens:     100,
+			StopSequences: []string{"STOP"},
+			Metadata:      &anthropic.Metadata{UserID: "some-user"},
+			System: []anthropic.ContentBlock{
+				{Type: "text", Text: "from-system"},
+				{Type: "text", Text: "from-developer"},
+			},
+			Messages: []anthropic.Message{
+				{Role: "user", Content: []anthropic.ContentBlock{
+					{Type: "text", Text: "what is in this image?"},
+					{Type: "image", Source: &anthropic.ImageSource{Type: "base64", MediaType: "image/png", Data: "aGVsbG8="}},
+					{Type: "image", Source: &anthropic.ImageSource{Type: "url", URL: "https://example.com/cat.png"}},
+				}},
+				{Role: "assistant", Content: []anthropic.ContentBlock{
+					{Type: "text", Text: "let me check"},
+					{Type: "tool_use", ID: "call_1", Name: "get_weather", Input: map[string]interface{}{"city": "Tokyo"}},
+					{Type: "tool_use", ID: "call_2", Name: "get_time", Input: map[string]interface{}{}},
+				}},
+				{Role: "user", Content: []anthropic.ContentBlock{
+					{Type: "tool_result", ToolUseID: "call_1", Content: []anthropic.ContentBlock{{Type: "text", Text: "sunny"}}},
+					{Type: "tool_result", ToolUseID: "call_2", Content: []anthropic.ContentBlock{{Type: "text", Text: "noon"}}},
+				}},
+				{Role: "user", Content: []anthropic.ContentBlock{{Type: "text", Text: "thanks"}}},
+			},
+			Tools: []anthropic.Tool{
+				{Name: "get_weather", Description: "weather", InputSchema: map[string]interface{}{"type": "object"}},
+			},
+			ToolChoice: &anthropic.ToolChoice{Type: "tool", Name: "get_weather"},
+		}
+		if !cmp.Equal(expected, actual) {
+			t.Errorf("RequestBody(), diff(got, expected) = %s\n", cmp.Diff(actual, expected))
+		}
+	})
+	t.Run("streaming and defaults", func(t *testing.T) {
+		req := &openai.ChatCompletionRequest{
+			Model:  "claude-3-5-haiku-latest",
+			Stream: true,
+			Messages: []openai.ChatCompletionMessageParamUnion{{
+				Type:  openai.ChatMessageRoleUser,
+				Value: openai.ChatCompletionUserMessageParam{Content: openai.StringOrUserRoleContentUnion{Value: "hi"}},
+			}},
+			ToolChoice: "required",
+		}
+		o := NewChatCompletionOpenAIToAnthropicTranslator("2024-01-01", "")
+		hm, bm, mode, err := o.RequestBody(nil, req)
+		require.NoError(t, err)
+		require.NotNil(t, mode)
+		require.Equal(t, extprocv3http.ProcessingMode_STREAMED, mode.ResponseBodyMode)
+		require.Equal(t, "2024-01-01", string(hm.SetHeaders[1].Header.RawValue))
+
+		var actual anthropic.MessagesRequest
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+		require.True(t, actual.Stream)
+		require.Equal(t, int64(anthropicDefaultMaxTokens), actual.MaxTokens)
+		require.Equal(t, &anthropic.ToolChoice{Type: "any"}, actual.ToolChoice)
+	})
+	t.Run("unsupported image type", func(t *testing.T) {
+		req := &openai.ChatCompletionRequest{
+			Messages: []openai.ChatCompletionMessageParamUnion{{
+				Type: openai.ChatMessageRoleUser,
+				Value: openai.ChatCompletionUserMessageParam{Content: openai.StringOrUserRoleContentUnion{
+					Value: []openai.ChatCompletionContentPartUserUnionParam{{
+						ImageContent: &openai.ChatCompletionContentPartImageParam{
+							ImageURL: openai.ChatCompletionContentPartImageImageURLParam{URL: "data:image/bmp;base64,aGVsbG8="},
+						},
+					}},
+				}},
+			}},
+		}
+		o := NewChatCompletionOpenAIToAnthropicTranslator("", "")
+		_, _, _, err := o.RequestBody(nil, req)
+		require.ErrorContains(t, err, "unsupported image type: image/bmp")
+	})
+	t.Run("unexpected tool choice", func(t *testing.T) {
+		o := NewChatCompletionOpenAIToAnthropicTranslator("", "")
+		_, _, _, err := o.RequestBody(nil, &openai.ChatCompletionRequest{ToolChoice: "foo"})
+		require.ErrorContains(t, err, "unexpected tool_choice: foo")
+	})
+}
+
+func TestOpenAIToAnthropicTranslatorV1ChatCompletion_ResponseBody(t *testing.T) {
+	t.Run("non-streaming", func(t *testing.T) {
+		const body = `{
+  "id": "msg_01", "type": "message", "role": "assistant", "model": "claude-3-5-sonnet-latest",
+  "content": [
+    {"type": "text", "text": "Let me check the weather."},
+    {"type": "tool_use", "id": "toolu_01", "name": "get_weather", "input": {"city": "Tokyo"}}
+  ],
+  "stop_reason": "tool_use", "stop_sequence": null,
+  "usage": {"input_tokens": 10, "output_tokens": 20}
+}`
+		o := NewChatCompletionOpenAIToAnthropicTranslator("", "")
+		hm, bm, usage, err := o.ResponseBody(map[string]string{":status": "200"}, strings.NewReader(body), true)
+		require.NoError(t, err)
+		require.NotNil(t, hm)
+		require.Equal(t, LLMTokenUsage{InputTokens: 10, OutputTokens: 20, TotalTokens: 30}, usage)
+
+		var actual openai.ChatCompletionResponse
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+		expected := openai.ChatCompletionResponse{
+			Object: "chat.completion",
+			Usage:  openai.ChatCompletionResponseUsage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30},
+			Choices: []openai.ChatCompletionResponseChoice{{
+				FinishReason: openai.ChatCompletionChoicesFinishReasonToolCalls,
+				Message: openai.ChatCompletionResponseChoiceMessage{
+					Role:    "assistant",
+					Content: ptr.To("Let me check the weather."),
+					ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+						ID:       "toolu_01",
+						Type:     openai.ChatCompletionMessageToolCallTypeFunction,
+						Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "get_weather", Arguments: `{"city":"Tokyo"}`},
+					}},
+				},
+			}},
+		}
+		if !cmp.Equal(expected, actual) {
+			t.Errorf("ResponseBody(), diff(got, expected) = %s\n", cmp.Diff(actual, expected))
+		}
+	})
+	t.Run("prompt caching", func(t *testing.T) {
+		const body = `{"type": "message", "role": "assistant", "content": [], "stop_reason": "end_turn",
+  "usage": {"input_tokens": 10, "output_tokens": 20, "cache_read_input_tokens": 100, "cache_creation_input_tokens": 50}}`
+		o := NewChatCompletionOpenAIToAnthropicTranslator("", "")
+		_, bm, usage, err := o.ResponseBody(map[string]string{":status": "200"}, strings.NewReader(body), true)
+		require.NoError(t, err)
+		require.Equal(t, LLMTokenUsage{
+			InputTokens: 160, OutputTokens: 20, TotalTokens: 180, CachedInputTokens: 100, CacheWriteInputTokens: 50,
+		}, usage)
+
+		var actual openai.ChatCompletionResponse
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+		require.Equal(t, openai.ChatCompletionResponseUsage{
+			PromptTokens: 160, CompletionTokens: 20, TotalTokens: 180,
+			PromptTokensDetails: &openai.PromptTokensDetails{CachedTokens: 100},
+		}, actual.Usage)
+	})
+	t.Run("streaming", func(t *testing.T) {
+		const body = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_01","type":"message","role":"assistant","content":[],"model":"claude-3-5-sonnet-latest","stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":25,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type": "ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_01","name":"get_weather","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\": \"Tokyo\"}"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":1}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use","stop_sequence":null},"usage":{"output_tokens":15}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+		o := NewChatCompletionOpenAIToAnthropicTranslator("", "")
+		_, _, _, err := o.RequestBody(nil, &openai.ChatCompletionRequest{Stream: true})
+		require.NoError(t, err)
+
+		// Feed the body in small pieces to make sure the buffering works.
+		var results []byte
+		var usage LLMTokenUsage
+		for i := 0; i < len(body); i += 7 {
+			end := min(i+7, len(body))
+			_, bm, u, err := o.ResponseBody(map[string]string{":status": "200"}, strings.NewReader(body[i:end]), end == len(body))
+			require.NoError(t, err)
+			results = append(results, bm.GetBody()...)
+			usage.InputTokens += u.InputTokens
+			usage.OutputTokens += u.OutputTokens
+			usage.TotalTokens += u.TotalTokens
+		}
+		require.Equal(t, LLMTokenUsage{InputTokens: 25, OutputTokens: 15, TotalTokens: 40}, usage)
+		require.Equal(t, `data: {"choices":[{"delta":{"content":"","role":"assistant"}}],"object":"chat.completion.chunk"}
+
+data: {"choices":[{"delta":{"content":"Hello","role":"assistant"}}],"object":"chat.completion.chunk"}
+
+data: {"choices":[{"delta":{"role":"assistant","tool_calls":[{"id":"toolu_01","function":{"arguments":"","name":"get_weather"},"type":"function"}]}}],"object":"chat.completion.chunk"}
+
+data: {"choices":[{"delta":{"role":"assistant","tool_calls":[{"id":"","function":{"arguments":"{\"city\": \"Tokyo\"}","name":""},"type":"function"}]}}],"object":"chat.completion.chunk"}
+
+data: {"choices":[{"delta":{"content":"","role":"assistant"},"finish_reason":"tool_calls"}],"object":"chat.completion.chunk"}
+
+data: {"object":"chat.completion.chunk","usage":{"completion_tokens":15,"prompt_tokens":25,"total_tokens":40}}
+
+data: [DONE]
+`, string(results))
+	})
+}
+
+func TestOpenAIToAnthropicTranslatorV1ChatCompletion_ResponseError(t *testing.T) {
+	t.Run("json error", func(t *testing.T) {
+		o := NewChatCompletionOpenAIToAnthropicTranslator("", "")
+		headers := map[string]string{":status": "529", "content-type": "application/json"}
+		body := `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`
+		hm, bm, _, err := o.ResponseBody(headers, bytes.NewBufferString(body), true)
+		require.NoError(t, err)
+		require.NotNil(t, hm)
+		var actual openai.Error
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+		require.Equal(t, openai.Error{
+			Type:  "error",
+			Error: openai.ErrorType{Type: "overloaded_error", Message: "Overloaded", Code: ptr.To("529")},
+		}, actual)
+	})
+	t.Run("non-json error", func(t *testing.T) {
+		o := NewChatCompletionOpenAIToAnthropicTranslator("", "")
+		headers := map[string]string{":status": "503", "content-type": "text/plain"}
+		hm, bm, err := o.ResponseError(headers, bytes.NewBufferString("service not available"))
+		require.NoError(t, err)
+		require.NotNil(t, hm)
+		var actual openai.Error
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+		require.Equal(t, openai.Error{
+			Type:  "error",
+			Error: openai.ErrorType{Type: anthropicBackendError, Message: "service not available", Code: ptr.To("503")},
+		}, actual)
+	})
+}
@@ -26,24 +26,34 @@ import (
 )
 
 // NewChatCompletionOpenAIToAWSBedrockTranslator implements [Factory] for OpenAI to AWS Bedrock translation.
-func NewChatCompletionOpenAIToAWSBedrockTranslator() OpenAIChatCompletionTranslator {
-	return &openAIToAWSBedrockTranslatorV1ChatCompletion{}
+//
+// When modelNameOverride is not empty, it is used as the model ID in the request path instead of the model in the request.
+func NewChatCompletionOpenAIToAWSBedrockTranslator(modelNameOverride string) OpenAIChatCompletionTranslator {
+	return &openAIToAWSBedrockTranslatorV1ChatCompletion{modelNameOverride: modelNameOverride}
 }
 
 // openAIToAWSBedrockTranslator implements [Translator] for /v1/chat/completions.
 type openAIToAWSBedrockTranslatorV1ChatCompletion struct {
-	stream       bool
-	bufferedBody []byte
-	events       []awsbedrock.ConverseStreamEvent
+	modelNameOverride string
+	stream            bool
+	bufferedBody      []byte
+	events            []awsbedrock.ConverseStreamEvent
 	// role is from MessageStartEvent in chunked messages, and used for all openai chat completion chunk choices.
 	// Translator is created for each request/response stream inside external processor, accordingly the role is not reused by multiple streams
 	role string
 }
 
 // RequestBody implements [Translator.RequestBody].
-func (o *openAIToAWSBedrockTranslatorV1ChatCompletion) RequestBody(openAIReq *openai.ChatCompletionRequest) (
+func (o *openAIToAWSBedrockTranslatorV1ChatCompletion) RequestBody(_ []byte, openAIReq *openai.ChatCompletionRequest) (
 	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error,
 ) {
+	if o.modelNameOverride != "" {
+		// Shallow copy the request not to modify the request of the caller.
+		r := *openAIReq
+		r.Model = o.modelNameOverride
+		openAIReq = &r
+	}
+
 	var pathTemplate string
 	if openAIReq.Stream {
 		o.stream = true
@@ -71,7 +81,7 @@ func (o *openAIToAWSBedrockTranslatorV1ChatCompletion) RequestBody(openAIReq *op
 	var bedrockReq awsbedrock.ConverseInput
 	// Convert InferenceConfiguration.
 	bedrockReq.InferenceConfig = &awsbedrock.InferenceConfiguration{}
-	bedrockReq.InferenceConfig.MaxTokens = openAIReq.MaxTokens
+	bedrockReq.InferenceConfig.MaxTokens = chatCompletionMaxTokens(openAIReq)
 	bedrockReq.InferenceConfig.StopSequences = openAIReq.Stop
 	bedrockReq.InferenceConfig.Temperature = openAIReq.Temperature
 	bedrockReq.InferenceConfig.TopP = openAIReq.TopP
@@ -457,6 +467,13 @@ func (o *openAIToAWSBedrockTranslatorV1ChatCompletion) bedrockToolUseToOpenAICal
 // If AWS Bedrock connection fails the error body is translated to OpenAI error type for events such as HTTP 503 or 504.
 func (o *openAIToAWSBedrockTranslatorV1ChatCompletion) ResponseError(respHeaders map[string]string, body io.Reader) (
 	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error,
+) {
+	return awsBedrockErrorToOpenAIError(respHeaders, body)
+}
+
+// awsBedrockErrorToOpenAIError translates the AWS Bedrock error body into the OpenAI error type.
+func awsBedrockErrorToOpenAIError(respHeaders map[string]string, body io.Reader) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error,
 ) {
 	statusCode := respHeaders[statusHeaderName]
 	var openaiError openai.Error
@@ -524,11 +541,7 @@ func (o *openAIToAWSBedrockTranslatorV1ChatCompletion) ResponseBody(respHeaders
 		for i := range o.events {
 			event := &o.events[i]
 			if usage := event.Usage; usage != nil {
-				tokenUsage = LLMTokenUsage{
-					InputTokens:  uint32(usage.InputTokens),  //nolint:gosec
-					OutputTokens: uint32(usage.OutputTokens), //nolint:gosec
-					TotalTokens:  uint32(usage.TotalTokens),  //nolint:gosec
-				}
+				tokenUsage = bedrockUsageToLLMTokenUsage(usage)
 			}
 			oaiEvent, ok := o.convertEvent(event)
 			if !ok {
@@ -561,16 +574,8 @@ func (o *openAIToAWSBedrockTranslatorV1ChatCompletion) ResponseBody(respHeaders
 	}
 	// Convert token usage.
 	if bedrockResp.Usage != nil {
-		tokenUsage = LLMTokenUsage{
-			InputTokens:  uint32(bedrockResp.Usage.InputTokens),  //nolint:gosec
-			OutputTokens: uint32(bedrockResp.Usage.OutputTokens), //nolint:gosec
-			TotalTokens:  uint32(bedrockResp.Usage.TotalTokens),  //nolint:gosec
-		}
-		openAIResp.Usage = openai.ChatCompletionResponseUsage{
-			TotalTokens:      bedrockResp.Usage.TotalTokens,
-			PromptTokens:     bedrockResp.Usage.InputTokens,
-			CompletionTokens: bedrockResp.Usage.OutputTokens,
-		}
+		tokenUsage = bedrockUsageToLLMTokenUsage(bedrockResp.Usage)
+		openAIResp.Usage = bedrockUsageToOpenAIUsage(bedrockResp.Usage)
 	}
 	// AWS Bedrock does not support N(multiple choices) > 0, so there could be only one choice.
 	choice := openai.ChatCompletionResponseChoice{
@@ -636,11 +641,8 @@ func (o *openAIToAWSBedrockTranslatorV1ChatCompletion) convertEvent(event *awsbe
 
 	switch {
 	case event.Usage != nil:
-		chunk.Usage = &openai.ChatCompletionResponseUsage{
-			TotalTokens:      event.Usage.TotalTokens,
-			PromptTokens:     event.Usage.InputTokens,
-			CompletionTokens: event.Usage.OutputTokens,
-		}
+		usage := bedrockUsageToOpenAIUsage(event.Usage)
+		chunk.Usage = &usage
 	case event.Role != nil:
 		chunk.Choices = append(chunk.Choices, openai.ChatCompletionResponseChunkChoice{
 			Delta: &openai.ChatCompletionResponseChunkChoiceDelta{
@@ -0,0 +1,238 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+// NewCompletionOpenAIToAWSBedrockTranslator implements [Factory] for OpenAI to AWS Bedrock translation for the legacy completions.
+//
+// The prompt is wrapped as a single user message of the Converse API. Parameters that have no equivalent in Converse,
+// such as echo, suffix and logprobs, are ignored. When modelNameOverride is not empty, it is used as the model ID
+// instead of the model in the request.
+func NewCompletionOpenAIToAWSBedrockTranslator(modelNameOverride string) OpenAICompletionTranslator {
+	return &openAIToAWSBedrockTranslatorV1Completion{
+		openAIToAWSBedrockTranslatorV1ChatCompletion: openAIToAWSBedrockTranslatorV1ChatCompletion{modelNameOverride: modelNameOverride},
+	}
+}
+
+// openAIToAWSBedrockTranslatorV1Completion implements [OpenAICompletionTranslator] for /v1/completions.
+//
+// This embeds the chat completion translator to share the handling of the Converse responses, such as the event stream
+// decoding, the stop reason conversion and the error translation.
+type openAIToAWSBedrockTranslatorV1Completion struct {
+	openAIToAWSBedrockTranslatorV1ChatCompletion
+	model string
+}
+
+// RequestBody implements [OpenAICompletionTranslator.RequestBody].
+func (o *openAIToAWSBedrockTranslatorV1Completion) RequestBody(_ []byte, openAIReq *openai.CompletionRequest) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error,
+) {
+	if o.modelNameOverride != "" {
+		// Shallow copy the request not to modify the request of the caller.
+		r := *openAIReq
+		r.Model = o.modelNameOverride
+		openAIReq = &r
+	}
+
+	var prompt string
+	switch v := openAIReq.Prompt.Value.(type) {
+	case string:
+		prompt = v
+	case []string:
+		if len(v) != 1 {
+			return nil, nil, nil, fmt.Errorf("only a single prompt is supported, but got %d", len(v))
+		}
+		prompt = v[0]
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported prompt type %T: only string or array of strings is supported", v)
+	}
+	stop, err := completionStopSequences(openAIReq.Stop)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	o.model = openAIReq.Model
+
+	var pathTemplate string
+	if openAIReq.Stream {
+		o.stream = true
+		override = &extprocv3http.ProcessingMode{
+			ResponseHeaderMode: extprocv3http.ProcessingMode_SEND,
+			ResponseBodyMode:   extprocv3http.ProcessingMode_STREAMED,
+		}
+		pathTemplate = "/model/%s/converse-stream"
+	} else {
+		pathTemplate = "/model/%s/converse"
+	}
+	headerMutation = &extprocv3.HeaderMutation{
+		SetHeaders: []*corev3.HeaderValueOption{
+			{Header: &corev3.HeaderValue{
+				Key:      ":path",
+				RawValue: []byte(fmt.Sprintf(pathTemplate, openAIReq.Model)),
+			}},
+		},
+	}
+
+	bedrockReq := awsbedrock.ConverseInput{
+		InferenceConfig: &awsbedrock.InferenceConfiguration{
+			MaxTokens:     openAIReq.MaxTokens,
+			StopSequences: stop,
+			Temperature:   openAIReq.Temperature,
+			TopP:          openAIReq.TopP,
+		},
+		Messages: []*awsbedrock.Message{
+			{
+				Role:    awsbedrock.ConversationRoleUser,
+				Content: []*awsbedrock.ContentBlock{{Text: &prompt}},
+			},
+		},
+	}
+
+	mut := &extprocv3.BodyMutation_Body{}
+	if mut.Body, err = json.Marshal(bedrockReq); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, override, nil
+}
+
+// completionStopSequences converts the stop parameter of the completions, which is either a string or an array
+// of strings, into the stop sequences of the Converse API.
+func completionStopSequences(stop any) ([]*string, error) {
+	switch v := stop.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []*string{&v}, nil
+	case []any:
+		ret := make([]*string, 0, len(v))
+		for _, s := range v {
+			str, ok := s.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid stop sequence type %T: only string is supported", s)
+			}
+			ret = append(ret, &str)
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("invalid stop type %T: only string or array of strings is supported", v)
+	}
+}
+
+// ResponseBody implements [OpenAICompletionTranslator.ResponseBody].
+func (o *openAIToAWSBedrockTranslatorV1Completion) ResponseBody(respHeaders map[string]string, body io.Reader, endOfStream bool) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage LLMTokenUsage, err error,
+) {
+	if statusStr, ok := respHeaders[statusHeaderName]; ok {
+		var status int
+		if status, err = strconv.Atoi(statusStr); err == nil {
+			if !isGoodStatusCode(status) {
+				headerMutation, bodyMutation, err = awsBedrockErrorToOpenAIError(respHeaders, body)
+				return headerMutation, bodyMutation, LLMTokenUsage{}, err
+			}
+		}
+	}
+	mut := &extprocv3.BodyMutation_Body{}
+	if o.stream {
+		var buf []byte
+		buf, err = io.ReadAll(body)
+		if err != nil {
+			return nil, nil, tokenUsage, fmt.Errorf("failed to read body: %w", err)
+		}
+		o.bufferedBody = append(o.bufferedBody, buf...)
+		o.extractAmazonEventStreamEvents()
+
+		for i := range o.events {
+			event := &o.events[i]
+			if usage := event.Usage; usage != nil {
+				tokenUsage = bedrockUsageToLLMTokenUsage(usage)
+			}
+			chunk, ok := o.convertCompletionEvent(event)
+			if !ok {
+				continue
+			}
+			var chunkBytes []byte
+			chunkBytes, err = json.Marshal(chunk)
+			if err != nil {
+				return nil, nil, tokenUsage, fmt.Errorf("failed to marshal event: %w", err)
+			}
+			mut.Body = append(mut.Body, dataPrefix...)
+			mut.Body = append(mut.Body, chunkBytes...)
+			mut.Body = append(mut.Body, []byte("\n\n")...)
+		}
+
+		if endOfStream {
+			mut.Body = append(mut.Body, []byte("data: [DONE]\n")...)
+		}
+		return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, tokenUsage, nil
+	}
+
+	var bedrockResp awsbedrock.ConverseResponse
+	if err = json.NewDecoder(body).Decode(&bedrockResp); err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+
+	openAIResp := openai.CompletionResponse{Object: "text_completion", Model: o.model}
+	if bedrockResp.Usage != nil {
+		tokenUsage = bedrockUsageToLLMTokenUsage(bedrockResp.Usage)
+		usage := bedrockUsageToOpenAIUsage(bedrockResp.Usage)
+		openAIResp.Usage = &usage
+	}
+	var text strings.Builder
+	if bedrockResp.Output != nil {
+		for _, output := range bedrockResp.Output.Message.Content {
+			if output.Text != nil {
+				text.WriteString(*output.Text)
+			}
+		}
+	}
+	openAIResp.Choices = []openai.CompletionResponseChoice{{
+		Text:         text.String(),
+		FinishReason: o.bedrockStopReasonToOpenAIStopReason(bedrockResp.StopReason),
+	}}
+
+	mut.Body, err = json.Marshal(openAIResp)
+	if err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, tokenUsage, nil
+}
+
+// convertCompletionEvent converts an [awsbedrock.ConverseStreamEvent] to a streamed [openai.CompletionResponse].
+// This returns false for the events that have no counterpart in the completions, such as the message start.
+func (o *openAIToAWSBedrockTranslatorV1Completion) convertCompletionEvent(event *awsbedrock.ConverseStreamEvent) (openai.CompletionResponse, bool) {
+	chunk := openai.CompletionResponse{Object: "text_completion", Model: o.model}
+	switch {
+	case event.Usage != nil:
+		chunk.Choices = []openai.CompletionResponseChoice{}
+		usage := bedrockUsageToOpenAIUsage(event.Usage)
+		chunk.Usage = &usage
+	case event.Delta != nil && event.Delta.Text != nil:
+		chunk.Choices = []openai.CompletionResponseChoice{{Text: *event.Delta.Text}}
+	case event.StopReason != nil:
+		chunk.Choices = []openai.CompletionResponseChoice{{
+			FinishReason: o.bedrockStopReasonToOpenAIStopReason(event.StopReason),
+		}}
+	default:
+		return chunk, false
+	}
+	return chunk, true
+}
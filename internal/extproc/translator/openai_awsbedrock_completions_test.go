@@ -0,0 +1,183 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func TestOpenAIToAWSBedrockTranslatorV1Completion_RequestBody(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		input       openai.CompletionRequest
+		expPath     string
+		expBody     string
+		expOverride bool
+		expErr      string
+	}{
+		{
+			name: "string prompt",
+			input: openai.CompletionRequest{
+				Model:       "anthropic.claude-v2",
+				Prompt:      openai.CompletionRequestPrompt{Value: "Say this is a test"},
+				MaxTokens:   ptr.To(int64(16)),
+				Temperature: ptr.To(0.5),
+				Stop:        "\n",
+			},
+			expPath: "/model/anthropic.claude-v2/converse",
+			expBody: `{"inferenceConfig":{"maxTokens":16,"stopSequences":["\n"],"temperature":0.5},` +
+				`"messages":[{"content":[{"text":"Say this is a test"}],"role":"user"}],"modelId":null}`,
+		},
+		{
+			name: "single element array prompt streaming",
+			input: openai.CompletionRequest{
+				Model:  "anthropic.claude-v2",
+				Prompt: openai.CompletionRequestPrompt{Value: []string{"hi"}},
+				Stop:   []any{"a", "b"},
+				Stream: true,
+			},
+			expPath:     "/model/anthropic.claude-v2/converse-stream",
+			expBody:     `{"inferenceConfig":{"stopSequences":["a","b"]},"messages":[{"content":[{"text":"hi"}],"role":"user"}],"modelId":null}`,
+			expOverride: true,
+		},
+		{
+			name: "multiple prompts",
+			input: openai.CompletionRequest{
+				Model:  "anthropic.claude-v2",
+				Prompt: openai.CompletionRequestPrompt{Value: []string{"a", "b"}},
+			},
+			expErr: "only a single prompt is supported, but got 2",
+		},
+		{
+			name: "token prompt",
+			input: openai.CompletionRequest{
+				Model:  "anthropic.claude-v2",
+				Prompt: openai.CompletionRequestPrompt{Value: []int64{1, 2}},
+			},
+			expErr: "unsupported prompt type []int64",
+		},
+		{
+			name: "invalid stop",
+			input: openai.CompletionRequest{
+				Model:  "anthropic.claude-v2",
+				Prompt: openai.CompletionRequestPrompt{Value: "a"},
+				Stop:   []any{1.0},
+			},
+			expErr: "invalid stop sequence type float64",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &openAIToAWSBedrockTranslatorV1Completion{}
+			hm, bm, override, err := o.RequestBody(nil, &tc.input)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, ":path", hm.SetHeaders[0].Header.Key)
+			require.Equal(t, tc.expPath, string(hm.SetHeaders[0].Header.RawValue))
+			require.JSONEq(t, tc.expBody, string(bm.GetBody()))
+			if tc.expOverride {
+				require.Equal(t, extprocv3http.ProcessingMode_STREAMED, override.ResponseBodyMode)
+				require.True(t, o.stream)
+			} else {
+				require.Nil(t, override)
+			}
+		})
+	}
+}
+
+func TestOpenAIToAWSBedrockTranslatorV1Completion_ResponseBody(t *testing.T) {
+	t.Run("buffered", func(t *testing.T) {
+		o := &openAIToAWSBedrockTranslatorV1Completion{model: "anthropic.claude-v2"}
+		body, err := json.Marshal(awsbedrock.ConverseResponse{
+			Output: &awsbedrock.ConverseOutput{Message: awsbedrock.Message{
+				Role:    awsbedrock.ConversationRoleAssistant,
+				Content: []*awsbedrock.ContentBlock{{Text: ptr.To("This is")}, {Text: ptr.To(" a test.")}},
+			}},
+			StopReason: ptr.To(awsbedrock.StopReasonMaxTokens),
+			Usage:      &awsbedrock.TokenUsage{InputTokens: 5, OutputTokens: 4, TotalTokens: 9},
+		})
+		require.NoError(t, err)
+		hm, bm, usage, err := o.ResponseBody(map[string]string{statusHeaderName: "200"}, bytes.NewReader(body), true)
+		require.NoError(t, err)
+		require.NotNil(t, hm)
+		require.Equal(t, LLMTokenUsage{InputTokens: 5, OutputTokens: 4, TotalTokens: 9}, usage)
+		require.JSONEq(t, `{"object":"text_completion","model":"anthropic.claude-v2",`+
+			`"choices":[{"text":"This is a test.","index":0,"finish_reason":"length"}],`+
+			`"usage":{"completion_tokens":4,"prompt_tokens":5,"total_tokens":9}}`, string(bm.GetBody()))
+	})
+	t.Run("streaming", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		e := eventstream.NewEncoder()
+		for _, data := range []awsbedrock.ConverseStreamEvent{
+			{Role: ptr.To(awsbedrock.ConversationRoleAssistant)},
+			{Delta: &awsbedrock.ConverseStreamEventContentBlockDelta{Text: ptr.To("This")}},
+			{Delta: &awsbedrock.ConverseStreamEventContentBlockDelta{Text: ptr.To(" is")}},
+			{StopReason: ptr.To(awsbedrock.StopReasonEndTurn)},
+			{Usage: &awsbedrock.TokenUsage{InputTokens: 5, OutputTokens: 2, TotalTokens: 7}},
+		} {
+			payload, err := json.Marshal(data)
+			require.NoError(t, err)
+			require.NoError(t, e.Encode(buf, eventstream.Message{
+				Headers: eventstream.Headers{{Name: "event-type", Value: eventstream.StringValue("content")}},
+				Payload: payload,
+			}))
+		}
+
+		o := &openAIToAWSBedrockTranslatorV1Completion{model: "m"}
+		o.stream = true
+		raw := buf.Bytes()
+		var results []string
+		var total LLMTokenUsage
+		for i := 0; i < len(raw); i++ {
+			_, bm, usage, err := o.ResponseBody(nil, bytes.NewReader(raw[i:i+1]), i == len(raw)-1)
+			require.NoError(t, err)
+			if body := bm.GetBody(); len(body) > 0 {
+				results = append(results, string(body))
+			}
+			total.InputTokens += usage.InputTokens
+			total.OutputTokens += usage.OutputTokens
+			total.TotalTokens += usage.TotalTokens
+		}
+		require.Equal(t, LLMTokenUsage{InputTokens: 5, OutputTokens: 2, TotalTokens: 7}, total)
+		require.Equal(t, `data: {"object":"text_completion","model":"m","choices":[{"text":"This","index":0}]}
+
+data: {"object":"text_completion","model":"m","choices":[{"text":" is","index":0}]}
+
+data: {"object":"text_completion","model":"m","choices":[{"text":"","index":0,"finish_reason":"stop"}]}
+
+data: {"object":"text_completion","model":"m","choices":[],"usage":{"completion_tokens":2,"prompt_tokens":5,"total_tokens":7}}
+
+data: [DONE]
+`, strings.Join(results, ""))
+	})
+	t.Run("error", func(t *testing.T) {
+		o := &openAIToAWSBedrockTranslatorV1Completion{}
+		_, bm, usage, err := o.ResponseBody(map[string]string{
+			statusHeaderName:       "429",
+			contentTypeHeaderName:  jsonContentType,
+			awsErrorTypeHeaderName: "ThrottlingException",
+		}, strings.NewReader(`{"message":"too many requests"}`), true)
+		require.NoError(t, err)
+		require.Equal(t, LLMTokenUsage{}, usage)
+		var openAIError openai.Error
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &openAIError))
+		require.Equal(t, "ThrottlingException", openAIError.Error.Type)
+		require.Equal(t, "too many requests", openAIError.Error.Message)
+	})
+}
@@ -0,0 +1,172 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+const (
+	// awsBedrockInputTokenCountHeaderName is the response header of InvokeModel that has the number of input tokens.
+	awsBedrockInputTokenCountHeaderName = "x-amzn-bedrock-input-token-count"
+	// cohereDefaultInputType is used since the input_type is required by Cohere Embed v3 models, and OpenAI has no equivalent.
+	cohereDefaultInputType = "search_document"
+)
+
+// NewEmbeddingOpenAIToAWSBedrockTranslator implements [Factory] for OpenAI to AWS Bedrock translation for embeddings.
+//
+// This uses the InvokeModel API, and supports the Amazon Titan Text Embeddings and Cohere Embed models.
+// When modelNameOverride is not empty, it is used as the model ID instead of the model in the request.
+func NewEmbeddingOpenAIToAWSBedrockTranslator(modelNameOverride string) OpenAIEmbeddingTranslator {
+	return &openAIToAWSBedrockTranslatorV1Embedding{modelNameOverride: modelNameOverride}
+}
+
+// openAIToAWSBedrockTranslatorV1Embedding implements [OpenAIEmbeddingTranslator] for /v1/embeddings.
+type openAIToAWSBedrockTranslatorV1Embedding struct {
+	modelNameOverride string
+	model             string
+	cohere            bool
+	base64            bool
+}
+
+// RequestBody implements [OpenAIEmbeddingTranslator.RequestBody].
+func (o *openAIToAWSBedrockTranslatorV1Embedding) RequestBody(_ []byte, openAIReq *openai.EmbeddingRequest) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error,
+) {
+	if o.modelNameOverride != "" {
+		// Shallow copy the request not to modify the request of the caller.
+		r := *openAIReq
+		r.Model = o.modelNameOverride
+		openAIReq = &r
+	}
+	o.model = openAIReq.Model
+	o.base64 = openAIReq.EncodingFormat != nil && *openAIReq.EncodingFormat == "base64"
+
+	var texts []string
+	switch v := openAIReq.Input.Value.(type) {
+	case string:
+		texts = []string{v}
+	case []string:
+		texts = v
+	default:
+		return nil, nil, fmt.Errorf("unsupported input type %T: only string or array of strings is supported", v)
+	}
+
+	var reqBody any
+	switch {
+	case strings.Contains(openAIReq.Model, "titan-embed"):
+		if len(texts) != 1 {
+			return nil, nil, fmt.Errorf("titan embedding models accept only a single input, but got %d", len(texts))
+		}
+		reqBody = awsbedrock.TitanEmbeddingRequest{InputText: texts[0], Dimensions: openAIReq.Dimensions}
+	case strings.Contains(openAIReq.Model, "cohere.embed"):
+		if openAIReq.Dimensions != nil {
+			return nil, nil, fmt.Errorf("dimensions is not supported by cohere embedding models")
+		}
+		o.cohere = true
+		reqBody = awsbedrock.CohereEmbeddingRequest{Texts: texts, InputType: cohereDefaultInputType}
+	default:
+		return nil, nil, fmt.Errorf("unsupported embedding model: %s", openAIReq.Model)
+	}
+
+	mut := &extprocv3.BodyMutation_Body{}
+	if mut.Body, err = json.Marshal(reqBody); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{
+		SetHeaders: []*corev3.HeaderValueOption{
+			{Header: &corev3.HeaderValue{
+				Key:      ":path",
+				RawValue: []byte(fmt.Sprintf("/model/%s/invoke", openAIReq.Model)),
+			}},
+		},
+	}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, nil
+}
+
+// ResponseBody implements [OpenAIEmbeddingTranslator.ResponseBody].
+func (o *openAIToAWSBedrockTranslatorV1Embedding) ResponseBody(respHeaders map[string]string, body io.Reader) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage LLMTokenUsage, err error,
+) {
+	if statusStr, ok := respHeaders[statusHeaderName]; ok {
+		var status int
+		if status, err = strconv.Atoi(statusStr); err == nil {
+			if !isGoodStatusCode(status) {
+				headerMutation, bodyMutation, err = awsBedrockErrorToOpenAIError(respHeaders, body)
+				return headerMutation, bodyMutation, LLMTokenUsage{}, err
+			}
+		}
+	}
+
+	var embeddings [][]float64
+	var inputTokens int
+	if o.cohere {
+		var resp awsbedrock.CohereEmbeddingResponse
+		if err = json.NewDecoder(body).Decode(&resp); err != nil {
+			return nil, nil, tokenUsage, fmt.Errorf("failed to unmarshal body: %w", err)
+		}
+		embeddings = resp.Embeddings
+		// Cohere doesn't return the token count in the body, but InvokeModel returns it in the header.
+		inputTokens, _ = strconv.Atoi(respHeaders[awsBedrockInputTokenCountHeaderName])
+	} else {
+		var resp awsbedrock.TitanEmbeddingResponse
+		if err = json.NewDecoder(body).Decode(&resp); err != nil {
+			return nil, nil, tokenUsage, fmt.Errorf("failed to unmarshal body: %w", err)
+		}
+		embeddings = [][]float64{resp.Embedding}
+		inputTokens = resp.InputTextTokenCount
+	}
+
+	openAIResp := openai.EmbeddingResponse{
+		Object: "list",
+		Model:  o.model,
+		Data:   make([]openai.Embedding, 0, len(embeddings)),
+		Usage:  openai.EmbeddingUsage{PromptTokens: inputTokens, TotalTokens: inputTokens},
+	}
+	for i, e := range embeddings {
+		data := openai.Embedding{Object: "embedding", Index: i, Embedding: e}
+		if o.base64 {
+			data.Embedding = embeddingToBase64(e)
+		}
+		openAIResp.Data = append(openAIResp.Data, data)
+	}
+	tokenUsage = LLMTokenUsage{
+		InputTokens: uint32(inputTokens), //nolint:gosec
+		TotalTokens: uint32(inputTokens), //nolint:gosec
+	}
+
+	mut := &extprocv3.BodyMutation_Body{}
+	if mut.Body, err = json.Marshal(openAIResp); err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, tokenUsage, nil
+}
+
+// embeddingToBase64 encodes the embedding in the same way as OpenAI does for the "base64" encoding format,
+// which is the base64 encoding of the little-endian float32 array.
+func embeddingToBase64(embedding []float64) string {
+	buf := make([]byte, 4*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(v)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
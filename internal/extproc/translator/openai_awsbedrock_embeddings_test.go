@@ -0,0 +1,170 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func TestOpenAIToAWSBedrockTranslatorV1EmbeddingRequestBody(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		input   openai.EmbeddingRequest
+		expPath string
+		expBody string
+		expErr  string
+	}{
+		{
+			name: "titan",
+			input: openai.EmbeddingRequest{
+				Model:      "amazon.titan-embed-text-v2:0",
+				Input:      openai.EmbeddingRequestInput{Value: "hello"},
+				Dimensions: ptr.To(256),
+			},
+			expPath: "/model/amazon.titan-embed-text-v2:0/invoke",
+			expBody: `{"inputText":"hello","dimensions":256}`,
+		},
+		{
+			name: "titan multiple inputs",
+			input: openai.EmbeddingRequest{
+				Model: "amazon.titan-embed-text-v2:0",
+				Input: openai.EmbeddingRequestInput{Value: []string{"a", "b"}},
+			},
+			expErr: "titan embedding models accept only a single input, but got 2",
+		},
+		{
+			name: "cohere",
+			input: openai.EmbeddingRequest{
+				Model: "cohere.embed-english-v3",
+				Input: openai.EmbeddingRequestInput{Value: []string{"a", "b"}},
+			},
+			expPath: "/model/cohere.embed-english-v3/invoke",
+			expBody: `{"texts":["a","b"],"input_type":"search_document"}`,
+		},
+		{
+			name: "cohere dimensions",
+			input: openai.EmbeddingRequest{
+				Model:      "cohere.embed-english-v3",
+				Input:      openai.EmbeddingRequestInput{Value: "a"},
+				Dimensions: ptr.To(256),
+			},
+			expErr: "dimensions is not supported by cohere embedding models",
+		},
+		{
+			name: "tokens input",
+			input: openai.EmbeddingRequest{
+				Model: "amazon.titan-embed-text-v2:0",
+				Input: openai.EmbeddingRequestInput{Value: []int64{1, 2, 3}},
+			},
+			expErr: "unsupported input type []int64",
+		},
+		{
+			name: "unsupported model",
+			input: openai.EmbeddingRequest{
+				Model: "some-model",
+				Input: openai.EmbeddingRequestInput{Value: "a"},
+			},
+			expErr: "unsupported embedding model: some-model",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &openAIToAWSBedrockTranslatorV1Embedding{}
+			hm, bm, err := o.RequestBody(nil, &tc.input)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, hm.SetHeaders, 2)
+			require.Equal(t, ":path", hm.SetHeaders[0].Header.Key)
+			require.Equal(t, tc.expPath, string(hm.SetHeaders[0].Header.RawValue))
+			require.Equal(t, "content-length", hm.SetHeaders[1].Header.Key)
+			require.JSONEq(t, tc.expBody, string(bm.GetBody()))
+		})
+	}
+}
+
+func TestOpenAIToAWSBedrockTranslatorV1EmbeddingResponseBody(t *testing.T) {
+	t.Run("titan", func(t *testing.T) {
+		o := &openAIToAWSBedrockTranslatorV1Embedding{model: "amazon.titan-embed-text-v2:0"}
+		body, err := json.Marshal(awsbedrock.TitanEmbeddingResponse{Embedding: []float64{0.1, 0.2}, InputTextTokenCount: 5})
+		require.NoError(t, err)
+		hm, bm, usage, err := o.ResponseBody(map[string]string{statusHeaderName: "200"}, bytes.NewReader(body))
+		require.NoError(t, err)
+		require.NotNil(t, hm)
+		require.Equal(t, LLMTokenUsage{InputTokens: 5, TotalTokens: 5}, usage)
+
+		var resp openai.EmbeddingResponse
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &resp))
+		require.Equal(t, "list", resp.Object)
+		require.Equal(t, "amazon.titan-embed-text-v2:0", resp.Model)
+		require.Len(t, resp.Data, 1)
+		require.Equal(t, []interface{}{0.1, 0.2}, resp.Data[0].Embedding)
+		require.Equal(t, openai.EmbeddingUsage{PromptTokens: 5, TotalTokens: 5}, resp.Usage)
+	})
+	t.Run("cohere", func(t *testing.T) {
+		o := &openAIToAWSBedrockTranslatorV1Embedding{model: "cohere.embed-english-v3", cohere: true}
+		body, err := json.Marshal(awsbedrock.CohereEmbeddingResponse{Embeddings: [][]float64{{0.1}, {0.2}}})
+		require.NoError(t, err)
+		_, bm, usage, err := o.ResponseBody(map[string]string{awsBedrockInputTokenCountHeaderName: "7"}, bytes.NewReader(body))
+		require.NoError(t, err)
+		require.Equal(t, LLMTokenUsage{InputTokens: 7, TotalTokens: 7}, usage)
+
+		var resp openai.EmbeddingResponse
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &resp))
+		require.Len(t, resp.Data, 2)
+		require.Equal(t, 1, resp.Data[1].Index)
+		require.Equal(t, []interface{}{0.2}, resp.Data[1].Embedding)
+	})
+	t.Run("base64", func(t *testing.T) {
+		o := &openAIToAWSBedrockTranslatorV1Embedding{model: "amazon.titan-embed-text-v2:0", base64: true}
+		body, err := json.Marshal(awsbedrock.TitanEmbeddingResponse{Embedding: []float64{0.5, -1}})
+		require.NoError(t, err)
+		_, bm, _, err := o.ResponseBody(nil, bytes.NewReader(body))
+		require.NoError(t, err)
+
+		var resp openai.EmbeddingResponse
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &resp))
+		encoded, ok := resp.Data[0].Embedding.(string)
+		require.True(t, ok)
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		require.NoError(t, err)
+		require.Len(t, raw, 8)
+		require.Equal(t, float32(0.5), math.Float32frombits(binary.LittleEndian.Uint32(raw[0:])))
+		require.Equal(t, float32(-1), math.Float32frombits(binary.LittleEndian.Uint32(raw[4:])))
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		o := &openAIToAWSBedrockTranslatorV1Embedding{}
+		_, _, _, err := o.ResponseBody(nil, strings.NewReader("invalid"))
+		require.ErrorContains(t, err, "failed to unmarshal body")
+	})
+	t.Run("error status", func(t *testing.T) {
+		o := &openAIToAWSBedrockTranslatorV1Embedding{}
+		_, bm, usage, err := o.ResponseBody(map[string]string{
+			statusHeaderName:       "400",
+			contentTypeHeaderName:  jsonContentType,
+			awsErrorTypeHeaderName: "ValidationException",
+		}, strings.NewReader(`{"message":"bad input"}`))
+		require.NoError(t, err)
+		require.Equal(t, LLMTokenUsage{}, usage)
+		var openAIError openai.Error
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &openAIError))
+		require.Equal(t, "ValidationException", openAIError.Error.Type)
+		require.Equal(t, "bad input", openAIError.Error.Message)
+	})
+}
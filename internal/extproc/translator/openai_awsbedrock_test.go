@@ -660,7 +660,7 @@ func TestOpenAIToAWSBedrockTranslatorV1ChatCompletion_RequestBody(t *testing.T)
 		t.Run(tt.name, func(t *testing.T) {
 			o := &openAIToAWSBedrockTranslatorV1ChatCompletion{}
 			originalReq := tt.input
-			hm, bm, mode, err := o.RequestBody(&originalReq)
+			hm, bm, mode, err := o.RequestBody(nil, &originalReq)
 			var expPath string
 			if tt.input.Stream {
 				expPath = fmt.Sprintf("/model/%s/converse-stream", tt.input.Model)
@@ -691,6 +691,19 @@ func TestOpenAIToAWSBedrockTranslatorV1ChatCompletion_RequestBody(t *testing.T)
 			}
 		})
 	}
+	t.Run("model name override", func(t *testing.T) {
+		o := NewChatCompletionOpenAIToAWSBedrockTranslator("anthropic.claude-3-5-sonnet-20241022-v2:0")
+		originalReq := &openai.ChatCompletionRequest{
+			Model:    "claude-sonnet",
+			Stream:   true,
+			Messages: []openai.ChatCompletionMessageParamUnion{{Type: openai.ChatMessageRoleUser, Value: openai.ChatCompletionUserMessageParam{Role: openai.ChatMessageRoleUser, Content: openai.StringOrUserRoleContentUnion{Value: "hi"}}}},
+		}
+		hm, _, _, err := o.RequestBody(nil, originalReq)
+		require.NoError(t, err)
+		require.Equal(t, ":path", hm.SetHeaders[0].Header.Key)
+		require.Equal(t, "/model/anthropic.claude-3-5-sonnet-20241022-v2:0/converse-stream", string(hm.SetHeaders[0].Header.RawValue))
+		require.Equal(t, "claude-sonnet", originalReq.Model)
+	})
 }
 
 func TestOpenAIToAWSBedrockTranslatorV1ChatCompletion_ResponseHeaders(t *testing.T) {
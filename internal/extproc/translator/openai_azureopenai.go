@@ -0,0 +1,84 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"fmt"
+	"net/url"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+// azureOpenAIDefaultAPIVersion is the api-version query parameter used when the schema version is not specified.
+//
+// https://learn.microsoft.com/en-us/azure/ai-services/openai/reference#api-specs
+const azureOpenAIDefaultAPIVersion = "2024-10-21"
+
+// NewChatCompletionOpenAIToAzureOpenAITranslator implements [Factory] for OpenAI to Azure OpenAI translation.
+//
+// apiVersion is used as the api-version query parameter, and deployments maps the model name in the request
+// to the Azure OpenAI deployment name. When the model is not found in deployments, the model name is used as
+// the deployment name as-is. When modelNameOverride is not empty, it is used as the model name both in the request
+// body and for the deployment lookup. forceStreamUsage is the same as [NewChatCompletionOpenAIToOpenAITranslator].
+func NewChatCompletionOpenAIToAzureOpenAITranslator(apiVersion string, deployments map[string]string, modelNameOverride string, forceStreamUsage bool) OpenAIChatCompletionTranslator {
+	if apiVersion == "" {
+		apiVersion = azureOpenAIDefaultAPIVersion
+	}
+	return &openAIToAzureOpenAITranslatorV1ChatCompletion{
+		openAIToOpenAITranslatorV1ChatCompletion: openAIToOpenAITranslatorV1ChatCompletion{
+			modelNameOverride: modelNameOverride, forceStreamUsage: forceStreamUsage,
+		},
+		apiVersion:  apiVersion,
+		deployments: deployments,
+	}
+}
+
+// openAIToAzureOpenAITranslatorV1ChatCompletion implements [Translator] for /v1/chat/completions.
+//
+// Since Azure OpenAI speaks the OpenAI schema, only the request path is rewritten and the rest
+// is delegated to the OpenAI to OpenAI translator.
+type openAIToAzureOpenAITranslatorV1ChatCompletion struct {
+	openAIToOpenAITranslatorV1ChatCompletion
+	apiVersion  string
+	deployments map[string]string
+}
+
+// RequestBody implements [Translator.RequestBody].
+func (o *openAIToAzureOpenAITranslatorV1ChatCompletion) RequestBody(raw []byte, req *openai.ChatCompletionRequest) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error,
+) {
+	model := req.Model
+	if o.modelNameOverride != "" {
+		model = o.modelNameOverride
+	}
+	deployment, ok := o.deployments[model]
+	if !ok {
+		deployment = model
+	}
+	if deployment == "" {
+		return nil, nil, nil, fmt.Errorf("model name is required to determine the Azure OpenAI deployment")
+	}
+
+	headerMutation, bodyMutation, override, err = o.openAIToOpenAITranslatorV1ChatCompletion.RequestBody(raw, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if headerMutation == nil {
+		headerMutation = &extprocv3.HeaderMutation{}
+	}
+	pathTemplate := "/openai/deployments/%s/chat/completions?api-version=%s"
+	headerMutation.SetHeaders = append(headerMutation.SetHeaders, &corev3.HeaderValueOption{
+		Header: &corev3.HeaderValue{
+			Key:      ":path",
+			RawValue: []byte(fmt.Sprintf(pathTemplate, url.PathEscape(deployment), url.QueryEscape(o.apiVersion))),
+		},
+	})
+	return headerMutation, bodyMutation, override, nil
+}
@@ -0,0 +1,103 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func TestOpenAIToAzureOpenAITranslatorV1ChatCompletion_RequestBody(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		apiVersion  string
+		deployments map[string]string
+		model       string
+		expPath     string
+	}{
+		{
+			name:    "model as deployment",
+			model:   "gpt-4o",
+			expPath: "/openai/deployments/gpt-4o/chat/completions?api-version=2024-10-21",
+		},
+		{
+			name:        "deployment map",
+			apiVersion:  "2025-01-01-preview",
+			deployments: map[string]string{"gpt-4o": "my-gpt4o-deployment"},
+			model:       "gpt-4o",
+			expPath:     "/openai/deployments/my-gpt4o-deployment/chat/completions?api-version=2025-01-01-preview",
+		},
+		{
+			name:        "not in deployment map",
+			apiVersion:  "2024-06-01",
+			deployments: map[string]string{"gpt-4o": "my-gpt4o-deployment"},
+			model:       "gpt 35/turbo",
+			expPath:     "/openai/deployments/gpt%2035%2Fturbo/chat/completions?api-version=2024-06-01",
+		},
+	} {
+		for _, stream := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s/stream=%t", tc.name, stream), func(t *testing.T) {
+				o := NewChatCompletionOpenAIToAzureOpenAITranslator(tc.apiVersion, tc.deployments, "", false)
+				hm, bm, mode, err := o.RequestBody(nil, &openai.ChatCompletionRequest{Model: tc.model, Stream: stream})
+				require.NoError(t, err)
+				require.Nil(t, bm)
+				require.NotNil(t, hm)
+				require.Len(t, hm.SetHeaders, 1)
+				require.Equal(t, ":path", hm.SetHeaders[0].Header.Key)
+				require.Equal(t, tc.expPath, string(hm.SetHeaders[0].Header.RawValue))
+				if stream {
+					require.NotNil(t, mode)
+					require.Equal(t, extprocv3http.ProcessingMode_STREAMED, mode.ResponseBodyMode)
+				} else {
+					require.Nil(t, mode)
+				}
+			})
+		}
+	}
+	t.Run("model name override", func(t *testing.T) {
+		o := NewChatCompletionOpenAIToAzureOpenAITranslator("", map[string]string{"gpt-4o": "my-gpt4o-deployment"}, "gpt-4o", false)
+		hm, bm, _, err := o.RequestBody([]byte(`{"model":"my-alias"}`), &openai.ChatCompletionRequest{Model: "my-alias"})
+		require.NoError(t, err)
+		require.Len(t, hm.SetHeaders, 2)
+		require.Equal(t, "content-length", hm.SetHeaders[0].Header.Key)
+		require.Equal(t, ":path", hm.SetHeaders[1].Header.Key)
+		require.Equal(t, "/openai/deployments/my-gpt4o-deployment/chat/completions?api-version=2024-10-21", string(hm.SetHeaders[1].Header.RawValue))
+		var req openai.ChatCompletionRequest
+		require.NoError(t, json.Unmarshal(bm.Mutation.(*extprocv3.BodyMutation_Body).Body, &req))
+		require.Equal(t, "gpt-4o", req.Model)
+	})
+	t.Run("missing model", func(t *testing.T) {
+		o := NewChatCompletionOpenAIToAzureOpenAITranslator("", nil, "", false)
+		_, _, _, err := o.RequestBody(nil, &openai.ChatCompletionRequest{})
+		require.ErrorContains(t, err, "model name is required")
+	})
+}
+
+func TestOpenAIToAzureOpenAITranslatorV1ChatCompletion_ResponseBody(t *testing.T) {
+	o := NewChatCompletionOpenAIToAzureOpenAITranslator("", nil, "", false)
+	_, _, _, err := o.RequestBody(nil, &openai.ChatCompletionRequest{Model: "gpt-4o", Stream: true})
+	require.NoError(t, err)
+
+	body := `data: {"choices":[{"delta":{"content":"hi"}}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}
+
+data: [DONE]
+`
+	hm, bm, usage, err := o.ResponseBody(map[string]string{":status": "200"}, strings.NewReader(body), true)
+	require.NoError(t, err)
+	require.Nil(t, hm)
+	require.Nil(t, bm)
+	require.Equal(t, LLMTokenUsage{InputTokens: 5, OutputTokens: 3, TotalTokens: 8}, usage)
+}
@@ -0,0 +1,616 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/gcpvertexai"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+const (
+	// gcpVertexAIDefaultAPIVersion is the API version in the request path used when the schema version is not specified.
+	gcpVertexAIDefaultAPIVersion = "v1"
+	gcpVertexAIBackendError      = "GCPVertexAIBackendError"
+)
+
+// NewChatCompletionOpenAIToGCPVertexAITranslator implements [Factory] for OpenAI to GCP Vertex AI Gemini translation.
+//
+// The apiVersion is the version prefix of the request path, e.g. "v1" or "v1beta1". The model name in the request
+// is either a publisher model name such as "gemini-2.0-flash", or a full resource name starting with "projects/".
+// When modelNameOverride is not empty, it is used as the model name instead of the model in the request.
+func NewChatCompletionOpenAIToGCPVertexAITranslator(apiVersion, modelNameOverride string) OpenAIChatCompletionTranslator {
+	if apiVersion == "" {
+		apiVersion = gcpVertexAIDefaultAPIVersion
+	}
+	return &openAIToGCPVertexAITranslatorV1ChatCompletion{apiVersion: apiVersion, modelNameOverride: modelNameOverride}
+}
+
+// openAIToGCPVertexAITranslatorV1ChatCompletion implements [Translator] for /v1/chat/completions.
+type openAIToGCPVertexAITranslatorV1ChatCompletion struct {
+	apiVersion        string
+	modelNameOverride string
+	stream            bool
+	bufferedBody      []byte
+	// usage is the latest usageMetadata in the stream. Gemini reports the cumulative counts in every event,
+	// so this is only reported at the end of the stream.
+	usage *gcpvertexai.UsageMetadata
+	// toolCallCount is used to generate the tool call IDs since Gemini doesn't assign IDs to function calls.
+	toolCallCount int
+}
+
+// RequestBody implements [Translator.RequestBody].
+func (o *openAIToGCPVertexAITranslatorV1ChatCompletion) RequestBody(_ []byte, openAIReq *openai.ChatCompletionRequest) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error,
+) {
+	model := openAIReq.Model
+	if o.modelNameOverride != "" {
+		model = o.modelNameOverride
+	}
+	if !strings.HasPrefix(model, "projects/") {
+		model = "publishers/google/models/" + url.PathEscape(model)
+	}
+	var p string
+	if openAIReq.Stream {
+		o.stream = true
+		// We need to change the processing mode for streaming requests.
+		// TODO: We can delete this explicit setting of ResponseHeaderMode below as it is the default value we use
+		// 	after https://github.com/envoyproxy/envoy/pull/38254 this is released.
+		override = &extprocv3http.ProcessingMode{
+			ResponseHeaderMode: extprocv3http.ProcessingMode_SEND,
+			ResponseBodyMode:   extprocv3http.ProcessingMode_STREAMED,
+		}
+		p = fmt.Sprintf("/%s/%s:streamGenerateContent?alt=sse", o.apiVersion, model)
+	} else {
+		p = fmt.Sprintf("/%s/%s:generateContent", o.apiVersion, model)
+	}
+
+	geminiReq := gcpvertexai.GenerateContentRequest{}
+	if err = o.openAIMessagesToGeminiContents(openAIReq, &geminiReq); err != nil {
+		return nil, nil, nil, err
+	}
+	if err = o.openAIToolsToGeminiTools(openAIReq, &geminiReq); err != nil {
+		return nil, nil, nil, err
+	}
+	geminiReq.GenerationConfig = openAIRequestToGeminiGenerationConfig(openAIReq)
+
+	mut := &extprocv3.BodyMutation_Body{}
+	if mut.Body, err = json.Marshal(geminiReq); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{
+		SetHeaders: []*corev3.HeaderValueOption{
+			{Header: &corev3.HeaderValue{Key: ":path", RawValue: []byte(p)}},
+		},
+	}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, override, nil
+}
+
+// openAIRequestToGeminiGenerationConfig converts the sampling parameters of the openai request. This returns nil
+// when none of them is set.
+func openAIRequestToGeminiGenerationConfig(openAIReq *openai.ChatCompletionRequest) *gcpvertexai.GenerationConfig {
+	config := gcpvertexai.GenerationConfig{
+		Temperature:      openAIReq.Temperature,
+		TopP:             openAIReq.TopP,
+		CandidateCount:   openAIReq.N,
+		MaxOutputTokens:  chatCompletionMaxTokens(openAIReq),
+		PresencePenalty:  openAIReq.PresencePenalty,
+		FrequencyPenalty: openAIReq.FrequencyPenalty,
+		Seed:             openAIReq.Seed,
+	}
+	for _, stop := range openAIReq.Stop {
+		if stop != nil {
+			config.StopSequences = append(config.StopSequences, *stop)
+		}
+	}
+	if f := openAIReq.ResponseFormat; f != nil && (f.Type == openai.ChatCompletionResponseFormatTypeJSONObject ||
+		f.Type == openai.ChatCompletionResponseFormatTypeJSONSchema) {
+		config.ResponseMimeType = jsonContentType
+	}
+	if config.Temperature == nil && config.TopP == nil && config.CandidateCount == nil && config.MaxOutputTokens == nil &&
+		config.PresencePenalty == nil && config.FrequencyPenalty == nil && config.Seed == nil &&
+		len(config.StopSequences) == 0 && config.ResponseMimeType == "" {
+		return nil
+	}
+	return &config
+}
+
+// openAIMessagesToGeminiContents converts openai ChatCompletion messages to gemini contents.
+// The system and developer messages are hoisted to the systemInstruction, and the consecutive tool messages
+// are merged into a single user content with multiple functionResponse parts.
+func (o *openAIToGCPVertexAITranslatorV1ChatCompletion) openAIMessagesToGeminiContents(openAIReq *openai.ChatCompletionRequest,
+	geminiReq *gcpvertexai.GenerateContentRequest,
+) error {
+	// Gemini function responses are matched with the calls by name, so we keep track of the names of the tool calls.
+	toolCallNames := map[string]string{}
+	geminiReq.Contents = make([]gcpvertexai.Content, 0, len(openAIReq.Messages))
+	for i := range openAIReq.Messages {
+		msg := &openAIReq.Messages[i]
+		switch msg.Type {
+		case openai.ChatMessageRoleUser:
+			userMessage := msg.Value.(openai.ChatCompletionUserMessageParam)
+			parts, err := openAIUserContentToGeminiParts(&userMessage)
+			if err != nil {
+				return err
+			}
+			geminiReq.Contents = append(geminiReq.Contents, gcpvertexai.Content{Role: gcpvertexai.RoleUser, Parts: parts})
+		case openai.ChatMessageRoleAssistant:
+			assistantMessage := msg.Value.(openai.ChatCompletionAssistantMessageParam)
+			var parts []gcpvertexai.Part
+			text := assistantMessage.Content.Text
+			if assistantMessage.Content.Type == openai.ChatCompletionAssistantMessageParamContentTypeRefusal {
+				text = assistantMessage.Content.Refusal
+			}
+			if text != nil && *text != "" {
+				parts = append(parts, gcpvertexai.Part{Text: *text})
+			}
+			for j := range assistantMessage.ToolCalls {
+				toolCall := &assistantMessage.ToolCalls[j]
+				args := map[string]interface{}{}
+				if toolCall.Function.Arguments != "" {
+					var err error
+					if args, err = unmarshalToolCallArguments(toolCall.Function.Arguments); err != nil {
+						return err
+					}
+				}
+				toolCallNames[toolCall.ID] = toolCall.Function.Name
+				parts = append(parts, gcpvertexai.Part{
+					FunctionCall: &gcpvertexai.FunctionCall{Name: toolCall.Function.Name, Args: args},
+				})
+			}
+			geminiReq.Contents = append(geminiReq.Contents, gcpvertexai.Content{Role: gcpvertexai.RoleModel, Parts: parts})
+		case openai.ChatMessageRoleSystem:
+			systemMessage := msg.Value.(openai.ChatCompletionSystemMessageParam)
+			text, err := stringOrArrayToString(&systemMessage.Content)
+			if err != nil {
+				return fmt.Errorf("unexpected content type for system message: %w", err)
+			}
+			if geminiReq.SystemInstruction == nil {
+				geminiReq.SystemInstruction = &gcpvertexai.Content{}
+			}
+			geminiReq.SystemInstruction.Parts = append(geminiReq.SystemInstruction.Parts, gcpvertexai.Part{Text: text})
+		case openai.ChatMessageRoleDeveloper:
+			developerMessage := msg.Value.(openai.ChatCompletionDeveloperMessageParam)
+			text, err := stringOrArrayToString(&developerMessage.Content)
+			if err != nil {
+				return fmt.Errorf("unexpected content type for developer message: %w", err)
+			}
+			if geminiReq.SystemInstruction == nil {
+				geminiReq.SystemInstruction = &gcpvertexai.Content{}
+			}
+			geminiReq.SystemInstruction.Parts = append(geminiReq.SystemInstruction.Parts, gcpvertexai.Part{Text: text})
+		case openai.ChatMessageRoleTool:
+			toolMessage := msg.Value.(openai.ChatCompletionToolMessageParam)
+			text, err := stringOrArrayToString(&toolMessage.Content)
+			if err != nil {
+				return fmt.Errorf("unexpected content type for tool message: %w", err)
+			}
+			name, ok := toolCallNames[toolMessage.ToolCallID]
+			if !ok {
+				return fmt.Errorf("tool call not found for the tool message: %s", toolMessage.ToolCallID)
+			}
+			part := gcpvertexai.Part{FunctionResponse: &gcpvertexai.FunctionResponse{
+				Name:     name,
+				Response: map[string]any{"content": text},
+			}}
+			// Responses for the parallel function calls must be in the same content.
+			if l := len(geminiReq.Contents); l > 0 && i > 0 && openAIReq.Messages[i-1].Type == openai.ChatMessageRoleTool {
+				geminiReq.Contents[l-1].Parts = append(geminiReq.Contents[l-1].Parts, part)
+			} else {
+				geminiReq.Contents = append(geminiReq.Contents, gcpvertexai.Content{
+					Role: gcpvertexai.RoleUser, Parts: []gcpvertexai.Part{part},
+				})
+			}
+		default:
+			return fmt.Errorf("unexpected role: %s", msg.Type)
+		}
+	}
+	return nil
+}
+
+// stringOrArrayToString concatenates the text parts of the system, developer and tool messages.
+func stringOrArrayToString(content *openai.StringOrArray) (string, error) {
+	switch v := content.Value.(type) {
+	case string:
+		return v, nil
+	case []openai.ChatCompletionContentPartTextParam:
+		var text strings.Builder
+		for i := range v {
+			text.WriteString(v[i].Text)
+		}
+		return text.String(), nil
+	default:
+		return "", fmt.Errorf("unexpected type: %T", content.Value)
+	}
+}
+
+// openAIUserContentToGeminiParts converts openai user role message content.
+func openAIUserContentToGeminiParts(openAIMessage *openai.ChatCompletionUserMessageParam) ([]gcpvertexai.Part, error) {
+	switch v := openAIMessage.Content.Value.(type) {
+	case string:
+		return []gcpvertexai.Part{{Text: v}}, nil
+	case []openai.ChatCompletionContentPartUserUnionParam:
+		parts := make([]gcpvertexai.Part, 0, len(v))
+		for i := range v {
+			contentPart := &v[i]
+			if contentPart.TextContent != nil {
+				parts = append(parts, gcpvertexai.Part{Text: contentPart.TextContent.Text})
+			} else if contentPart.ImageContent != nil {
+				part, err := openAIImageURLToGeminiPart(contentPart.ImageContent.ImageURL.URL)
+				if err != nil {
+					return nil, err
+				}
+				parts = append(parts, part)
+			}
+		}
+		return parts, nil
+	default:
+		return nil, fmt.Errorf("unexpected content type")
+	}
+}
+
+// openAIImageURLToGeminiPart converts the image URL which is either a data URI or a URI of the file.
+// The mime type of the file is inferred from the extension since it is required by Gemini.
+func openAIImageURLToGeminiPart(imageURL string) (gcpvertexai.Part, error) {
+	if strings.HasPrefix(imageURL, "data:") {
+		contentType, b, err := parseDataURI(imageURL)
+		if err != nil {
+			return gcpvertexai.Part{}, fmt.Errorf("failed to parse image URL: %s %w", imageURL, err)
+		}
+		return gcpvertexai.Part{InlineData: &gcpvertexai.Blob{
+			MimeType: contentType,
+			Data:     base64.StdEncoding.EncodeToString(b),
+		}}, nil
+	}
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return gcpvertexai.Part{}, fmt.Errorf("failed to parse image URL: %s %w", imageURL, err)
+	}
+	mimeType := mime.TypeByExtension(path.Ext(u.Path))
+	if mimeType == "" {
+		return gcpvertexai.Part{}, fmt.Errorf("unable to determine the mime type of the image URL: %s", imageURL)
+	}
+	return gcpvertexai.Part{FileData: &gcpvertexai.FileData{MimeType: mimeType, FileURI: imageURL}}, nil
+}
+
+// openAIToolsToGeminiTools converts openai ChatCompletion tools and tool_choice to gemini ones.
+func (o *openAIToGCPVertexAITranslatorV1ChatCompletion) openAIToolsToGeminiTools(openAIReq *openai.ChatCompletionRequest,
+	geminiReq *gcpvertexai.GenerateContentRequest,
+) error {
+	var declarations []gcpvertexai.FunctionDeclaration
+	for i := range openAIReq.Tools {
+		tool := &openAIReq.Tools[i]
+		if tool.Function == nil {
+			continue
+		}
+		declarations = append(declarations, gcpvertexai.FunctionDeclaration{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		})
+	}
+	if len(declarations) > 0 {
+		geminiReq.Tools = []gcpvertexai.Tool{{FunctionDeclarations: declarations}}
+	}
+
+	var config *gcpvertexai.FunctionCallingConfig
+	switch toolChoice := openAIReq.ToolChoice.(type) {
+	case nil:
+	case string:
+		switch toolChoice {
+		case "auto":
+			config = &gcpvertexai.FunctionCallingConfig{Mode: gcpvertexai.FunctionCallingModeAuto}
+		case "required":
+			config = &gcpvertexai.FunctionCallingConfig{Mode: gcpvertexai.FunctionCallingModeAny}
+		case "none":
+			config = &gcpvertexai.FunctionCallingConfig{Mode: gcpvertexai.FunctionCallingModeNone}
+		default:
+			return fmt.Errorf("unexpected tool_choice: %s", toolChoice)
+		}
+	case openai.ToolChoice:
+		config = &gcpvertexai.FunctionCallingConfig{
+			Mode: gcpvertexai.FunctionCallingModeAny, AllowedFunctionNames: []string{toolChoice.Function.Name},
+		}
+	case map[string]interface{}:
+		// This is the case when the request body is unmarshalled from JSON.
+		function, _ := toolChoice["function"].(map[string]interface{})
+		name, _ := function["name"].(string)
+		if name == "" {
+			return fmt.Errorf("tool_choice function name is required")
+		}
+		config = &gcpvertexai.FunctionCallingConfig{
+			Mode: gcpvertexai.FunctionCallingModeAny, AllowedFunctionNames: []string{name},
+		}
+	default:
+		return fmt.Errorf("unexpected type: %T", openAIReq.ToolChoice)
+	}
+	if config != nil {
+		geminiReq.ToolConfig = &gcpvertexai.ToolConfig{FunctionCallingConfig: config}
+	}
+	return nil
+}
+
+// ResponseHeaders implements [Translator.ResponseHeaders].
+func (o *openAIToGCPVertexAITranslatorV1ChatCompletion) ResponseHeaders(map[string]string) (
+	headerMutation *extprocv3.HeaderMutation, err error,
+) {
+	return nil, nil
+}
+
+// ResponseError implements [Translator.ResponseError].
+// Translate GCP errors to OpenAI error type.
+// If the connection fails the error body is translated to OpenAI error type for events such as HTTP 503 or 504.
+func (o *openAIToGCPVertexAITranslatorV1ChatCompletion) ResponseError(respHeaders map[string]string, body io.Reader) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error,
+) {
+	statusCode := respHeaders[statusHeaderName]
+	buf, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read error body: %w", err)
+	}
+	openaiError := openai.Error{
+		Type: "error",
+		Error: openai.ErrorType{
+			Type:    gcpVertexAIBackendError,
+			Message: string(buf),
+			Code:    &statusCode,
+		},
+	}
+	// The content-type may have the charset parameter, e.g. "application/json; charset=UTF-8".
+	if v := respHeaders[contentTypeHeaderName]; strings.HasPrefix(v, jsonContentType) {
+		// The streaming endpoint returns the error as a JSON array.
+		var gcpErrors []gcpvertexai.Error
+		var gcpError gcpvertexai.Error
+		if err = json.Unmarshal(buf, &gcpError); err == nil && gcpError.Error.Message != "" {
+			openaiError.Error.Type = gcpError.Error.Status
+			openaiError.Error.Message = gcpError.Error.Message
+		} else if err = json.Unmarshal(buf, &gcpErrors); err == nil && len(gcpErrors) > 0 {
+			openaiError.Error.Type = gcpErrors[0].Error.Status
+			openaiError.Error.Message = gcpErrors[0].Error.Message
+		}
+	}
+	mut := &extprocv3.BodyMutation_Body{}
+	mut.Body, err = json.Marshal(openaiError)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal error body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, nil
+}
+
+// ResponseBody implements [Translator.ResponseBody].
+func (o *openAIToGCPVertexAITranslatorV1ChatCompletion) ResponseBody(respHeaders map[string]string, body io.Reader, endOfStream bool) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage LLMTokenUsage, err error,
+) {
+	if statusStr, ok := respHeaders[statusHeaderName]; ok {
+		var status int
+		if status, err = strconv.Atoi(statusStr); err == nil {
+			if !isGoodStatusCode(status) {
+				headerMutation, bodyMutation, err = o.ResponseError(respHeaders, body)
+				return headerMutation, bodyMutation, LLMTokenUsage{}, err
+			}
+		}
+	}
+	mut := &extprocv3.BodyMutation_Body{}
+	if o.stream {
+		var buf []byte
+		buf, err = io.ReadAll(body)
+		if err != nil {
+			return nil, nil, tokenUsage, fmt.Errorf("failed to read body: %w", err)
+		}
+		o.bufferedBody = append(o.bufferedBody, buf...)
+		var chunks []openai.ChatCompletionResponseChunk
+		for _, event := range o.extractServerSentEvents() {
+			if event.UsageMetadata != nil {
+				o.usage = event.UsageMetadata
+			}
+			chunks = append(chunks, o.convertEvent(event)...)
+		}
+		if endOfStream && o.usage != nil {
+			tokenUsage = geminiUsageToLLMTokenUsage(o.usage)
+			chunks = append(chunks, openai.ChatCompletionResponseChunk{
+				Object: "chat.completion.chunk",
+				Usage:  geminiUsageToOpenAIUsage(o.usage),
+			})
+		}
+		for i := range chunks {
+			var chunk []byte
+			chunk, err = json.Marshal(chunks[i])
+			if err != nil {
+				panic(fmt.Errorf("failed to marshal event: %w", err))
+			}
+			mut.Body = append(mut.Body, dataPrefix...)
+			mut.Body = append(mut.Body, chunk...)
+			mut.Body = append(mut.Body, []byte("\n\n")...)
+		}
+		if endOfStream {
+			mut.Body = append(mut.Body, []byte("data: [DONE]\n")...)
+		}
+		return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, tokenUsage, nil
+	}
+
+	var geminiResp gcpvertexai.GenerateContentResponse
+	if err = json.NewDecoder(body).Decode(&geminiResp); err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+	openAIResp := openai.ChatCompletionResponse{Object: "chat.completion"}
+	if geminiResp.UsageMetadata != nil {
+		tokenUsage = geminiUsageToLLMTokenUsage(geminiResp.UsageMetadata)
+		openAIResp.Usage = *geminiUsageToOpenAIUsage(geminiResp.UsageMetadata)
+	}
+	for i := range geminiResp.Candidates {
+		candidate := &geminiResp.Candidates[i]
+		choice := openai.ChatCompletionResponseChoice{
+			Index:   candidate.Index,
+			Message: openai.ChatCompletionResponseChoiceMessage{Role: openai.ChatMessageRoleAssistant},
+		}
+		var text strings.Builder
+		for j := range candidate.Content.Parts {
+			part := &candidate.Content.Parts[j]
+			if part.FunctionCall != nil {
+				toolCall, err := o.geminiFunctionCallToOpenAIToolCall(part.FunctionCall)
+				if err != nil {
+					return nil, nil, tokenUsage, err
+				}
+				choice.Message.ToolCalls = append(choice.Message.ToolCalls, toolCall)
+			} else {
+				text.WriteString(part.Text)
+			}
+		}
+		if text.Len() > 0 {
+			choice.Message.Content = ptr.To(text.String())
+		}
+		choice.FinishReason = geminiFinishReasonToOpenAIFinishReason(candidate.FinishReason, len(choice.Message.ToolCalls) > 0)
+		openAIResp.Choices = append(openAIResp.Choices, choice)
+	}
+
+	mut.Body, err = json.Marshal(openAIResp)
+	if err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to marshal body: %w", err)
+	}
+	headerMutation = &extprocv3.HeaderMutation{}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, tokenUsage, nil
+}
+
+// geminiFunctionCallToOpenAIToolCall converts the gemini function call to the openai tool call with a generated ID.
+func (o *openAIToGCPVertexAITranslatorV1ChatCompletion) geminiFunctionCallToOpenAIToolCall(
+	call *gcpvertexai.FunctionCall,
+) (openai.ChatCompletionMessageToolCallParam, error) {
+	args := call.Args
+	if args == nil {
+		args = map[string]any{}
+	}
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		return openai.ChatCompletionMessageToolCallParam{}, fmt.Errorf("failed to marshal function call args: %w", err)
+	}
+	id := fmt.Sprintf("call_%d", o.toolCallCount)
+	o.toolCallCount++
+	return openai.ChatCompletionMessageToolCallParam{
+		ID:   id,
+		Type: openai.ChatCompletionMessageToolCallTypeFunction,
+		Function: openai.ChatCompletionMessageToolCallFunctionParam{
+			Name:      call.Name,
+			Arguments: string(arguments),
+		},
+	}, nil
+}
+
+// extractServerSentEvents extracts [gcpvertexai.GenerateContentResponse] from the buffered body.
+// The incomplete event at the end of the buffer is kept for the next call.
+func (o *openAIToGCPVertexAITranslatorV1ChatCompletion) extractServerSentEvents() (events []*gcpvertexai.GenerateContentResponse) {
+	// Events may be delimited by "\r\n\r\n", so normalize them first.
+	o.bufferedBody = bytes.ReplaceAll(o.bufferedBody, []byte("\r\n"), []byte("\n"))
+	for {
+		i := bytes.Index(o.bufferedBody, []byte("\n\n"))
+		if i == -1 {
+			return
+		}
+		raw := o.bufferedBody[:i]
+		o.bufferedBody = o.bufferedBody[i+2:]
+		for _, line := range bytes.Split(raw, []byte("\n")) {
+			if !bytes.HasPrefix(line, dataPrefix) {
+				continue
+			}
+			var event gcpvertexai.GenerateContentResponse
+			if err := json.Unmarshal(bytes.TrimPrefix(line, dataPrefix), &event); err == nil {
+				events = append(events, &event)
+			}
+		}
+	}
+}
+
+// convertEvent converts a [gcpvertexai.GenerateContentResponse] event to zero or more [openai.ChatCompletionResponseChunk].
+func (o *openAIToGCPVertexAITranslatorV1ChatCompletion) convertEvent(event *gcpvertexai.GenerateContentResponse) []openai.ChatCompletionResponseChunk {
+	const object = "chat.completion.chunk"
+	var chunks []openai.ChatCompletionResponseChunk
+	for i := range event.Candidates {
+		candidate := &event.Candidates[i]
+		var hasToolCalls bool
+		for j := range candidate.Content.Parts {
+			part := &candidate.Content.Parts[j]
+			delta := &openai.ChatCompletionResponseChunkChoiceDelta{Role: openai.ChatMessageRoleAssistant}
+			if part.FunctionCall != nil {
+				toolCall, err := o.geminiFunctionCallToOpenAIToolCall(part.FunctionCall)
+				if err != nil {
+					continue
+				}
+				hasToolCalls = true
+				delta.ToolCalls = []openai.ChatCompletionMessageToolCallParam{toolCall}
+			} else if part.Text != "" {
+				delta.Content = ptr.To(part.Text)
+			} else {
+				continue
+			}
+			chunks = append(chunks, openai.ChatCompletionResponseChunk{
+				Object:  object,
+				Choices: []openai.ChatCompletionResponseChunkChoice{{Index: candidate.Index, Delta: delta}},
+			})
+		}
+		if candidate.FinishReason != "" {
+			chunks = append(chunks, openai.ChatCompletionResponseChunk{
+				Object: object,
+				Choices: []openai.ChatCompletionResponseChunkChoice{{
+					Index:        candidate.Index,
+					Delta:        &openai.ChatCompletionResponseChunkChoiceDelta{Role: openai.ChatMessageRoleAssistant, Content: ptr.To(emptyString)},
+					FinishReason: geminiFinishReasonToOpenAIFinishReason(candidate.FinishReason, hasToolCalls),
+				}},
+			})
+		}
+	}
+	return chunks
+}
+
+// geminiFinishReasonToOpenAIFinishReason converts the gemini finish reason to the openai finish reason.
+// Gemini reports "STOP" for function calls, so hasToolCalls is used to distinguish them.
+func geminiFinishReasonToOpenAIFinishReason(reason string, hasToolCalls bool) openai.ChatCompletionChoicesFinishReason {
+	switch reason {
+	case gcpvertexai.FinishReasonMaxTokens:
+		return openai.ChatCompletionChoicesFinishReasonLength
+	case gcpvertexai.FinishReasonSafety, gcpvertexai.FinishReasonRecitation, gcpvertexai.FinishReasonBlocklist,
+		gcpvertexai.FinishReasonProhibitedContent, gcpvertexai.FinishReasonSPII:
+		return openai.ChatCompletionChoicesFinishReasonContentFilter
+	default:
+		if hasToolCalls {
+			return openai.ChatCompletionChoicesFinishReasonToolCalls
+		}
+		return openai.ChatCompletionChoicesFinishReasonStop
+	}
+}
+
+func geminiUsageToLLMTokenUsage(usage *gcpvertexai.UsageMetadata) LLMTokenUsage {
+	return LLMTokenUsage{
+		InputTokens:  uint32(usage.PromptTokenCount),     //nolint:gosec
+		OutputTokens: uint32(usage.CandidatesTokenCount), //nolint:gosec
+		TotalTokens:  uint32(usage.TotalTokenCount),      //nolint:gosec
+	}
+}
+
+func geminiUsageToOpenAIUsage(usage *gcpvertexai.UsageMetadata) *openai.ChatCompletionResponseUsage {
+	return &openai.ChatCompletionResponseUsage{
+		PromptTokens:     usage.PromptTokenCount,
+		CompletionTokens: usage.CandidatesTokenCount,
+		TotalTokens:      usage.TotalTokenCount,
+	}
+}
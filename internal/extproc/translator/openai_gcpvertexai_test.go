@@ -0,0 +1,300 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/gcpvertexai"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func TestOpenAIToGCPVertexAITranslatorV1ChatCompletion_RequestBody(t *testing.T) {
+	t.Run("messages", func(t *testing.T) {
+		const body = `{
+  "model": "gemini-2.0-flash",
+  "max_tokens": 100,
+  "temperature": 0.5,
+  "stop": ["STOP"],
+  "response_format": {"type": "json_object"},
+  "messages": [
+    {"role": "system", "content": "from-system"},
+    {"role": "developer", "content": [{"type": "text", "text": "from-developer"}]},
+    {"role": "user", "content": [
+      {"type": "text", "text": "what is in this image?"},
+      {"type": "image_url", "image_url": {"url": "data:image/png;base64,aGVsbG8="}},
+      {"type": "image_url", "image_url": {"url": "gs://bucket/cat.jpg"}}
+    ]},
+    {"role": "assistant", "content": {"type": "text", "text": "let me check"}, "tool_calls": [
+      {"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Tokyo\"}"}},
+      {"id": "call_2", "type": "function", "function": {"name": "get_time", "arguments": ""}}
+    ]},
+    {"role": "tool", "tool_call_id": "call_1", "content": "sunny"},
+    {"role": "tool", "tool_call_id": "call_2", "content": [{"type": "text", "text": "noon"}]},
+    {"role": "user", "content": "thanks"}
+  ],
+  "tools": [{"type": "function", "function": {"name": "get_weather", "description": "weather", "parameters": {"type": "object"}}}],
+  "tool_choice": {"type": "function", "function": {"name": "get_weather"}}
+}`
+		var req openai.ChatCompletionRequest
+		require.NoError(t, json.Unmarshal([]byte(body), &req))
+
+		o := NewChatCompletionOpenAIToGCPVertexAITranslator("", "")
+		hm, bm, mode, err := o.RequestBody(nil, &req)
+		require.NoError(t, err)
+		require.Nil(t, mode)
+		require.Len(t, hm.SetHeaders, 2)
+		require.Equal(t, ":path", hm.SetHeaders[0].Header.Key)
+		require.Equal(t, "/v1/publishers/google/models/gemini-2.0-flash:generateContent", string(hm.SetHeaders[0].Header.RawValue))
+		require.Equal(t, "content-length", hm.SetHeaders[1].Header.Key)
+
+		var actual gcpvertexai.GenerateContentRequest
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+		expected := gcpvertexai.GenerateContentRequest{
+			SystemInstruction: &gcpvertexai.Content{Parts: []gcpvertexai.Part{{Text: "from-system"}, {Text: "from-developer"}}},
+			Contents: []gcpvertexai.Content{
+				{Role: "user", Parts: []gcpvertexai.Part{
+					{Text: "what is in this image?"},
+					{InlineData: &gcpvertexai.Blob{MimeType: "image/png", Data: "aGVsbG8="}},
+					{FileData: &gcpvertexai.FileData{MimeType: "image/jpeg", FileURI: "gs://bucket/cat.jpg"}},
+				}},
+				{Role: "model", Parts: []gcpvertexai.Part{
+					{Text: "let me check"},
+					{FunctionCall: &gcpvertexai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "Tokyo"}}},
+					{FunctionCall: &gcpvertexai.FunctionCall{Name: "get_time"}},
+				}},
+				{Role: "user", Parts: []gcpvertexai.Part{
+					{FunctionResponse: &gcpvertexai.FunctionResponse{Name: "get_weather", Response: map[string]any{"content": "sunny"}}},
+					{FunctionResponse: &gcpvertexai.FunctionResponse{Name: "get_time", Response: map[string]any{"content": "noon"}}},
+				}},
+				{Role: "user", Parts: []gcpvertexai.Part{{Text: "thanks"}}},
+			},
+			Tools: []gcpvertexai.Tool{{FunctionDeclarations: []gcpvertexai.FunctionDeclaration{
+				{Name: "get_weather", Description: "weather", Parameters: map[string]any{"type": "object"}},
+			}}},
+			ToolConfig: &gcpvertexai.ToolConfig{FunctionCallingConfig: &gcpvertexai.FunctionCallingConfig{
+				Mode: "ANY", AllowedFunctionNames: []string{"get_weather"},
+			}},
+			GenerationConfig: &gcpvertexai.GenerationConfig{
+				Temperature:      ptr.To(0.5),
+				MaxOutputTokens:  ptr.To[int64](100),
+				StopSequences:    []string{"STOP"},
+				ResponseMimeType: "application/json",
+			},
+		}
+		if !cmp.Equal(expected, actual) {
+			t.Errorf("RequestBody(), diff(got, expected) = %s\n", cmp.Diff(actual, expected))
+		}
+	})
+	t.Run("streaming with resource name", func(t *testing.T) {
+		req := &openai.ChatCompletionRequest{
+			Model:  "projects/p/locations/us-central1/publishers/google/models/gemini-2.0-flash",
+			Stream: true,
+			Messages: []openai.ChatCompletionMessageParamUnion{{
+				Type:  openai.ChatMessageRoleUser,
+				Value: openai.ChatCompletionUserMessageParam{Content: openai.StringOrUserRoleContentUnion{Value: "hi"}},
+			}},
+			ToolChoice: "none",
+		}
+		o := NewChatCompletionOpenAIToGCPVertexAITranslator("v1beta1", "")
+		hm, bm, mode, err := o.RequestBody(nil, req)
+		require.NoError(t, err)
+		require.NotNil(t, mode)
+		require.Equal(t, extprocv3http.ProcessingMode_STREAMED, mode.ResponseBodyMode)
+		require.Equal(t, "/v1beta1/projects/p/locations/us-central1/publishers/google/models/gemini-2.0-flash:streamGenerateContent?alt=sse",
+			string(hm.SetHeaders[0].Header.RawValue))
+
+		var actual gcpvertexai.GenerateContentRequest
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+		require.Nil(t, actual.GenerationConfig)
+		require.Equal(t, &gcpvertexai.ToolConfig{FunctionCallingConfig: &gcpvertexai.FunctionCallingConfig{Mode: "NONE"}}, actual.ToolConfig)
+	})
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			name   string
+			req    *openai.ChatCompletionRequest
+			expErr string
+		}{
+			{
+				name: "unknown tool call",
+				req: &openai.ChatCompletionRequest{Messages: []openai.ChatCompletionMessageParamUnion{{
+					Type:  openai.ChatMessageRoleTool,
+					Value: openai.ChatCompletionToolMessageParam{ToolCallID: "foo", Content: openai.StringOrArray{Value: "bar"}},
+				}}},
+				expErr: "tool call not found for the tool message: foo",
+			},
+			{
+				name: "unknown image type",
+				req: &openai.ChatCompletionRequest{Messages: []openai.ChatCompletionMessageParamUnion{{
+					Type: openai.ChatMessageRoleUser,
+					Value: openai.ChatCompletionUserMessageParam{Content: openai.StringOrUserRoleContentUnion{
+						Value: []openai.ChatCompletionContentPartUserUnionParam{{
+							ImageContent: &openai.ChatCompletionContentPartImageParam{
+								ImageURL: openai.ChatCompletionContentPartImageImageURLParam{URL: "https://example.com/image"},
+							},
+						}},
+					}},
+				}}},
+				expErr: "unable to determine the mime type of the image URL: https://example.com/image",
+			},
+			{
+				name:   "unexpected tool choice",
+				req:    &openai.ChatCompletionRequest{ToolChoice: "foo"},
+				expErr: "unexpected tool_choice: foo",
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				o := NewChatCompletionOpenAIToGCPVertexAITranslator("", "")
+				_, _, _, err := o.RequestBody(nil, tc.req)
+				require.ErrorContains(t, err, tc.expErr)
+			})
+		}
+	})
+}
+
+func TestOpenAIToGCPVertexAITranslatorV1ChatCompletion_ResponseBody(t *testing.T) {
+	t.Run("non-streaming", func(t *testing.T) {
+		const body = `{
+  "candidates": [{
+    "content": {"role": "model", "parts": [
+      {"text": "Let me check the weather."},
+      {"functionCall": {"name": "get_weather", "args": {"city": "Tokyo"}}}
+    ]},
+    "finishReason": "STOP"
+  }],
+  "usageMetadata": {"promptTokenCount": 10, "candidatesTokenCount": 20, "totalTokenCount": 30},
+  "modelVersion": "gemini-2.0-flash"
+}`
+		o := NewChatCompletionOpenAIToGCPVertexAITranslator("", "")
+		hm, bm, usage, err := o.ResponseBody(map[string]string{":status": "200"}, strings.NewReader(body), true)
+		require.NoError(t, err)
+		require.NotNil(t, hm)
+		require.Equal(t, LLMTokenUsage{InputTokens: 10, OutputTokens: 20, TotalTokens: 30}, usage)
+
+		var actual openai.ChatCompletionResponse
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+		expected := openai.ChatCompletionResponse{
+			Object: "chat.completion",
+			Usage:  openai.ChatCompletionResponseUsage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30},
+			Choices: []openai.ChatCompletionResponseChoice{{
+				FinishReason: openai.ChatCompletionChoicesFinishReasonToolCalls,
+				Message: openai.ChatCompletionResponseChoiceMessage{
+					Role:    "assistant",
+					Content: ptr.To("Let me check the weather."),
+					ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+						ID:       "call_0",
+						Type:     openai.ChatCompletionMessageToolCallTypeFunction,
+						Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "get_weather", Arguments: `{"city":"Tokyo"}`},
+					}},
+				},
+			}},
+		}
+		if !cmp.Equal(expected, actual) {
+			t.Errorf("ResponseBody(), diff(got, expected) = %s\n", cmp.Diff(actual, expected))
+		}
+	})
+	t.Run("streaming", func(t *testing.T) {
+		body := strings.Join([]string{
+			`data: {"candidates": [{"content": {"role": "model","parts": [{"text": "Hello"}]}}],"usageMetadata": {"promptTokenCount": 5,"candidatesTokenCount": 1,"totalTokenCount": 6}}`,
+			`data: {"candidates": [{"content": {"role": "model","parts": [{"text": " world"}]}}],"usageMetadata": {"promptTokenCount": 5,"candidatesTokenCount": 2,"totalTokenCount": 7}}`,
+			`data: {"candidates": [{"content": {"role": "model","parts": [{"text": ""}]},"finishReason": "MAX_TOKENS"}],"usageMetadata": {"promptTokenCount": 5,"candidatesTokenCount": 3,"totalTokenCount": 8}}`,
+		}, "\r\n\r\n") + "\r\n\r\n"
+
+		o := NewChatCompletionOpenAIToGCPVertexAITranslator("", "")
+		_, _, _, err := o.RequestBody(nil, &openai.ChatCompletionRequest{Model: "gemini-2.0-flash", Stream: true})
+		require.NoError(t, err)
+
+		// Feed the body in small pieces to make sure the buffering works.
+		var results []byte
+		var usage LLMTokenUsage
+		for i := 0; i < len(body); i += 5 {
+			end := min(i+5, len(body))
+			_, bm, u, err := o.ResponseBody(map[string]string{":status": "200"}, strings.NewReader(body[i:end]), false)
+			require.NoError(t, err)
+			require.Equal(t, LLMTokenUsage{}, u)
+			results = append(results, bm.GetBody()...)
+		}
+		_, bm, usage, err := o.ResponseBody(map[string]string{":status": "200"}, strings.NewReader(""), true)
+		require.NoError(t, err)
+		results = append(results, bm.GetBody()...)
+		require.Equal(t, LLMTokenUsage{InputTokens: 5, OutputTokens: 3, TotalTokens: 8}, usage)
+		require.Equal(t, `data: {"choices":[{"delta":{"content":"Hello","role":"assistant"}}],"object":"chat.completion.chunk"}
+
+data: {"choices":[{"delta":{"content":" world","role":"assistant"}}],"object":"chat.completion.chunk"}
+
+data: {"choices":[{"delta":{"content":"","role":"assistant"},"finish_reason":"length"}],"object":"chat.completion.chunk"}
+
+data: {"object":"chat.completion.chunk","usage":{"completion_tokens":3,"prompt_tokens":5,"total_tokens":8}}
+
+data: [DONE]
+`, string(results))
+	})
+	t.Run("streaming multiple candidates", func(t *testing.T) {
+		body := `data: {"candidates": [{"content": {"role": "model","parts": [{"text": "Hi"}]}},` +
+			`{"content": {"role": "model","parts": [{"text": "Hey"}]},"index": 1,"finishReason": "STOP"}]}` + "\n\n"
+
+		o := NewChatCompletionOpenAIToGCPVertexAITranslator("", "")
+		_, _, _, err := o.RequestBody(nil, &openai.ChatCompletionRequest{Model: "gemini-2.0-flash", Stream: true})
+		require.NoError(t, err)
+		_, bm, _, err := o.ResponseBody(map[string]string{":status": "200"}, strings.NewReader(body), false)
+		require.NoError(t, err)
+		// The deltas of each candidate are sent as the choice of the same index.
+		require.Equal(t, `data: {"choices":[{"delta":{"content":"Hi","role":"assistant"}}],"object":"chat.completion.chunk"}
+
+data: {"choices":[{"index":1,"delta":{"content":"Hey","role":"assistant"}}],"object":"chat.completion.chunk"}
+
+data: {"choices":[{"index":1,"delta":{"content":"","role":"assistant"},"finish_reason":"stop"}],"object":"chat.completion.chunk"}
+
+`, string(bm.GetBody()))
+	})
+}
+
+func TestOpenAIToGCPVertexAITranslatorV1ChatCompletion_ResponseError(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		contentType string
+		body        string
+		exp         openai.ErrorType
+	}{
+		{
+			name:        "json error",
+			contentType: "application/json; charset=UTF-8",
+			body:        `{"error": {"code": 429, "message": "Resource exhausted.", "status": "RESOURCE_EXHAUSTED"}}`,
+			exp:         openai.ErrorType{Type: "RESOURCE_EXHAUSTED", Message: "Resource exhausted.", Code: ptr.To("429")},
+		},
+		{
+			name:        "json array error",
+			contentType: "application/json",
+			body:        `[{"error": {"code": 429, "message": "Resource exhausted.", "status": "RESOURCE_EXHAUSTED"}}]`,
+			exp:         openai.ErrorType{Type: "RESOURCE_EXHAUSTED", Message: "Resource exhausted.", Code: ptr.To("429")},
+		},
+		{
+			name:        "non-json error",
+			contentType: "text/plain",
+			body:        "service not available",
+			exp:         openai.ErrorType{Type: gcpVertexAIBackendError, Message: "service not available", Code: ptr.To("429")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := NewChatCompletionOpenAIToGCPVertexAITranslator("", "")
+			headers := map[string]string{":status": "429", "content-type": tc.contentType}
+			hm, bm, _, err := o.ResponseBody(headers, bytes.NewBufferString(tc.body), true)
+			require.NoError(t, err)
+			require.NotNil(t, hm)
+			var actual openai.Error
+			require.NoError(t, json.Unmarshal(bm.GetBody(), &actual))
+			require.Equal(t, openai.Error{Type: "error", Error: tc.exp}, actual)
+		})
+	}
+}
@@ -19,19 +19,27 @@ import (
 )
 
 // NewChatCompletionOpenAIToOpenAITranslator implements [Factory] for OpenAI to OpenAI translation.
-func NewChatCompletionOpenAIToOpenAITranslator() OpenAIChatCompletionTranslator {
-	return &openAIToOpenAITranslatorV1ChatCompletion{}
+//
+// When modelNameOverride is not empty, the model in the request body is rewritten to it. When forceStreamUsage is true,
+// the streamed requests always ask for the usage, and the usage chunk is removed from the response when the client
+// did not ask for it.
+func NewChatCompletionOpenAIToOpenAITranslator(modelNameOverride string, forceStreamUsage bool) OpenAIChatCompletionTranslator {
+	return &openAIToOpenAITranslatorV1ChatCompletion{modelNameOverride: modelNameOverride, forceStreamUsage: forceStreamUsage}
 }
 
 // openAIToOpenAITranslatorV1ChatCompletion implements [Translator] for /v1/chat/completions.
 type openAIToOpenAITranslatorV1ChatCompletion struct {
-	stream        bool
-	buffered      []byte
-	bufferingDone bool
+	modelNameOverride string
+	forceStreamUsage  bool
+	stream            bool
+	// stripUsageChunk is true if the usage chunk is removed from the streamed response since the client did not ask for it.
+	stripUsageChunk bool
+	buffered        []byte
+	bufferingDone   bool
 }
 
 // RequestBody implements [Translator.RequestBody].
-func (o *openAIToOpenAITranslatorV1ChatCompletion) RequestBody(req *openai.ChatCompletionRequest) (
+func (o *openAIToOpenAITranslatorV1ChatCompletion) RequestBody(raw []byte, req *openai.ChatCompletionRequest) (
 	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error,
 ) {
 	if req.Stream {
@@ -43,7 +51,21 @@ func (o *openAIToOpenAITranslatorV1ChatCompletion) RequestBody(req *openai.ChatC
 			ResponseBodyMode:   extprocv3http.ProcessingMode_STREAMED,
 		}
 	}
-	return nil, nil, override, nil
+	forceUsage := req.Stream && o.forceStreamUsage && (req.StreamOptions == nil || !req.StreamOptions.IncludeUsage)
+	var patches []requestBodyPatch
+	if o.modelNameOverride != "" {
+		patches = append(patches, requestBodyPatch{path: "model", value: o.modelNameOverride})
+	}
+	if forceUsage {
+		o.stripUsageChunk = true
+		patches = append(patches, requestBodyPatch{path: "stream_options.include_usage", value: true})
+	}
+	if len(patches) > 0 {
+		if headerMutation, bodyMutation, err = patchRequestBody(raw, patches...); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return headerMutation, bodyMutation, override, nil
 }
 
 // ResponseError implements [Translator.ResponseError]
@@ -51,6 +73,14 @@ func (o *openAIToOpenAITranslatorV1ChatCompletion) RequestBody(req *openai.ChatC
 // If connection fails the error body is translated to OpenAI error type for events such as HTTP 503 or 504.
 func (o *openAIToOpenAITranslatorV1ChatCompletion) ResponseError(respHeaders map[string]string, body io.Reader) (
 	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error,
+) {
+	return openAIBackendErrorToOpenAIError(respHeaders, body)
+}
+
+// openAIBackendErrorToOpenAIError wraps the non-JSON error body of the OpenAI compatible backends into the OpenAI error type.
+// This returns nil mutations when the error body is already in JSON.
+func openAIBackendErrorToOpenAIError(respHeaders map[string]string, body io.Reader) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error,
 ) {
 	statusCode := respHeaders[statusHeaderName]
 	if v, ok := respHeaders[contentTypeHeaderName]; ok && v != jsonContentType {
@@ -85,7 +115,7 @@ func (o *openAIToOpenAITranslatorV1ChatCompletion) ResponseHeaders(map[string]st
 }
 
 // ResponseBody implements [Translator.ResponseBody].
-func (o *openAIToOpenAITranslatorV1ChatCompletion) ResponseBody(respHeaders map[string]string, body io.Reader, _ bool) (
+func (o *openAIToOpenAITranslatorV1ChatCompletion) ResponseBody(respHeaders map[string]string, body io.Reader, endOfStream bool) (
 	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage LLMTokenUsage, err error,
 ) {
 	if v, ok := respHeaders[statusHeaderName]; ok {
@@ -97,6 +127,9 @@ func (o *openAIToOpenAITranslatorV1ChatCompletion) ResponseBody(respHeaders map[
 		}
 	}
 	if o.stream {
+		if o.stripUsageChunk {
+			return o.responseBodyWithoutUsageChunk(body, endOfStream)
+		}
 		if !o.bufferingDone {
 			buf, err := io.ReadAll(body)
 			if err != nil {
@@ -111,11 +144,7 @@ func (o *openAIToOpenAITranslatorV1ChatCompletion) ResponseBody(respHeaders map[
 	if err := json.NewDecoder(body).Decode(&resp); err != nil {
 		return nil, nil, tokenUsage, fmt.Errorf("failed to unmarshal body: %w", err)
 	}
-	tokenUsage = LLMTokenUsage{
-		InputTokens:  uint32(resp.Usage.PromptTokens),     //nolint:gosec
-		OutputTokens: uint32(resp.Usage.CompletionTokens), //nolint:gosec
-		TotalTokens:  uint32(resp.Usage.TotalTokens),      //nolint:gosec
-	}
+	tokenUsage = openAIUsageToLLMTokenUsage(&resp.Usage)
 	return
 }
 
@@ -139,14 +168,91 @@ func (o *openAIToOpenAITranslatorV1ChatCompletion) extractUsageFromBufferEvent()
 			continue
 		}
 		if usage := event.Usage; usage != nil {
-			tokenUsage = LLMTokenUsage{
-				InputTokens:  uint32(usage.PromptTokens),     //nolint:gosec
-				OutputTokens: uint32(usage.CompletionTokens), //nolint:gosec
-				TotalTokens:  uint32(usage.TotalTokens),      //nolint:gosec
-			}
+			tokenUsage = openAIUsageToLLMTokenUsage(usage)
 			o.bufferingDone = true
 			o.buffered = nil
 			return
 		}
 	}
 }
+
+// sseEventEnd returns the index right after the blank line terminating the first event in b,
+// or -1 if b has no complete event yet.
+//
+// Any of "\r\n", "\n" and "\r" ends a line as per the SSE specification, so the blank line can be e.g. "\n\n",
+// "\r\n\r\n" or "\r\r". The "\r" at the end of b is not taken as the line ending until the next byte is known,
+// since it can be the first half of "\r\n".
+func sseEventEnd(b []byte) int {
+	lineStart := 0
+	for i := 0; i < len(b); i++ {
+		var n int
+		switch b[i] {
+		case '\n':
+			n = 1
+		case '\r':
+			if i+1 == len(b) {
+				return -1
+			}
+			n = 1
+			if b[i+1] == '\n' {
+				n = 2
+			}
+		default:
+			continue
+		}
+		if i == lineStart {
+			return i + n
+		}
+		i += n - 1
+		lineStart = i + 1
+	}
+	return -1
+}
+
+// responseBodyWithoutUsageChunk returns the body mutation of the streamed response that removes the usage chunk,
+// and the usage extracted from it. The incomplete event at the end of the body is held back until its rest arrives.
+func (o *openAIToOpenAITranslatorV1ChatCompletion) responseBodyWithoutUsageChunk(body io.Reader, endOfStream bool) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, tokenUsage LLMTokenUsage, err error,
+) {
+	buf, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, tokenUsage, fmt.Errorf("failed to read body: %w", err)
+	}
+	o.buffered = append(o.buffered, buf...)
+	var out []byte
+	for {
+		i := sseEventEnd(o.buffered)
+		if i == -1 {
+			break
+		}
+		event := o.buffered[:i]
+		o.buffered = o.buffered[i:]
+		if usage := usageChunkUsage(event); usage != nil {
+			tokenUsage = openAIUsageToLLMTokenUsage(usage)
+			continue
+		}
+		out = append(out, event...)
+	}
+	if endOfStream {
+		out = append(out, o.buffered...)
+		o.buffered = nil
+	}
+	return nil, &extprocv3.BodyMutation{Mutation: &extprocv3.BodyMutation_Body{Body: out}}, tokenUsage, nil
+}
+
+// usageChunkUsage returns the usage of the event if it is the extra chunk only carrying the usage, i.e. without any choices.
+// Otherwise, this returns nil.
+func usageChunkUsage(event []byte) *openai.ChatCompletionResponseUsage {
+	line := bytes.TrimSpace(event)
+	if !bytes.HasPrefix(line, dataPrefix) {
+		return nil
+	}
+	var chunk openai.ChatCompletionResponseChunk
+	if err := json.Unmarshal(bytes.TrimPrefix(line, dataPrefix), &chunk); err != nil {
+		return nil
+	}
+	if chunk.Usage == nil || len(chunk.Choices) > 0 {
+		return nil
+	}
+	return chunk.Usage
+}
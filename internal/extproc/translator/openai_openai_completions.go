@@ -0,0 +1,49 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+// NewCompletionOpenAIToOpenAITranslator implements [Factory] for OpenAI to OpenAI translation for the legacy completions.
+//
+// When modelNameOverride is not empty, the model in the request body is rewritten to it.
+func NewCompletionOpenAIToOpenAITranslator(modelNameOverride string) OpenAICompletionTranslator {
+	return &openAIToOpenAITranslatorV1Completion{
+		openAIToOpenAITranslatorV1ChatCompletion: openAIToOpenAITranslatorV1ChatCompletion{modelNameOverride: modelNameOverride},
+	}
+}
+
+// openAIToOpenAITranslatorV1Completion implements [OpenAICompletionTranslator] for /v1/completions.
+//
+// The usage object of the completions is the same as the chat completions both in the buffered and the streamed
+// responses, so the response handling is delegated to the chat completion translator.
+type openAIToOpenAITranslatorV1Completion struct {
+	openAIToOpenAITranslatorV1ChatCompletion
+}
+
+// RequestBody implements [OpenAICompletionTranslator.RequestBody].
+func (o *openAIToOpenAITranslatorV1Completion) RequestBody(raw []byte, req *openai.CompletionRequest) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, override *extprocv3http.ProcessingMode, err error,
+) {
+	if req.Stream {
+		o.stream = true
+		override = &extprocv3http.ProcessingMode{
+			ResponseHeaderMode: extprocv3http.ProcessingMode_SEND,
+			ResponseBodyMode:   extprocv3http.ProcessingMode_STREAMED,
+		}
+	}
+	if o.modelNameOverride != "" {
+		if headerMutation, bodyMutation, err = patchRequestBody(raw, requestBodyPatch{path: "model", value: o.modelNameOverride}); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return headerMutation, bodyMutation, override, nil
+}
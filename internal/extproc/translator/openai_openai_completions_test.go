@@ -0,0 +1,75 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
+	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func TestOpenAIToOpenAITranslatorV1CompletionRequestBody(t *testing.T) {
+	for _, stream := range []bool{true, false} {
+		o := &openAIToOpenAITranslatorV1Completion{}
+		hm, bm, mode, err := o.RequestBody(nil, &openai.CompletionRequest{Stream: stream})
+		require.NoError(t, err)
+		require.Nil(t, hm)
+		require.Nil(t, bm)
+		if stream {
+			require.Equal(t, extprocv3http.ProcessingMode_STREAMED, mode.ResponseBodyMode)
+			require.True(t, o.stream)
+		} else {
+			require.Nil(t, mode)
+		}
+	}
+}
+
+func TestOpenAIToOpenAITranslatorV1CompletionRequestBody_modelNameOverride(t *testing.T) {
+	o := NewCompletionOpenAIToOpenAITranslator("gpt-3.5-turbo-instruct")
+	hm, bm, _, err := o.RequestBody([]byte(`{"model":"alias","prompt":"hi","top_k":20}`), &openai.CompletionRequest{Model: "alias"})
+	require.NoError(t, err)
+	body := bm.Mutation.(*extprocv3.BodyMutation_Body).Body
+	// The fields unknown to the parsed request are kept.
+	require.JSONEq(t, `{"model":"gpt-3.5-turbo-instruct","prompt":"hi","top_k":20}`, string(body))
+	require.Equal(t, "content-length", hm.SetHeaders[0].Header.Key)
+}
+
+func TestOpenAIToOpenAITranslatorV1CompletionResponseBody(t *testing.T) {
+	t.Run("buffered", func(t *testing.T) {
+		o := &openAIToOpenAITranslatorV1Completion{}
+		body := `{"object":"text_completion","choices":[{"text":"hi","index":0,"finish_reason":"stop"}],` +
+			`"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}`
+		_, _, usage, err := o.ResponseBody(nil, strings.NewReader(body), true)
+		require.NoError(t, err)
+		require.Equal(t, LLMTokenUsage{InputTokens: 5, OutputTokens: 7, TotalTokens: 12}, usage)
+	})
+	t.Run("streaming", func(t *testing.T) {
+		o := &openAIToOpenAITranslatorV1Completion{}
+		o.stream = true
+		chunks := []string{
+			"data: {\"object\":\"text_completion\",\"choices\":[{\"text\":\"hi\",\"index\":0}]}\n\n",
+			"data: {\"object\":\"text_completion\",\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":1,\"total_tokens\":6}}\n\n",
+			"data: [DONE]\n\n",
+		}
+		var total LLMTokenUsage
+		for i, c := range chunks {
+			hm, bm, usage, err := o.ResponseBody(nil, bytes.NewBufferString(c), i == len(chunks)-1)
+			require.NoError(t, err)
+			require.Nil(t, hm)
+			require.Nil(t, bm)
+			total.InputTokens += usage.InputTokens
+			total.OutputTokens += usage.OutputTokens
+			total.TotalTokens += usage.TotalTokens
+		}
+		require.Equal(t, LLMTokenUsage{InputTokens: 5, OutputTokens: 1, TotalTokens: 6}, total)
+	})
+}
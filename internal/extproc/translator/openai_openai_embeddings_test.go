@@ -0,0 +1,61 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+)
+
+func TestOpenAIToOpenAITranslatorV1EmbeddingRequestBody(t *testing.T) {
+	o := &openAIToOpenAITranslatorV1Embedding{}
+	hm, bm, err := o.RequestBody(nil, &openai.EmbeddingRequest{Model: "text-embedding-3-small"})
+	require.NoError(t, err)
+	require.Nil(t, hm)
+	require.Nil(t, bm)
+}
+
+func TestOpenAIToOpenAITranslatorV1EmbeddingResponseBody(t *testing.T) {
+	t.Run("invalid json", func(t *testing.T) {
+		o := &openAIToOpenAITranslatorV1Embedding{}
+		_, _, _, err := o.ResponseBody(nil, bytes.NewBuffer([]byte("invalid")))
+		require.ErrorContains(t, err, "failed to unmarshal body")
+	})
+	t.Run("valid body", func(t *testing.T) {
+		var resp openai.EmbeddingResponse
+		resp.Usage.PromptTokens = 8
+		resp.Usage.TotalTokens = 8
+		body, err := json.Marshal(resp)
+		require.NoError(t, err)
+		o := &openAIToOpenAITranslatorV1Embedding{}
+		hm, bm, usedToken, err := o.ResponseBody(nil, bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Nil(t, hm)
+		require.Nil(t, bm)
+		require.Equal(t, LLMTokenUsage{InputTokens: 8, TotalTokens: 8}, usedToken)
+	})
+	t.Run("error status", func(t *testing.T) {
+		o := &openAIToOpenAITranslatorV1Embedding{}
+		hm, bm, usedToken, err := o.ResponseBody(map[string]string{
+			statusHeaderName:      "503",
+			contentTypeHeaderName: "text/plain",
+		}, strings.NewReader("service not available"))
+		require.NoError(t, err)
+		require.NotNil(t, hm)
+		require.NotNil(t, bm)
+		require.Equal(t, LLMTokenUsage{}, usedToken)
+		var openAIError openai.Error
+		require.NoError(t, json.Unmarshal(bm.GetBody(), &openAIError))
+		require.Equal(t, openAIBackendError, openAIError.Error.Type)
+		require.Equal(t, "service not available", openAIError.Error.Message)
+	})
+}
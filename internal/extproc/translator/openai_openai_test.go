@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"testing"
 
 	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
@@ -29,7 +30,7 @@ func TestOpenAIToOpenAITranslatorV1ChatCompletionRequestBody(t *testing.T) {
 				originalReq := &openai.ChatCompletionRequest{Model: "foo-bar-ai", Stream: stream}
 
 				o := &openAIToOpenAITranslatorV1ChatCompletion{}
-				hm, bm, mode, err := o.RequestBody(originalReq)
+				hm, bm, mode, err := o.RequestBody(nil, originalReq)
 				require.Nil(t, bm)
 				require.NoError(t, err)
 				require.Equal(t, stream, o.stream)
@@ -45,6 +46,75 @@ func TestOpenAIToOpenAITranslatorV1ChatCompletionRequestBody(t *testing.T) {
 			})
 		}
 	})
+	t.Run("model name override", func(t *testing.T) {
+		originalReq := &openai.ChatCompletionRequest{Model: "claude-sonnet", Stream: true}
+		o := NewChatCompletionOpenAIToOpenAITranslator("claude-3-5-sonnet-latest", false)
+		hm, bm, mode, err := o.RequestBody([]byte(`{"model":"claude-sonnet","stream":true}`), originalReq)
+		require.NoError(t, err)
+		require.NotNil(t, mode)
+		require.Equal(t, "claude-sonnet", originalReq.Model)
+
+		newBody := bm.Mutation.(*extprocv3.BodyMutation_Body).Body
+		require.JSONEq(t, `{"model":"claude-3-5-sonnet-latest","stream":true}`, string(newBody))
+		require.Len(t, hm.SetHeaders, 1)
+		require.Equal(t, "content-length", hm.SetHeaders[0].Header.Key)
+		require.Equal(t, strconv.Itoa(len(newBody)), string(hm.SetHeaders[0].Header.RawValue))
+	})
+	t.Run("unknown fields are kept", func(t *testing.T) {
+		raw := `{"model":"gpt","stream":true,"reasoning_effort":"low","metadata":{"foo":"bar"},"store":true,` +
+			`"service_tier":"flex","modalities":["text"],"top_k":20,"stream_options":{"include_usage":false,"foo":1}}`
+		var req openai.ChatCompletionRequest
+		require.NoError(t, json.Unmarshal([]byte(raw), &req))
+		o := NewChatCompletionOpenAIToOpenAITranslator("gpt-4o", true)
+		_, bm, _, err := o.RequestBody([]byte(raw), &req)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"model":"gpt-4o","stream":true,"reasoning_effort":"low","metadata":{"foo":"bar"},"store":true,`+
+			`"service_tier":"flex","modalities":["text"],"top_k":20,"stream_options":{"include_usage":true,"foo":1}}`,
+			string(bm.Mutation.(*extprocv3.BodyMutation_Body).Body))
+	})
+	t.Run("force stream usage", func(t *testing.T) {
+		for _, tc := range []struct {
+			name          string
+			req           *openai.ChatCompletionRequest
+			expMutation   bool
+			expStripUsage bool
+		}{
+			{name: "non-streaming", req: &openai.ChatCompletionRequest{Model: "gpt-4o"}},
+			{
+				name: "usage not requested", req: &openai.ChatCompletionRequest{Model: "gpt-4o", Stream: true},
+				expMutation: true, expStripUsage: true,
+			},
+			{
+				name: "usage options without include_usage",
+				req: &openai.ChatCompletionRequest{
+					Model: "gpt-4o", Stream: true, StreamOptions: &openai.StreamOptions{},
+				},
+				expMutation: true, expStripUsage: true,
+			},
+			{
+				name: "usage requested",
+				req: &openai.ChatCompletionRequest{
+					Model: "gpt-4o", Stream: true, StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+				},
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				o := NewChatCompletionOpenAIToOpenAITranslator("", true).(*openAIToOpenAITranslatorV1ChatCompletion)
+				raw, err := json.Marshal(tc.req)
+				require.NoError(t, err)
+				_, bm, _, err := o.RequestBody(raw, tc.req)
+				require.NoError(t, err)
+				require.Equal(t, tc.expStripUsage, o.stripUsageChunk)
+				if !tc.expMutation {
+					require.Nil(t, bm)
+					return
+				}
+				var req openai.ChatCompletionRequest
+				require.NoError(t, json.Unmarshal(bm.Mutation.(*extprocv3.BodyMutation_Body).Body, &req))
+				require.Equal(t, &openai.StreamOptions{IncludeUsage: true}, req.StreamOptions)
+			})
+		}
+	})
 }
 
 func TestOpenAIToOpenAITranslator_ResponseError(t *testing.T) {
@@ -173,6 +243,57 @@ data: [DONE]
 			}
 		}
 	})
+	t.Run("streaming without usage chunk", func(t *testing.T) {
+		const (
+			content = `data: {"id":"chatcmpl-foo","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":"stop"}],"usage":null}
+
+`
+			usage = `data: {"id":"chatcmpl-foo","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":13,"completion_tokens":12,"total_tokens":25}}
+
+`
+			done = "data: [DONE]\n\n"
+		)
+		wholeBody := []byte(content + usage + done)
+		o := &openAIToOpenAITranslatorV1ChatCompletion{stream: true, stripUsageChunk: true}
+		var out []byte
+		var tokenUsage LLMTokenUsage
+		for i := 0; i < len(wholeBody); i++ {
+			hm, bm, u, err := o.ResponseBody(nil, bytes.NewReader(wholeBody[i:i+1]), i == len(wholeBody)-1)
+			require.NoError(t, err)
+			require.Nil(t, hm)
+			out = append(out, bm.Mutation.(*extprocv3.BodyMutation_Body).Body...)
+			tokenUsage.InputTokens += u.InputTokens
+			tokenUsage.OutputTokens += u.OutputTokens
+			tokenUsage.TotalTokens += u.TotalTokens
+		}
+		require.Equal(t, content+done, string(out))
+		require.Equal(t, LLMTokenUsage{InputTokens: 13, OutputTokens: 12, TotalTokens: 25}, tokenUsage)
+
+		// Every form of the line endings separates the events, where each event is split across two calls.
+		for _, eol := range []string{"\n", "\r\n", "\r"} {
+			t.Run(strconv.Quote(eol), func(t *testing.T) {
+				replacer := strings.NewReplacer("\n", eol)
+				content, usage, done := replacer.Replace(content), replacer.Replace(usage), replacer.Replace(done)
+				o := &openAIToOpenAITranslatorV1ChatCompletion{stream: true, stripUsageChunk: true}
+				var out []byte
+				var tokenUsage LLMTokenUsage
+				for _, chunk := range []string{content[:10], content[10:] + usage[:20], usage[20:] + done[:len(done)-1], done[len(done)-1:]} {
+					_, bm, u, err := o.ResponseBody(nil, strings.NewReader(chunk), chunk == done[len(done)-1:])
+					require.NoError(t, err)
+					out = append(out, bm.Mutation.(*extprocv3.BodyMutation_Body).Body...)
+					tokenUsage.TotalTokens += u.TotalTokens
+				}
+				require.Equal(t, content+done, string(out))
+				require.Equal(t, uint32(25), tokenUsage.TotalTokens)
+			})
+		}
+
+		// The incomplete event is flushed at the end of the stream.
+		o = &openAIToOpenAITranslatorV1ChatCompletion{stream: true, stripUsageChunk: true}
+		_, bm, _, err := o.ResponseBody(nil, bytes.NewReader([]byte("data: [DONE]")), true)
+		require.NoError(t, err)
+		require.Equal(t, "data: [DONE]", string(bm.Mutation.(*extprocv3.BodyMutation_Body).Body))
+	})
 	t.Run("non-streaming", func(t *testing.T) {
 		t.Run("invalid body", func(t *testing.T) {
 			o := &openAIToOpenAITranslatorV1ChatCompletion{}
@@ -189,6 +310,16 @@ data: [DONE]
 			require.NoError(t, err)
 			require.Equal(t, LLMTokenUsage{TotalTokens: 42}, usedToken)
 		})
+		t.Run("cached and reasoning tokens", func(t *testing.T) {
+			body := `{"usage":{"prompt_tokens":100,"completion_tokens":50,"total_tokens":150,
+"prompt_tokens_details":{"cached_tokens":80},"completion_tokens_details":{"reasoning_tokens":30}}}`
+			o := &openAIToOpenAITranslatorV1ChatCompletion{}
+			_, _, usedToken, err := o.ResponseBody(nil, bytes.NewBuffer([]byte(body)), false)
+			require.NoError(t, err)
+			require.Equal(t, LLMTokenUsage{
+				InputTokens: 100, OutputTokens: 50, TotalTokens: 150, CachedInputTokens: 80, ReasoningTokens: 30,
+			}, usedToken)
+		})
 	})
 }
 
@@ -235,3 +366,25 @@ func TestExtractUsageFromBufferEvent(t *testing.T) {
 		require.NotNil(t, o.buffered)
 	})
 }
+
+func Test_sseEventEnd(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		exp int
+	}{
+		{in: "", exp: -1},
+		{in: "data: a\n", exp: -1},
+		{in: "data: a\n\n", exp: 9},
+		{in: "data: a\r\n\r\ndata: b\r\n\r\n", exp: 11},
+		{in: "data: a\r\rdata: b", exp: 9},
+		{in: "data: a\r\n\n", exp: 10},
+		{in: "data: a\nid: 1\n\n", exp: 15},
+		// The trailing "\r" can be followed by "\n" in the next body.
+		{in: "data: a\r\n\r", exp: -1},
+		{in: "\n", exp: 1},
+	} {
+		t.Run(strconv.Quote(tc.in), func(t *testing.T) {
+			require.Equal(t, tc.exp, sseEventEnd([]byte(tc.in)))
+		})
+	}
+}
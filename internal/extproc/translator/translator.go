@@ -12,7 +12,9 @@ import (
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extprocv3http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_proc/v3"
 	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/tidwall/sjson"
 
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
 	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
 )
 
@@ -38,10 +40,12 @@ func isGoodStatusCode(code int) bool {
 // This is created per request and is not thread-safe.
 type OpenAIChatCompletionTranslator interface {
 	// RequestBody translates the request body.
+	// 	- `raw` is the original request body. The translators to the OpenAI compatible backends patch it instead of
+	// 	  marshaling `body` so that the fields unknown to [openai.ChatCompletionRequest] are kept.
 	// 	- `body` is the request body parsed into the [openai.ChatCompletionRequest].
 	//	- This returns `headerMutation` and `bodyMutation` that can be nil to indicate no mutation.
 	//  - This returns `override` that to change the processing mode. This is used to process streaming requests properly.
-	RequestBody(body *openai.ChatCompletionRequest) (
+	RequestBody(raw []byte, body *openai.ChatCompletionRequest) (
 		headerMutation *extprocv3.HeaderMutation,
 		bodyMutation *extprocv3.BodyMutation,
 		override *extprocv3http.ProcessingMode,
@@ -80,6 +84,139 @@ type OpenAIChatCompletionTranslator interface {
 	)
 }
 
+// OpenAICompletionTranslator translates the request and response messages between the client and the backend API schemas
+// for the legacy /v1/completions endpoint of OpenAI.
+//
+// This is created per request and is not thread-safe.
+type OpenAICompletionTranslator interface {
+	// RequestBody translates the request body.
+	// 	- `raw` is the original request body. The translators to the OpenAI compatible backends patch it instead of
+	// 	  marshaling `body` so that the fields unknown to [openai.CompletionRequest] are kept.
+	// 	- `body` is the request body parsed into the [openai.CompletionRequest].
+	//	- This returns `headerMutation` and `bodyMutation` that can be nil to indicate no mutation.
+	//  - This returns `override` that to change the processing mode. This is used to process streaming requests properly.
+	RequestBody(raw []byte, body *openai.CompletionRequest) (
+		headerMutation *extprocv3.HeaderMutation,
+		bodyMutation *extprocv3.BodyMutation,
+		override *extprocv3http.ProcessingMode,
+		err error,
+	)
+
+	// ResponseHeaders translates the response headers.
+	// 	- `headers` is the response headers.
+	//	- This returns `headerMutation` that can be nil to indicate no mutation.
+	ResponseHeaders(headers map[string]string) (
+		headerMutation *extprocv3.HeaderMutation,
+		err error,
+	)
+
+	// ResponseBody translates the response body.
+	// 	- `body` is the response body either chunk or the entire body, depending on the context.
+	//	- This returns `headerMutation` and `bodyMutation` that can be nil to indicate no mutation.
+	//  - This returns `tokenUsage` that is extracted from the body and will be used to do token rate limiting.
+	ResponseBody(respHeaders map[string]string, body io.Reader, endOfStream bool) (
+		headerMutation *extprocv3.HeaderMutation,
+		bodyMutation *extprocv3.BodyMutation,
+		tokenUsage LLMTokenUsage,
+		err error,
+	)
+}
+
+// AWSBedrockConverseTranslator translates the request and response messages between the client and the backend API schemas
+// for the Converse and ConverseStream APIs of AWS Bedrock, i.e. /model/{modelId}/converse(-stream) endpoints.
+//
+// This is created per request and is not thread-safe.
+type AWSBedrockConverseTranslator interface {
+	// RequestBody translates the request body.
+	// 	- `body` is the request body parsed into the [awsbedrock.ConverseInput] whose ModelID is populated from the path.
+	// 	- `stream` is true when the request is made to the ConverseStream API.
+	//	- This returns `headerMutation` and `bodyMutation` that can be nil to indicate no mutation.
+	//  - This returns `override` that to change the processing mode. This is used to process streaming requests properly.
+	RequestBody(body *awsbedrock.ConverseInput, stream bool) (
+		headerMutation *extprocv3.HeaderMutation,
+		bodyMutation *extprocv3.BodyMutation,
+		override *extprocv3http.ProcessingMode,
+		err error,
+	)
+
+	// ResponseHeaders translates the response headers.
+	// 	- `headers` is the response headers.
+	//	- This returns `headerMutation` that can be nil to indicate no mutation.
+	ResponseHeaders(headers map[string]string) (
+		headerMutation *extprocv3.HeaderMutation,
+		err error,
+	)
+
+	// ResponseBody translates the response body.
+	// 	- `body` is the response body either chunk or the entire body, depending on the context.
+	//	- This returns `headerMutation` and `bodyMutation` that can be nil to indicate no mutation.
+	//  - This returns `tokenUsage` that is extracted from the body and will be used to do token rate limiting.
+	ResponseBody(respHeaders map[string]string, body io.Reader, endOfStream bool) (
+		headerMutation *extprocv3.HeaderMutation,
+		bodyMutation *extprocv3.BodyMutation,
+		tokenUsage LLMTokenUsage,
+		err error,
+	)
+}
+
+// OpenAIEmbeddingTranslator translates the request and response messages between the client and the backend API schemas
+// for /v1/embeddings endpoint of OpenAI.
+//
+// This is created per request and is not thread-safe.
+type OpenAIEmbeddingTranslator interface {
+	// RequestBody translates the request body.
+	// 	- `raw` is the original request body. The translators to the OpenAI compatible backends patch it instead of
+	// 	  marshaling `body` so that the fields unknown to [openai.EmbeddingRequest] are kept.
+	// 	- `body` is the request body parsed into the [openai.EmbeddingRequest].
+	//	- This returns `headerMutation` and `bodyMutation` that can be nil to indicate no mutation.
+	RequestBody(raw []byte, body *openai.EmbeddingRequest) (
+		headerMutation *extprocv3.HeaderMutation,
+		bodyMutation *extprocv3.BodyMutation,
+		err error,
+	)
+
+	// ResponseBody translates the response body. Unlike chat completions, embeddings are never streamed,
+	// so `body` is always the entire body.
+	//	- This returns `headerMutation` and `bodyMutation` that can be nil to indicate no mutation.
+	//  - This returns `tokenUsage` that is extracted from the body and will be used to do token rate limiting.
+	ResponseBody(respHeaders map[string]string, body io.Reader) (
+		headerMutation *extprocv3.HeaderMutation,
+		bodyMutation *extprocv3.BodyMutation,
+		tokenUsage LLMTokenUsage,
+		err error,
+	)
+}
+
+// chatCompletionMaxTokens returns the max_tokens of the request, or the max_completion_tokens if not set,
+// for the backends having only one field for the maximum number of the output tokens.
+func chatCompletionMaxTokens(req *openai.ChatCompletionRequest) *int64 {
+	if req.MaxTokens != nil {
+		return req.MaxTokens
+	}
+	return req.MaxCompletionTokens
+}
+
+// requestBodyPatch is the value set at the path of the JSON request body by [patchRequestBody].
+type requestBodyPatch struct {
+	path  string
+	value any
+}
+
+// patchRequestBody sets the values at the paths of the original JSON request body, keeping the other fields as is.
+func patchRequestBody(original []byte, patches ...requestBodyPatch) (
+	headerMutation *extprocv3.HeaderMutation, bodyMutation *extprocv3.BodyMutation, err error,
+) {
+	mut := &extprocv3.BodyMutation_Body{Body: original}
+	for _, p := range patches {
+		if mut.Body, err = sjson.SetBytes(mut.Body, p.path, p.value); err != nil {
+			return nil, nil, fmt.Errorf("failed to set %s in body: %w", p.path, err)
+		}
+	}
+	headerMutation = &extprocv3.HeaderMutation{}
+	setContentLength(headerMutation, mut.Body)
+	return headerMutation, &extprocv3.BodyMutation{Mutation: mut}, nil
+}
+
 func setContentLength(headers *extprocv3.HeaderMutation, body []byte) {
 	headers.SetHeaders = append(headers.SetHeaders, &corev3.HeaderValueOption{
 		Header: &corev3.HeaderValue{
@@ -90,6 +227,9 @@ func setContentLength(headers *extprocv3.HeaderMutation, body []byte) {
 }
 
 // LLMTokenUsage represents the token usage reported usually by the backend API in the response body.
+//
+// The usage is normalized to the OpenAI convention regardless of the backend: the input tokens include the cached
+// and the cache write input tokens, and the output tokens include the reasoning tokens.
 type LLMTokenUsage struct {
 	// InputTokens is the number of tokens consumed from the input.
 	InputTokens uint32
@@ -97,4 +237,66 @@ type LLMTokenUsage struct {
 	OutputTokens uint32
 	// TotalTokens is the total number of tokens consumed.
 	TotalTokens uint32
+	// CachedInputTokens is the number of the input tokens read from the cache.
+	CachedInputTokens uint32
+	// CacheWriteInputTokens is the number of the input tokens written to the cache.
+	CacheWriteInputTokens uint32
+	// ReasoningTokens is the number of the output tokens generated for reasoning.
+	ReasoningTokens uint32
+}
+
+// openAIUsageToLLMTokenUsage converts the usage in the OpenAI response to the LLMTokenUsage.
+func openAIUsageToLLMTokenUsage(usage *openai.ChatCompletionResponseUsage) LLMTokenUsage {
+	tokenUsage := LLMTokenUsage{
+		InputTokens:  uint32(usage.PromptTokens),     //nolint:gosec
+		OutputTokens: uint32(usage.CompletionTokens), //nolint:gosec
+		TotalTokens:  uint32(usage.TotalTokens),      //nolint:gosec
+	}
+	if d := usage.PromptTokensDetails; d != nil {
+		tokenUsage.CachedInputTokens = uint32(d.CachedTokens) //nolint:gosec
+	}
+	if d := usage.CompletionTokensDetails; d != nil {
+		tokenUsage.ReasoningTokens = uint32(d.ReasoningTokens) //nolint:gosec
+	}
+	return tokenUsage
+}
+
+// bedrockUsageToLLMTokenUsage converts the usage in the AWS Bedrock response to the LLMTokenUsage.
+// The input tokens of AWS Bedrock do not include the cache read and write tokens unlike OpenAI, so they are added.
+func bedrockUsageToLLMTokenUsage(usage *awsbedrock.TokenUsage) LLMTokenUsage {
+	return LLMTokenUsage{
+		InputTokens:           uint32(usage.InputTokens + usage.CacheReadInputTokens + usage.CacheWriteInputTokens), //nolint:gosec
+		OutputTokens:          uint32(usage.OutputTokens),                                                           //nolint:gosec
+		TotalTokens:           uint32(usage.TotalTokens),                                                            //nolint:gosec
+		CachedInputTokens:     uint32(usage.CacheReadInputTokens),                                                   //nolint:gosec
+		CacheWriteInputTokens: uint32(usage.CacheWriteInputTokens),                                                  //nolint:gosec
+	}
+}
+
+// openAIUsageToBedrockUsage converts the usage in the OpenAI response to the usage in the AWS Bedrock response.
+func openAIUsageToBedrockUsage(usage *openai.ChatCompletionResponseUsage) *awsbedrock.TokenUsage {
+	bedrockUsage := &awsbedrock.TokenUsage{
+		InputTokens:  usage.PromptTokens,
+		OutputTokens: usage.CompletionTokens,
+		TotalTokens:  usage.TotalTokens,
+	}
+	if d := usage.PromptTokensDetails; d != nil {
+		bedrockUsage.InputTokens -= d.CachedTokens
+		bedrockUsage.CacheReadInputTokens = d.CachedTokens
+	}
+	return bedrockUsage
+}
+
+// bedrockUsageToOpenAIUsage converts the usage in the AWS Bedrock response to the usage in the OpenAI response.
+func bedrockUsageToOpenAIUsage(usage *awsbedrock.TokenUsage) openai.ChatCompletionResponseUsage {
+	tokenUsage := bedrockUsageToLLMTokenUsage(usage)
+	openAIUsage := openai.ChatCompletionResponseUsage{
+		PromptTokens:     int(tokenUsage.InputTokens),
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+	if usage.CacheReadInputTokens > 0 {
+		openAIUsage.PromptTokensDetails = &openai.PromptTokensDetails{CachedTokens: usage.CacheReadInputTokens}
+	}
+	return openAIUsage
 }
@@ -10,6 +10,9 @@ import (
 
 	extprocv3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
 )
 
 func TestIsGoodStatusCode(t *testing.T) {
@@ -32,3 +35,29 @@ func TestSetContentLength(t *testing.T) {
 	require.Len(t, hm.SetHeaders, 1)
 	require.Equal(t, "4", string(hm.SetHeaders[0].Header.RawValue))
 }
+
+func TestChatCompletionMaxTokens(t *testing.T) {
+	maxTokens, maxCompletionTokens := int64(10), int64(20)
+	require.Nil(t, chatCompletionMaxTokens(&openai.ChatCompletionRequest{}))
+	require.Equal(t, &maxTokens, chatCompletionMaxTokens(&openai.ChatCompletionRequest{MaxTokens: &maxTokens, MaxCompletionTokens: &maxCompletionTokens}))
+	require.Equal(t, &maxCompletionTokens, chatCompletionMaxTokens(&openai.ChatCompletionRequest{MaxCompletionTokens: &maxCompletionTokens}))
+}
+
+func TestBedrockUsage(t *testing.T) {
+	usage := &awsbedrock.TokenUsage{
+		InputTokens: 10, OutputTokens: 20, TotalTokens: 130, CacheReadInputTokens: 80, CacheWriteInputTokens: 20,
+	}
+	// The cache read and write tokens are included in the input tokens.
+	require.Equal(t, LLMTokenUsage{
+		InputTokens: 110, OutputTokens: 20, TotalTokens: 130, CachedInputTokens: 80, CacheWriteInputTokens: 20,
+	}, bedrockUsageToLLMTokenUsage(usage))
+	openAIUsage := bedrockUsageToOpenAIUsage(usage)
+	require.Equal(t, openai.ChatCompletionResponseUsage{
+		PromptTokens: 110, CompletionTokens: 20, TotalTokens: 130,
+		PromptTokensDetails: &openai.PromptTokensDetails{CachedTokens: 80},
+	}, openAIUsage)
+	// The cache write tokens are not known to OpenAI.
+	require.Equal(t, &awsbedrock.TokenUsage{
+		InputTokens: 30, OutputTokens: 20, TotalTokens: 130, CacheReadInputTokens: 80,
+	}, openAIUsageToBedrockUsage(&openAIUsage))
+}
@@ -0,0 +1,234 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+
+	"github.com/envoyproxy/ai-gateway/internal/apischema/awsbedrock"
+	"github.com/envoyproxy/ai-gateway/internal/apischema/openai"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+// usageEstimator estimates the token usage of the request with the tokenizer for the backends that do not
+// return the usage, e.g. some builds of the self-hosted inference servers or the streams without the usage chunk.
+//
+// The response body in the input schema is observed as it is sent to the client. The output of the streamed
+// response is counted per event so that the usage of the stream terminated in the middle is also available.
+type usageEstimator struct {
+	tokenizer   tokenizer.Tokenizer
+	stream      bool
+	inputTokens int
+	output      responseOutput
+	// buffered is the whole body of the non-streamed response, or the incomplete event of the streamed response.
+	buffered []byte
+	// outputTokens is the number of the output tokens of the streamed response observed so far.
+	outputTokens int
+}
+
+// responseOutput extracts the output text of the response in the input schema of an endpoint.
+// The zero value is for the endpoints without any output tokens, e.g. the embeddings.
+type responseOutput struct {
+	// nextEvent returns the payload of the first complete event of the streamed response and the rest of the
+	// given buffer, or ok=false if the buffer does not have a complete event yet.
+	nextEvent func(buf []byte) (payload, rest []byte, ok bool)
+	// eventText returns the output text of the payload of an event of the streamed response.
+	eventText func(payload []byte) string
+	// bodyText returns the output text of the non-streamed response.
+	bodyText func(body []byte) string
+}
+
+var (
+	// chatCompletionOutput is the [responseOutput] of the OpenAI chat completions.
+	chatCompletionOutput = responseOutput{nextEvent: nextSSEData, eventText: chatCompletionChunkText, bodyText: chatCompletionText}
+	// completionOutput is the [responseOutput] of the OpenAI completions.
+	completionOutput = responseOutput{nextEvent: nextSSEData, eventText: completionText, bodyText: completionText}
+	// converseOutput is the [responseOutput] of the AWS Bedrock Converse.
+	converseOutput = responseOutput{nextEvent: nextAWSEvent, eventText: converseStreamEventText, bodyText: converseText}
+)
+
+// newUsageEstimator creates the usageEstimator of the request with the estimated number of the input tokens.
+func newUsageEstimator(t tokenizer.Tokenizer, stream bool, inputTokens int, output responseOutput) *usageEstimator {
+	return &usageEstimator{tokenizer: t, stream: stream, inputTokens: inputTokens, output: output}
+}
+
+// observeResponseBody observes the chunk of the response body sent to the client.
+func (e *usageEstimator) observeResponseBody(body []byte) {
+	if e.output.bodyText == nil {
+		return
+	}
+	e.buffered = append(e.buffered, body...)
+	if !e.stream {
+		return
+	}
+	for {
+		payload, rest, ok := e.output.nextEvent(e.buffered)
+		if !ok {
+			return
+		}
+		e.buffered = rest
+		if text := e.output.eventText(payload); text != "" {
+			e.outputTokens += e.tokenizer.CountTokens(text)
+		}
+	}
+}
+
+// usage returns the estimated token usage of the response observed so far.
+func (e *usageEstimator) usage() translator.LLMTokenUsage {
+	outputTokens := e.outputTokens
+	if !e.stream && e.output.bodyText != nil {
+		outputTokens = e.tokenizer.CountTokens(e.output.bodyText(e.buffered))
+	}
+	input, output := uint32(e.inputTokens), uint32(outputTokens) //nolint:gosec
+	return translator.LLMTokenUsage{InputTokens: input, OutputTokens: output, TotalTokens: input + output}
+}
+
+// fillMissing returns the given usage reported by the backend with the input and the output tokens missing in it
+// filled with the estimation, and true if any of them is filled. For example, the stream terminated in the middle
+// might only have reported the input tokens.
+func (e *usageEstimator) fillMissing(u translator.LLMTokenUsage) (translator.LLMTokenUsage, bool) {
+	estimated := e.usage()
+	var filled bool
+	if u.InputTokens == 0 && estimated.InputTokens != 0 {
+		u.InputTokens, filled = estimated.InputTokens, true
+	}
+	if u.OutputTokens == 0 && estimated.OutputTokens != 0 {
+		u.OutputTokens, filled = estimated.OutputTokens, true
+	}
+	if filled {
+		u.TotalTokens = max(u.TotalTokens, u.InputTokens+u.OutputTokens)
+	}
+	return u, filled
+}
+
+// nextSSEData implements [responseOutput.nextEvent] for the server-sent events, where the payload is the data line.
+// The other lines are returned as the empty payloads.
+func nextSSEData(buf []byte) (payload, rest []byte, ok bool) {
+	i := bytes.IndexByte(buf, '\n')
+	if i == -1 {
+		return nil, buf, false
+	}
+	line := bytes.TrimSuffix(buf[:i], []byte("\r"))
+	payload, _ = bytes.CutPrefix(line, []byte("data: "))
+	if len(payload) == len(line) {
+		payload = nil
+	}
+	return payload, buf[i+1:], true
+}
+
+// nextAWSEvent implements [responseOutput.nextEvent] for the AWS event stream.
+func nextAWSEvent(buf []byte) (payload, rest []byte, ok bool) {
+	r := bytes.NewReader(buf)
+	msg, err := eventstream.NewDecoder().Decode(r, nil)
+	if err != nil {
+		return nil, buf, false
+	}
+	return msg.Payload, buf[len(buf)-r.Len():], true
+}
+
+// chatCompletionChunkText returns the output text of the streamed chat completion chunk.
+func chatCompletionChunkText(payload []byte) string {
+	var chunk openai.ChatCompletionResponseChunk
+	if len(payload) == 0 || json.Unmarshal(payload, &chunk) != nil {
+		return ""
+	}
+	var output strings.Builder
+	for i := range chunk.Choices {
+		delta := chunk.Choices[i].Delta
+		if delta == nil {
+			continue
+		}
+		if delta.Content != nil {
+			output.WriteString(*delta.Content)
+		}
+		for _, call := range delta.ToolCalls {
+			output.WriteString(call.Function.Name)
+			output.WriteString(call.Function.Arguments)
+		}
+	}
+	return output.String()
+}
+
+// chatCompletionText returns the output text of the chat completion response.
+func chatCompletionText(body []byte) string {
+	var resp openai.ChatCompletionResponse
+	if json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	var output strings.Builder
+	for i := range resp.Choices {
+		msg := &resp.Choices[i].Message
+		if msg.Content != nil {
+			output.WriteString(*msg.Content)
+		}
+		for _, call := range msg.ToolCalls {
+			output.WriteString(call.Function.Name)
+			output.WriteString(call.Function.Arguments)
+		}
+	}
+	return output.String()
+}
+
+// completionText returns the output text of the completion response, which is also the format of the streamed chunks.
+func completionText(body []byte) string {
+	var resp openai.CompletionResponse
+	if len(body) == 0 || json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	var output strings.Builder
+	for i := range resp.Choices {
+		output.WriteString(resp.Choices[i].Text)
+	}
+	return output.String()
+}
+
+// converseStreamEventText returns the output text of the event of the Converse stream.
+func converseStreamEventText(payload []byte) string {
+	var event awsbedrock.ConverseStreamEvent
+	if json.Unmarshal(payload, &event) != nil {
+		return ""
+	}
+	var output strings.Builder
+	if event.Start != nil && event.Start.ToolUse != nil {
+		output.WriteString(event.Start.ToolUse.Name)
+	}
+	if event.Delta != nil {
+		if event.Delta.Text != nil {
+			output.WriteString(*event.Delta.Text)
+		}
+		if event.Delta.ToolUse != nil {
+			output.WriteString(event.Delta.ToolUse.Input)
+		}
+	}
+	return output.String()
+}
+
+// converseText returns the output text of the Converse response.
+func converseText(body []byte) string {
+	var resp awsbedrock.ConverseResponse
+	if json.Unmarshal(body, &resp) != nil || resp.Output == nil {
+		return ""
+	}
+	var output strings.Builder
+	for _, block := range resp.Output.Message.Content {
+		switch {
+		case block == nil:
+		case block.Text != nil:
+			output.WriteString(*block.Text)
+		case block.ToolUse != nil:
+			output.WriteString(block.ToolUse.Name)
+			if input, err := json.Marshal(block.ToolUse.Input); err == nil {
+				output.Write(input)
+			}
+		}
+	}
+	return output.String()
+}
@@ -0,0 +1,138 @@
+// Copyright Envoy AI Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package extproc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/internal/extproc/tokenizer"
+	"github.com/envoyproxy/ai-gateway/internal/extproc/translator"
+)
+
+func Test_usageEstimator(t *testing.T) {
+	t.Run("non-streaming", func(t *testing.T) {
+		// "Hello world!" is 12 characters and "get{"city":"Tokyo"}" is 19 characters.
+		body := []byte(`{"choices":[{"message":{"role":"assistant","content":"Hello world!",
+"tool_calls":[{"id":"1","type":"function","function":{"name":"get","arguments":"{\"city\":\"Tokyo\"}"}}]}}]}`)
+		e := newUsageEstimator(tokenizer.Heuristic{}, false, 10, chatCompletionOutput)
+		e.observeResponseBody(body[:20])
+		e.observeResponseBody(body[20:])
+		require.Equal(t, translator.LLMTokenUsage{InputTokens: 10, OutputTokens: 3 + 5, TotalTokens: 18}, e.usage())
+	})
+	t.Run("streaming", func(t *testing.T) {
+		// Each chunk is counted separately: "Hello" is 5 characters and " world!" is 7 characters.
+		body := []byte(`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}
+
+data: {"choices":[{"index":0,"delta":{"content":" world!"}}]}
+
+data: {"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}
+
+data: [DONE]
+
+`)
+		e := newUsageEstimator(tokenizer.Heuristic{}, true, 10, chatCompletionOutput)
+		for i := range body {
+			e.observeResponseBody(body[i : i+1])
+		}
+		require.Equal(t, translator.LLMTokenUsage{InputTokens: 10, OutputTokens: 2 + 2, TotalTokens: 14}, e.usage())
+	})
+	t.Run("streaming with CRLF", func(t *testing.T) {
+		body := []byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hello\"}}]}\r\n\r\ndata: [DONE]\r\n\r\n")
+		e := newUsageEstimator(tokenizer.Heuristic{}, true, 10, chatCompletionOutput)
+		e.observeResponseBody(body)
+		require.Equal(t, translator.LLMTokenUsage{InputTokens: 10, OutputTokens: 2, TotalTokens: 12}, e.usage())
+	})
+	t.Run("completions", func(t *testing.T) {
+		e := newUsageEstimator(tokenizer.Heuristic{}, false, 10, completionOutput)
+		e.observeResponseBody([]byte(`{"choices":[{"text":"Hello world!","index":0}]}`))
+		require.Equal(t, translator.LLMTokenUsage{InputTokens: 10, OutputTokens: 3, TotalTokens: 13}, e.usage())
+	})
+	t.Run("completions streaming", func(t *testing.T) {
+		body := []byte(`data: {"choices":[{"text":"Hello","index":0}]}
+
+data: {"choices":[{"text":" world!","index":0}]}
+
+data: [DONE]
+
+`)
+		e := newUsageEstimator(tokenizer.Heuristic{}, true, 10, completionOutput)
+		for i := range body {
+			e.observeResponseBody(body[i : i+1])
+		}
+		require.Equal(t, translator.LLMTokenUsage{InputTokens: 10, OutputTokens: 2 + 2, TotalTokens: 14}, e.usage())
+	})
+	t.Run("converse", func(t *testing.T) {
+		// "Hello world!" is 12 characters and "get{"city":"Tokyo"}" is 19 characters.
+		body := []byte(`{"output":{"message":{"role":"assistant","content":[{"text":"Hello world!"},
+{"toolUse":{"name":"get","toolUseId":"1","input":{"city":"Tokyo"}}}]}},"stopReason":"tool_use"}`)
+		e := newUsageEstimator(tokenizer.Heuristic{}, false, 10, converseOutput)
+		e.observeResponseBody(body)
+		require.Equal(t, translator.LLMTokenUsage{InputTokens: 10, OutputTokens: 3 + 5, TotalTokens: 18}, e.usage())
+	})
+	t.Run("converse streaming", func(t *testing.T) {
+		var body bytes.Buffer
+		enc := eventstream.NewEncoder()
+		for _, payload := range []string{
+			`{"role":"assistant"}`,
+			`{"contentBlockIndex":0,"delta":{"text":"Hello"}}`,
+			`{"contentBlockIndex":0,"delta":{"text":" world!"}}`,
+			`{"contentBlockIndex":1,"start":{"toolUse":{"name":"get","toolUseId":"1"}}}`,
+			`{"contentBlockIndex":1,"delta":{"toolUse":{"input":"{\"city\":\"Tokyo\"}"}}}`,
+			`{"stopReason":"tool_use"}`,
+		} {
+			require.NoError(t, enc.Encode(&body, eventstream.Message{Payload: []byte(payload)}))
+		}
+		e := newUsageEstimator(tokenizer.Heuristic{}, true, 10, converseOutput)
+		for _, b := range body.Bytes() {
+			e.observeResponseBody([]byte{b})
+		}
+		require.Empty(t, e.buffered)
+		require.Equal(t, translator.LLMTokenUsage{InputTokens: 10, OutputTokens: 2 + 2 + 1 + 4, TotalTokens: 19}, e.usage())
+	})
+	t.Run("embeddings", func(t *testing.T) {
+		e := newUsageEstimator(tokenizer.Heuristic{}, false, 10, responseOutput{})
+		e.observeResponseBody([]byte(`{"object":"list","data":[{"object":"embedding","embedding":[0.1],"index":0}]}`))
+		require.Empty(t, e.buffered)
+		require.Equal(t, translator.LLMTokenUsage{InputTokens: 10, TotalTokens: 10}, e.usage())
+	})
+	t.Run("invalid body", func(t *testing.T) {
+		e := newUsageEstimator(tokenizer.Heuristic{}, false, 10, chatCompletionOutput)
+		e.observeResponseBody([]byte("not json"))
+		require.Equal(t, translator.LLMTokenUsage{InputTokens: 10, TotalTokens: 10}, e.usage())
+	})
+	t.Run("fill missing", func(t *testing.T) {
+		e := newUsageEstimator(tokenizer.Heuristic{}, true, 10, chatCompletionOutput)
+		e.observeResponseBody([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hello\"}}]}\n\n"))
+		for _, tc := range []struct {
+			name      string
+			in, exp   translator.LLMTokenUsage
+			expFilled bool
+		}{
+			{name: "none", exp: translator.LLMTokenUsage{InputTokens: 10, OutputTokens: 2, TotalTokens: 12}, expFilled: true},
+			{
+				name:      "input only",
+				in:        translator.LLMTokenUsage{InputTokens: 100, TotalTokens: 100, CachedInputTokens: 50},
+				exp:       translator.LLMTokenUsage{InputTokens: 100, OutputTokens: 2, TotalTokens: 102, CachedInputTokens: 50},
+				expFilled: true,
+			},
+			{
+				name: "all",
+				in:   translator.LLMTokenUsage{InputTokens: 100, OutputTokens: 5, TotalTokens: 105},
+				exp:  translator.LLMTokenUsage{InputTokens: 100, OutputTokens: 5, TotalTokens: 105},
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				actual, filled := e.fillMissing(tc.in)
+				require.Equal(t, tc.exp, actual)
+				require.Equal(t, tc.expFilled, filled)
+			})
+		}
+	})
+}
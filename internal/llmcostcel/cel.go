@@ -11,8 +11,11 @@ package llmcostcel
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/google/cel-go/cel"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
 )
 
 const (
@@ -21,6 +24,17 @@ const (
 	celInputTokensKey  = "input_tokens"
 	celOutputTokensKey = "output_tokens"
 	celTotalTokensKey  = "total_tokens"
+	// celCachedInputTokensKey is the key for the number of the input tokens read from the cache.
+	celCachedInputTokensKey = "cached_input_tokens"
+	// celCacheWriteInputTokensKey is the key for the number of the input tokens written to the cache.
+	celCacheWriteInputTokensKey = "cache_write_input_tokens"
+	// celReasoningTokensKey is the key for the number of the output tokens generated for reasoning.
+	celReasoningTokensKey = "reasoning_tokens"
+	celSchemaKey          = "schema"
+	celHeadersKey         = "headers"
+	celUserKey            = "user"
+	celStreamKey          = "stream"
+	celMaxTokensKey       = "max_tokens"
 )
 
 var env *cel.Env
@@ -33,12 +47,50 @@ func init() {
 		cel.Variable(celInputTokensKey, cel.UintType),
 		cel.Variable(celOutputTokensKey, cel.UintType),
 		cel.Variable(celTotalTokensKey, cel.UintType),
+		cel.Variable(celCachedInputTokensKey, cel.UintType),
+		cel.Variable(celCacheWriteInputTokensKey, cel.UintType),
+		cel.Variable(celReasoningTokensKey, cel.UintType),
+		cel.Variable(celSchemaKey, cel.StringType),
+		cel.Variable(celHeadersKey, cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable(celUserKey, cel.StringType),
+		cel.Variable(celStreamKey, cel.BoolType),
+		cel.Variable(celMaxTokensKey, cel.UintType),
 	)
 	if err != nil {
 		panic(fmt.Sprintf("cannot create CEL environment: %v", err))
 	}
 }
 
+// Variables are the values of the variables available to the CEL expression.
+type Variables struct {
+	// Model is the model name extracted from the request content.
+	Model string
+	// Backend is the backend name in the form of "name.namespace".
+	Backend string
+	// Schema is the name of the API schema of the backend, e.g. "OpenAI".
+	Schema string
+	// Headers are the request headers keyed by the lower-cased names.
+	Headers map[string]string
+	// User is the "user" field of the request body, or empty if not set.
+	User string
+	// Stream is true if the response is streamed.
+	Stream bool
+	// MaxTokens is the maximum number of the output tokens set in the request, or zero if not set.
+	MaxTokens uint32
+	// InputTokens is the number of input tokens.
+	InputTokens uint32
+	// OutputTokens is the number of output tokens.
+	OutputTokens uint32
+	// TotalTokens is the total number of tokens.
+	TotalTokens uint32
+	// CachedInputTokens is the number of the input tokens read from the cache.
+	CachedInputTokens uint32
+	// CacheWriteInputTokens is the number of the input tokens written to the cache.
+	CacheWriteInputTokens uint32
+	// ReasoningTokens is the number of the output tokens generated for reasoning.
+	ReasoningTokens uint32
+}
+
 // NewProgram creates a new CEL program from the given expression.
 func NewProgram(expr string) (prog cel.Program, err error) {
 	ast, issues := env.Compile(expr)
@@ -52,7 +104,7 @@ func NewProgram(expr string) (prog cel.Program, err error) {
 	}
 
 	// Sanity check by evaluating the expression with some dummy values.
-	_, err = EvaluateProgram(prog, "dummy", "dummy", 0, 0, 0)
+	_, err = EvaluateProgram(prog, &Variables{Model: "dummy", Backend: "dummy", Schema: "dummy"}, 1, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate CEL expression: %w", err)
 	}
@@ -60,17 +112,35 @@ func NewProgram(expr string) (prog cel.Program, err error) {
 }
 
 // EvaluateProgram evaluates the given CEL program with the given variables.
-func EvaluateProgram(prog cel.Program, modelName, backend string, inputTokens, outputTokens, totalTokens uint32) (uint64, error) {
+//
+// The result of the expression is multiplied by the scale, where zero is treated as 1. When the result is a double,
+// it is then rounded to an integer with the given rounding, where empty is treated as [filterapi.LLMRequestCostRoundingRound].
+func EvaluateProgram(prog cel.Program, vars *Variables, scale int64, rounding filterapi.LLMRequestCostRounding) (uint64, error) {
+	headers := vars.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
 	out, _, err := prog.Eval(map[string]interface{}{
-		celModelNameKey:    modelName,
-		celBackendKey:      backend,
-		celInputTokensKey:  inputTokens,
-		celOutputTokensKey: outputTokens,
-		celTotalTokensKey:  totalTokens,
+		celModelNameKey:             vars.Model,
+		celBackendKey:               vars.Backend,
+		celInputTokensKey:           vars.InputTokens,
+		celOutputTokensKey:          vars.OutputTokens,
+		celTotalTokensKey:           vars.TotalTokens,
+		celCachedInputTokensKey:     vars.CachedInputTokens,
+		celCacheWriteInputTokensKey: vars.CacheWriteInputTokens,
+		celReasoningTokensKey:       vars.ReasoningTokens,
+		celSchemaKey:                vars.Schema,
+		celHeadersKey:               headers,
+		celUserKey:                  vars.User,
+		celStreamKey:                vars.Stream,
+		celMaxTokensKey:             vars.MaxTokens,
 	})
 	if err != nil || out == nil {
 		return 0, fmt.Errorf("failed to evaluate CEL expression: %w", err)
 	}
+	if scale == 0 {
+		scale = 1
+	}
 
 	switch out.Type() {
 	case cel.IntType:
@@ -78,10 +148,39 @@ func EvaluateProgram(prog cel.Program, modelName, backend string, inputTokens, o
 		if result < 0 {
 			return 0, fmt.Errorf("CEL expression result is negative (%d)", result)
 		}
-		return uint64(result), nil
+		return uint64(result) * uint64(scale), nil //nolint:gosec
 	case cel.UintType:
-		return out.Value().(uint64), nil
+		return out.Value().(uint64) * uint64(scale), nil //nolint:gosec
+	case cel.DoubleType:
+		result, err := ScaleDouble(out.Value().(float64), scale, rounding)
+		if err != nil {
+			return 0, fmt.Errorf("CEL expression result is %w", err)
+		}
+		return result, nil
 	default:
-		return 0, fmt.Errorf("CEL expression result is not an integer, got %v", out.Type())
+		return 0, fmt.Errorf("CEL expression result is not a number, got %v", out.Type())
+	}
+}
+
+// ScaleDouble multiplies the given double by the scale, where zero is treated as 1, and rounds it to an integer
+// with the given rounding, where empty is treated as [filterapi.LLMRequestCostRoundingRound].
+func ScaleDouble(v float64, scale int64, rounding filterapi.LLMRequestCostRounding) (uint64, error) {
+	if scale == 0 {
+		scale = 1
+	}
+	v *= float64(scale)
+	switch rounding {
+	case filterapi.LLMRequestCostRoundingFloor:
+		v = math.Floor(v)
+	case filterapi.LLMRequestCostRoundingCeil:
+		v = math.Ceil(v)
+	default:
+		v = math.Round(v)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("negative (%g)", v)
+	} else if math.IsNaN(v) || v >= math.MaxUint64 {
+		return 0, fmt.Errorf("out of range (%g)", v)
 	}
+	return uint64(v), nil
 }
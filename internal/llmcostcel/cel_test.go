@@ -10,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/envoyproxy/ai-gateway/filterapi"
 )
 
 func TestNewProgram(t *testing.T) {
@@ -28,14 +30,21 @@ func TestNewProgram(t *testing.T) {
 	t.Run("variables", func(t *testing.T) {
 		prog, err := NewProgram("model == 'cool_model' ?  input_tokens * output_tokens : total_tokens")
 		require.NoError(t, err)
-		v, err := EvaluateProgram(prog, "cool_model", "cool_backend", 100, 2, 3)
+		v, err := EvaluateProgram(prog, &Variables{Model: "cool_model", Backend: "cool_backend", InputTokens: 100, OutputTokens: 2, TotalTokens: 3}, 1, "")
 		require.NoError(t, err)
 		require.Equal(t, uint64(200), v)
 
-		v, err = EvaluateProgram(prog, "not_cool_model", "cool_backend", 100, 2, 3)
+		v, err = EvaluateProgram(prog, &Variables{Model: "not_cool_model", Backend: "cool_backend", InputTokens: 100, OutputTokens: 2, TotalTokens: 3}, 1, "")
 		require.NoError(t, err)
 		require.Equal(t, uint64(3), v)
 	})
+	t.Run("cached and reasoning tokens", func(t *testing.T) {
+		prog, err := NewProgram("(input_tokens - cached_input_tokens) * 10u + cached_input_tokens + cache_write_input_tokens * 2u + reasoning_tokens")
+		require.NoError(t, err)
+		v, err := EvaluateProgram(prog, &Variables{Model: "cool_model", Backend: "cool_backend", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, CachedInputTokens: 80, CacheWriteInputTokens: 10, ReasoningTokens: 30}, 1, "")
+		require.NoError(t, err)
+		require.Equal(t, uint64(20*10+80+10*2+30), v)
+	})
 
 	t.Run("uint", func(t *testing.T) {
 		_, err := NewProgram("uint(1)-uint(1200)")
@@ -61,15 +70,74 @@ func TestEvaluateProgram(t *testing.T) {
 	t.Run("signed integer negative", func(t *testing.T) {
 		prog, err := NewProgram("int(input_tokens) - int(output_tokens)")
 		require.NoError(t, err)
-		_, err = EvaluateProgram(prog, "cool_model", "cool_backend", 100, 2000, 3)
+		_, err = EvaluateProgram(prog, &Variables{Model: "cool_model", Backend: "cool_backend", InputTokens: 100, OutputTokens: 2000, TotalTokens: 3}, 1, "")
 		require.ErrorContains(t, err, "CEL expression result is negative (-1900)")
 	})
 	t.Run("unsigned integer overflow", func(t *testing.T) {
 		prog, err := NewProgram("input_tokens - output_tokens")
 		require.NoError(t, err)
-		_, err = EvaluateProgram(prog, "cool_model", "cool_backend", 100, 2000, 3)
+		_, err = EvaluateProgram(prog, &Variables{Model: "cool_model", Backend: "cool_backend", InputTokens: 100, OutputTokens: 2000, TotalTokens: 3}, 1, "")
 		require.ErrorContains(t, err, "failed to evaluate CEL expression: unsigned integer overflow")
 	})
+	t.Run("request parameters", func(t *testing.T) {
+		prog, err := NewProgram("('x-tenant' in headers && headers['x-tenant'] == 'free') || user == 'admin' ? 0u : " +
+			"(stream && schema == 'AWSBedrock' ? max_tokens : total_tokens)")
+		require.NoError(t, err)
+		for _, tc := range []struct {
+			name string
+			vars Variables
+			exp  uint64
+		}{
+			{name: "free tenant", vars: Variables{Headers: map[string]string{"x-tenant": "free"}, TotalTokens: 10}, exp: 0},
+			{name: "admin", vars: Variables{User: "admin", TotalTokens: 10}, exp: 0},
+			{name: "no headers", vars: Variables{TotalTokens: 10}, exp: 10},
+			{name: "stream", vars: Variables{Stream: true, Schema: "AWSBedrock", MaxTokens: 100, TotalTokens: 10}, exp: 100},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				v, err := EvaluateProgram(prog, &tc.vars, 1, "")
+				require.NoError(t, err)
+				require.Equal(t, tc.exp, v)
+			})
+		}
+	})
+	t.Run("scale", func(t *testing.T) {
+		prog, err := NewProgram("input_tokens + output_tokens")
+		require.NoError(t, err)
+		v, err := EvaluateProgram(prog, &Variables{InputTokens: 1, OutputTokens: 2}, 1000, "")
+		require.NoError(t, err)
+		require.Equal(t, uint64(3000), v)
+	})
+	t.Run("double", func(t *testing.T) {
+		// $3 per million input tokens and $15 per million output tokens in dollars.
+		prog, err := NewProgram("(double(input_tokens) * 3.0 + double(output_tokens) * 15.0) / 1000000.0")
+		require.NoError(t, err)
+		vars := &Variables{InputTokens: 1001, OutputTokens: 1}
+		// The cost is 0.003018 dollars.
+		for _, tc := range []struct {
+			scale    int64
+			rounding filterapi.LLMRequestCostRounding
+			exp      uint64
+		}{
+			{scale: 1000000, exp: 3018},
+			{scale: 1000, rounding: filterapi.LLMRequestCostRoundingRound, exp: 3},
+			{scale: 1000, rounding: filterapi.LLMRequestCostRoundingFloor, exp: 3},
+			{scale: 1000, rounding: filterapi.LLMRequestCostRoundingCeil, exp: 4},
+		} {
+			v, err := EvaluateProgram(prog, vars, tc.scale, tc.rounding)
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, v, tc.rounding)
+		}
+	})
+	t.Run("double negative", func(t *testing.T) {
+		prog, err := NewProgram("10.0 - double(input_tokens)")
+		require.NoError(t, err)
+		_, err = EvaluateProgram(prog, &Variables{InputTokens: 20}, 1, "")
+		require.ErrorContains(t, err, "CEL expression result is negative (-10)")
+	})
+	t.Run("not a number", func(t *testing.T) {
+		_, err := NewProgram("model")
+		require.ErrorContains(t, err, "CEL expression result is not a number, got string")
+	})
 	t.Run("ensure concurrency safety", func(t *testing.T) {
 		prog, err := NewProgram("model == 'cool_model' ?  input_tokens * output_tokens : total_tokens")
 		require.NoError(t, err)
@@ -80,7 +148,7 @@ func TestEvaluateProgram(t *testing.T) {
 		for i := 0; i < 100; i++ {
 			go func() {
 				defer wg.Done()
-				v, err := EvaluateProgram(prog, "cool_model", "cool_backend", 100, 2, 3)
+				v, err := EvaluateProgram(prog, &Variables{Model: "cool_model", Backend: "cool_backend", InputTokens: 100, OutputTokens: 2, TotalTokens: 3}, 1, "")
 				require.NoError(t, err)
 				require.Equal(t, uint64(200), v)
 			}()
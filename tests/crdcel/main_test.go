@@ -32,17 +32,39 @@ func TestAIGatewayRoutes(t *testing.T) {
 	}{
 		{name: "basic.yaml"},
 		{name: "llmcosts.yaml"},
+		{name: "aws_bedrock_schema.yaml"},
+		{name: "header_matches.yaml"},
+		{name: "consistent_hash.yaml"},
 		{
 			name:   "non_openai_schema.yaml",
-			expErr: `spec.schema: Invalid value: "object": failed rule: self.name == 'OpenAI'`,
+			expErr: `spec.schema: Invalid value: "object": failed rule: self.name == 'OpenAI' || self.name == 'AWSBedrock'`,
 		},
 		{
 			name:   "unknown_schema.yaml",
-			expErr: "spec.schema.name: Unsupported value: \"SomeRandomVendor\": supported values: \"OpenAI\", \"AWSBedrock\"",
+			expErr: "spec.schema.name: Unsupported value: \"SomeRandomVendor\": supported values: \"OpenAI\", \"AWSBedrock\", \"Anthropic\", \"AzureOpenAI\", \"GCPVertexAI\"",
 		},
 		{
 			name:   "unsupported_match.yaml",
-			expErr: "spec.rules[0].matches[0].headers: Invalid value: \"array\": currently only exact match is supported",
+			expErr: "spec.rules[0].matches[0].headers[0].type: Unsupported value: \"Suffix\": supported values: \"Exact\", \"Prefix\", \"RegularExpression\"",
+		},
+		{
+			name:   "consistent_hash_no_header.yaml",
+			expErr: `spec.rules[0].loadBalancingPolicy.consistentHash: Invalid value: "object": header must be set if and only if the type is Header`,
+		},
+		{
+			name:   "llmcosts_scale_non_cel.yaml",
+			expErr: `spec.llmRequestCosts[2]: Invalid value: "object": scale and rounding can only be set for the CEL and Price types`,
+		},
+		{name: "token_budgets.yaml"},
+		{name: "request_limits.yaml"},
+		{name: "gateway_api_keys.yaml"},
+		{
+			name:   "llmcosts_price_no_pricing_name.yaml",
+			expErr: `spec.llmRequestCosts[1]: Invalid value: "object": llmPricingName must be set if and only if the type is Price`,
+		},
+		{
+			name:   "invalid_token_range.yaml",
+			expErr: `spec.rules[0].matches[2].estimatedInputTokens: Invalid value: "object": min must be less than max`,
 		},
 		{
 			name:   "no_target_refs.yaml",
@@ -77,9 +99,14 @@ func TestAIServiceBackends(t *testing.T) {
 	}{
 		{name: "basic.yaml"},
 		{name: "basic-eg-backend.yaml"},
+		{name: "azure_openai.yaml"},
+		{
+			name:   "deployments_non_azure.yaml",
+			expErr: "spec.schema: Invalid value: \"object\": deployments can only be set for the AzureOpenAI schema",
+		},
 		{
 			name:   "unknown_schema.yaml",
-			expErr: "spec.schema.name: Unsupported value: \"SomeRandomVendor\": supported values: \"OpenAI\", \"AWSBedrock\"",
+			expErr: "spec.schema.name: Unsupported value: \"SomeRandomVendor\": supported values: \"OpenAI\", \"AWSBedrock\", \"Anthropic\", \"AzureOpenAI\", \"GCPVertexAI\"",
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -111,11 +138,11 @@ func TestBackendSecurityPolicies(t *testing.T) {
 		{name: "basic.yaml"},
 		{
 			name:   "unknown_provider.yaml",
-			expErr: "spec.type: Unsupported value: \"UnknownType\": supported values: \"APIKey\", \"AWSCredentials\"",
+			expErr: "spec.type: Unsupported value: \"UnknownType\": supported values: \"APIKey\", \"AWSCredentials\", \"AzureCredentials\"",
 		},
 		{
 			name:   "missing_type.yaml",
-			expErr: "spec.type: Unsupported value: \"\": supported values: \"APIKey\", \"AWSCredentials\"",
+			expErr: "spec.type: Unsupported value: \"\": supported values: \"APIKey\", \"AWSCredentials\", \"AzureCredentials\"",
 		},
 		{
 			name:   "multiple_security_policies.yaml",
@@ -123,6 +150,12 @@ func TestBackendSecurityPolicies(t *testing.T) {
 		},
 		{name: "aws_credential_file.yaml"},
 		{name: "aws_oidc.yaml"},
+		{name: "api_key_header_name.yaml"},
+		{name: "azure_credentials.yaml"},
+		{
+			name:   "azure_credentials_missing_tenant.yaml",
+			expErr: "spec.azureCredentials.tenantID: Invalid value: \"\": spec.azureCredentials.tenantID in body should be at least 1 chars long",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			data, err := testdata.ReadFile(path.Join("testdata/backendsecuritypolicies", tc.name))
@@ -141,3 +174,75 @@ func TestBackendSecurityPolicies(t *testing.T) {
 		})
 	}
 }
+
+func TestLLMPricings(t *testing.T) {
+	c, _, _ := testsinternal.NewEnvTest(t)
+	ctx := t.Context()
+
+	for _, tc := range []struct {
+		name   string
+		expErr string
+	}{
+		{name: "basic.yaml"},
+		{
+			name:   "invalid_price.yaml",
+			expErr: "spec.models[0].inputTokenPrice: Invalid value: \"$2.5\": spec.models[0].inputTokenPrice in body should match '^[0-9]+(\\.[0-9]+)?$'",
+		},
+		{
+			name:   "no_models.yaml",
+			expErr: "spec.models: Invalid value: 0: spec.models in body should have at least 1 items",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := testdata.ReadFile(path.Join("testdata/llmpricings", tc.name))
+			require.NoError(t, err)
+
+			llmPricing := &aigv1a1.LLMPricing{}
+			err = yaml.UnmarshalStrict(data, llmPricing)
+			require.NoError(t, err)
+
+			if tc.expErr != "" {
+				require.ErrorContains(t, c.Create(ctx, llmPricing), tc.expErr)
+			} else {
+				require.NoError(t, c.Create(ctx, llmPricing))
+				require.NoError(t, c.Delete(ctx, llmPricing))
+			}
+		})
+	}
+}
+
+func TestGatewayAPIKeys(t *testing.T) {
+	c, _, _ := testsinternal.NewEnvTest(t)
+	ctx := t.Context()
+
+	for _, tc := range []struct {
+		name   string
+		expErr string
+	}{
+		{name: "basic.yaml"},
+		{
+			name:   "plain_key.yaml",
+			expErr: "spec.keyHash: Invalid value: \"sk-plain-key\": spec.keyHash in body should match '^[0-9a-f]{64}$'",
+		},
+		{
+			name:   "no_owner.yaml",
+			expErr: "spec.owner: Invalid value: \"\": spec.owner in body should be at least 1 chars long",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := testdata.ReadFile(path.Join("testdata/gatewayapikeys", tc.name))
+			require.NoError(t, err)
+
+			gatewayAPIKey := &aigv1a1.GatewayAPIKey{}
+			err = yaml.UnmarshalStrict(data, gatewayAPIKey)
+			require.NoError(t, err)
+
+			if tc.expErr != "" {
+				require.ErrorContains(t, c.Create(ctx, gatewayAPIKey), tc.expErr)
+			} else {
+				require.NoError(t, c.Create(ctx, gatewayAPIKey))
+				require.NoError(t, c.Delete(ctx, gatewayAPIKey))
+			}
+		})
+	}
+}